@@ -24,6 +24,7 @@ class LivenessResult:
     matched_gestures: Sequence[str] = field(default_factory=tuple)
     missing_gestures: Sequence[str] = field(default_factory=tuple)
     error: str | None = None
+    recorded_video_url: str | None = None
 
 
 class GestureDetector(ABC):
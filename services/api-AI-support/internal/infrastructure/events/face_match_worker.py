@@ -53,7 +53,13 @@ class FaceMatchWorker:
                 "error": result.error,
             },
         }
-        self._backoffice.record_face_checks(result.session_id, [check], overall, status)
+        self._backoffice.record_face_checks(
+            result.session_id,
+            [check],
+            overall,
+            status,
+            idempotency_key=f"face-match:{job.job_id}",
+        )
 
 
 def _decode(value: str) -> bytes:
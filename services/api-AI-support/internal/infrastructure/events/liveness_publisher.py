@@ -45,6 +45,7 @@ class RabbitLivenessResultPublisher(LivenessResultPublisher):
             "matched": list(result.matched_gestures),
             "missing": list(result.missing_gestures),
             "error": result.error,
+            "recorded_video_url": result.recorded_video_url,
             "completed_at": datetime.now(timezone.utc).isoformat(),
         }
         headers = {"x-job-type": "liveness.result"}
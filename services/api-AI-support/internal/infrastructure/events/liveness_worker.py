@@ -2,6 +2,7 @@ from __future__ import annotations
 
 import base64
 import json
+from dataclasses import replace
 from typing import Dict, Optional
 
 from internal.domain.liveness import (
@@ -51,8 +52,10 @@ class LivenessWorker:
             mime_type=payload.get("mime_type"),
         )
         result = self._processor.evaluate(job)
-        self._result_publisher.publish(result)
         video_url = self._upload_video(job)
+        # The backoffice may consume the result queue directly, so the event carries the video too.
+        result = replace(result, recorded_video_url=video_url)
+        self._result_publisher.publish(result)
         per_gesture = {gesture: "PASS" for gesture in result.matched_gestures}
         for gesture in result.missing_gestures:
             per_gesture[gesture] = "MISSING"
@@ -66,6 +69,7 @@ class LivenessWorker:
             status,
             video_url,
             metadata,
+            idempotency_key=f"liveness:{job.job_id}",
         )
 
     def _upload_video(self, job: LivenessJob) -> Optional[str]:
@@ -20,13 +20,14 @@ class BackofficeEkycClient:
         checks: List[Dict[str, Any]],
         overall: str,
         status: str,
+        idempotency_key: Optional[str] = None,
     ) -> None:
         payload = {
             "checks": checks,
             "overallResult": overall,
             "status": status,
         }
-        self._post(f"/api/ekyc/sessions/{session_id}/face-checks", payload)
+        self._post(f"/api/ekyc/sessions/{session_id}/face-checks", payload, idempotency_key)
 
     def record_liveness(
         self,
@@ -36,6 +37,7 @@ class BackofficeEkycClient:
         status: str,
         video_url: Optional[str] = None,
         metadata: Optional[Dict[str, Any]] = None,
+        idempotency_key: Optional[str] = None,
     ) -> None:
         payload = {
             "overallResult": overall,
@@ -44,12 +46,31 @@ class BackofficeEkycClient:
             "status": status,
             "rawMetadata": metadata or {},
         }
-        self._post(f"/api/ekyc/sessions/{session_id}/liveness", payload)
+        self._post(f"/api/ekyc/sessions/{session_id}/liveness", payload, idempotency_key)
 
-    def _post(self, path: str, payload: Dict[str, Any]) -> None:
+    def record_ocr(
+        self,
+        session_id: str,
+        fields: Dict[str, Optional[str]],
+        confidence: Dict[str, float],
+        metadata: Optional[Dict[str, Any]] = None,
+        idempotency_key: Optional[str] = None,
+    ) -> None:
+        payload = {
+            "nik": fields.get("nik"),
+            "name": fields.get("name"),
+            "birthDate": fields.get("birthDate"),
+            "address": fields.get("address"),
+            "confidence": confidence,
+            "rawMetadata": metadata or {},
+        }
+        self._post(f"/api/ekyc/sessions/{session_id}/ocr", payload, idempotency_key)
+
+    def _post(self, path: str, payload: Dict[str, Any], idempotency_key: Optional[str] = None) -> None:
         url = f"{self._base_url}{path}"
+        headers = {"Idempotency-Key": idempotency_key} if idempotency_key else None
         try:
-            response = self._session.post(url, json=payload, timeout=self._timeout)
+            response = self._session.post(url, json=payload, headers=headers, timeout=self._timeout)
             if response.status_code >= 400:
                 logger.warning(
                     "backoffice call %s failed with %s: %s",
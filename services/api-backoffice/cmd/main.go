@@ -2,10 +2,18 @@ package main
 
 import (
 	"context"
+	"e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/services/api-backoffice/internal/infrastructure/aisupport"
+	"e-kyc/services/api-backoffice/internal/infrastructure/bankfile"
 	db "e-kyc/services/api-backoffice/internal/infrastructure/database"
+	"e-kyc/services/api-backoffice/internal/infrastructure/events"
 	httpInfra "e-kyc/services/api-backoffice/internal/infrastructure/http"
+	"e-kyc/services/api-backoffice/internal/infrastructure/media"
 	"e-kyc/services/api-backoffice/internal/infrastructure/repository"
+	"e-kyc/services/api-backoffice/internal/infrastructure/webhook"
 	"e-kyc/services/api-backoffice/internal/service"
+	"e-kyc/shared/geo"
+	"e-kyc/shared/pii"
 	"errors"
 	"log"
 	"net/http"
@@ -14,6 +22,7 @@ import (
 	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // visit scheduling resolves the configured IANA timezone
 )
 
 const defaultAddr = ":8081"
@@ -29,10 +38,17 @@ func main() {
 	}
 	defer pool.Close()
 
+	piiKeys, err := pii.LoadKeyring()
+	if err != nil {
+		log.Fatalf("api-backoffice: load pii keyring: %v", err)
+	}
+
 	// REPOSITORIES
 	appRepo := repository.NewApplicationRepository(nil, pool)
-	authRepo := repository.NewAuthRepository(pool)
-	backofficeRepo := repository.NewBackofficeRepository(pool)
+	authRepo := repository.NewAuthRepository(pool, piiKeys)
+	backofficeRepo := repository.NewBackofficeRepository(pool, piiKeys)
+	idempotencyRepo := repository.NewIdempotencyRepository(pool)
+	webhookRepo := repository.NewWebhookRepository(pool)
 
 	jwtSecret := resolveJWTSecret()
 	sessionManager, err := service.NewJWTSessionManager(jwtSecret)
@@ -40,26 +56,94 @@ func main() {
 		log.Fatalf("api-backoffice: init jwt manager: %v", err)
 	}
 
+	// CLIENTS
+	var aiClient domain.AiSupportClient
+	if endpoint := os.Getenv("AI_SUPPORT_GRPC_ENDPOINT"); endpoint != "" {
+		client, err := aisupport.NewClient(endpoint)
+		if err != nil {
+			log.Fatalf("api-backoffice: init ai support client: %v", err)
+		}
+		defer client.Close()
+		aiClient = client
+	} else {
+		log.Println("api-backoffice: AI_SUPPORT_GRPC_ENDPOINT not set, verification re-runs disabled")
+	}
+	mediaClient := media.NewClient(nil, resolveMediaStorageURL())
+	webhookSender := webhook.NewSender(nil)
+	sessionEvents := events.NewSessionEventHub(pool.Config().ConnConfig.Copy())
+
 	// SERVICES
 	applicationService := service.NewApplicationService(appRepo)
 	authSvc := service.NewAuthService(authRepo, sessionManager)
-	backofficeSvc := service.NewBackofficeService(backofficeRepo)
-	ekycSvc := service.NewEkycService(backofficeRepo)
+	boundaries := loadRegionBoundaries()
+	var visitGeofence *service.VisitGeofence
+	if boundaries != nil {
+		visitGeofence = service.NewVisitGeofence(boundaries, backofficeRepo)
+	}
+	backofficeSvc := service.NewBackofficeService(backofficeRepo, visitGeofence)
+	ekycSvc := service.NewEkycService(backofficeRepo, aiClient, mediaClient)
+	webhookSvc := service.NewWebhookService(webhookRepo)
+	retentionSvc := service.NewRetentionService(backofficeRepo, mediaClient)
+	dataSubjectSvc := service.NewDataSubjectService(backofficeRepo, mediaClient)
+	visitScheduler := service.NewVisitScheduler(backofficeRepo)
+	visitSyncSvc := service.NewVisitSyncService(backofficeRepo, visitGeofence)
+	checklistSvc := service.NewChecklistTemplateService(backofficeRepo)
+	visitRoutePlanner := service.NewVisitRoutePlanner(backofficeRepo, boundaries)
+	visitPhotoSvc := service.NewVisitPhotoService(backofficeRepo, mediaClient)
+	visitReviewSvc := service.NewVisitReviewService(backofficeRepo)
+	calendarFeedSvc := service.NewCalendarFeedService(backofficeRepo)
+	batchManifestSvc := service.NewBatchManifestService(backofficeRepo)
+	disbursementExportSvc := service.NewDisbursementExportService(backofficeRepo, mediaClient,
+		bankfile.CSVExporter{}, bankfile.Pain001Exporter{})
+	batchReconciliationSvc := service.NewBatchReconciliationService(backofficeRepo, mediaClient,
+		bankfile.CSVResultParser{}, bankfile.Pain002Parser{})
 
 	// HANDLERS
 	appHandler := httpInfra.NewApplicationHandler(applicationService)
 	authHandler := httpInfra.NewAuthHTTPHandler(authSvc)
 	backofficeHandler := httpInfra.NewBackofficeHTTPHandler(backofficeSvc)
-	ekycHandler := httpInfra.NewEkycHTTPHandler(ekycSvc)
+	ekycHandler := httpInfra.NewEkycHTTPHandler(ekycSvc, authSvc, sessionEvents)
 	portalHandler := httpInfra.NewPortalHTTPHandler(backofficeSvc)
+	webhookHandler := httpInfra.NewWebhookHTTPHandler(webhookSvc)
+	retentionHandler := httpInfra.NewRetentionHTTPHandler(retentionSvc)
+	dataSubjectHandler := httpInfra.NewDataSubjectHTTPHandler(dataSubjectSvc)
+	visitScheduleHandler := httpInfra.NewVisitScheduleHTTPHandler(visitScheduler)
+	visitSyncHandler := httpInfra.NewVisitSyncHTTPHandler(visitSyncSvc)
+	checklistHandler := httpInfra.NewChecklistTemplateHTTPHandler(checklistSvc)
+	visitRouteHandler := httpInfra.NewVisitRouteHTTPHandler(visitRoutePlanner)
+	visitPhotoHandler := httpInfra.NewVisitPhotoHTTPHandler(visitPhotoSvc)
+	visitReviewHandler := httpInfra.NewVisitReviewHTTPHandler(visitReviewSvc, authSvc)
+	calendarFeedHandler := httpInfra.NewCalendarFeedHTTPHandler(calendarFeedSvc, authSvc)
+	batchManifestHandler := httpInfra.NewBatchManifestHTTPHandler(batchManifestSvc)
+	disbursementExportHandler := httpInfra.NewDisbursementExportHTTPHandler(disbursementExportSvc)
+	batchReconciliationHandler := httpInfra.NewBatchReconciliationHTTPHandler(batchReconciliationSvc)
+	idempotencyTTL := resolveIdempotencyTTL()
+	idempotency := httpInfra.NewIdempotencyMiddleware(idempotencyRepo, authSvc, idempotencyTTL)
 
 	// SERVER
-	server := httpInfra.NewServer(appHandler, backofficeHandler, authHandler, ekycHandler, portalHandler)
+	server := httpInfra.NewServer(appHandler, backofficeHandler, authHandler, ekycHandler, portalHandler, webhookHandler, retentionHandler, dataSubjectHandler,
+		visitScheduleHandler, visitSyncHandler, checklistHandler, visitRouteHandler, visitPhotoHandler, visitReviewHandler, calendarFeedHandler,
+		batchManifestHandler, disbursementExportHandler, batchReconciliationHandler, idempotency)
 
 	// GRACEFUL SHUTDOWN BY ECHO
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// BACKGROUND JOBS
+	go service.NewIdempotencySweeper(idempotencyRepo, time.Hour).Run(ctx)
+	go sessionEvents.Run(ctx)
+	go service.NewWebhookDispatcher(webhookRepo, webhookSender, service.DefaultWebhookRetryConfig(), 5*time.Second).Run(ctx)
+	go service.NewRetentionPurger(retentionSvc, 24*time.Hour).Run(ctx)
+	if rabbitURL := os.Getenv("AI_SUPPORT_RABBIT_URL"); rabbitURL != "" {
+		consumer := events.NewAMQPConsumer(rabbitURL, events.DefaultRetryConfig())
+		defer consumer.Close()
+		processor := service.NewAiResultProcessor(ekycSvc,
+			os.Getenv("AI_SUPPORT_FACE_RESULT_QUEUE"), os.Getenv("AI_SUPPORT_LIVENESS_RESULT_QUEUE"))
+		go processor.Run(ctx, consumer)
+	} else {
+		log.Println("api-backoffice: AI_SUPPORT_RABBIT_URL not set, relying on HTTP callbacks for AI results")
+	}
+
 	go func() {
 		if err := server.Start(addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatalf("api-backoffice: server error: %v", err)
@@ -117,3 +201,43 @@ func resolveJWTSecret() string {
 	log.Println("api-backoffice: BACKOFFICE_JWT_SECRET not set, using default development secret")
 	return defaultJWTSecret
 }
+
+const defaultIdempotencyTTL = 24 * time.Hour
+
+func resolveIdempotencyTTL() time.Duration {
+	if fromEnv := os.Getenv("BACKOFFICE_IDEMPOTENCY_TTL"); fromEnv != "" {
+		if ttl, err := time.ParseDuration(fromEnv); err == nil && ttl > 0 {
+			return ttl
+		}
+		log.Printf("api-backoffice: invalid BACKOFFICE_IDEMPOTENCY_TTL %q, using %s", fromEnv, defaultIdempotencyTTL)
+	}
+	return defaultIdempotencyTTL
+}
+
+const defaultMediaStorageURL = "http://127.0.0.1:8090"
+
+func resolveMediaStorageURL() string {
+	if fromEnv := os.Getenv("BACKOFFICE_MEDIA_STORAGE_URL"); fromEnv != "" {
+		return fromEnv
+	}
+	return defaultMediaStorageURL
+}
+
+const defaultRegionBoundariesFile = "shared/dataset/kelurahan_boundaries.geojson"
+
+// loadRegionBoundaries reads the kelurahan boundaries submitted visits are checked
+// against and routes fall back to. Without them the geofence check is disabled rather
+// than flagging every visit, and routes only use earlier visit geotags.
+func loadRegionBoundaries() *geo.Boundaries {
+	path := defaultRegionBoundariesFile
+	if fromEnv := os.Getenv("BACKOFFICE_REGION_BOUNDARIES_FILE"); fromEnv != "" {
+		path = fromEnv
+	}
+	boundaries, err := geo.LoadBoundaries(path)
+	if err != nil {
+		log.Printf("api-backoffice: load region boundaries %s: %v, visit geofence check disabled", path, err)
+		return nil
+	}
+	log.Printf("api-backoffice: loaded %d kelurahan boundaries from %s", boundaries.Len(), path)
+	return boundaries
+}
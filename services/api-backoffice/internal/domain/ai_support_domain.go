@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrAiSupportUnavailable is returned when the AI support service cannot be reached or
+// is not configured for this deployment.
+var ErrAiSupportUnavailable = errors.New("ai support service unavailable")
+
+// ErrMediaOutsideStorage is returned for media URLs that do not point into media storage.
+// Such URLs come from clients and are never fetched.
+var ErrMediaOutsideStorage = errors.New("outside media storage")
+
+// DefaultFaceMatchThreshold applies when thresholds.face_min is not configured.
+const DefaultFaceMatchThreshold = 0.8
+
+type BinaryImage struct {
+	Content  []byte
+	MimeType string
+}
+
+type StartFaceMatchJobPayload struct {
+	SessionID          string
+	KtpImage           BinaryImage
+	SelfieImage        BinaryImage
+	FaceMatchThreshold float64
+}
+
+type StartLivenessJobPayload struct {
+	SessionID      string
+	LivenessFrames []BinaryImage
+	Gestures       []string
+}
+
+// AsyncJobHandle identifies a job queued on the AI support service. Results arrive
+// later through the face-check and liveness callbacks.
+type AsyncJobHandle struct {
+	JobID string `json:"jobId"`
+	Queue string `json:"queue"`
+}
+
+type AiSupportClient interface {
+	StartFaceMatchJob(ctx context.Context, payload StartFaceMatchJobPayload) (*AsyncJobHandle, error)
+	StartLivenessJob(ctx context.Context, payload StartLivenessJobPayload) (*AsyncJobHandle, error)
+}
+
+// MediaClient reads stored artifacts back from the media storage service and deletes
+// them once their retention period has passed. Backoffice uploads, such as visit photos,
+// go through it too.
+type MediaClient interface {
+	Download(ctx context.Context, url string) (*BinaryImage, error)
+	// Upload stores content and returns its URL.
+	Upload(ctx context.Context, filename, mimeType string, content []byte) (string, error)
+	// Delete removes the media behind url. Media that is already gone is not an error.
+	Delete(ctx context.Context, url string) error
+}
@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// Granularities and region levels accepted by the eKYC funnel report.
+var (
+	FunnelGranularities = []string{"day", "week"}
+	FunnelRegionLevels  = []string{"prov", "kab", "kec", "kel"}
+)
+
+// DefaultFunnelWindow is the reporting period when no range is given.
+const DefaultFunnelWindow = 30 * 24 * time.Hour
+
+// EkycFunnelParams selects the sessions of the funnel report by creation time. Buckets
+// are cut in WIB. RegionLevel splits buckets by the linked user's region; Region filters
+// on it.
+type EkycFunnelParams struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+	RegionLevel string
+	Region      string
+}
+
+// EkycFunnelBucket counts one period (and region, when split). Rates are nil when their
+// denominator is zero.
+type EkycFunnelBucket struct {
+	Period                time.Time `json:"period"`
+	Region                *string   `json:"region,omitempty"`
+	SessionsCreated       int       `json:"sessionsCreated"`
+	ArtifactsUploaded     int       `json:"artifactsUploaded"`
+	FaceChecked           int       `json:"faceChecked"`
+	FacePassed            int       `json:"facePassed"`
+	FacePassRate          *float64  `json:"facePassRate,omitempty"`
+	LivenessChecked       int       `json:"livenessChecked"`
+	LivenessPassed        int       `json:"livenessPassed"`
+	LivenessPassRate      *float64  `json:"livenessPassRate,omitempty"`
+	Decided               int       `json:"decided"`
+	Approved              int       `json:"approved"`
+	Rejected              int       `json:"rejected"`
+	ApprovalRate          *float64  `json:"approvalRate,omitempty"`
+	MedianDecisionSeconds *float64  `json:"medianDecisionSeconds,omitempty"`
+}
+
+// GesturePassRate is the liveness outcome of one gesture over all recorded attempts.
+type GesturePassRate struct {
+	Gesture  string  `json:"gesture"`
+	Attempts int     `json:"attempts"`
+	Passed   int     `json:"passed"`
+	PassRate float64 `json:"passRate"`
+}
+
+type RejectionReasonCount struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+type EkycFunnelReport struct {
+	From                time.Time              `json:"from"`
+	To                  time.Time              `json:"to"`
+	Granularity         string                 `json:"granularity"`
+	RegionLevel         string                 `json:"regionLevel,omitempty"`
+	Region              string                 `json:"region,omitempty"`
+	Totals              EkycFunnelBucket       `json:"totals"`
+	Buckets             []EkycFunnelBucket     `json:"buckets"`
+	Gestures            []GesturePassRate      `json:"gestures"`
+	TopRejectionReasons []RejectionReasonCount `json:"topRejectionReasons"`
+}
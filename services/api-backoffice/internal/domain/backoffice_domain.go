@@ -11,6 +11,7 @@ import (
 var (
 	ErrNotFound     = errors.New("not found")
 	ErrInvalidState = errors.New("invalid state")
+	ErrForbidden    = errors.New("forbidden")
 )
 
 type TimelineEntry struct {
@@ -45,12 +46,22 @@ type UpdateVisitParams struct {
 	Photos    []string
 	Checklist map[string]any
 	Timeline  TimelineEntry
+	// Geofence checks the geotag when the visit is submitted.
+	Geofence GeofenceCheck
+	// ChecklistCheck validates the checklist against the template bound to the visit.
+	ChecklistCheck ChecklistCheck
 }
 
 type UpdateBatchStatusParams struct {
 	BatchID string
 	Status  string
-	Audit   AuditEntry
+	// From is the status the batch must still be in.
+	From string
+	// Manifest and Checksum are set when the batch leaves DRAFT: the items are
+	// snapshotted from Manifest and Checksum is stored with the batch.
+	Manifest []BatchManifestItem
+	Checksum string
+	Audit    AuditEntry
 }
 
 type UpdateDistributionStatusParams struct {
@@ -75,7 +86,9 @@ type ListVisitsParams struct {
 	Status        string
 	From          *time.Time
 	To            *time.Time
-	Limit         int
+	// GeofenceFlagged keeps only visits whose geotag failed the geofence check.
+	GeofenceFlagged bool
+	Limit           int
 }
 
 type AssignClusteringCandidateParams struct {
@@ -138,7 +151,6 @@ type BackofficeRepository interface {
 	ListDistributionsByApplication(ctx context.Context, appID string) ([]Distribution, error)
 
 	UpdateApplicationStatus(ctx context.Context, params UpdateApplicationStatusParams) error
-	CreateVisit(ctx context.Context, visit *Visit, timeline TimelineEntry) error
 	UpdateVisit(ctx context.Context, params UpdateVisitParams) error
 
 	ListBatches(ctx context.Context) ([]Batch, error)
@@ -161,10 +173,23 @@ type BackofficeRepository interface {
 
 	ListAuditLogs(ctx context.Context, limit int) ([]AuditLog, error)
 	Overview(ctx context.Context) (map[string]any, error)
+	EkycFunnel(ctx context.Context, params EkycFunnelParams) (*EkycFunnelReport, error)
 
 	NormalizeUserIDs(ctx context.Context, ids []string) ([]string, error)
 
 	EkycRepository
+	RetentionRepository
+	DataSubjectRepository
+	VisitScheduleRepository
+	VisitSyncRepository
+	ChecklistTemplateRepository
+	VisitRouteRepository
+	VisitPhotoRepository
+	VisitReviewRepository
+	CalendarFeedRepository
+	BatchManifestRepository
+	DisbursementExportRepository
+	BatchReconciliationRepository
 
 	GetSurvey(ctx context.Context, applicationID string) (*SurveyState, error)
 	SaveSurveyDraft(ctx context.Context, params SurveyDraftParams) (*SurveyState, error)
@@ -205,6 +230,7 @@ type BackofficeService interface {
 
 	ListAuditLogs(ctx context.Context, limit int) ([]AuditLog, error)
 	Overview(ctx context.Context) (map[string]any, error)
+	EkycFunnel(ctx context.Context, params EkycFunnelParams) (*EkycFunnelReport, error)
 
 	ListBatchesByUser(ctx context.Context, userID string) ([]Batch, error)
 	GetSurvey(ctx context.Context, applicationID string) (*SurveyState, error)
@@ -237,4 +263,5 @@ type BackofficeHTTPHandler interface {
 	UpdateClusteringCandidateStatus(ctx echo.Context) error
 	ListAuditLogs(ctx echo.Context) error
 	Overview(ctx echo.Context) error
+	EkycFunnel(ctx echo.Context) error
 }
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Batch statuses. A batch is locked once it leaves DRAFT: its items are frozen and the
+// checksum of its manifest is stored.
+const (
+	BatchStatusDraft    = "DRAFT"
+	BatchStatusSigned   = "SIGNED"
+	BatchStatusExported = "EXPORTED"
+	BatchStatusSent     = "SENT"
+)
+
+// BatchTransitions lists the statuses a batch may be moved to by hand from each status.
+// SENT is not among them: only an export, which stores the bank file it sent, sets it.
+var BatchTransitions = map[string][]string{
+	BatchStatusDraft:  {BatchStatusSigned},
+	BatchStatusSigned: {BatchStatusExported},
+}
+
+// Keys of SystemConfig.Disbursement.
+const (
+	DisbursementKeyCurrency      = "currency" // ISO 4217 code, IDR when missing
+	DisbursementKeyAmounts       = "amounts"  // bansos_utama program -> amount in whole currency units
+	DisbursementKeyDebtorName    = "debtor_name"
+	DisbursementKeyDebtorAccount = "debtor_account" // account the disbursements are paid from
+	DisbursementKeyDebtorBIC     = "debtor_bic"
+	DisbursementKeyCSVColumns    = "csv_columns" // DisbursementCSVColumns, in file order
+	DisbursementKeyCSVDelimiter  = "csv_delimiter"
+	DisbursementKeyCSVHeader     = "csv_header"
+)
+
+const DefaultDisbursementCurrency = "IDR"
+
+// DisbursementConfig is the parsed disbursement config.
+type DisbursementConfig struct {
+	Currency      string
+	Amounts       map[string]int64
+	DebtorName    string
+	DebtorAccount string
+	DebtorBIC     string
+	CSV           DisbursementCSVLayout
+}
+
+// BatchManifestItem is one disbursement in a batch: the beneficiary's masked NIK, their
+// program and the amount it pays.
+type BatchManifestItem struct {
+	ApplicationID string `json:"applicationId"`
+	NikMask       string `json:"nikMask"`
+	Program       string `json:"program"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+}
+
+// BatchManifest is a batch with the items it was locked with. Items of a DRAFT batch
+// carry no snapshot yet.
+type BatchManifest struct {
+	BatchID  string              `json:"batchId"`
+	Code     string              `json:"code"`
+	Status   string              `json:"status"`
+	Checksum *string             `json:"checksum"`
+	LockedAt *time.Time          `json:"lockedAt"`
+	Items    []BatchManifestItem `json:"items"`
+}
+
+// BatchManifestCheck compares a locked batch's stored checksum with one recomputed from
+// its items.
+type BatchManifestCheck struct {
+	BatchID   string    `json:"batchId"`
+	Code      string    `json:"code"`
+	Status    string    `json:"status"`
+	Stored    string    `json:"stored"`
+	Computed  string    `json:"computed"`
+	Valid     bool      `json:"valid"`
+	Items     int       `json:"items"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+type ReplaceBatchItemsParams struct {
+	BatchID string
+	Items   []string
+	Audit   AuditEntry
+}
+
+// REPOSITORIES
+type BatchManifestRepository interface {
+	GetConfig(ctx context.Context) (*SystemConfig, error)
+	GetApplicationsByIDs(ctx context.Context, ids []string) ([]Application, error)
+	GetBatch(ctx context.Context, batchID string) (*Batch, error)
+	// ListBatchManifestSources returns the batch's items with the beneficiary's current
+	// masked NIK and program, for locking. Amount and Currency are left empty.
+	ListBatchManifestSources(ctx context.Context, batchID string) ([]BatchManifestItem, error)
+	// GetBatchManifest returns the batch with its items as snapshotted when it was locked.
+	GetBatchManifest(ctx context.Context, batchID string) (*BatchManifest, error)
+	// ReplaceBatchItems sets the items of a DRAFT batch. A locked batch is rejected.
+	ReplaceBatchItems(ctx context.Context, params ReplaceBatchItemsParams) error
+	RecordBatchAudit(ctx context.Context, audit AuditEntry) error
+}
+
+// SERVICES
+type BatchManifestService interface {
+	Get(ctx context.Context, batchID string) (*BatchManifest, error)
+	// Verify recomputes the checksum of a locked batch and compares it with the stored one.
+	Verify(ctx context.Context, batchID, actor string) (*BatchManifestCheck, error)
+	ReplaceItems(ctx context.Context, batchID string, applicationIDs []string, actor string) (*Batch, error)
+}
+
+// HTTP HANDLERS
+type BatchManifestHTTPHandler interface {
+	Get(c echo.Context) error
+	Verify(c echo.Context) error
+	ReplaceItems(c echo.Context) error
+}
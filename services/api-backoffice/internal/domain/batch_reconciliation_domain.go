@@ -0,0 +1,142 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Bank result file formats shipped with api-backoffice.
+const (
+	DisbursementResultFormatCSV     = "csv"
+	DisbursementResultFormatPain002 = "pain.002"
+)
+
+// Final outcomes of a transfer. They are also the application status they set.
+const (
+	DisbursementDisbursed = "DISBURSED"
+	DisbursementFailed    = "DISBURSEMENT_FAILED"
+)
+
+const MaxBankResultBytes = 10 << 20
+
+// DisbursementResult is one transfer reported in a bank result file. Status is
+// DisbursementDisbursed, DisbursementFailed, or empty while the bank has not settled it.
+type DisbursementResult struct {
+	// Line is the row, or for XML the transaction, the result was read from, from 1.
+	Line          int    `json:"line"`
+	ApplicationID string `json:"applicationId"`
+	Status        string `json:"status"`
+	// BankStatus is the status as the bank wrote it.
+	BankStatus    string `json:"bankStatus"`
+	Reason        string `json:"reason,omitempty"`
+	BankReference string `json:"bankReference,omitempty"`
+	Amount        *int64 `json:"amount,omitempty"`
+}
+
+// DisbursementResultFile is a parsed bank result file. OriginalMessageID is the bank file
+// it answers, when the format carries it.
+type DisbursementResultFile struct {
+	OriginalMessageID string
+	Results           []DisbursementResult
+}
+
+// DisbursementResultParser reads a bank result file. Parsers are registered with the
+// reconciliation service under their Format.
+type DisbursementResultParser interface {
+	Format() string
+	ContentType() string
+	Extension() string
+	Parse(content []byte) (*DisbursementResultFile, error)
+}
+
+// BatchItemResult is the last outcome recorded for a batch item.
+type BatchItemResult struct {
+	Status        string
+	Reason        string
+	BankReference string
+}
+
+// ReconciliationIssue is a result that was not applied, and why.
+type ReconciliationIssue struct {
+	DisbursementResult
+	Issue string `json:"issue"`
+}
+
+// ReconciliationReport summarises an import. Disbursed and Failed count the items whose
+// outcome changed, Unchanged those that already had it, so re-importing a file applies
+// nothing twice. Outstanding lists the items still without a final outcome.
+type ReconciliationReport struct {
+	Rows        int                   `json:"rows"`
+	Disbursed   int                   `json:"disbursed"`
+	Failed      int                   `json:"failed"`
+	Unchanged   int                   `json:"unchanged"`
+	Pending     []DisbursementResult  `json:"pending"`
+	Unmatched   []ReconciliationIssue `json:"unmatched"`
+	Duplicates  []ReconciliationIssue `json:"duplicates"`
+	Conflicts   []ReconciliationIssue `json:"conflicts"`
+	Outstanding []string              `json:"outstanding"`
+}
+
+// BatchReconciliation is one import of a bank result file. Checksum is the SHA-256 of the
+// file as stored in media storage.
+type BatchReconciliation struct {
+	ID        int64                `json:"id"`
+	BatchID   string               `json:"batchId"`
+	Format    string               `json:"format"`
+	Filename  string               `json:"filename"`
+	URL       string               `json:"url"`
+	Checksum  string               `json:"checksum"`
+	Report    ReconciliationReport `json:"report"`
+	CreatedBy string               `json:"createdBy"`
+	CreatedAt time.Time            `json:"createdAt"`
+}
+
+type ImportBatchReconciliationParams struct {
+	BatchID  string
+	Format   string
+	Filename string
+	Content  []byte
+	Actor    string
+}
+
+// ReconciliationOutcome sets a batch item, and its application, to Result.Status.
+// Previous is the item's outcome it was decided against.
+type ReconciliationOutcome struct {
+	Result   DisbursementResult
+	Previous string
+	Timeline TimelineEntry
+	Audit    AuditEntry
+}
+
+type RecordBatchReconciliationParams struct {
+	Reconciliation *BatchReconciliation
+	Outcomes       []ReconciliationOutcome
+	Audit          AuditEntry
+}
+
+// REPOSITORIES
+type BatchReconciliationRepository interface {
+	GetBatchManifest(ctx context.Context, batchID string) (*BatchManifest, error)
+	// ListBatchItemResults returns the items that have an outcome, by application id.
+	ListBatchItemResults(ctx context.Context, batchID string) (map[string]BatchItemResult, error)
+	// RecordBatchReconciliation applies the outcomes and stores the import. It fails with
+	// ErrInvalidState if an item no longer has its Previous outcome.
+	RecordBatchReconciliation(ctx context.Context, params RecordBatchReconciliationParams) error
+	ListBatchReconciliations(ctx context.Context, batchID string) ([]BatchReconciliation, error)
+}
+
+// SERVICES
+type BatchReconciliationService interface {
+	// Import matches a bank result file to the items of a SENT batch and marks their
+	// applications DISBURSED or DISBURSEMENT_FAILED.
+	Import(ctx context.Context, params ImportBatchReconciliationParams) (*BatchReconciliation, error)
+	List(ctx context.Context, batchID string) ([]BatchReconciliation, error)
+}
+
+// HTTP HANDLERS
+type BatchReconciliationHTTPHandler interface {
+	Import(c echo.Context) error
+	List(c echo.Context) error
+}
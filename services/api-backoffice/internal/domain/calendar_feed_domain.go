@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CalendarFeedRoles lists the roles that can subscribe to a calendar feed.
+var CalendarFeedRoles = []string{"ADMIN", "TKSK", "AUDITOR"}
+
+// CalendarFeed is a user's iCalendar subscription. Only a digest of the token is stored,
+// so Token and URL are only set when the feed is issued.
+type CalendarFeed struct {
+	UserID    string    `json:"userId"`
+	Token     string    `json:"token,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CalendarFeedOwner is the user a feed belongs to.
+type CalendarFeedOwner struct {
+	UserID      string
+	Name        string
+	Role        string
+	RegionScope []string
+}
+
+// CalendarFeedEvent is an event as published in a user's feed. Fingerprint is a digest
+// of the published content and Sequence grows each time it changes. CancelledAt is set
+// once the event dropped out of the feed. Label is a title for the cancelled entry that
+// carries no personal data.
+type CalendarFeedEvent struct {
+	UID         string
+	Fingerprint string
+	Sequence    int
+	Label       string
+	StartsAt    time.Time
+	EndsAt      time.Time
+	CancelledAt *time.Time
+}
+
+// CalendarFeedReconcile turns the events last published in a feed into those to publish
+// now.
+type CalendarFeedReconcile func(published []CalendarFeedEvent) []CalendarFeedEvent
+
+// VisitHousehold is what is known of the household an application's visits go to. Lat
+// and Lng come from the latest unflagged visit geotag.
+type VisitHousehold struct {
+	ApplicationID string
+	ApplicantName string
+	Region        Region
+	Lat           *float64
+	Lng           *float64
+}
+
+type CalendarFeedParams struct {
+	UserID    string
+	ActorID   string
+	ActorRole string
+}
+
+// REPOSITORIES
+type CalendarFeedRepository interface {
+	GetConfig(ctx context.Context) (*SystemConfig, error)
+	// ListVisits returns the latest visits first, so Limit drops the earliest ones.
+	ListVisits(ctx context.Context, params ListVisitsParams) ([]Visit, error)
+	ListVisitHouseholds(ctx context.Context, appIDs []string) (map[string]VisitHousehold, error)
+	// ListRegionDistributions returns distributions from the given time on that have a
+	// beneficiary in scope or a location naming it. An empty scope covers every region.
+	ListRegionDistributions(ctx context.Context, scope []string, from time.Time) ([]Distribution, error)
+	GetCalendarFeedOwner(ctx context.Context, userID string) (*CalendarFeedOwner, error)
+	// SaveCalendarFeed sets the user's feed token, replacing any earlier one.
+	SaveCalendarFeed(ctx context.Context, userID, tokenHash string) (*CalendarFeed, error)
+	DeleteCalendarFeed(ctx context.Context, userID string) error
+	FindCalendarFeedOwner(ctx context.Context, tokenHash string) (*CalendarFeedOwner, error)
+	// PublishCalendarFeed passes the events last published for the user to reconcile and
+	// stores what it returns in their place.
+	PublishCalendarFeed(ctx context.Context, userID string, reconcile CalendarFeedReconcile) ([]CalendarFeedEvent, error)
+}
+
+// SERVICES
+type CalendarFeedService interface {
+	Issue(ctx context.Context, params CalendarFeedParams) (*CalendarFeed, error)
+	Revoke(ctx context.Context, params CalendarFeedParams) error
+	// Render returns the iCalendar text of the feed the token belongs to.
+	Render(ctx context.Context, token string) ([]byte, error)
+}
+
+// HTTP HANDLERS
+type CalendarFeedHTTPHandler interface {
+	Issue(c echo.Context) error
+	Revoke(c echo.Context) error
+	Feed(c echo.Context) error
+}
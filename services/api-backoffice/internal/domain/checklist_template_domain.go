@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+var ErrChecklistInvalid = errors.New("checklist does not match its template")
+
+const (
+	ChecklistTemplatePublished = "PUBLISHED"
+	ChecklistTemplateRetired   = "RETIRED"
+	// ChecklistAnyProgram is the program of a template that applies when the
+	// beneficiary's program has none of its own.
+	ChecklistAnyProgram = "*"
+)
+
+// Types a ChecklistSchema field can have.
+const (
+	ChecklistTypeObject  = "object"
+	ChecklistTypeString  = "string"
+	ChecklistTypeNumber  = "number"
+	ChecklistTypeInteger = "integer"
+	ChecklistTypeBoolean = "boolean"
+	ChecklistTypeArray   = "array"
+)
+
+// ChecklistSchema describes a checklist with a subset of JSON Schema: type, properties,
+// required, items, enum and the min/max keywords. Unlike JSON Schema, objects reject
+// unknown properties unless additionalProperties is true, so checklists stay comparable.
+type ChecklistSchema struct {
+	Type                 string                      `json:"type"`
+	Title                string                      `json:"title,omitempty"`
+	Properties           map[string]*ChecklistSchema `json:"properties,omitempty"`
+	Required             []string                    `json:"required,omitempty"`
+	AdditionalProperties bool                        `json:"additionalProperties,omitempty"`
+	Items                *ChecklistSchema            `json:"items,omitempty"`
+	Enum                 []any                       `json:"enum,omitempty"`
+	Minimum              *float64                    `json:"minimum,omitempty"`
+	Maximum              *float64                    `json:"maximum,omitempty"`
+	MinLength            *int                        `json:"minLength,omitempty"`
+	MaxLength            *int                        `json:"maxLength,omitempty"`
+	Pattern              string                      `json:"pattern,omitempty"`
+	MinItems             *int                        `json:"minItems,omitempty"`
+	MaxItems             *int                        `json:"maxItems,omitempty"`
+}
+
+type ChecklistTemplate struct {
+	ID          int64           `json:"id"`
+	Program     string          `json:"program"`
+	Period      string          `json:"period"`
+	Version     int             `json:"version"`
+	Status      string          `json:"status"`
+	Schema      ChecklistSchema `json:"schema"`
+	Notes       *string         `json:"notes,omitempty"`
+	PublishedBy string          `json:"publishedBy"`
+	PublishedAt time.Time       `json:"publishedAt"`
+	RetiredBy   *string         `json:"retiredBy,omitempty"`
+	RetiredAt   *time.Time      `json:"retiredAt,omitempty"`
+}
+
+// ChecklistFieldError is one problem with a checklist field. Field is a path such as
+// "rumah.dinding" or "anggota[2]".
+type ChecklistFieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ChecklistValidationError lists why a checklist does not match the template bound to
+// its visit. It matches ErrChecklistInvalid.
+type ChecklistValidationError struct {
+	TemplateID      int64
+	TemplateVersion int
+	Fields          []ChecklistFieldError
+}
+
+func (e *ChecklistValidationError) Error() string {
+	return fmt.Sprintf("%s: %d field tidak valid (template versi %d)", ErrChecklistInvalid, len(e.Fields), e.TemplateVersion)
+}
+
+func (e *ChecklistValidationError) Unwrap() error {
+	return ErrChecklistInvalid
+}
+
+// ChecklistCheck validates a visit checklist against its template. Required fields are
+// only enforced when the visit is being submitted, so drafts can be saved partially.
+type ChecklistCheck func(template *ChecklistTemplate, checklist map[string]any, submitting bool) []ChecklistFieldError
+
+type ListChecklistTemplatesParams struct {
+	Program string
+	Period  string
+}
+
+type PublishChecklistTemplateParams struct {
+	Program string
+	Period  string
+	Schema  ChecklistSchema
+	Notes   string
+	Actor   string
+}
+
+// REPOSITORIES
+type ChecklistTemplateRepository interface {
+	ListChecklistTemplates(ctx context.Context, params ListChecklistTemplatesParams) ([]ChecklistTemplate, error)
+	GetChecklistTemplate(ctx context.Context, id int64) (*ChecklistTemplate, error)
+	// PublishChecklistTemplate stores the schema as the next version of its program and
+	// period.
+	PublishChecklistTemplate(ctx context.Context, params PublishChecklistTemplateParams) (*ChecklistTemplate, error)
+	// RetireChecklistTemplate stops binding the version to new visits.
+	RetireChecklistTemplate(ctx context.Context, id int64, actor string) (*ChecklistTemplate, error)
+}
+
+// SERVICES
+type ChecklistTemplateService interface {
+	List(ctx context.Context, params ListChecklistTemplatesParams) ([]ChecklistTemplate, error)
+	Get(ctx context.Context, id int64) (*ChecklistTemplate, error)
+	Publish(ctx context.Context, params PublishChecklistTemplateParams) (*ChecklistTemplate, error)
+	Retire(ctx context.Context, id int64, actor string) (*ChecklistTemplate, error)
+}
+
+// HTTP HANDLERS
+type ChecklistTemplateHTTPHandler interface {
+	List(c echo.Context) error
+	Get(c echo.Context) error
+	Publish(c echo.Context) error
+	Retire(c echo.Context) error
+}
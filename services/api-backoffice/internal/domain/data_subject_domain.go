@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	ErasureStatusPending   = "PENDING"
+	ErasureStatusCompleted = "COMPLETED"
+	ErasureStatusRejected  = "REJECTED"
+)
+
+// DataSubjectMedia is a media file referenced by one of the subject's records. Path and
+// SHA256 locate it in the export bundle; Error is set when it could not be fetched.
+type DataSubjectMedia struct {
+	Table    string `json:"table"`
+	RecordID string `json:"recordId"`
+	Field    string `json:"field"`
+	URL      string `json:"url"`
+	Path     string `json:"path,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// DataSubjectExport is everything stored about one user, keyed by table. PII is
+// returned decrypted; secrets such as the PIN hash and blind indexes are left out.
+type DataSubjectExport struct {
+	UserID      string                      `json:"userId"`
+	GeneratedAt time.Time                   `json:"generatedAt"`
+	GeneratedBy string                      `json:"generatedBy"`
+	Records     map[string][]map[string]any `json:"records"`
+	Media       []DataSubjectMedia          `json:"media"`
+}
+
+// DataSubjectBundle is the zip archive handed to the subject: export.json plus media/.
+type DataSubjectBundle struct {
+	FileName string
+	Content  []byte
+}
+
+type ErasureRequest struct {
+	ID          int64          `json:"id"`
+	UserID      string         `json:"userId"`
+	Reason      string         `json:"reason"`
+	Status      string         `json:"status"`
+	RequestedBy string         `json:"requestedBy"`
+	RequestedAt time.Time      `json:"requestedAt"`
+	ReviewedBy  *string        `json:"reviewedBy,omitempty"`
+	ReviewedAt  *time.Time     `json:"reviewedAt,omitempty"`
+	ReviewNote  *string        `json:"reviewNote,omitempty"`
+	Summary     map[string]any `json:"summary"`
+	// MediaPending counts media files of a completed erasure not deleted yet.
+	MediaPending int `json:"mediaPending"`
+}
+
+// ErasureMedia is a media file queued for deletion by an approved erasure.
+type ErasureMedia struct {
+	ID        int64
+	RequestID int64
+	URL       string
+	Attempts  int
+	LastError *string
+}
+
+type CreateErasureRequestParams struct {
+	UserID string `json:"-"`
+	Reason string `json:"reason"`
+	Actor  string `json:"actor"`
+}
+
+type ReviewErasureRequestParams struct {
+	ID    int64  `json:"-"`
+	Actor string `json:"actor"`
+	Note  string `json:"note"`
+}
+
+type ListErasureRequestsParams struct {
+	Status string
+	Limit  int
+}
+
+// REPOSITORIES
+type DataSubjectRepository interface {
+	// ExportDataSubject collects the user's records in one snapshot and audits the export.
+	ExportDataSubject(ctx context.Context, userID, actor string) (*DataSubjectExport, error)
+
+	CreateErasureRequest(ctx context.Context, params CreateErasureRequestParams) (*ErasureRequest, error)
+	ListErasureRequests(ctx context.Context, params ListErasureRequestsParams) ([]ErasureRequest, error)
+	GetErasureRequest(ctx context.Context, id int64) (*ErasureRequest, error)
+	RejectErasureRequest(ctx context.Context, params ReviewErasureRequestParams) (*ErasureRequest, error)
+	// ApproveErasureRequest pseudonymizes the subject, completes the request and queues the
+	// media it unlinked for deletion, all in one transaction. It returns ErrForbidden when
+	// the approver is the requester and ErrInvalidState when the subject is under legal hold.
+	ApproveErasureRequest(ctx context.Context, params ReviewErasureRequestParams) (*ErasureRequest, error)
+	// ListPendingErasureMedia returns the queued media of a request not deleted yet.
+	ListPendingErasureMedia(ctx context.Context, requestID int64) ([]ErasureMedia, error)
+	MarkErasureMediaDeleted(ctx context.Context, id int64) error
+	MarkErasureMediaFailed(ctx context.Context, id int64, reason string) error
+}
+
+// SERVICES
+type DataSubjectService interface {
+	Export(ctx context.Context, userID, actor string) (*DataSubjectBundle, error)
+	RequestErasure(ctx context.Context, params CreateErasureRequestParams) (*ErasureRequest, error)
+	ListErasureRequests(ctx context.Context, params ListErasureRequestsParams) ([]ErasureRequest, error)
+	GetErasureRequest(ctx context.Context, id int64) (*ErasureRequest, error)
+	ApproveErasure(ctx context.Context, params ReviewErasureRequestParams) (*ErasureRequest, error)
+	RejectErasure(ctx context.Context, params ReviewErasureRequestParams) (*ErasureRequest, error)
+	// RetryErasureMedia deletes the media a completed erasure failed to delete.
+	RetryErasureMedia(ctx context.Context, id int64) (*ErasureRequest, error)
+}
+
+// HTTP HANDLERS
+type DataSubjectHTTPHandler interface {
+	Export(c echo.Context) error
+	RequestErasure(c echo.Context) error
+	ListErasureRequests(c echo.Context) error
+	GetErasureRequest(c echo.Context) error
+	ApproveErasure(c echo.Context) error
+	RejectErasure(c echo.Context) error
+	RetryErasureMedia(c echo.Context) error
+}
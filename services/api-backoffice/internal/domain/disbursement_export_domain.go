@@ -0,0 +1,232 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PayoutChannelBankTransfer pays into a bank account. Other channels, such as POS, hand
+// the money out against a reference instead.
+const PayoutChannelBankTransfer = "BANK_TRANSFER"
+
+// Bank file formats shipped with api-backoffice.
+const (
+	DisbursementFormatCSV     = "csv"
+	DisbursementFormatPain001 = "pain.001"
+)
+
+// BatchExportStatuses lists the statuses a batch can be exported from. An exported
+// batch moves to SENT.
+var BatchExportStatuses = []string{BatchStatusSigned, BatchStatusExported}
+
+// Columns of the CSV bank file. reference is the account for bank transfers and the
+// channel reference otherwise.
+const (
+	DisbursementColumnBatch         = "batch"
+	DisbursementColumnApplicationID = "application_id"
+	DisbursementColumnName          = "name"
+	DisbursementColumnNikMask       = "nik_mask"
+	DisbursementColumnProgram       = "program"
+	DisbursementColumnAmount        = "amount"
+	DisbursementColumnCurrency      = "currency"
+	DisbursementColumnChannel       = "channel"
+	DisbursementColumnBank          = "bank"
+	DisbursementColumnAccount       = "account"
+	DisbursementColumnReference     = "reference"
+	DisbursementColumnRemittance    = "remittance"
+)
+
+var DisbursementCSVColumns = []string{
+	DisbursementColumnBatch, DisbursementColumnApplicationID, DisbursementColumnName, DisbursementColumnNikMask,
+	DisbursementColumnProgram, DisbursementColumnAmount, DisbursementColumnCurrency, DisbursementColumnChannel,
+	DisbursementColumnBank, DisbursementColumnAccount, DisbursementColumnReference, DisbursementColumnRemittance,
+}
+
+// DisbursementCSVLayout is the layout of the CSV bank file.
+type DisbursementCSVLayout struct {
+	Columns   []string
+	Delimiter rune
+	Header    bool
+}
+
+// DefaultDisbursementCSVLayout is used for whatever the config leaves out.
+func DefaultDisbursementCSVLayout() DisbursementCSVLayout {
+	return DisbursementCSVLayout{
+		Columns: []string{
+			DisbursementColumnBatch, DisbursementColumnApplicationID, DisbursementColumnName, DisbursementColumnChannel,
+			DisbursementColumnBank, DisbursementColumnReference, DisbursementColumnProgram, DisbursementColumnAmount,
+			DisbursementColumnCurrency,
+		},
+		Delimiter: ',',
+		Header:    true,
+	}
+}
+
+// IsBIC reports whether code is a SWIFT BIC: bank, country and location code, with an
+// optional branch code.
+func IsBIC(code string) bool {
+	if len(code) != 8 && len(code) != 11 {
+		return false
+	}
+	for i, r := range code {
+		switch {
+		case r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i >= 6:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// MaxEndToEndIDLength is the longest end-to-end id ISO 20022 bank files carry (Max35Text).
+const MaxEndToEndIDLength = 35
+
+// EndToEndID is the reference a bank file carries for an application, which the bank
+// echoes in its results. Ids that fit are used as they are. Applications built from an
+// eKYC session share its 36-character UUID, which goes without its hyphens. An id that
+// fits neither way is an error rather than being cut, as a cut id cannot be matched.
+func EndToEndID(applicationID string) (string, error) {
+	if len(applicationID) <= MaxEndToEndIDLength {
+		return applicationID, nil
+	}
+	if isUUID(applicationID) {
+		return strings.ReplaceAll(applicationID, "-", ""), nil
+	}
+	return "", fmt.Errorf("%w: id aplikasi %s lebih dari %d karakter", ErrInvalidState, applicationID, MaxEndToEndIDLength)
+}
+
+// isUUID reports whether s is a UUID in its canonical 8-4-4-4-12 form.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if r != '-' {
+				return false
+			}
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'f', r >= 'A' && r <= 'F':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// DisbursementPayee is where a beneficiary is paid, with the account opened.
+type DisbursementPayee struct {
+	ApplicationID string
+	Name          string
+	Channel       string
+	Bank          string
+	Account       string
+}
+
+// DisbursementLine is one credit transfer in a bank file.
+type DisbursementLine struct {
+	ApplicationID string
+	Name          string
+	NikMask       string
+	Program       string
+	Amount        int64
+	Currency      string
+	Channel       string
+	Bank          string
+	Account       string
+	// Reference identifies the destination: the account, or for non-bank channels the
+	// account if any, else the application id.
+	Reference  string
+	Remittance string
+}
+
+// DisbursementFile is what an exporter renders: a locked batch with the payee of each
+// item. Lines are ordered by application id.
+type DisbursementFile struct {
+	BatchID          string
+	BatchCode        string
+	ManifestChecksum string
+	CreatedAt        time.Time
+	Currency         string
+	Total            int64
+	Lines            []DisbursementLine
+	Config           DisbursementConfig
+}
+
+// DisbursementExporter renders a batch into a bank file. Exporters are registered with
+// the export service under their Format.
+type DisbursementExporter interface {
+	Format() string
+	ContentType() string
+	Extension() string
+	Render(file DisbursementFile) ([]byte, error)
+}
+
+// BatchExport is a bank file generated for a batch. Checksum is the SHA-256 of the file.
+type BatchExport struct {
+	ID               int64     `json:"id"`
+	BatchID          string    `json:"batchId"`
+	Format           string    `json:"format"`
+	Filename         string    `json:"filename"`
+	URL              string    `json:"url"`
+	Checksum         string    `json:"checksum"`
+	ManifestChecksum string    `json:"manifestChecksum"`
+	Items            int       `json:"items"`
+	TotalAmount      int64     `json:"totalAmount"`
+	Currency         string    `json:"currency"`
+	CreatedBy        string    `json:"createdBy"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+type RecordBatchExportParams struct {
+	Export *BatchExport
+	// From is the status the batch must still be in. It moves to SENT.
+	From  string
+	Audit AuditEntry
+}
+
+// BeneficiaryPayout is where a beneficiary is paid. Account is stored sealed.
+type BeneficiaryPayout struct {
+	UserID  string
+	Channel string
+	Bank    string
+	Account string
+}
+
+type SetBeneficiaryPayoutParams struct {
+	Payout BeneficiaryPayout
+	Audit  AuditEntry
+}
+
+// REPOSITORIES
+type DisbursementExportRepository interface {
+	GetConfig(ctx context.Context) (*SystemConfig, error)
+	GetBatchManifest(ctx context.Context, batchID string) (*BatchManifest, error)
+	// ListDisbursementPayees returns the payee of every item in the batch, by application id.
+	ListDisbursementPayees(ctx context.Context, batchID string) (map[string]DisbursementPayee, error)
+	// RecordBatchExport stores the export and moves the batch to SENT.
+	RecordBatchExport(ctx context.Context, params RecordBatchExportParams) error
+	ListBatchExports(ctx context.Context, batchID string) ([]BatchExport, error)
+	SetBeneficiaryPayout(ctx context.Context, params SetBeneficiaryPayoutParams) error
+}
+
+// SERVICES
+type DisbursementExportService interface {
+	// Export renders the batch in the given format, stores the file in media storage
+	// and marks the batch SENT.
+	Export(ctx context.Context, batchID, format, actor string) (*BatchExport, error)
+	List(ctx context.Context, batchID string) ([]BatchExport, error)
+	SetPayout(ctx context.Context, payout BeneficiaryPayout, actor string) error
+}
+
+// HTTP HANDLERS
+type DisbursementExportHTTPHandler interface {
+	Export(c echo.Context) error
+	List(c echo.Context) error
+	SetPayout(c echo.Context) error
+}
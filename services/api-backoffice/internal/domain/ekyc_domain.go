@@ -2,29 +2,45 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+// ErrSessionLocked is returned when a session exhausted its verification attempts
+// and now waits for a reviewer.
+var ErrSessionLocked = errors.New("session locked for manual review")
+
+// DecisionOverrideRoles lists the roles allowed to override a recorded eKYC decision.
+var DecisionOverrideRoles = []string{"ADMIN"}
+
+// DefaultEkycMaxAttempts applies when thresholds.ekyc_max_attempts is not configured.
+const DefaultEkycMaxAttempts = 3
+
 type EkycSession struct {
-	ID                 string         `json:"id"`
-	UserID             *string        `json:"userId,omitempty"`
-	Status             string         `json:"status"`
-	FaceMatchingStatus string         `json:"faceMatchingStatus"`
-	LivenessStatus     string         `json:"livenessStatus"`
-	FinalDecision      string         `json:"finalDecision"`
-	IDCardURL          *string        `json:"idCardUrl,omitempty"`
-	SelfieWithIDURL    *string        `json:"selfieWithIdUrl,omitempty"`
-	RecordedVideoURL   *string        `json:"recordedVideoUrl,omitempty"`
-	FaceMatchOverall   *string        `json:"faceMatchOverall,omitempty"`
-	LivenessOverall    *string        `json:"livenessOverall,omitempty"`
-	RejectionReason    *string        `json:"rejectionReason,omitempty"`
-	Metadata           map[string]any `json:"metadata"`
-	CreatedAt          time.Time      `json:"createdAt"`
-	UpdatedAt          time.Time      `json:"updatedAt"`
-	FaceChecks         []FaceCheck    `json:"faceChecks,omitempty"`
-	LivenessCheck      *LivenessCheck `json:"livenessCheck,omitempty"`
+	ID                 string             `json:"id"`
+	UserID             *string            `json:"userId,omitempty"`
+	Status             string             `json:"status"`
+	FaceMatchingStatus string             `json:"faceMatchingStatus"`
+	LivenessStatus     string             `json:"livenessStatus"`
+	FinalDecision      string             `json:"finalDecision"`
+	IDCardURL          *string            `json:"idCardUrl,omitempty"`
+	SelfieWithIDURL    *string            `json:"selfieWithIdUrl,omitempty"`
+	RecordedVideoURL   *string            `json:"recordedVideoUrl,omitempty"`
+	FaceMatchOverall   *string            `json:"faceMatchOverall,omitempty"`
+	LivenessOverall    *string            `json:"livenessOverall,omitempty"`
+	RejectionReason    *string            `json:"rejectionReason,omitempty"`
+	Metadata           map[string]any     `json:"metadata"`
+	CreatedAt          time.Time          `json:"createdAt"`
+	UpdatedAt          time.Time          `json:"updatedAt"`
+	FaceChecks         []FaceCheck        `json:"faceChecks,omitempty"`
+	LivenessCheck      *LivenessCheck     `json:"livenessCheck,omitempty"`
+	FaceAttempts       int                `json:"faceAttempts"`
+	LivenessAttempts   int                `json:"livenessAttempts"`
+	FaceCheckHistory   []FaceCheck        `json:"faceCheckHistory,omitempty"`
+	LivenessHistory    []LivenessCheck    `json:"livenessHistory,omitempty"`
+	OverrideHistory    []DecisionOverride `json:"overrideHistory,omitempty"`
 }
 
 type FaceCheck struct {
@@ -35,6 +51,8 @@ type FaceCheck struct {
 	Threshold   *float64       `json:"threshold,omitempty"`
 	Result      string         `json:"result"`
 	RawMetadata map[string]any `json:"rawMetadata"`
+	AttemptNo   int            `json:"attemptNo"`
+	IsCurrent   bool           `json:"isCurrent"`
 	CreatedAt   time.Time      `json:"createdAt"`
 }
 
@@ -45,6 +63,8 @@ type LivenessCheck struct {
 	PerGestureResult map[string]any `json:"perGestureResult"`
 	RecordedVideoURL *string        `json:"recordedVideoUrl,omitempty"`
 	RawMetadata      map[string]any `json:"rawMetadata"`
+	AttemptNo        int            `json:"attemptNo"`
+	IsCurrent        bool           `json:"isCurrent"`
 	CreatedAt        time.Time      `json:"createdAt"`
 }
 
@@ -71,19 +91,23 @@ type FaceCheckInput struct {
 }
 
 type SaveFaceChecksParams struct {
-	SessionID string           `json:"sessionId"`
-	Checks    []FaceCheckInput `json:"checks"`
-	Overall   string           `json:"overallResult"`
-	Status    string           `json:"status"`
+	SessionID   string           `json:"sessionId"`
+	Checks      []FaceCheckInput `json:"checks"`
+	Overall     string           `json:"overallResult"`
+	Status      string           `json:"status"`
+	JobID       string           `json:"-"`
+	MaxAttempts int              `json:"-"`
 }
 
 type SaveLivenessResultParams struct {
-	SessionID  string         `json:"sessionId"`
-	Overall    string         `json:"overallResult"`
-	PerGesture map[string]any `json:"perGestureResult"`
-	VideoURL   *string        `json:"recordedVideoUrl"`
-	Status     string         `json:"status"`
-	Metadata   map[string]any `json:"rawMetadata"`
+	SessionID   string         `json:"sessionId"`
+	Overall     string         `json:"overallResult"`
+	PerGesture  map[string]any `json:"perGestureResult"`
+	VideoURL    *string        `json:"recordedVideoUrl"`
+	Status      string         `json:"status"`
+	Metadata    map[string]any `json:"rawMetadata"`
+	JobID       string         `json:"-"`
+	MaxAttempts int            `json:"-"`
 }
 
 type ApplicantSubmission struct {
@@ -95,6 +119,9 @@ type ApplicantSubmission struct {
 	Phone     string
 	Email     string
 	Pin       string
+	Region    Region
+	// Flags is merged into applications.flags; NIK cross-check findings land here.
+	Flags map[string]any
 }
 
 type ListEkycSessionsParams struct {
@@ -109,6 +136,96 @@ type UpdateEkycDecisionParams struct {
 	Reason        *string `json:"reason"`
 }
 
+// DecisionOverrideReason is an entry of the managed reason-code list for overrides.
+type DecisionOverrideReason struct {
+	Code   string `json:"code"`
+	Label  string `json:"label"`
+	Active bool   `json:"active"`
+}
+
+// DecisionOverride records one manual change of a session's final decision.
+type DecisionOverride struct {
+	ID               int64     `json:"id"`
+	SessionID        string    `json:"ekycSessionId"`
+	PreviousDecision string    `json:"previousDecision"`
+	NewDecision      string    `json:"newDecision"`
+	ReasonCode       string    `json:"reasonCode"`
+	ReasonText       string    `json:"reasonText"`
+	Actor            string    `json:"actor"`
+	ActorRole        string    `json:"actorRole"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+type OverrideEkycDecisionParams struct {
+	SessionID     string
+	FinalDecision string
+	ReasonCode    string
+	ReasonText    string
+	Actor         string
+	ActorRole     string
+	Timeline      TimelineEntry
+	Audit         AuditEntry
+}
+
+// OcrResult is the KTP text the AI service extracted for a session, with the comparison
+// against what the applicant declared. Confidence is keyed by field name (0..1).
+type OcrResult struct {
+	SessionID   string               `json:"ekycSessionId"`
+	Nik         *string              `json:"nik,omitempty"`
+	Name        *string              `json:"name,omitempty"`
+	BirthDate   *time.Time           `json:"birthDate,omitempty"`
+	Address     *string              `json:"address,omitempty"`
+	Confidence  map[string]float64   `json:"confidence"`
+	RawMetadata map[string]any       `json:"rawMetadata"`
+	Score       *float64             `json:"score,omitempty"`
+	Fields      []OcrFieldComparison `json:"fields"`
+	CreatedAt   time.Time            `json:"createdAt"`
+	UpdatedAt   time.Time            `json:"updatedAt"`
+}
+
+// OcrFieldComparison is one extracted field matched against the declared value.
+type OcrFieldComparison struct {
+	Field      string   `json:"field"`
+	Extracted  string   `json:"extracted"`
+	Declared   string   `json:"declared"`
+	Similarity float64  `json:"similarity"`
+	Confidence *float64 `json:"confidence,omitempty"`
+	Match      bool     `json:"match"`
+}
+
+// EkycSessionEventChannel is the Postgres NOTIFY channel announcing new session events.
+const EkycSessionEventChannel = "ekyc_session_events"
+
+const (
+	EkycEventArtifactUploaded  = "artifact.uploaded"
+	EkycEventStatusChanged     = "status.changed"
+	EkycEventFaceCheckRecorded = "face_check.recorded"
+	EkycEventLivenessRecorded  = "liveness.recorded"
+	EkycEventDecisionMade      = "decision.made"
+)
+
+// EkycSessionEvent is one committed state change of a session. IDs increase
+// monotonically and double as the SSE event id.
+type EkycSessionEvent struct {
+	ID        int64          `json:"id"`
+	SessionID string         `json:"ekycSessionId"`
+	Type      string         `json:"type"`
+	Payload   map[string]any `json:"payload"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// EkycSessionEventStream wakes subscribers when events for a session are committed.
+// Subscribers read the events themselves, so a missed wake-up only delays delivery.
+type EkycSessionEventStream interface {
+	Subscribe(sessionID string) (wake <-chan struct{}, cancel func())
+}
+
+// EkycRerunResult is returned when a verification step is queued again from the backoffice.
+type EkycRerunResult struct {
+	Session *EkycSession    `json:"session"`
+	Job     *AsyncJobHandle `json:"job"`
+}
+
 type EkycRepository interface {
 	CreateEkycSession(ctx context.Context, params CreateEkycSessionParams) (*EkycSession, error)
 	UpdateEkycSession(ctx context.Context, params UpdateEkycArtifactsParams) (*EkycSession, error)
@@ -119,6 +236,20 @@ type EkycRepository interface {
 	GetEkycSession(ctx context.Context, id string) (*EkycSession, error)
 	UpdateEkycDecision(ctx context.Context, params UpdateEkycDecisionParams) (*EkycSession, error)
 	EnsureApplicationFromSession(ctx context.Context, sessionID string) error
+	GetConfig(ctx context.Context) (*SystemConfig, error)
+	OverrideEkycDecision(ctx context.Context, params OverrideEkycDecisionParams) (*EkycSession, error)
+	ListDecisionOverrideReasons(ctx context.Context) ([]DecisionOverrideReason, error)
+	SaveOcrResult(ctx context.Context, result OcrResult) (*OcrResult, error)
+	GetOcrResult(ctx context.Context, sessionID string) (*OcrResult, error)
+	ListEkycSessionEvents(ctx context.Context, sessionID string, afterID int64, limit int) ([]EkycSessionEvent, error)
+	// ListReviewCandidates returns a page of the undecided sessions with at least one review
+	// signal, highest score first and oldest change first within a score.
+	ListReviewCandidates(ctx context.Context, params ListReviewCandidatesParams) ([]ReviewCandidate, error)
+	GetReviewCandidate(ctx context.Context, sessionID string) (*ReviewCandidate, error)
+	ClaimReviewSession(ctx context.Context, sessionID, reviewer string, ttl time.Duration) (*ReviewClaim, error)
+	ReleaseReviewClaim(ctx context.Context, sessionID, reviewer string) error
+	RecordReviewVerdict(ctx context.Context, params ReviewVerdictParams) (*EkycSession, error)
+	ListReviewVerdicts(ctx context.Context, sessionID string) ([]ReviewVerdict, error)
 }
 
 type EkycService interface {
@@ -130,7 +261,17 @@ type EkycService interface {
 	ListSessions(ctx context.Context, params ListEkycSessionsParams) ([]EkycSession, error)
 	GetSession(ctx context.Context, id string) (*EkycSession, error)
 	FinalizeSession(ctx context.Context, id string) (*EkycSession, error)
-	OverrideDecision(ctx context.Context, params UpdateEkycDecisionParams) (*EkycSession, error)
+	OverrideDecision(ctx context.Context, params OverrideEkycDecisionParams) (*EkycSession, error)
+	ListOverrideReasons(ctx context.Context) ([]DecisionOverrideReason, error)
+	RecordOcrResult(ctx context.Context, result OcrResult) (*OcrResult, error)
+	GetOcrResult(ctx context.Context, sessionID string) (*OcrResult, error)
+	RerunFaceMatch(ctx context.Context, id string) (*EkycRerunResult, error)
+	ListSessionEvents(ctx context.Context, sessionID string, afterID int64) ([]EkycSessionEvent, error)
+	ListReviewQueue(ctx context.Context, params ListReviewQueueParams) ([]ReviewQueueItem, error)
+	GetReviewCase(ctx context.Context, sessionID string) (*ReviewCase, error)
+	ClaimReview(ctx context.Context, params ReviewClaimParams) (*ReviewClaim, error)
+	ReleaseReview(ctx context.Context, params ReviewClaimParams) error
+	SubmitReviewVerdict(ctx context.Context, params ReviewVerdictParams) (*EkycSession, error)
 }
 
 type EkycHTTPHandler interface {
@@ -143,4 +284,14 @@ type EkycHTTPHandler interface {
 	GetSession(c echo.Context) error
 	Finalize(c echo.Context) error
 	OverrideDecision(c echo.Context) error
+	ListOverrideReasons(c echo.Context) error
+	RecordOcrResult(c echo.Context) error
+	GetOcrResult(c echo.Context) error
+	RerunFaceMatch(c echo.Context) error
+	StreamSessionEvents(c echo.Context) error
+	ListReviewQueue(c echo.Context) error
+	GetReviewCase(c echo.Context) error
+	ClaimReview(c echo.Context) error
+	ReleaseReview(c echo.Context) error
+	SubmitReviewVerdict(c echo.Context) error
 }
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrReviewClaimed is returned when another reviewer holds the claim on a session.
+var ErrReviewClaimed = errors.New("session claimed by another reviewer")
+
+// EkycReviewRoles lists the roles allowed to work the manual review queue.
+var EkycReviewRoles = []string{"ADMIN"}
+
+const (
+	// DefaultReviewClaimTTL is how long a claim blocks other reviewers before it lapses.
+	DefaultReviewClaimTTL = 30 * time.Minute
+	// DefaultReviewFaceMargin is how far below the face-match threshold a similarity
+	// still counts as borderline.
+	DefaultReviewFaceMargin = 0.05
+)
+
+// Review signal codes, in roughly descending weight.
+const (
+	ReviewSignalLocked           = "LOCKED"
+	ReviewSignalResultConflict   = "FACE_LIVENESS_CONFLICT"
+	ReviewSignalFaceBorderline   = "FACE_NEAR_THRESHOLD"
+	ReviewSignalNikMismatch      = "NIK_MISMATCH"
+	ReviewSignalOcrDiscrepancy   = "OCR_DISCREPANCY"
+	ReviewSignalRepeatedAttempts = "REPEATED_ATTEMPTS"
+)
+
+// Weights of the review signals. A locked session or contradicting results outrank a
+// borderline score, repeated attempts only nudge the order. The repository ranks the
+// queue by them and the service explains each rank with them.
+const (
+	ReviewWeightLocked           = 40
+	ReviewWeightResultConflict   = 35
+	ReviewWeightFaceBorderline   = 30
+	ReviewWeightNikMismatch      = 20
+	ReviewWeightOcrDiscrepancy   = 15
+	ReviewWeightRepeatedAttempts = 10
+)
+
+// ReviewSignal is one reason a session needs a human look. Weights add up to the
+// queue score.
+type ReviewSignal struct {
+	Code   string `json:"code"`
+	Weight int    `json:"weight"`
+	Detail string `json:"detail"`
+}
+
+// ReviewClaim marks a session as being worked by one reviewer until ExpiresAt.
+type ReviewClaim struct {
+	SessionID string    `json:"ekycSessionId"`
+	Reviewer  string    `json:"reviewer"`
+	ClaimedAt time.Time `json:"claimedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ReviewCandidate is an undecided session with the application flags the ranking reads.
+// Claim is nil when nobody holds an unexpired claim.
+type ReviewCandidate struct {
+	Session EkycSession
+	Flags   map[string]any
+	Claim   *ReviewClaim
+}
+
+type ReviewQueueItem struct {
+	Session *EkycSession   `json:"session"`
+	Score   int            `json:"score"`
+	Signals []ReviewSignal `json:"signals"`
+	Claim   *ReviewClaim   `json:"claim,omitempty"`
+}
+
+// ReviewArtifacts puts the media a reviewer compares side by side in one place.
+type ReviewArtifacts struct {
+	IDCardURL        *string `json:"idCardUrl,omitempty"`
+	SelfieWithIDURL  *string `json:"selfieWithIdUrl,omitempty"`
+	RecordedVideoURL *string `json:"recordedVideoUrl,omitempty"`
+}
+
+// ReviewCase is everything the reviewer sees for one session.
+type ReviewCase struct {
+	ReviewQueueItem
+	Artifacts ReviewArtifacts `json:"artifacts"`
+	Flags     map[string]any  `json:"flags"`
+	Ocr       *OcrResult      `json:"ocr,omitempty"`
+	Verdicts  []ReviewVerdict `json:"verdicts"`
+}
+
+type ListReviewQueueParams struct {
+	Limit  int
+	Offset int
+	// IncludeClaimed also lists sessions claimed by other reviewers.
+	IncludeClaimed bool
+	Reviewer       string
+}
+
+// ListReviewCandidatesParams selects a page of the ranked review queue. FaceThreshold
+// applies to face checks that carry no threshold of their own.
+type ListReviewCandidatesParams struct {
+	FaceThreshold  float64
+	Reviewer       string
+	IncludeClaimed bool
+	Limit          int
+	Offset         int
+}
+
+type ReviewClaimParams struct {
+	SessionID    string
+	Reviewer     string
+	ReviewerRole string
+}
+
+type ReviewVerdictParams struct {
+	SessionID    string
+	Verdict      string
+	Reason       string
+	Reviewer     string
+	ReviewerRole string
+	Signals      []ReviewSignal
+	Timeline     TimelineEntry
+	Audit        AuditEntry
+}
+
+// ReviewVerdict is a recorded reviewer decision, kept next to the session's final
+// decision so later overrides can be traced back to it.
+type ReviewVerdict struct {
+	ID           int64          `json:"id"`
+	SessionID    string         `json:"ekycSessionId"`
+	Verdict      string         `json:"verdict"`
+	Reason       string         `json:"reason"`
+	Reviewer     string         `json:"reviewer"`
+	ReviewerRole string         `json:"reviewerRole"`
+	Signals      []ReviewSignal `json:"signals"`
+	CreatedAt    time.Time      `json:"createdAt"`
+}
@@ -32,6 +32,7 @@ type Application struct {
 	Timeline         []TimelineItem
 	Survey           *SurveyState
 	Portal           *PortalInfo
+	Ocr              *OcrResult
 }
 
 type Region struct {
@@ -61,6 +62,9 @@ type Visit struct {
 	Status        string
 	TkskID        string
 	CreatedAt     time.Time
+	Geofence      *VisitGeofence
+	// ChecklistTemplateID is the checklist template bound when the visit was scheduled.
+	ChecklistTemplateID *int64
 }
 
 type TimelineItem struct {
@@ -108,10 +112,12 @@ type User struct {
 }
 
 type Batch struct {
-	ID        string
-	Code      string
-	Status    string
-	Checksum  *string
+	ID       string
+	Code     string
+	Status   string
+	Checksum *string
+	// LockedAt is set when the batch left DRAFT. Its items cannot change after that.
+	LockedAt  *time.Time
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Items     []string
@@ -187,5 +193,14 @@ type SystemConfig struct {
 	Period     string
 	Thresholds map[string]any
 	Features   map[string]any
-	UpdatedAt  time.Time
+	// Retention holds artifact retention periods in days, keyed by RetentionPeriodKey.
+	// A nil map on update keeps the stored periods.
+	Retention map[string]any
+	// Scheduling holds the TKSK visit duration and working hours, keyed by the
+	// ScheduleKey constants. A nil map on update keeps the stored settings.
+	Scheduling map[string]any
+	// Disbursement holds the currency and per-program amounts, keyed by the
+	// DisbursementKey constants. A nil map on update keeps the stored settings.
+	Disbursement map[string]any
+	UpdatedAt    time.Time
 }
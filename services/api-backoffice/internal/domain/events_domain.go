@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrPoisonEvent marks an event that can never be processed, e.g. a malformed payload.
+// Consumers dead-letter it immediately instead of retrying.
+var ErrPoisonEvent = errors.New("poison event")
+
+const (
+	FaceMatchResultQueue = "ai.face_match.results"
+	LivenessResultQueue  = "ai.liveness.results"
+)
+
+// Event is a message received from a broker queue.
+type Event struct {
+	ID          string
+	Queue       string
+	Type        string
+	Body        []byte
+	Headers     map[string]any
+	Redelivered bool
+}
+
+// EventHandler processes one event. Returning nil acknowledges it, ErrPoisonEvent
+// dead-letters it, and any other error makes the consumer retry with backoff before
+// dead-lettering.
+type EventHandler func(ctx context.Context, event Event) error
+
+// EventConsumer delivers queued events to a handler. Consume blocks until ctx is done.
+type EventConsumer interface {
+	Consume(ctx context.Context, queue string, handler EventHandler) error
+	Close() error
+}
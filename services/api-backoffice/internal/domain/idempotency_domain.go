@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyKey identifies a stored request. Caller is the subject of the bearer token,
+// empty for unauthenticated callers, so two callers that pick the same key never see
+// each other's responses.
+type IdempotencyKey struct {
+	Key    string
+	Method string
+	Path   string
+	Caller string
+}
+
+// IdempotencyRecord is the stored outcome of a request sent with an Idempotency-Key header.
+// A record without Completed set is still being processed by another request.
+// Reservation is a token of the request holding the key. Completing or releasing the key
+// only succeeds while it still matches, so a request whose reservation was taken over
+// after it stalled cannot touch the new owner's record.
+type IdempotencyRecord struct {
+	IdempotencyKey
+	Reservation  string
+	RequestHash  string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	Completed    bool
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+type IdempotencyRepository interface {
+	// Reserve claims the key for the caller. When the key is already taken the existing
+	// record is returned and reserved is false.
+	Reserve(ctx context.Context, record IdempotencyRecord) (existing *IdempotencyRecord, reserved bool, err error)
+	Find(ctx context.Context, key IdempotencyKey) (*IdempotencyRecord, error)
+	Complete(ctx context.Context, record IdempotencyRecord) error
+	Release(ctx context.Context, key IdempotencyKey, reservation string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
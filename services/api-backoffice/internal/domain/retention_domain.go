@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Biometric artifacts of an eKYC session, named after their ekyc_sessions column minus _url.
+const (
+	ArtifactIDCard        = "id_card"
+	ArtifactSelfieWithID  = "selfie_with_id"
+	ArtifactRecordedVideo = "recorded_video"
+)
+
+var RetentionArtifacts = []string{ArtifactIDCard, ArtifactSelfieWithID, ArtifactRecordedVideo}
+
+// RetentionPeriodKey is the SystemConfig.Retention key holding the retention period in
+// days for artifact, e.g. "id_card_days". A missing or zero period keeps the artifact.
+func RetentionPeriodKey(artifact string) string {
+	return artifact + "_days"
+}
+
+// Legal holds exempt a session, or the application built from it, from purging.
+const (
+	LegalHoldScopeSession     = "SESSION"
+	LegalHoldScopeApplication = "APPLICATION"
+)
+
+const RetentionPurgeActor = "system:retention"
+
+// RetentionCandidate is an artifact past its retention period. The clock starts when the
+// session is decided; undecided sessions are never purged.
+type RetentionCandidate struct {
+	SessionID     string    `json:"sessionId"`
+	Artifact      string    `json:"artifact"`
+	URL           string    `json:"url"`
+	DecidedAt     time.Time `json:"decidedAt"`
+	RetentionDays int       `json:"retentionDays"`
+	DueAt         time.Time `json:"dueAt"`
+	Held          bool      `json:"held"`
+}
+
+type RetentionFailure struct {
+	SessionID string `json:"sessionId"`
+	Artifact  string `json:"artifact"`
+	Error     string `json:"error"`
+}
+
+// RetentionReport lists due artifacts. On a dry run nothing is deleted, Purged stays 0
+// and held artifacts are listed too. Held counts every due artifact under a legal hold,
+// listed or not.
+type RetentionReport struct {
+	GeneratedAt time.Time            `json:"generatedAt"`
+	DryRun      bool                 `json:"dryRun"`
+	Periods     map[string]int       `json:"periods"`
+	Candidates  []RetentionCandidate `json:"candidates"`
+	Held        int                  `json:"held"`
+	Purged      int                  `json:"purged"`
+	Failures    []RetentionFailure   `json:"failures"`
+}
+
+// ArtifactTombstone records that an artifact existed and when it was purged.
+type ArtifactTombstone struct {
+	ID            int64     `json:"id"`
+	SessionID     string    `json:"sessionId"`
+	Artifact      string    `json:"artifact"`
+	MediaURL      string    `json:"mediaUrl"`
+	RetentionDays int       `json:"retentionDays"`
+	PurgedBy      string    `json:"purgedBy"`
+	PurgedAt      time.Time `json:"purgedAt"`
+}
+
+type LegalHold struct {
+	ID         int64      `json:"id"`
+	Scope      string     `json:"scope"`
+	TargetID   string     `json:"targetId"`
+	Reason     string     `json:"reason"`
+	PlacedBy   string     `json:"placedBy"`
+	PlacedAt   time.Time  `json:"placedAt"`
+	ReleasedBy *string    `json:"releasedBy,omitempty"`
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+}
+
+type PlaceLegalHoldParams struct {
+	Scope    string `json:"scope"`
+	TargetID string `json:"targetId"`
+	Reason   string `json:"reason"`
+	Actor    string `json:"actor"`
+}
+
+type ReleaseLegalHoldParams struct {
+	ID    int64
+	Actor string
+}
+
+// REPOSITORIES
+type RetentionRepository interface {
+	GetConfig(ctx context.Context) (*SystemConfig, error)
+	// ListRetentionCandidates returns up to limit artifacts due at now under periods (days
+	// per artifact), oldest first. Held artifacts are flagged and listed after the others,
+	// and only when includeHeld is set.
+	ListRetentionCandidates(ctx context.Context, periods map[string]int, now time.Time, limit int, includeHeld bool) ([]RetentionCandidate, error)
+	// CountHeldRetentionCandidates counts the due artifacts kept by a legal hold.
+	CountHeldRetentionCandidates(ctx context.Context, periods map[string]int, now time.Time) (int, error)
+	// TombstoneArtifact nulls the artifact URL and records the tombstone, then calls
+	// deleteMedia before committing; an error from it rolls the change back. It returns
+	// ErrInvalidState when the URL changed or a hold was placed since the candidate was listed.
+	TombstoneArtifact(ctx context.Context, candidate RetentionCandidate, actor string, deleteMedia func(context.Context) error) (*ArtifactTombstone, error)
+	ListArtifactTombstones(ctx context.Context, sessionID string, limit int) ([]ArtifactTombstone, error)
+
+	ListLegalHolds(ctx context.Context, activeOnly bool) ([]LegalHold, error)
+	PlaceLegalHold(ctx context.Context, params PlaceLegalHoldParams) (*LegalHold, error)
+	ReleaseLegalHold(ctx context.Context, params ReleaseLegalHoldParams) (*LegalHold, error)
+}
+
+// SERVICES
+type RetentionService interface {
+	Report(ctx context.Context, limit int) (*RetentionReport, error)
+	Purge(ctx context.Context, actor string, limit int) (*RetentionReport, error)
+	ListTombstones(ctx context.Context, sessionID string, limit int) ([]ArtifactTombstone, error)
+	ListLegalHolds(ctx context.Context, activeOnly bool) ([]LegalHold, error)
+	PlaceLegalHold(ctx context.Context, params PlaceLegalHoldParams) (*LegalHold, error)
+	ReleaseLegalHold(ctx context.Context, params ReleaseLegalHoldParams) (*LegalHold, error)
+}
+
+// HTTP HANDLERS
+type RetentionHTTPHandler interface {
+	Report(c echo.Context) error
+	Purge(c echo.Context) error
+	ListTombstones(c echo.Context) error
+	ListLegalHolds(c echo.Context) error
+	PlaceLegalHold(c echo.Context) error
+	ReleaseLegalHold(c echo.Context) error
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// ThresholdGeofenceMaxDistance is the SystemConfig.Thresholds key holding how far, in
+// metres, a submitted visit's geotag may lie from the beneficiary's kelurahan centroid.
+const ThresholdGeofenceMaxDistance = "geofence_max_distance_m"
+
+// Flags of a visit geotag that failed the geofence check.
+const (
+	GeofenceOutsideKelurahan = "OUTSIDE_KELURAHAN"
+	GeofenceTooFar           = "TOO_FAR"
+	GeofenceNoGeotag         = "NO_GEOTAG"
+	GeofenceUnknownKelurahan = "UNKNOWN_KELURAHAN" // beneficiary's kelurahan is not in the boundary dataset
+)
+
+// VisitGeofence is the result of checking a submitted visit's geotag. Kelurahan is where
+// the geotag fell, if inside a known boundary. DistanceMeters is measured to the centroid
+// of the beneficiary's registered kelurahan.
+type VisitGeofence struct {
+	Flags          []string  `json:"flags"`
+	Kelurahan      string    `json:"kelurahan,omitempty"`
+	DistanceMeters *float64  `json:"distanceMeters,omitempty"`
+	CheckedAt      time.Time `json:"checkedAt"`
+}
+
+func (g *VisitGeofence) Flagged() bool {
+	return g != nil && len(g.Flags) > 0
+}
+
+// GeofenceCheck checks a geotag against the beneficiary's registered region.
+type GeofenceCheck func(lat, lng *float64, region Region) VisitGeofence
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+var ErrPhotoReused = errors.New("photo already used on a visit")
+
+const MaxVisitPhotoBytes = 20 << 20
+
+// SystemConfig.Thresholds keys for visit photo verification.
+const (
+	// ThresholdPhotoTimeTolerance is how many minutes a photo's capture time may be from
+	// the visit's scheduled start.
+	ThresholdPhotoTimeTolerance = "photo_time_tolerance_minutes"
+	// ThresholdPhotoMaxDistance is how far, in metres, a photo's GPS position may be from
+	// the visit geotag.
+	ThresholdPhotoMaxDistance = "photo_max_distance_m"
+	// ThresholdPhotoReuseDistance is the largest perceptual hash distance, in bits, at
+	// which two photos count as the same picture.
+	ThresholdPhotoReuseDistance = "photo_reuse_max_hash_distance"
+)
+
+// Flags of a visit photo that failed verification.
+const (
+	PhotoNoExif           = "NO_EXIF" // stripped, so neither time nor place can be checked
+	PhotoNoCaptureTime    = "NO_CAPTURE_TIME"
+	PhotoNoGPS            = "NO_GPS"
+	PhotoTimeMismatch     = "TIME_MISMATCH"
+	PhotoLocationMismatch = "LOCATION_MISMATCH"
+	PhotoLowDetail        = "LOW_DETAIL" // too flat to hash, so never checked for re-use
+)
+
+// VisitPhotoVerification compares a photo's EXIF with its visit. TimeDeltaMinutes is the
+// capture time minus the scheduled start. DistanceMeters is measured to the visit geotag
+// and left nil when either position is missing.
+type VisitPhotoVerification struct {
+	Flags            []string `json:"flags"`
+	TimeDeltaMinutes *int     `json:"timeDeltaMinutes,omitempty"`
+	DistanceMeters   *float64 `json:"distanceMeters,omitempty"`
+}
+
+func (v VisitPhotoVerification) Flagged() bool {
+	return len(v.Flags) > 0
+}
+
+// VisitPhoto is a photo uploaded for a visit. PerceptualHash is the hex difference hash.
+type VisitPhoto struct {
+	ID             int64                  `json:"id"`
+	VisitID        string                 `json:"visitId"`
+	URL            string                 `json:"url"`
+	SHA256         string                 `json:"sha256"`
+	PerceptualHash string                 `json:"perceptualHash"`
+	CapturedAt     *time.Time             `json:"capturedAt,omitempty"`
+	GpsLat         *float64               `json:"gpsLat,omitempty"`
+	GpsLng         *float64               `json:"gpsLng,omitempty"`
+	Camera         string                 `json:"camera,omitempty"`
+	Verification   VisitPhotoVerification `json:"verification"`
+	UploadedBy     string                 `json:"uploadedBy"`
+	UploadedAt     time.Time              `json:"uploadedAt"`
+}
+
+// VisitPhotoMatch is an earlier photo that looks the same as an upload.
+type VisitPhotoMatch struct {
+	PhotoID       int64  `json:"photoId"`
+	VisitID       string `json:"visitId"`
+	ApplicationID string `json:"applicationId"`
+	URL           string `json:"url"`
+	HashDistance  int    `json:"hashDistance"`
+}
+
+// PhotoReuseError rejects a photo already uploaded for a visit. It matches
+// ErrPhotoReused.
+type PhotoReuseError struct {
+	Match VisitPhotoMatch
+}
+
+func (e *PhotoReuseError) Error() string {
+	return fmt.Sprintf("%s: sama dengan foto kunjungan %s", ErrPhotoReused, e.Match.VisitID)
+}
+
+func (e *PhotoReuseError) Unwrap() error {
+	return ErrPhotoReused
+}
+
+// VisitPhotoCheck compares a photo with the visit as it is when the photo is stored.
+type VisitPhotoCheck func(photo *VisitPhoto, scheduledAt time.Time, geotagLat, geotagLng *float64) VisitPhotoVerification
+
+type UploadVisitPhotoParams struct {
+	ApplicationID string
+	VisitID       string
+	Actor         string
+	Filename      string
+	Content       []byte
+}
+
+type AddVisitPhotoParams struct {
+	ApplicationID string
+	Actor         string
+	Photo         VisitPhoto
+	// Hash is the perceptual hash of the photo, which must be at least ReuseDistance+1
+	// bits away from every photo already stored. It is nil for a photo too flat to
+	// compare, which is neither checked nor matched against later.
+	Hash          *uint64
+	ReuseDistance int
+	Check         VisitPhotoCheck
+}
+
+// REPOSITORIES
+type VisitPhotoRepository interface {
+	GetConfig(ctx context.Context) (*SystemConfig, error)
+	// FindSimilarVisitPhoto returns the stored photo closest to hash within maxDistance
+	// bits, or ErrNotFound.
+	FindSimilarVisitPhoto(ctx context.Context, hash uint64, maxDistance int) (*VisitPhotoMatch, error)
+	// AddVisitPhoto stores the photo and appends its URL to the visit's photos. Verified
+	// visits take no more photos. It returns a *PhotoReuseError when a similar photo was
+	// stored in the meantime.
+	AddVisitPhoto(ctx context.Context, params AddVisitPhotoParams) (*VisitPhoto, error)
+	// ListVisitPhotos returns the uploaded photos still attached to the visit.
+	ListVisitPhotos(ctx context.Context, appID, visitID string) ([]VisitPhoto, error)
+}
+
+// SERVICES
+type VisitPhotoService interface {
+	Upload(ctx context.Context, params UploadVisitPhotoParams) (*VisitPhoto, error)
+	List(ctx context.Context, appID, visitID string) ([]VisitPhoto, error)
+}
+
+// HTTP HANDLERS
+type VisitPhotoHTTPHandler interface {
+	Upload(c echo.Context) error
+	List(c echo.Context) error
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// VisitReviewRoles lists the roles allowed to verify or return submitted visits.
+var VisitReviewRoles = []string{"ADMIN"}
+
+// FeatureRequireVerifiedVisit is the SystemConfig.Features flag that makes FINAL_APPROVED
+// wait for a supervisor-verified visit instead of any submitted one. An approved eKYC
+// then moves the application to FIELD_VISIT instead.
+const FeatureRequireVerifiedVisit = "requireVerifiedVisit"
+
+// Supervisor decisions on a submitted visit. A returned visit goes back to IN_PROGRESS.
+const (
+	VisitReviewVerified = "VERIFIED"
+	VisitReviewReturned = "RETURNED"
+)
+
+// VisitReview is a supervisor decision. VisitVersion is the visit version reviewed.
+type VisitReview struct {
+	ID           int64     `json:"id"`
+	VisitID      string    `json:"visitId"`
+	Decision     string    `json:"decision"`
+	Comment      string    `json:"comment"`
+	Reviewer     string    `json:"reviewer"`
+	ReviewerRole string    `json:"reviewerRole"`
+	VisitVersion int       `json:"visitVersion"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// VisitReviewQueueItem is a submitted visit waiting for a supervisor, with what the
+// earlier checks found. Returns counts how often it was sent back before.
+type VisitReviewQueueItem struct {
+	VisitID       string    `json:"visitId"`
+	ApplicationID string    `json:"applicationId"`
+	ApplicantName string    `json:"applicantName"`
+	Region        Region    `json:"region"`
+	TkskID        string    `json:"tkskId"`
+	TkskName      string    `json:"tkskName"`
+	ScheduledAt   time.Time `json:"scheduledAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+	Version       int       `json:"version"`
+	GeofenceFlags []string  `json:"geofenceFlags"`
+	Photos        int       `json:"photos"`
+	FlaggedPhotos int       `json:"flaggedPhotos"`
+	Returns       int       `json:"returns"`
+}
+
+type ListVisitReviewQueueParams struct {
+	// Region narrows the queue to one province, regency, district or village name.
+	Region string
+	// RegionScope is the supervisor's scope. An empty scope covers every region.
+	RegionScope []string
+	Limit       int
+}
+
+// VisitReviewTarget is the visit a supervisor is about to review.
+type VisitReviewTarget struct {
+	TkskID string
+	Status string
+	Region Region
+}
+
+type ReviewVisitParams struct {
+	ApplicationID string
+	VisitID       string
+	Decision      string
+	Comment       string
+	// Version, when set, is the visit version the supervisor looked at. The review is
+	// refused if the TKSK changed the visit since.
+	Version      int
+	Reviewer     string
+	ReviewerRole string
+	RegionScope  []string
+	Timeline     TimelineEntry
+	Audit        AuditEntry
+}
+
+// REPOSITORIES
+type VisitReviewRepository interface {
+	// ListVisitReviewQueue returns submitted visits, longest waiting first.
+	ListVisitReviewQueue(ctx context.Context, params ListVisitReviewQueueParams) ([]VisitReviewQueueItem, error)
+	GetVisitReviewTarget(ctx context.Context, appID, visitID string) (*VisitReviewTarget, error)
+	// RecordVisitReview moves a SUBMITTED visit to VERIFIED or back to IN_PROGRESS and
+	// stores the review with its timeline and audit entries.
+	RecordVisitReview(ctx context.Context, params ReviewVisitParams) (*VisitReview, error)
+	ListVisitReviews(ctx context.Context, appID, visitID string) ([]VisitReview, error)
+}
+
+// SERVICES
+type VisitReviewService interface {
+	Queue(ctx context.Context, params ListVisitReviewQueueParams) ([]VisitReviewQueueItem, error)
+	Review(ctx context.Context, params ReviewVisitParams) (*VisitReview, error)
+	List(ctx context.Context, appID, visitID string) ([]VisitReview, error)
+}
+
+// HTTP HANDLERS
+type VisitReviewHTTPHandler interface {
+	Queue(c echo.Context) error
+	Verify(c echo.Context) error
+	Return(c echo.Context) error
+	List(c echo.Context) error
+}
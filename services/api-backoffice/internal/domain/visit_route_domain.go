@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Where the location of a RouteStop comes from.
+const (
+	// RouteLocationHousehold is the geotag of an earlier visit to the same application.
+	RouteLocationHousehold = "HOUSEHOLD"
+	// RouteLocationKelurahan is the centroid of the beneficiary's kelurahan.
+	RouteLocationKelurahan = "KELURAHAN"
+	// RouteLocationUnknown stops are put at the end of the route in schedule order.
+	RouteLocationUnknown = "UNKNOWN"
+)
+
+// RouteVisit is a planned visit with what is known of where the household is.
+// HouseholdLat and HouseholdLng come from an earlier, unflagged visit geotag.
+type RouteVisit struct {
+	VisitID       string
+	ApplicationID string
+	ApplicantName string
+	ScheduledAt   time.Time
+	Region        Region
+	HouseholdLat  *float64
+	HouseholdLng  *float64
+}
+
+// RouteStop is one visit of a planned route. DistanceMeters is the straight-line distance
+// from the previous stop (or the start), TravelMinutes the estimated travel time on the
+// road. SuggestedAt is the start time to apply back to the visit.
+type RouteStop struct {
+	Sequence           int       `json:"sequence"`
+	VisitID            string    `json:"visitId"`
+	ApplicationID      string    `json:"applicationId"`
+	ApplicantName      string    `json:"applicantName"`
+	Kelurahan          string    `json:"kelurahan,omitempty"`
+	Lat                *float64  `json:"lat,omitempty"`
+	Lng                *float64  `json:"lng,omitempty"`
+	LocationSource     string    `json:"locationSource"`
+	ScheduledAt        time.Time `json:"scheduledAt"`
+	SuggestedAt        time.Time `json:"suggestedAt"`
+	SuggestedEnd       time.Time `json:"suggestedEnd"`
+	DistanceMeters     float64   `json:"distanceMeters"`
+	TravelMinutes      int       `json:"travelMinutes"`
+	WithinWorkingHours bool      `json:"withinWorkingHours"`
+}
+
+type VisitRoute struct {
+	TkskID               string       `json:"tkskId"`
+	TkskName             string       `json:"tkskName"`
+	Date                 string       `json:"date"`
+	Timezone             string       `json:"timezone"`
+	Start                *VisitGeotag `json:"start,omitempty"`
+	VisitDurationMinutes int          `json:"visitDurationMinutes"`
+	TravelSpeedKmh       float64      `json:"travelSpeedKmh"`
+	Stops                []RouteStop  `json:"stops"`
+	TotalDistanceMeters  float64      `json:"totalDistanceMeters"`
+	TotalTravelMinutes   int          `json:"totalTravelMinutes"`
+	// ScheduledDistanceMeters is the distance of the located stops in their current
+	// schedule order, for comparison with TotalDistanceMeters.
+	ScheduledDistanceMeters float64 `json:"scheduledDistanceMeters"`
+	// FitsWorkingHours is false when the suggested schedule runs past the end of the
+	// working day. The stops that do not fit are marked.
+	FitsWorkingHours bool `json:"fitsWorkingHours"`
+}
+
+type PlanVisitRouteParams struct {
+	TkskID string
+	// Date is the local day to plan, YYYY-MM-DD. Empty means today.
+	Date string
+	// Start is where the TKSK sets off from. Without it the route starts at whichever
+	// visit gives the shortest route.
+	Start *VisitGeotag
+}
+
+// VisitReschedule moves one visit to a new start time.
+type VisitReschedule struct {
+	VisitID     string    `json:"visitId"`
+	ScheduledAt time.Time `json:"scheduledAt"`
+}
+
+type ApplyVisitRouteParams struct {
+	TkskID string
+	Actor  string
+	Stops  []VisitReschedule
+}
+
+type RescheduleVisitsParams struct {
+	TkskID   string
+	Actor    string
+	Stops    []VisitReschedule
+	Duration time.Duration
+}
+
+// REPOSITORIES
+type VisitRouteRepository interface {
+	GetConfig(ctx context.Context) (*SystemConfig, error)
+	GetVisitAssignee(ctx context.Context, userID string) (*VisitAssignee, error)
+	// ListRouteVisits returns the TKSK's planned visits starting in [from, to), earliest
+	// first.
+	ListRouteVisits(ctx context.Context, tkskID string, from, to time.Time) ([]RouteVisit, error)
+	// RescheduleVisits moves planned visits of the TKSK in one transaction. It returns a
+	// *ScheduleConflictError when a new time overlaps another planned or in-progress
+	// visit of the TKSK, and the moved visits otherwise.
+	RescheduleVisits(ctx context.Context, params RescheduleVisitsParams) ([]CalendarVisit, error)
+}
+
+// SERVICES
+type VisitRouteService interface {
+	Plan(ctx context.Context, params PlanVisitRouteParams) (*VisitRoute, error)
+	Apply(ctx context.Context, params ApplyVisitRouteParams) ([]CalendarVisit, error)
+}
+
+// HTTP HANDLERS
+type VisitRouteHTTPHandler interface {
+	Plan(c echo.Context) error
+	Apply(c echo.Context) error
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+var ErrScheduleConflict = errors.New("visit slot taken")
+
+const (
+	VisitStatusPlanned    = "PLANNED"
+	VisitStatusInProgress = "IN_PROGRESS"
+	VisitStatusSubmitted  = "SUBMITTED"
+	VisitStatusVerified   = "VERIFIED"
+)
+
+// Keys of SystemConfig.Scheduling. Missing keys fall back to DefaultVisitSchedule.
+const (
+	ScheduleKeyVisitDuration = "visit_duration_minutes"
+	ScheduleKeyWorkStart     = "work_start" // "HH:MM", local time
+	ScheduleKeyWorkEnd       = "work_end"
+	ScheduleKeyWorkDays      = "work_days" // ISO weekdays, 1 = Monday .. 7 = Sunday
+	ScheduleKeyTimezone      = "timezone"  // IANA name, e.g. "Asia/Jakarta"
+	ScheduleKeyTravelSpeed   = "travel_speed_kmh"
+)
+
+// VisitSchedule is the parsed scheduling config. WorkStart and WorkEnd are offsets from
+// local midnight; a visit must start and end inside them on a work day.
+type VisitSchedule struct {
+	VisitDuration time.Duration
+	WorkStart     time.Duration
+	WorkEnd       time.Duration
+	WorkDays      []time.Weekday
+	Location      *time.Location
+	// TravelSpeedKmh is the average speed between households, used by route planning.
+	TravelSpeedKmh float64
+}
+
+// DefaultVisitSchedule: 90 minute visits, 08:00-16:00 WIB, Monday to Friday, travelling
+// at 20 km/h.
+func DefaultVisitSchedule() VisitSchedule {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.FixedZone("WIB", 7*60*60)
+	}
+	return VisitSchedule{
+		VisitDuration:  90 * time.Minute,
+		WorkStart:      8 * time.Hour,
+		WorkEnd:        16 * time.Hour,
+		WorkDays:       []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Location:       loc,
+		TravelSpeedKmh: 20,
+	}
+}
+
+type VisitSlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// CalendarVisit is a visit as it occupies a TKSK's calendar.
+type CalendarVisit struct {
+	VisitID       string    `json:"visitId"`
+	ApplicationID string    `json:"applicationId"`
+	ApplicantName string    `json:"applicantName"`
+	Status        string    `json:"status"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+}
+
+type TkskCalendar struct {
+	TkskID               string          `json:"tkskId"`
+	TkskName             string          `json:"tkskName"`
+	Timezone             string          `json:"timezone"`
+	VisitDurationMinutes int             `json:"visitDurationMinutes"`
+	From                 time.Time       `json:"from"`
+	To                   time.Time       `json:"to"`
+	Visits               []CalendarVisit `json:"visits"`
+}
+
+// VisitAssignee is the user a visit is assigned to.
+type VisitAssignee struct {
+	ID          string
+	Name        string
+	Role        string
+	RegionScope []string
+}
+
+// ScheduleConflictError lists the visits a requested slot overlaps and free slots to
+// offer instead. It matches ErrScheduleConflict.
+type ScheduleConflictError struct {
+	Conflicts   []CalendarVisit
+	Suggestions []VisitSlot
+}
+
+func (e *ScheduleConflictError) Error() string {
+	return fmt.Sprintf("%s: bentrok dengan %d kunjungan lain", ErrScheduleConflict, len(e.Conflicts))
+}
+
+func (e *ScheduleConflictError) Unwrap() error {
+	return ErrScheduleConflict
+}
+
+type SuggestVisitSlotsParams struct {
+	TkskID string
+	After  time.Time
+	Count  int
+}
+
+type TkskCalendarParams struct {
+	TkskID string
+	From   time.Time
+	To     time.Time
+}
+
+// REPOSITORIES
+type VisitScheduleRepository interface {
+	GetConfig(ctx context.Context) (*SystemConfig, error)
+	GetVisitAssignee(ctx context.Context, userID string) (*VisitAssignee, error)
+	// GetApplicationRegion returns the region of the application's beneficiary.
+	GetApplicationRegion(ctx context.Context, appID string) (*Region, error)
+	// ListTkskVisits returns the TKSK's visits in one of statuses starting in [from, to),
+	// earliest first. End is left zero.
+	ListTkskVisits(ctx context.Context, tkskID string, from, to time.Time, statuses []string) ([]CalendarVisit, error)
+	// CreateScheduledVisit inserts the visit unless the TKSK already has a planned or
+	// in-progress visit less than duration away, in which case it returns a
+	// *ScheduleConflictError. Scheduling is serialized per TKSK.
+	CreateScheduledVisit(ctx context.Context, visit *Visit, duration time.Duration, timeline TimelineEntry) error
+	// CurrentChecklistTemplate returns the newest published checklist template for the
+	// program of the application's beneficiary in the configured period, falling back
+	// to ChecklistAnyProgram. ErrNotFound if there is none.
+	CurrentChecklistTemplate(ctx context.Context, appID string) (*ChecklistTemplate, error)
+}
+
+// SERVICES
+type VisitScheduleService interface {
+	ScheduleVisit(ctx context.Context, appID, actor string, scheduledAt time.Time, tkskID string) (*Visit, error)
+	SuggestSlots(ctx context.Context, params SuggestVisitSlotsParams) ([]VisitSlot, error)
+	Calendar(ctx context.Context, params TkskCalendarParams) (*TkskCalendar, error)
+}
+
+// HTTP HANDLERS
+type VisitScheduleHTTPHandler interface {
+	SuggestSlots(c echo.Context) error
+	Calendar(c echo.Context) error
+}
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// FeatureOfflineTKSK is the SystemConfig.Features flag gating offline sync.
+const FeatureOfflineTKSK = "enableOfflineTKSK"
+
+// Results of an offline visit mutation.
+const (
+	VisitSyncApplied   = "APPLIED"
+	VisitSyncDuplicate = "DUPLICATE" // applied by an earlier sync of the same device
+	VisitSyncConflict  = "CONFLICT"  // the visit changed on the server since BaseVersion
+	VisitSyncRejected  = "REJECTED"
+)
+
+type VisitGeotag struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// VisitMutation is one change a TKSK recorded on a device while offline. BaseVersion is
+// the visit version the device last received. Only the fields that are set change.
+type VisitMutation struct {
+	MutationID       string         `json:"mutationId"`
+	VisitID          string         `json:"visitId"`
+	BaseVersion      int            `json:"baseVersion"`
+	ClientRecordedAt time.Time      `json:"clientRecordedAt"`
+	Status           *string        `json:"status,omitempty"`
+	Geotag           *VisitGeotag   `json:"geotag,omitempty"`
+	Checklist        map[string]any `json:"checklist,omitempty"`
+	Photos           []string       `json:"photos,omitempty"`
+}
+
+// SyncedVisit is a visit as a device stores it.
+type SyncedVisit struct {
+	ID            string         `json:"id"`
+	ApplicationID string         `json:"applicationId"`
+	ApplicantName string         `json:"applicantName"`
+	ScheduledAt   time.Time      `json:"scheduledAt"`
+	Status        string         `json:"status"`
+	GeotagLat     *float64       `json:"geotagLat,omitempty"`
+	GeotagLng     *float64       `json:"geotagLng,omitempty"`
+	Photos        []string       `json:"photos"`
+	Checklist     map[string]any `json:"checklist"`
+	Version       int            `json:"version"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+	Geofence      *VisitGeofence `json:"geofence,omitempty"`
+	// ChecklistTemplateID names the template the checklist must follow.
+	ChecklistTemplateID *int64 `json:"checklistTemplateId,omitempty"`
+}
+
+// VisitMutationResult reports one mutation. Version is the visit version after an
+// applied or duplicate mutation. Visit carries the server state on a conflict.
+type VisitMutationResult struct {
+	MutationID string       `json:"mutationId"`
+	VisitID    string       `json:"visitId"`
+	Result     string       `json:"result"`
+	Version    int          `json:"version,omitempty"`
+	Error      string       `json:"error,omitempty"`
+	Visit      *SyncedVisit `json:"visit,omitempty"`
+	// FieldErrors explain a checklist the template rejected.
+	FieldErrors []ChecklistFieldError `json:"fieldErrors,omitempty"`
+}
+
+type VisitSyncParams struct {
+	TkskID    string
+	DeviceID  string
+	Actor     string
+	SyncToken string
+	Mutations []VisitMutation
+}
+
+// VisitSyncResult answers a sync: per-mutation results in request order, the TKSK's
+// visits changed since the given sync token and the token to send next time.
+type VisitSyncResult struct {
+	DeviceID  string                `json:"deviceId"`
+	SyncToken string                `json:"syncToken"`
+	Results   []VisitMutationResult `json:"results"`
+	Changes   []SyncedVisit         `json:"changes"`
+}
+
+type ApplyVisitSyncParams struct {
+	TkskID    string
+	DeviceID  string
+	Actor     string
+	Since     int64
+	Mutations []VisitMutation
+	// Geofence checks the geotag of visits the mutations submit.
+	Geofence GeofenceCheck
+	// ChecklistCheck validates checklists against the template bound to their visit.
+	ChecklistCheck ChecklistCheck
+}
+
+// REPOSITORIES
+type VisitSyncRepository interface {
+	GetConfig(ctx context.Context) (*SystemConfig, error)
+	GetVisitAssignee(ctx context.Context, userID string) (*VisitAssignee, error)
+	// ApplyVisitSync registers the device to the TKSK (ErrForbidden if it belongs to
+	// another one), applies the mutations in order and returns their results, the
+	// TKSK's visits with a change after since and the new sync token. It runs in one
+	// transaction serialized with every other write to the TKSK's visits.
+	ApplyVisitSync(ctx context.Context, params ApplyVisitSyncParams) ([]VisitMutationResult, []SyncedVisit, int64, error)
+}
+
+// SERVICES
+type VisitSyncService interface {
+	Sync(ctx context.Context, params VisitSyncParams) (*VisitSyncResult, error)
+}
+
+// HTTP HANDLERS
+type VisitSyncHTTPHandler interface {
+	Sync(c echo.Context) error
+}
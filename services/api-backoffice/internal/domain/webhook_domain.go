@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Webhook event types partners can subscribe to. WebhookEventAll matches every type.
+const (
+	WebhookEventEkycFinalized            = "ekyc.session.finalized"
+	WebhookEventApplicationStatusChanged = "application.status_changed"
+	WebhookEventAll                      = "*"
+)
+
+var WebhookEventTypes = []string{WebhookEventEkycFinalized, WebhookEventApplicationStatusChanged}
+
+// Delivery states. A DEAD delivery ran out of attempts and only moves again through
+// a redelivery.
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryDelivered = "DELIVERED"
+	WebhookDeliveryDead      = "DEAD"
+)
+
+// WebhookSubscription is a partner endpoint. Secret signs every payload and is only
+// returned when the subscription is created.
+type WebhookSubscription struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"eventTypes"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+type CreateWebhookSubscriptionParams struct {
+	Name       string   `json:"name"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	Secret     string   `json:"secret"`
+}
+
+// UpdateWebhookSubscriptionParams leaves nil fields unchanged.
+type UpdateWebhookSubscriptionParams struct {
+	ID         string    `json:"-"`
+	Name       *string   `json:"name"`
+	URL        *string   `json:"url"`
+	EventTypes *[]string `json:"eventTypes"`
+	Active     *bool     `json:"active"`
+}
+
+// WebhookEvent is the envelope posted to subscribers.
+type WebhookEvent struct {
+	ID        string         `json:"id"`
+	Type      string         `json:"type"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Data      map[string]any `json:"data"`
+}
+
+// WebhookDelivery is one event queued for one subscription. URL and Secret are filled
+// when the dispatcher claims the delivery.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID string          `json:"subscriptionId"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastError      *string         `json:"lastError,omitempty"`
+	LastStatusCode *int            `json:"lastStatusCode,omitempty"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+	URL            string          `json:"-"`
+	Secret         string          `json:"-"`
+}
+
+type ListWebhookDeliveriesParams struct {
+	SubscriptionID string
+	Status         string
+	Limit          int
+}
+
+// WebhookFailure records a failed attempt. A nil NextAttemptAt dead-letters the delivery.
+type WebhookFailure struct {
+	DeliveryID    int64
+	StatusCode    *int
+	Error         string
+	NextAttemptAt *time.Time
+}
+
+type WebhookRepository interface {
+	ListWebhookSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	CreateWebhookSubscription(ctx context.Context, sub WebhookSubscription) (*WebhookSubscription, error)
+	UpdateWebhookSubscription(ctx context.Context, params UpdateWebhookSubscriptionParams) (*WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id string) error
+	ListWebhookDeliveries(ctx context.Context, params ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	// ClaimDueWebhookDeliveries takes up to limit pending deliveries whose time has come,
+	// counts the attempt and hides them from other dispatchers for lease.
+	ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error)
+	MarkWebhookDelivered(ctx context.Context, id int64, statusCode int) error
+	MarkWebhookFailed(ctx context.Context, failure WebhookFailure) error
+	RedeliverWebhook(ctx context.Context, id int64) (*WebhookDelivery, error)
+}
+
+// WebhookSender posts a claimed delivery to its subscriber and returns the HTTP status.
+type WebhookSender interface {
+	Send(ctx context.Context, delivery WebhookDelivery) (int, error)
+}
+
+type WebhookService interface {
+	ListSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, params CreateWebhookSubscriptionParams) (*WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, params UpdateWebhookSubscriptionParams) (*WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, params ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	Redeliver(ctx context.Context, id int64) (*WebhookDelivery, error)
+}
+
+type WebhookHTTPHandler interface {
+	ListSubscriptions(c echo.Context) error
+	CreateSubscription(c echo.Context) error
+	UpdateSubscription(c echo.Context) error
+	DeleteSubscription(c echo.Context) error
+	ListDeliveries(c echo.Context) error
+	Redeliver(c echo.Context) error
+}
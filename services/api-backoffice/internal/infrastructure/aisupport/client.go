@@ -0,0 +1,110 @@
+package aisupport
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	ekycv1 "e-kyc/shared/proto/ekyc/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+const defaultCallTimeout = 15 * time.Second
+
+// Client starts face-match and liveness jobs on the AI support service over gRPC.
+type Client struct {
+	conn    *grpc.ClientConn
+	rpc     ekycv1.EkycSupportServiceClient
+	timeout time.Duration
+}
+
+var _ domain.AiSupportClient = (*Client)(nil)
+
+// NewClient dials target lazily. The endpoint may carry an http:// scheme, as in
+// AI_SUPPORT_GRPC_ENDPOINT shared with the gateway.
+func NewClient(target string) (*Client, error) {
+	target = strings.TrimSpace(target)
+	for _, scheme := range []string{"http://", "https://"} {
+		target = strings.TrimPrefix(target, scheme)
+	}
+	if target == "" {
+		return nil, fmt.Errorf("ai support endpoint is empty")
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("dial ai support: %w", err)
+	}
+	return NewClientFromConn(conn), nil
+}
+
+// NewClientFromConn wraps an existing connection, e.g. one dialed to ekycfake.
+func NewClientFromConn(conn *grpc.ClientConn) *Client {
+	return &Client{conn: conn, rpc: ekycv1.NewEkycSupportServiceClient(conn), timeout: defaultCallTimeout}
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) StartFaceMatchJob(ctx context.Context, payload domain.StartFaceMatchJobPayload) (*domain.AsyncJobHandle, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	resp, err := c.rpc.StartFaceMatchJob(ctx, &ekycv1.StartFaceMatchRequest{
+		SessionId:          payload.SessionID,
+		KtpImage:           toImagePayload(payload.KtpImage),
+		SelfieImage:        toImagePayload(payload.SelfieImage),
+		FaceMatchThreshold: payload.FaceMatchThreshold,
+	})
+	if err != nil {
+		return nil, mapStatus(err)
+	}
+	job := resp.GetJob()
+	return &domain.AsyncJobHandle{JobID: job.GetJobId(), Queue: job.GetQueue()}, nil
+}
+
+func (c *Client) StartLivenessJob(ctx context.Context, payload domain.StartLivenessJobPayload) (*domain.AsyncJobHandle, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	frames := make([]*ekycv1.ImagePayload, 0, len(payload.LivenessFrames))
+	for _, frame := range payload.LivenessFrames {
+		frames = append(frames, toImagePayload(frame))
+	}
+	resp, err := c.rpc.StartLivenessJob(ctx, &ekycv1.StartLivenessRequest{
+		SessionId:      payload.SessionID,
+		LivenessFrames: frames,
+		Gestures:       payload.Gestures,
+	})
+	if err != nil {
+		return nil, mapStatus(err)
+	}
+	job := resp.GetJob()
+	return &domain.AsyncJobHandle{JobID: job.GetJobId(), Queue: job.GetQueue()}, nil
+}
+
+func toImagePayload(img domain.BinaryImage) *ekycv1.ImagePayload {
+	return &ekycv1.ImagePayload{Content: img.Content, MimeType: img.MimeType}
+}
+
+// mapStatus translates gRPC status codes into the domain errors the handlers understand.
+func mapStatus(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	switch st.Code() {
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		return fmt.Errorf("%w: %s", domain.ErrInvalidState, st.Message())
+	case codes.NotFound:
+		return fmt.Errorf("%w: %s", domain.ErrNotFound, st.Message())
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return fmt.Errorf("%w: %s", domain.ErrAiSupportUnavailable, st.Message())
+	default:
+		return fmt.Errorf("ai support: %s", st.Message())
+	}
+}
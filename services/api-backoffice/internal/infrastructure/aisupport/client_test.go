@@ -0,0 +1,110 @@
+package aisupport
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/proto/ekyc/v1/ekycfake"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func newFakeClient(t *testing.T) (*Client, *ekycfake.Server) {
+	t.Helper()
+	fake := ekycfake.NewServer()
+	fake.Start()
+	t.Cleanup(fake.Stop)
+	conn, err := fake.Dial(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClientFromConn(conn)
+	t.Cleanup(func() { client.Close() })
+	return client, fake
+}
+
+func TestClientStartsJobs(t *testing.T) {
+	client, fake := newFakeClient(t)
+	ctx := context.Background()
+
+	job, err := client.StartFaceMatchJob(ctx, domain.StartFaceMatchJobPayload{
+		SessionID:          "sess-1",
+		KtpImage:           domain.BinaryImage{Content: []byte("ktp"), MimeType: "image/jpeg"},
+		SelfieImage:        domain.BinaryImage{Content: []byte("selfie"), MimeType: "image/jpeg"},
+		FaceMatchThreshold: 0.8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.JobID != "fake-face-match-1" || job.Queue != ekycfake.FaceMatchQueue {
+		t.Errorf("face match job %+v", job)
+	}
+	requests := fake.FaceMatchRequests()
+	if len(requests) != 1 || requests[0].GetSessionId() != "sess-1" ||
+		string(requests[0].GetKtpImage().GetContent()) != "ktp" || requests[0].GetFaceMatchThreshold() != 0.8 {
+		t.Errorf("face match requests %v", requests)
+	}
+
+	job, err = client.StartLivenessJob(ctx, domain.StartLivenessJobPayload{
+		SessionID:      "sess-1",
+		LivenessFrames: []domain.BinaryImage{{Content: []byte("f1")}, {Content: []byte("f2")}},
+		Gestures:       []string{"BLINK", "TURN_LEFT"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.JobID != "fake-liveness-2" || job.Queue != ekycfake.LivenessQueue {
+		t.Errorf("liveness job %+v", job)
+	}
+	liveness := fake.LivenessRequests()
+	if len(liveness) != 1 || len(liveness[0].GetLivenessFrames()) != 2 || len(liveness[0].GetGestures()) != 2 {
+		t.Errorf("liveness requests %v", liveness)
+	}
+}
+
+func TestClientMapsStatusCodes(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want error
+	}{
+		{codes.InvalidArgument, domain.ErrInvalidState},
+		{codes.FailedPrecondition, domain.ErrInvalidState},
+		{codes.NotFound, domain.ErrNotFound},
+		{codes.Unavailable, domain.ErrAiSupportUnavailable},
+		{codes.DeadlineExceeded, domain.ErrAiSupportUnavailable},
+		{codes.ResourceExhausted, domain.ErrAiSupportUnavailable},
+		{codes.Internal, nil},
+		{codes.PermissionDenied, nil},
+	}
+	client, fake := newFakeClient(t)
+	sentinels := []error{domain.ErrInvalidState, domain.ErrNotFound, domain.ErrAiSupportUnavailable}
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			fake.FailWith(status.Error(tt.code, "scripted failure"))
+			calls := map[string]func() error{
+				"face match": func() error {
+					_, err := client.StartFaceMatchJob(context.Background(), domain.StartFaceMatchJobPayload{SessionID: "s"})
+					return err
+				},
+				"liveness": func() error {
+					_, err := client.StartLivenessJob(context.Background(), domain.StartLivenessJobPayload{SessionID: "s"})
+					return err
+				},
+			}
+			for name, call := range calls {
+				err := call()
+				if err == nil {
+					t.Fatalf("%s: no error", name)
+				}
+				for _, sentinel := range sentinels {
+					if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+						t.Errorf("%s: errors.Is(%v, %v) = %v", name, err, sentinel, got)
+					}
+				}
+			}
+		})
+	}
+}
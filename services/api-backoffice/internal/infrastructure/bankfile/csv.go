@@ -0,0 +1,95 @@
+// Package bankfile renders disbursement batches into the files banks take for bulk
+// credit transfers, and reads the result files they send back.
+package bankfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// CSVExporter writes one row per transfer, in the layout of DisbursementConfig.CSV.
+type CSVExporter struct{}
+
+var _ domain.DisbursementExporter = CSVExporter{}
+
+func (CSVExporter) Format() string      { return domain.DisbursementFormatCSV }
+func (CSVExporter) ContentType() string { return "text/csv; charset=utf-8" }
+func (CSVExporter) Extension() string   { return "csv" }
+
+func (CSVExporter) Render(file domain.DisbursementFile) ([]byte, error) {
+	layout := file.Config.CSV
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = layout.Delimiter
+	// Bank portals are mostly Windows software.
+	w.UseCRLF = true
+	if layout.Header {
+		if err := w.Write(layout.Columns); err != nil {
+			return nil, err
+		}
+	}
+	row := make([]string, len(layout.Columns))
+	for _, line := range file.Lines {
+		for i, column := range layout.Columns {
+			value, err := csvValue(file, line, column)
+			if err != nil {
+				return nil, err
+			}
+			row[i] = value
+		}
+		if err := w.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func csvValue(file domain.DisbursementFile, line domain.DisbursementLine, column string) (string, error) {
+	switch column {
+	case domain.DisbursementColumnBatch:
+		return file.BatchCode, nil
+	case domain.DisbursementColumnApplicationID:
+		return line.ApplicationID, nil
+	case domain.DisbursementColumnName:
+		return textCell(line.Name), nil
+	case domain.DisbursementColumnNikMask:
+		return line.NikMask, nil
+	case domain.DisbursementColumnProgram:
+		return textCell(line.Program), nil
+	case domain.DisbursementColumnAmount:
+		return strconv.FormatInt(line.Amount, 10), nil
+	case domain.DisbursementColumnCurrency:
+		return line.Currency, nil
+	case domain.DisbursementColumnChannel:
+		return line.Channel, nil
+	case domain.DisbursementColumnBank:
+		return line.Bank, nil
+	case domain.DisbursementColumnAccount:
+		return line.Account, nil
+	case domain.DisbursementColumnReference:
+		return textCell(line.Reference), nil
+	case domain.DisbursementColumnRemittance:
+		return textCell(line.Remittance), nil
+	}
+	return "", fmt.Errorf("bankfile: unknown csv column %q", column)
+}
+
+// textCell keeps spreadsheet software from running a free-text cell as a formula: text
+// starting with =, +, -, @, a tab or a carriage return gets a leading apostrophe, which
+// Excel and LibreOffice read as "this is text". A signed run of digits, such as an
+// e-wallet reference like +6281234567890, cannot run anything and is left as it is.
+func textCell(value string) string {
+	if value == "" || !strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return value
+	}
+	if (value[0] == '+' || value[0] == '-') && len(value) > 1 && strings.Trim(value[1:], "0123456789") == "" {
+		return value
+	}
+	return "'" + value
+}
@@ -0,0 +1,112 @@
+package bankfile
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// Columns of a CSV result file, with the names banks use for them. application_id and
+// status are required.
+var csvResultColumns = map[string]string{
+	"application_id": "application_id",
+	"end_to_end_id":  "application_id",
+	"status":         "status",
+	"reason":         "reason",
+	"bank_reference": "bank_reference",
+	"reference":      "bank_reference",
+	"amount":         "amount",
+}
+
+// CSVResultParser reads a CSV result file with a header row. The delimiter is whichever
+// of comma, semicolon, tab or pipe the header uses most.
+type CSVResultParser struct{}
+
+var _ domain.DisbursementResultParser = CSVResultParser{}
+
+func (CSVResultParser) Format() string      { return domain.DisbursementResultFormatCSV }
+func (CSVResultParser) ContentType() string { return "text/csv; charset=utf-8" }
+func (CSVResultParser) Extension() string   { return "csv" }
+
+func (CSVResultParser) Parse(content []byte) (*domain.DisbursementResultFile, error) {
+	// Excel saves CSV with a byte order mark.
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+	header, _, _ := bytes.Cut(content, []byte("\n"))
+	r := csv.NewReader(bytes.NewReader(content))
+	r.Comma = csvDelimiter(string(header))
+	// The reader would trim a tab delimiter as leading space and shift empty cells away.
+	r.TrimLeadingSpace = r.Comma != '\t'
+	r.FieldsPerRecord = -1
+
+	names, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: header CSV tidak terbaca: %v", domain.ErrInvalidState, err)
+	}
+	index := map[string]int{}
+	for i, name := range names {
+		if column, ok := csvResultColumns[strings.ToLower(strings.TrimSpace(name))]; ok {
+			index[column] = i
+		}
+	}
+	if _, ok := index["application_id"]; !ok {
+		return nil, fmt.Errorf("%w: CSV butuh kolom application_id", domain.ErrInvalidState)
+	}
+	if _, ok := index["status"]; !ok {
+		return nil, fmt.Errorf("%w: CSV butuh kolom status", domain.ErrInvalidState)
+	}
+
+	file := &domain.DisbursementResultFile{}
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: CSV tidak terbaca: %v", domain.ErrInvalidState, err)
+		}
+		line, _ := r.FieldPos(0)
+		field := func(column string) string {
+			i, ok := index[column]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		result := domain.DisbursementResult{
+			Line:          line,
+			ApplicationID: field("application_id"),
+			BankStatus:    field("status"),
+			Reason:        field("reason"),
+			BankReference: field("bank_reference"),
+		}
+		if result.Status, err = resultStatus(line, result.BankStatus); err != nil {
+			return nil, err
+		}
+		if result.Amount, err = resultAmount(line, field("amount")); err != nil {
+			return nil, err
+		}
+		file.Results = append(file.Results, result)
+	}
+	if len(file.Results) == 0 {
+		return nil, fmt.Errorf("%w: CSV tidak berisi hasil transfer", domain.ErrInvalidState)
+	}
+	return file, nil
+}
+
+func csvDelimiter(header string) rune {
+	delimiter, most := ',', 0
+	for _, candidate := range []rune{',', ';', '\t', '|'} {
+		if n := strings.Count(header, string(candidate)); n > most {
+			delimiter, most = candidate, n
+		}
+	}
+	return delimiter
+}
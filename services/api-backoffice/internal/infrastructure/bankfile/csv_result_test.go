@@ -0,0 +1,88 @@
+package bankfile
+
+import (
+	"errors"
+	"testing"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+func TestCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   rune
+	}{
+		{"comma", "application_id,status,reason", ','},
+		{"semicolon", "application_id;status;reason", ';'},
+		{"tab", "application_id\tstatus\treason", '\t'},
+		{"pipe", "application_id|status|reason", '|'},
+		{"one column defaults to comma", "application_id", ','},
+		{"most used wins", "application_id;status;reason,code", ';'},
+		{"tie keeps the earlier candidate", "application_id;status,reason", ','},
+		{"CRLF header", "application_id;status\r", ';'},
+		{"empty", "", ','},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := csvDelimiter(tt.header); got != tt.want {
+				t.Errorf("csvDelimiter(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSVResultParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"comma", "application_id,status,reason,amount\nAPP-1,BERHASIL,,300000\nAPP-2,GAGAL,\"Rekening ditutup, AC04\",300000\n"},
+		{"semicolon from Excel", "\xef\xbb\xbfApplication_ID;Status;Reason;Amount\r\nAPP-1;berhasil;;300000\r\nAPP-2;GAGAL;Rekening ditutup, AC04;300000\r\n"},
+		{"tab", "end_to_end_id\tstatus\treason\tamount\nAPP-1\tACSC\t\t300000\n\nAPP-2\tRJCT\tRekening ditutup, AC04\t300000.00\n"},
+		{"pipe with spaces", "application_id | status | reason | amount\nAPP-1 | SUCCESS | | 300000\nAPP-2 | FAILED | Rekening ditutup, AC04 | 300000\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := CSVResultParser{}.Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(file.Results) != 2 {
+				t.Fatalf("%d results, want 2", len(file.Results))
+			}
+			first, second := file.Results[0], file.Results[1]
+			if first.ApplicationID != "APP-1" || first.Status != domain.DisbursementDisbursed || first.Reason != "" {
+				t.Errorf("first result %+v", first)
+			}
+			if second.ApplicationID != "APP-2" || second.Status != domain.DisbursementFailed || second.Reason != "Rekening ditutup, AC04" {
+				t.Errorf("second result %+v", second)
+			}
+			for _, r := range file.Results {
+				if r.Amount == nil || *r.Amount != 300000 {
+					t.Errorf("%s amount %v", r.ApplicationID, r.Amount)
+				}
+			}
+		})
+	}
+}
+
+func TestCSVResultParseRejects(t *testing.T) {
+	tests := map[string]string{
+		"empty":                "",
+		"header only":          "application_id,status\n",
+		"no application id":    "nik,status\n123,BERHASIL\n",
+		"no status":            "application_id,reason\nAPP-1,ok\n",
+		"unknown status":       "application_id,status\nAPP-1,MUNGKIN\n",
+		"decimal comma amount": "application_id;status;amount\nAPP-1;BERHASIL;300000,50\n",
+		"fractional amount":    "application_id,status,amount\nAPP-1,BERHASIL,300000.50\n",
+		"broken quoting":       "application_id,status\n\"APP-1,BERHASIL\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := (CSVResultParser{}).Parse([]byte(content)); !errors.Is(err, domain.ErrInvalidState) {
+				t.Errorf("Parse = %v, want ErrInvalidState", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package bankfile
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+func TestCSVExportNeutralizesFormulas(t *testing.T) {
+	tests := []struct {
+		name, value, want string
+	}{
+		{"plain", "Siti Aminah", "Siti Aminah"},
+		{"equals", "=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"plus", "+cmd|' /C calc'!A0", "'+cmd|' /C calc'!A0"},
+		{"minus", "-2+3", "'-2+3"},
+		{"at", "@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"tab", "\t=1", "'\t=1"},
+		{"signed digits", "+6281234567890", "+6281234567890"},
+		{"formula inside", "Siti =1", "Siti =1"},
+		{"lone sign", "-", "'-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := testDisbursementFile("APP-1")
+			file.Lines[0].Name = tt.value
+			file.Lines[0].Reference = tt.value
+			file.Lines[0].Remittance = tt.value
+			file.Lines[0].Program = tt.value
+			file.Config.CSV = domain.DisbursementCSVLayout{
+				Columns: []string{domain.DisbursementColumnName, domain.DisbursementColumnReference,
+					domain.DisbursementColumnRemittance, domain.DisbursementColumnProgram, domain.DisbursementColumnAmount},
+				Delimiter: ';',
+			}
+			content, err := CSVExporter{}.Render(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := csv.NewReader(strings.NewReader(string(content)))
+			r.Comma = ';'
+			rows, err := r.ReadAll()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != 1 {
+				t.Fatalf("got %d rows", len(rows))
+			}
+			for i, cell := range rows[0][:4] {
+				if cell != tt.want {
+					t.Errorf("column %s = %q, want %q", file.Config.CSV.Columns[i], cell, tt.want)
+				}
+			}
+			if rows[0][4] != "300000" {
+				t.Errorf("amount = %q", rows[0][4])
+			}
+		})
+	}
+}
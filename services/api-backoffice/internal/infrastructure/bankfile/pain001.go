@@ -0,0 +1,193 @@
+package bankfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+const pain001Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+
+// Pain001Exporter writes an ISO 20022 customer credit transfer initiation
+// (pain.001.001.03): one payment information block debiting the configured account, with
+// a transfer per item. Only bank transfers fit, so every payee needs a bank and account.
+// Each transfer's EndToEndId is domain.EndToEndID of its application.
+type Pain001Exporter struct{}
+
+var _ domain.DisbursementExporter = Pain001Exporter{}
+
+func (Pain001Exporter) Format() string      { return domain.DisbursementFormatPain001 }
+func (Pain001Exporter) ContentType() string { return "application/xml" }
+func (Pain001Exporter) Extension() string   { return "xml" }
+
+func (Pain001Exporter) Render(file domain.DisbursementFile) ([]byte, error) {
+	cfg := file.Config
+	if cfg.DebtorName == "" || cfg.DebtorAccount == "" {
+		return nil, fmt.Errorf("%w: pain.001 butuh disbursement %q dan %q", domain.ErrInvalidState,
+			domain.DisbursementKeyDebtorName, domain.DisbursementKeyDebtorAccount)
+	}
+	total := strconv.FormatInt(file.Total, 10)
+	info := painPaymentInfo{
+		ID:          maxText(file.BatchCode, 35),
+		Method:      "TRF",
+		BatchBook:   true,
+		Count:       len(file.Lines),
+		ControlSum:  total,
+		ExecutionDt: file.CreatedAt.Format("2006-01-02"),
+		Debtor:      painParty{Name: maxText(cfg.DebtorName, 70)},
+		DebtorAcct:  painAccount{ID: painAccountID{Other: &painOther{ID: cfg.DebtorAccount}}},
+		DebtorAgent: painAgent(cfg.DebtorBIC),
+	}
+	seen := make(map[string]string, len(file.Lines))
+	for _, line := range file.Lines {
+		if line.Channel != domain.PayoutChannelBankTransfer || line.Bank == "" || line.Account == "" {
+			return nil, fmt.Errorf("%w: pain.001 hanya untuk transfer bank, %s tidak punya rekening", domain.ErrInvalidState, line.ApplicationID)
+		}
+		endToEndID, err := domain.EndToEndID(line.ApplicationID)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[endToEndID]; ok {
+			return nil, fmt.Errorf("%w: %s dan %s mendapat EndToEndId yang sama", domain.ErrInvalidState, other, line.ApplicationID)
+		}
+		seen[endToEndID] = line.ApplicationID
+		info.Transfers = append(info.Transfers, painTransfer{
+			PaymentID:  painPaymentID{EndToEndID: endToEndID},
+			Amount:     painAmount{Currency: line.Currency, Value: strconv.FormatInt(line.Amount, 10)},
+			Agent:      painAgent(line.Bank),
+			Creditor:   painParty{Name: maxText(line.Name, 70)},
+			CreditAcct: painAccount{ID: painAccountID{Other: &painOther{ID: line.Account}}},
+			Remittance: &painRemittance{Unstructured: maxText(line.Remittance, 140)},
+		})
+	}
+	doc := painDocument{
+		Namespace: pain001Namespace,
+		Initiation: painInitiation{
+			Header: painGroupHeader{
+				MessageID:  maxText(file.BatchCode, 35),
+				CreatedAt:  file.CreatedAt.UTC().Format("2006-01-02T15:04:05"),
+				Count:      len(file.Lines),
+				ControlSum: total,
+				Initiator:  painParty{Name: maxText(cfg.DebtorName, 70)},
+			},
+			Payments: []painPaymentInfo{info},
+		},
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// painAgent identifies a bank by BIC, or by its local clearing code when bank is not one.
+func painAgent(bank string) painFinancialAgent {
+	switch {
+	case bank == "":
+		return painFinancialAgent{Institution: painInstitution{Other: &painOther{ID: "NOTPROVIDED"}}}
+	case domain.IsBIC(bank):
+		return painFinancialAgent{Institution: painInstitution{BIC: bank}}
+	default:
+		return painFinancialAgent{Institution: painInstitution{Clearing: &painClearing{MemberID: bank}}}
+	}
+}
+
+// maxText cuts s to the schema's MaxNText length in characters.
+func maxText(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+type painDocument struct {
+	XMLName    xml.Name       `xml:"Document"`
+	Namespace  string         `xml:"xmlns,attr"`
+	Initiation painInitiation `xml:"CstmrCdtTrfInitn"`
+}
+
+type painInitiation struct {
+	Header   painGroupHeader   `xml:"GrpHdr"`
+	Payments []painPaymentInfo `xml:"PmtInf"`
+}
+
+type painGroupHeader struct {
+	MessageID  string    `xml:"MsgId"`
+	CreatedAt  string    `xml:"CreDtTm"`
+	Count      int       `xml:"NbOfTxs"`
+	ControlSum string    `xml:"CtrlSum"`
+	Initiator  painParty `xml:"InitgPty"`
+}
+
+type painPaymentInfo struct {
+	ID          string             `xml:"PmtInfId"`
+	Method      string             `xml:"PmtMtd"`
+	BatchBook   bool               `xml:"BtchBookg"`
+	Count       int                `xml:"NbOfTxs"`
+	ControlSum  string             `xml:"CtrlSum"`
+	ExecutionDt string             `xml:"ReqdExctnDt"`
+	Debtor      painParty          `xml:"Dbtr"`
+	DebtorAcct  painAccount        `xml:"DbtrAcct"`
+	DebtorAgent painFinancialAgent `xml:"DbtrAgt"`
+	Transfers   []painTransfer     `xml:"CdtTrfTxInf"`
+}
+
+type painTransfer struct {
+	PaymentID  painPaymentID      `xml:"PmtId"`
+	Amount     painAmount         `xml:"Amt>InstdAmt"`
+	Agent      painFinancialAgent `xml:"CdtrAgt"`
+	Creditor   painParty          `xml:"Cdtr"`
+	CreditAcct painAccount        `xml:"CdtrAcct"`
+	Remittance *painRemittance    `xml:"RmtInf"`
+}
+
+type painPaymentID struct {
+	EndToEndID string `xml:"EndToEndId"`
+}
+
+type painAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type painParty struct {
+	Name string `xml:"Nm"`
+}
+
+type painAccount struct {
+	ID painAccountID `xml:"Id"`
+}
+
+type painAccountID struct {
+	Other *painOther `xml:"Othr"`
+}
+
+type painOther struct {
+	ID string `xml:"Id"`
+}
+
+type painFinancialAgent struct {
+	Institution painInstitution `xml:"FinInstnId"`
+}
+
+type painInstitution struct {
+	BIC      string        `xml:"BIC,omitempty"`
+	Clearing *painClearing `xml:"ClrSysMmbId"`
+	Other    *painOther    `xml:"Othr"`
+}
+
+type painClearing struct {
+	MemberID string `xml:"MmbId"`
+}
+
+type painRemittance struct {
+	Unstructured string `xml:"Ustrd"`
+}
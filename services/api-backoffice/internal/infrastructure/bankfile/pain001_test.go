@@ -0,0 +1,104 @@
+package bankfile
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+func testDisbursementFile(appIDs ...string) domain.DisbursementFile {
+	file := domain.DisbursementFile{
+		BatchID:   "batch-1",
+		BatchCode: "BATCH-2025-11-001",
+		CreatedAt: time.Date(2025, time.November, 3, 9, 0, 0, 0, time.UTC),
+		Currency:  "IDR",
+		Config: domain.DisbursementConfig{
+			Currency:      "IDR",
+			DebtorName:    "Dinas Sosial",
+			DebtorAccount: "1234567890",
+			DebtorBIC:     "BRINIDJA",
+		},
+	}
+	for i, appID := range appIDs {
+		file.Lines = append(file.Lines, domain.DisbursementLine{
+			ApplicationID: appID,
+			Name:          "Penerima " + appID,
+			Amount:        int64(300000 + i*1000),
+			Currency:      "IDR",
+			Channel:       domain.PayoutChannelBankTransfer,
+			Bank:          "BMRIIDJA",
+			Account:       "98765432" + strings.Repeat("1", i+1),
+			Remittance:    "Bansos " + appID,
+		})
+		file.Total += file.Lines[i].Amount
+	}
+	return file
+}
+
+func renderedEndToEndIDs(t *testing.T, content []byte) []string {
+	t.Helper()
+	var doc painDocument
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, payment := range doc.Initiation.Payments {
+		for _, transfer := range payment.Transfers {
+			ids = append(ids, transfer.PaymentID.EndToEndID)
+		}
+	}
+	return ids
+}
+
+func TestPain001EndToEndID(t *testing.T) {
+	tests := []struct {
+		name    string
+		appIDs  []string
+		want    []string
+		wantErr bool
+	}{
+		{"seed id", []string{"APP-2025-0001"}, []string{"APP-2025-0001"}, false},
+		{
+			"session UUID",
+			[]string{"3f2b8c1e-7a4d-4e9b-b1c2-9d8e7f6a5b4c"},
+			[]string{"3f2b8c1e7a4d4e9bb1c29d8e7f6a5b4c"},
+			false,
+		},
+		{
+			"UUIDs differing in the last character",
+			[]string{"3f2b8c1e-7a4d-4e9b-b1c2-9d8e7f6a5b4c", "3f2b8c1e-7a4d-4e9b-b1c2-9d8e7f6a5b4d"},
+			[]string{"3f2b8c1e7a4d4e9bb1c29d8e7f6a5b4c", "3f2b8c1e7a4d4e9bb1c29d8e7f6a5b4d"},
+			false,
+		},
+		{"exactly 35 characters", []string{strings.Repeat("A", 35)}, []string{strings.Repeat("A", 35)}, false},
+		{"too long and not a UUID", []string{strings.Repeat("A", 36)}, nil, true},
+		{"UUID clashing with an id that fits", []string{"3f2b8c1e-7a4d-4e9b-b1c2-9d8e7f6a5b4c", "3f2b8c1e7a4d4e9bb1c29d8e7f6a5b4c"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := Pain001Exporter{}.Render(testDisbursementFile(tt.appIDs...))
+			if tt.wantErr {
+				if !errors.Is(err, domain.ErrInvalidState) {
+					t.Fatalf("Render = %v, want ErrInvalidState", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := renderedEndToEndIDs(t, content)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("EndToEndIds %v, want %v", got, tt.want)
+			}
+			for _, id := range got {
+				if len(id) > domain.MaxEndToEndIDLength {
+					t.Errorf("EndToEndId %q longer than %d", id, domain.MaxEndToEndIDLength)
+				}
+			}
+		})
+	}
+}
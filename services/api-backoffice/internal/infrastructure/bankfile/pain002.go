@@ -0,0 +1,121 @@
+package bankfile
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// Pain002Parser reads an ISO 20022 payment status report (pain.002, any version that
+// keeps the pain.002.001.03 element names). Transfers are matched by their original
+// end-to-end id, the application id in the pain.001 file. A transaction without its own
+// status takes that of its payment information block, or else of the group.
+type Pain002Parser struct{}
+
+var _ domain.DisbursementResultParser = Pain002Parser{}
+
+func (Pain002Parser) Format() string      { return domain.DisbursementResultFormatPain002 }
+func (Pain002Parser) ContentType() string { return "application/xml" }
+func (Pain002Parser) Extension() string   { return "xml" }
+
+func (Pain002Parser) Parse(content []byte) (*domain.DisbursementResultFile, error) {
+	var doc pain002Document
+	if err := xml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("%w: pain.002 tidak terbaca: %v", domain.ErrInvalidState, err)
+	}
+	group := doc.Report.Group
+	file := &domain.DisbursementResultFile{OriginalMessageID: strings.TrimSpace(group.MessageID)}
+	line := 0
+	for _, payment := range doc.Report.Payments {
+		for _, tx := range payment.Transactions {
+			line++
+			result := domain.DisbursementResult{
+				Line:          line,
+				ApplicationID: strings.TrimSpace(tx.EndToEndID),
+				BankStatus:    firstNonEmpty(tx.Status, payment.Status, group.Status),
+				Reason:        tx.reason(),
+				BankReference: strings.TrimSpace(tx.StatusID),
+			}
+			var err error
+			if result.Status, err = resultStatus(line, result.BankStatus); err != nil {
+				return nil, err
+			}
+			if tx.Amount != nil {
+				if result.Amount, err = resultAmount(line, strings.TrimSpace(tx.Amount.Value)); err != nil {
+					return nil, err
+				}
+			}
+			file.Results = append(file.Results, result)
+		}
+	}
+	if len(file.Results) == 0 {
+		return nil, fmt.Errorf("%w: pain.002 tidak berisi status transaksi", domain.ErrInvalidState)
+	}
+	return file, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+type pain002Document struct {
+	XMLName xml.Name      `xml:"Document"`
+	Report  pain002Report `xml:"CstmrPmtStsRpt"`
+}
+
+type pain002Report struct {
+	Group    pain002Group     `xml:"OrgnlGrpInfAndSts"`
+	Payments []pain002Payment `xml:"OrgnlPmtInfAndSts"`
+}
+
+type pain002Group struct {
+	MessageID string `xml:"OrgnlMsgId"`
+	Status    string `xml:"GrpSts"`
+}
+
+type pain002Payment struct {
+	Status       string               `xml:"PmtInfSts"`
+	Transactions []pain002Transaction `xml:"TxInfAndSts"`
+}
+
+type pain002Transaction struct {
+	StatusID   string          `xml:"StsId"`
+	EndToEndID string          `xml:"OrgnlEndToEndId"`
+	Status     string          `xml:"TxSts"`
+	Reasons    []pain002Reason `xml:"StsRsnInf"`
+	Amount     *pain002Amount  `xml:"OrgnlTxRef>Amt>InstdAmt"`
+}
+
+type pain002Reason struct {
+	Code        string   `xml:"Rsn>Cd"`
+	Proprietary string   `xml:"Rsn>Prtry"`
+	Info        []string `xml:"AddtlInf"`
+}
+
+type pain002Amount struct {
+	Value string `xml:",chardata"`
+}
+
+// reason joins the reason codes and their additional information, e.g. "AC04 Rekening
+// ditutup".
+func (tx pain002Transaction) reason() string {
+	var parts []string
+	for _, reason := range tx.Reasons {
+		if code := firstNonEmpty(reason.Code, reason.Proprietary); code != "" {
+			parts = append(parts, code)
+		}
+		for _, info := range reason.Info {
+			if info = strings.TrimSpace(info); info != "" {
+				parts = append(parts, info)
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
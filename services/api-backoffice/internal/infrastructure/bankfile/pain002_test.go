@@ -0,0 +1,148 @@
+package bankfile
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// bankAnswer is how the bank answers one transfer. An empty status leaves TxSts out so
+// the transfer takes the status of its payment block.
+type bankAnswer struct {
+	status, reason string
+}
+
+// pain002Answering builds the status report a bank sends for a rendered pain.001, one
+// answer per transfer in file order, under a payment block with paymentStatus.
+func pain002Answering(t *testing.T, pain001 []byte, paymentStatus string, answers []bankAnswer) []byte {
+	t.Helper()
+	var doc painDocument
+	if err := xml.Unmarshal(pain001, &doc); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.03"><CstmrPmtStsRpt>
+<GrpHdr><MsgId>STS-1</MsgId></GrpHdr>
+<OrgnlGrpInfAndSts><OrgnlMsgId>%s</OrgnlMsgId><OrgnlMsgNmId>pain.001.001.03</OrgnlMsgNmId><GrpSts>PART</GrpSts></OrgnlGrpInfAndSts>`,
+		doc.Initiation.Header.MessageID)
+	i := 0
+	for _, payment := range doc.Initiation.Payments {
+		fmt.Fprintf(&b, `<OrgnlPmtInfAndSts><OrgnlPmtInfId>%s</OrgnlPmtInfId><PmtInfSts>%s</PmtInfSts>`, payment.ID, paymentStatus)
+		for _, transfer := range payment.Transfers {
+			answer := answers[i]
+			i++
+			fmt.Fprintf(&b, `<TxInfAndSts><StsId>BANK-%d</StsId><OrgnlEndToEndId>%s</OrgnlEndToEndId>`, i, transfer.PaymentID.EndToEndID)
+			if answer.status != "" {
+				fmt.Fprintf(&b, `<TxSts>%s</TxSts>`, answer.status)
+			}
+			if answer.reason != "" {
+				fmt.Fprintf(&b, `<StsRsnInf><Rsn><Cd>%s</Cd></Rsn><AddtlInf>Rekening ditutup</AddtlInf></StsRsnInf>`, answer.reason)
+			}
+			// Banks echo IDR with a zero fraction.
+			fmt.Fprintf(&b, `<OrgnlTxRef><Amt><InstdAmt Ccy="%s">%s.00</InstdAmt></Amt></OrgnlTxRef></TxInfAndSts>`,
+				transfer.Amount.Currency, transfer.Amount.Value)
+		}
+		b.WriteString(`</OrgnlPmtInfAndSts>`)
+	}
+	b.WriteString(`</CstmrPmtStsRpt></Document>`)
+	return []byte(b.String())
+}
+
+func TestPain001ToPain002RoundTrip(t *testing.T) {
+	appIDs := []string{
+		"APP-2025-0001",
+		"3f2b8c1e-7a4d-4e9b-b1c2-9d8e7f6a5b4c",
+		"3f2b8c1e-7a4d-4e9b-b1c2-9d8e7f6a5b4d",
+		"APP-2025-0002",
+	}
+	file := testDisbursementFile(appIDs...)
+	pain001, err := Pain001Exporter{}.Render(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := renderedEndToEndIDs(t, pain001)
+
+	tests := []struct {
+		name          string
+		paymentStatus string
+		answers       []bankAnswer
+		want          []string
+		bankStatus    []string
+	}{
+		{
+			name:          "every transfer answered",
+			paymentStatus: "PART",
+			answers:       []bankAnswer{{"ACSC", ""}, {"RJCT", "AC04"}, {"ACSC", ""}, {"PDNG", ""}},
+			want:          []string{domain.DisbursementDisbursed, domain.DisbursementFailed, domain.DisbursementDisbursed, ""},
+			bankStatus:    []string{"ACSC", "RJCT", "ACSC", "PDNG"},
+		},
+		{
+			name:          "transfers take the payment status",
+			paymentStatus: "ACSC",
+			answers:       []bankAnswer{{}, {"RJCT", "AC04"}, {}, {}},
+			want:          []string{domain.DisbursementDisbursed, domain.DisbursementFailed, domain.DisbursementDisbursed, domain.DisbursementDisbursed},
+			bankStatus:    []string{"ACSC", "RJCT", "ACSC", "ACSC"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Pain002Parser{}.Parse(pain002Answering(t, pain001, tt.paymentStatus, tt.answers))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.OriginalMessageID != file.BatchCode {
+				t.Errorf("answers message %q, want %q", result.OriginalMessageID, file.BatchCode)
+			}
+			if len(result.Results) != len(appIDs) {
+				t.Fatalf("%d results, want %d", len(result.Results), len(appIDs))
+			}
+			for i, r := range result.Results {
+				if r.ApplicationID != sent[i] {
+					t.Errorf("result %d for %q, the pain.001 sent %q", i, r.ApplicationID, sent[i])
+				}
+				if want, err := domain.EndToEndID(appIDs[i]); err != nil || r.ApplicationID != want {
+					t.Errorf("result %d for %q does not map back to %s", i, r.ApplicationID, appIDs[i])
+				}
+				if r.Line != i+1 || r.Status != tt.want[i] || r.BankStatus != tt.bankStatus[i] {
+					t.Errorf("result %d = line %d %q (%s), want %q (%s)", i, r.Line, r.Status, r.BankStatus, tt.want[i], tt.bankStatus[i])
+				}
+				if r.BankReference != fmt.Sprintf("BANK-%d", i+1) {
+					t.Errorf("result %d bank reference %q", i, r.BankReference)
+				}
+				if r.Amount == nil || *r.Amount != file.Lines[i].Amount {
+					t.Errorf("result %d amount %v, want %d", i, r.Amount, file.Lines[i].Amount)
+				}
+			}
+			if reason := result.Results[1].Reason; reason != "AC04 Rekening ditutup" {
+				t.Errorf("rejection reason %q", reason)
+			}
+		})
+	}
+}
+
+func TestPain002ParseRejects(t *testing.T) {
+	report := func(tx string) []byte {
+		return []byte(`<Document><CstmrPmtStsRpt><OrgnlGrpInfAndSts><OrgnlMsgId>B</OrgnlMsgId></OrgnlGrpInfAndSts>` +
+			`<OrgnlPmtInfAndSts>` + tx + `</OrgnlPmtInfAndSts></CstmrPmtStsRpt></Document>`)
+	}
+	tests := map[string][]byte{
+		"not XML":          []byte("status,application_id\n"),
+		"no transactions":  report(""),
+		"unknown status":   report(`<TxInfAndSts><OrgnlEndToEndId>A</OrgnlEndToEndId><TxSts>MAYBE</TxSts></TxInfAndSts>`),
+		"no status at all": report(`<TxInfAndSts><OrgnlEndToEndId>A</OrgnlEndToEndId></TxInfAndSts>`),
+		"fractional amount": report(`<TxInfAndSts><OrgnlEndToEndId>A</OrgnlEndToEndId><TxSts>ACSC</TxSts>` +
+			`<OrgnlTxRef><Amt><InstdAmt Ccy="IDR">300000.50</InstdAmt></Amt></OrgnlTxRef></TxInfAndSts>`),
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := (Pain002Parser{}).Parse(content); !errors.Is(err, domain.ErrInvalidState) {
+				t.Errorf("Parse = %v, want ErrInvalidState", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package bankfile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// resultStatuses maps the status a bank reports for a transfer to its outcome: the ISO
+// 20022 transaction status codes and the words Indonesian bank portals use. Statuses
+// mapped to "" are not final yet.
+var resultStatuses = map[string]string{
+	"ACSC":                domain.DisbursementDisbursed,
+	"SUCCESS":             domain.DisbursementDisbursed,
+	"BERHASIL":            domain.DisbursementDisbursed,
+	"PAID":                domain.DisbursementDisbursed,
+	"DISBURSED":           domain.DisbursementDisbursed,
+	"RJCT":                domain.DisbursementFailed,
+	"FAILED":              domain.DisbursementFailed,
+	"GAGAL":               domain.DisbursementFailed,
+	"REJECTED":            domain.DisbursementFailed,
+	"DISBURSEMENT_FAILED": domain.DisbursementFailed,
+	"ACCP":                "",
+	"ACSP":                "",
+	"ACTC":                "",
+	"ACWC":                "",
+	"PDNG":                "",
+	"PENDING":             "",
+	"PROSES":              "",
+}
+
+func resultStatus(line int, code string) (string, error) {
+	status, ok := resultStatuses[strings.ToUpper(code)]
+	if !ok {
+		return "", fmt.Errorf("%w: baris %d: status %q tidak dikenal", domain.ErrInvalidState, line, code)
+	}
+	return status, nil
+}
+
+// resultAmount reads an amount in whole currency units. Banks often write IDR with a
+// zero fraction, which is accepted.
+func resultAmount(line int, text string) (*int64, error) {
+	if text == "" {
+		return nil, nil
+	}
+	whole, fraction, _ := strings.Cut(text, ".")
+	amount, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || strings.Trim(fraction, "0") != "" {
+		return nil, fmt.Errorf("%w: baris %d: nominal %q harus bilangan bulat", domain.ErrInvalidState, line, text)
+	}
+	return &amount, nil
+}
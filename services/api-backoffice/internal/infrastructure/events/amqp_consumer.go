@@ -0,0 +1,203 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/retry"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const defaultPrefetch = 4
+
+// AMQPConsumer consumes RabbitMQ queues with manual acknowledgement. Failed events are
+// retried in-process and then published to the queue's ".dead" companion. Queues are
+// declared exactly like the Python workers declare them (durable, no arguments) so both
+// sides can start in any order.
+type AMQPConsumer struct {
+	url      string
+	retry    retry.Config
+	prefetch int
+
+	mu     sync.Mutex
+	conns  map[*amqp.Connection]struct{}
+	closed bool
+}
+
+var _ domain.EventConsumer = (*AMQPConsumer)(nil)
+
+func NewAMQPConsumer(url string, retryCfg retry.Config) *AMQPConsumer {
+	return &AMQPConsumer{
+		url:      url,
+		retry:    retryCfg,
+		prefetch: defaultPrefetch,
+		conns:    make(map[*amqp.Connection]struct{}),
+	}
+}
+
+// Consume blocks until ctx is cancelled or Close is called, reconnecting with backoff
+// whenever the broker connection drops.
+func (c *AMQPConsumer) Consume(ctx context.Context, queue string, handler domain.EventHandler) error {
+	reconnect := retry.Config{MaxRetries: 10, InitialWait: time.Second, MaxWait: 30 * time.Second}
+	for {
+		err := retry.WithBackoff(ctx, reconnect, func() error {
+			return c.consumeOnce(ctx, queue, handler)
+		})
+		if ctx.Err() != nil || c.isClosed() {
+			return nil
+		}
+		log.Printf("events: consumer for %s gave up reconnecting: %v", queue, err)
+	}
+}
+
+func (c *AMQPConsumer) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	var errs []error
+	for conn := range c.conns {
+		if err := conn.Close(); err != nil && !errors.Is(err, amqp.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	c.conns = map[*amqp.Connection]struct{}{}
+	return errors.Join(errs...)
+}
+
+func (c *AMQPConsumer) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+// consumeOnce runs one connection's lifetime. It returns nil on shutdown and an error when
+// the connection is lost so the caller reconnects.
+func (c *AMQPConsumer) consumeOnce(ctx context.Context, queue string, handler domain.EventHandler) error {
+	if c.isClosed() {
+		return nil
+	}
+	conn, err := amqp.Dial(c.url)
+	if err != nil {
+		return fmt.Errorf("dial rabbitmq: %w", err)
+	}
+	c.track(conn)
+	defer c.untrack(conn)
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("open channel: %w", err)
+	}
+	defer ch.Close()
+
+	deadQueue := DeadLetterQueue(queue)
+	for _, name := range []string{queue, deadQueue} {
+		if _, err := ch.QueueDeclare(name, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("declare %s: %w", name, err)
+		}
+	}
+	if err := ch.Qos(c.prefetch, 0, false); err != nil {
+		return fmt.Errorf("set qos: %w", err)
+	}
+	deliveries, err := ch.Consume(queue, "", false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("consume %s: %w", queue, err)
+	}
+	closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	log.Printf("events: consuming %s", queue)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case amqpErr, ok := <-closed:
+			if c.isClosed() || ctx.Err() != nil {
+				return nil
+			}
+			if ok && amqpErr != nil {
+				return amqpErr
+			}
+			return errors.New("rabbitmq connection closed")
+		case d, ok := <-deliveries:
+			if !ok {
+				if c.isClosed() || ctx.Err() != nil {
+					return nil
+				}
+				return errors.New("rabbitmq delivery channel closed")
+			}
+			c.handle(ctx, ch, queue, deadQueue, d, handler)
+		}
+	}
+}
+
+func (c *AMQPConsumer) handle(ctx context.Context, ch *amqp.Channel, queue, deadQueue string, d amqp.Delivery, handler domain.EventHandler) {
+	event := domain.Event{
+		ID:          d.MessageId,
+		Queue:       queue,
+		Type:        d.Type,
+		Body:        d.Body,
+		Headers:     map[string]any(d.Headers),
+		Redelivered: d.Redelivered,
+	}
+	if event.Type == "" {
+		if jobType, ok := d.Headers["x-job-type"].(string); ok {
+			event.Type = jobType
+		}
+	}
+
+	err := deliver(ctx, c.retry, handler, event)
+	if err == nil {
+		if ackErr := d.Ack(false); ackErr != nil {
+			log.Printf("events: ack %s: %v", queue, ackErr)
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		// Shutting down: hand the event back to the broker for the next consumer.
+		_ = d.Nack(false, true)
+		return
+	}
+
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers["x-original-queue"] = queue
+	headers["x-dead-letter-reason"] = err.Error()
+	pubErr := ch.PublishWithContext(context.Background(), "", deadQueue, false, false, amqp.Publishing{
+		ContentType:  d.ContentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    d.MessageId,
+		Type:         d.Type,
+		Headers:      headers,
+		Timestamp:    time.Now().UTC(),
+		Body:         d.Body,
+	})
+	if pubErr != nil {
+		log.Printf("events: dead-letter %s: %v", queue, pubErr)
+		_ = d.Nack(false, true)
+		return
+	}
+	log.Printf("events: dead-lettered event from %s: %v", queue, err)
+	if ackErr := d.Ack(false); ackErr != nil {
+		log.Printf("events: ack %s: %v", queue, ackErr)
+	}
+}
+
+func (c *AMQPConsumer) track(conn *amqp.Connection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conns[conn] = struct{}{}
+}
+
+func (c *AMQPConsumer) untrack(conn *amqp.Connection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.conns, conn)
+}
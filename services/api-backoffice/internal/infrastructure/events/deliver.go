@@ -0,0 +1,39 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/retry"
+)
+
+// DefaultRetryConfig is used for in-process redelivery of a failed event before it is
+// dead-lettered.
+func DefaultRetryConfig() retry.Config {
+	return retry.Config{MaxRetries: 4, InitialWait: 500 * time.Millisecond, MaxWait: 10 * time.Second}
+}
+
+// deliver runs handler with backoff. It returns nil when the event was handled and the
+// last error otherwise. Poison events are not retried.
+func deliver(ctx context.Context, cfg retry.Config, handler domain.EventHandler, event domain.Event) error {
+	var poison error
+	err := retry.WithBackoff(ctx, cfg, func() error {
+		err := handler(ctx, event)
+		if errors.Is(err, domain.ErrPoisonEvent) {
+			poison = err
+			return nil
+		}
+		return err
+	})
+	if poison != nil {
+		return poison
+	}
+	return err
+}
+
+// DeadLetterQueue names the queue that receives events from queue once retries run out.
+func DeadLetterQueue(queue string) string {
+	return queue + ".dead"
+}
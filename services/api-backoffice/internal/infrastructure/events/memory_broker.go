@@ -0,0 +1,135 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/retry"
+)
+
+const memoryQueueSize = 256
+
+// DeadLetter is an event the MemoryBroker gave up on, with the final handler error.
+type DeadLetter struct {
+	Event  domain.Event
+	Reason string
+}
+
+// MemoryBroker is an in-process EventConsumer for tests and local development. Publish
+// enqueues events, Consume delivers them with the same retry and dead-letter rules as
+// AMQPConsumer.
+type MemoryBroker struct {
+	retry retry.Config
+
+	mu     sync.Mutex
+	queues map[string]chan domain.Event
+	dead   map[string][]DeadLetter
+	seq    int
+	closed bool
+	done   chan struct{}
+}
+
+var _ domain.EventConsumer = (*MemoryBroker)(nil)
+
+func NewMemoryBroker(retryCfg retry.Config) *MemoryBroker {
+	return &MemoryBroker{
+		retry:  retryCfg,
+		queues: make(map[string]chan domain.Event),
+		dead:   make(map[string][]DeadLetter),
+		done:   make(chan struct{}),
+	}
+}
+
+// Publish enqueues body on queue and returns the generated event id.
+func (b *MemoryBroker) Publish(queue string, body []byte, headers map[string]any) (string, error) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return "", errors.New("memory broker closed")
+	}
+	b.seq++
+	id := fmt.Sprintf("mem-%d", b.seq)
+	ch := b.queue(queue)
+	b.mu.Unlock()
+
+	event := domain.Event{ID: id, Queue: queue, Body: body, Headers: headers}
+	if jobType, ok := headers["x-job-type"].(string); ok {
+		event.Type = jobType
+	}
+	select {
+	case ch <- event:
+		return id, nil
+	case <-b.done:
+		return "", errors.New("memory broker closed")
+	}
+}
+
+func (b *MemoryBroker) Consume(ctx context.Context, queue string, handler domain.EventHandler) error {
+	b.mu.Lock()
+	ch := b.queue(queue)
+	b.mu.Unlock()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-b.done:
+			return nil
+		case event := <-ch:
+			err := deliver(ctx, b.retry, handler, event)
+			if err == nil {
+				continue
+			}
+			if ctx.Err() != nil {
+				// Put it back so a later consumer sees it, as a broker requeue would.
+				event.Redelivered = true
+				go func() {
+					select {
+					case ch <- event:
+					case <-b.done:
+					}
+				}()
+				return nil
+			}
+			b.mu.Lock()
+			b.dead[queue] = append(b.dead[queue], DeadLetter{Event: event, Reason: err.Error()})
+			b.mu.Unlock()
+		}
+	}
+}
+
+// DeadLetters returns the events dead-lettered from queue so far.
+func (b *MemoryBroker) DeadLetters(queue string) []DeadLetter {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]DeadLetter(nil), b.dead[queue]...)
+}
+
+// Pending reports how many events wait on queue.
+func (b *MemoryBroker) Pending(queue string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.queue(queue))
+}
+
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.closed {
+		b.closed = true
+		close(b.done)
+	}
+	return nil
+}
+
+// queue returns the channel for name, creating it on first use. Callers hold b.mu.
+func (b *MemoryBroker) queue(name string) chan domain.Event {
+	ch, ok := b.queues[name]
+	if !ok {
+		ch = make(chan domain.Event, memoryQueueSize)
+		b.queues[name] = ch
+	}
+	return ch
+}
@@ -0,0 +1,202 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/retry"
+)
+
+const testQueue = "ai.face.results"
+
+func testRetryConfig() retry.Config {
+	return retry.Config{MaxRetries: 3, InitialWait: time.Millisecond, MaxWait: 5 * time.Millisecond}
+}
+
+// recordingHandler answers each delivery with the next error of results, then nil.
+type recordingHandler struct {
+	mu      sync.Mutex
+	results []error
+	events  []domain.Event
+	calls   chan struct{}
+}
+
+func newRecordingHandler(results ...error) *recordingHandler {
+	return &recordingHandler{results: results, calls: make(chan struct{}, 64)}
+}
+
+func (h *recordingHandler) handle(_ context.Context, event domain.Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, event)
+	h.calls <- struct{}{}
+	if len(h.results) == 0 {
+		return nil
+	}
+	err := h.results[0]
+	h.results = h.results[1:]
+	return err
+}
+
+func (h *recordingHandler) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.events)
+}
+
+func startConsumer(t *testing.T, broker *MemoryBroker, handler domain.EventHandler) (stop func()) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := broker.Consume(ctx, testQueue, handler); err != nil {
+			t.Errorf("Consume: %v", err)
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMemoryBrokerAcksHandledEvent(t *testing.T) {
+	broker := NewMemoryBroker(testRetryConfig())
+	defer broker.Close()
+	handler := newRecordingHandler()
+	defer startConsumer(t, broker, handler.handle)()
+
+	id, err := broker.Publish(testQueue, []byte(`{"jobId":"j1"}`), map[string]any{"x-job-type": "FACE_MATCH"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "delivery", func() bool { return handler.count() == 1 })
+	time.Sleep(20 * time.Millisecond)
+
+	if got := handler.count(); got != 1 {
+		t.Fatalf("handled %d times, want 1", got)
+	}
+	event := handler.events[0]
+	if event.ID != id || event.Type != "FACE_MATCH" || string(event.Body) != `{"jobId":"j1"}` || event.Redelivered {
+		t.Errorf("delivered %+v", event)
+	}
+	if dead := broker.DeadLetters(testQueue); len(dead) != 0 {
+		t.Errorf("dead letters %+v, want none", dead)
+	}
+	if pending := broker.Pending(testQueue); pending != 0 {
+		t.Errorf("%d events pending, want 0", pending)
+	}
+}
+
+func TestMemoryBrokerRetriesTransientFailures(t *testing.T) {
+	broker := NewMemoryBroker(testRetryConfig())
+	defer broker.Close()
+	transient := errors.New("database unavailable")
+	handler := newRecordingHandler(transient, transient)
+	defer startConsumer(t, broker, handler.handle)()
+
+	if _, err := broker.Publish(testQueue, []byte(`{}`), nil); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "third attempt", func() bool { return handler.count() == 3 })
+	time.Sleep(20 * time.Millisecond)
+
+	if got := handler.count(); got != 3 {
+		t.Fatalf("handled %d times, want 2 failures and 1 success", got)
+	}
+	if dead := broker.DeadLetters(testQueue); len(dead) != 0 {
+		t.Errorf("dead letters %+v, want none", dead)
+	}
+}
+
+func TestMemoryBrokerDeadLettersAfterMaxRetries(t *testing.T) {
+	cfg := testRetryConfig()
+	broker := NewMemoryBroker(cfg)
+	defer broker.Close()
+	results := make([]error, cfg.MaxRetries+1)
+	for i := range results {
+		results[i] = fmt.Errorf("attempt %d failed", i+1)
+	}
+	handler := newRecordingHandler(results...)
+	defer startConsumer(t, broker, handler.handle)()
+
+	id, err := broker.Publish(testQueue, []byte(`{}`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "dead letter", func() bool { return len(broker.DeadLetters(testQueue)) == 1 })
+
+	if got := handler.count(); got != cfg.MaxRetries+1 {
+		t.Errorf("handled %d times, want %d", got, cfg.MaxRetries+1)
+	}
+	dead := broker.DeadLetters(testQueue)[0]
+	if dead.Event.ID != id || dead.Reason != fmt.Sprintf("attempt %d failed", cfg.MaxRetries+1) {
+		t.Errorf("dead letter %+v", dead)
+	}
+}
+
+func TestMemoryBrokerDeadLettersPoisonEventWithoutRetry(t *testing.T) {
+	broker := NewMemoryBroker(testRetryConfig())
+	defer broker.Close()
+	handler := newRecordingHandler(fmt.Errorf("%w: unknown job", domain.ErrPoisonEvent))
+	defer startConsumer(t, broker, handler.handle)()
+
+	if _, err := broker.Publish(testQueue, []byte(`not json`), nil); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "dead letter", func() bool { return len(broker.DeadLetters(testQueue)) == 1 })
+
+	if got := handler.count(); got != 1 {
+		t.Errorf("poison event handled %d times, want 1", got)
+	}
+}
+
+func TestMemoryBrokerRequeuesOnShutdown(t *testing.T) {
+	cfg := retry.Config{MaxRetries: 3, InitialWait: time.Hour, MaxWait: time.Hour}
+	broker := NewMemoryBroker(cfg)
+	defer broker.Close()
+	handler := newRecordingHandler(errors.New("database unavailable"))
+	stop := startConsumer(t, broker, handler.handle)
+
+	if _, err := broker.Publish(testQueue, []byte(`{}`), nil); err != nil {
+		t.Fatal(err)
+	}
+	<-handler.calls
+	// The consumer is now waiting out the backoff. Stopping it must hand the event back.
+	stop()
+	waitFor(t, "requeue", func() bool { return broker.Pending(testQueue) == 1 })
+
+	next := newRecordingHandler()
+	defer startConsumer(t, broker, next.handle)()
+	waitFor(t, "redelivery", func() bool { return next.count() == 1 })
+	if !next.events[0].Redelivered {
+		t.Error("requeued event not marked redelivered")
+	}
+	if dead := broker.DeadLetters(testQueue); len(dead) != 0 {
+		t.Errorf("dead letters %+v, want none", dead)
+	}
+}
+
+func TestMemoryBrokerRejectsPublishAfterClose(t *testing.T) {
+	broker := NewMemoryBroker(testRetryConfig())
+	broker.Close()
+	if _, err := broker.Publish(testQueue, []byte(`{}`), nil); err == nil {
+		t.Fatal("publish after close succeeded")
+	}
+}
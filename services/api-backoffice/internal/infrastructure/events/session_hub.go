@@ -0,0 +1,130 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/retry"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SessionEventHub listens on EkycSessionEventChannel and wakes the SSE streams of the
+// session named in each notification. Every replica runs its own hub, so a change
+// committed through one replica reaches clients connected to any other.
+//
+// The hub holds a dedicated connection outside the pool: LISTEN needs a connection for
+// its whole lifetime and the pool is small.
+type SessionEventHub struct {
+	connConfig *pgx.ConnConfig
+
+	mu   sync.Mutex
+	subs map[string]map[chan struct{}]struct{}
+}
+
+var _ domain.EkycSessionEventStream = (*SessionEventHub)(nil)
+
+func NewSessionEventHub(connConfig *pgx.ConnConfig) *SessionEventHub {
+	return &SessionEventHub{
+		connConfig: connConfig,
+		subs:       make(map[string]map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe returns a channel that receives a value whenever sessionID may have new
+// events. Wake-ups are coalesced; the subscriber reads the events from the database.
+func (h *SessionEventHub) Subscribe(sessionID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	if h.subs[sessionID] == nil {
+		h.subs[sessionID] = make(map[chan struct{}]struct{})
+	}
+	h.subs[sessionID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.subs[sessionID], ch)
+			if len(h.subs[sessionID]) == 0 {
+				delete(h.subs, sessionID)
+			}
+		})
+	}
+}
+
+// Run listens until ctx is done, reconnecting with backoff whenever the connection drops.
+func (h *SessionEventHub) Run(ctx context.Context) {
+	reconnect := retry.Config{MaxRetries: 10, InitialWait: time.Second, MaxWait: 30 * time.Second}
+	for {
+		err := retry.WithBackoff(ctx, reconnect, func() error {
+			return h.listenOnce(ctx)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("events: session hub gave up reconnecting: %v", err)
+	}
+}
+
+func (h *SessionEventHub) listenOnce(ctx context.Context) error {
+	conn, err := pgx.ConnectConfig(ctx, h.connConfig)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{domain.EkycSessionEventChannel}.Sanitize()); err != nil {
+		return err
+	}
+	// Notifications sent while we were disconnected are lost; every stream re-reads.
+	h.wakeAll()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		var notice struct {
+			SessionID string `json:"sessionId"`
+		}
+		if err := json.Unmarshal([]byte(notification.Payload), &notice); err != nil || notice.SessionID == "" {
+			continue
+		}
+		h.wake(notice.SessionID)
+	}
+}
+
+func (h *SessionEventHub) wake(sessionID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[sessionID] {
+		signal(ch)
+	}
+}
+
+func (h *SessionEventHub) wakeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for ch := range subs {
+			signal(ch)
+		}
+	}
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -91,10 +91,7 @@ func (h *BackofficeHTTPHandler) CreateVisit(c echo.Context) error {
 	}
 	visit, err := h.Service.CreateVisit(c.Request().Context(), id, req.Actor, t, req.TkskID)
 	if err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			return respondError(c, http.StatusNotFound, err)
-		}
-		return respondError(c, http.StatusInternalServerError, err)
+		return respondVisitScheduleError(c, err)
 	}
 	return c.JSON(http.StatusCreated, visit)
 }
@@ -113,10 +110,7 @@ func (h *BackofficeHTTPHandler) UpdateVisit(c echo.Context) error {
 		return respondError(c, http.StatusBadRequest, errors.New("actor required"))
 	}
 	if err := h.Service.UpdateVisit(c.Request().Context(), appID, visitID, req.Actor, req.UpdateVisitPayload); err != nil {
-		if errors.Is(err, domain.ErrNotFound) {
-			return respondError(c, http.StatusNotFound, err)
-		}
-		return respondError(c, http.StatusInternalServerError, err)
+		return respondChecklistError(c, err)
 	}
 	return c.NoContent(http.StatusNoContent)
 }
@@ -127,6 +121,9 @@ func (h *BackofficeHTTPHandler) ListVisits(c echo.Context) error {
 		TkskID:        c.QueryParam("tkskId"),
 		Status:        c.QueryParam("status"),
 	}
+	if flagged, err := strconv.ParseBool(c.QueryParam("geofenceFlagged")); err == nil {
+		params.GeofenceFlagged = flagged
+	}
 	if limitStr := c.QueryParam("limit"); limitStr != "" {
 		if v, err := strconv.Atoi(limitStr); err == nil {
 			params.Limit = v
@@ -175,6 +172,9 @@ func (h *BackofficeHTTPHandler) UpdateConfig(c echo.Context) error {
 	}
 	cfg, err := h.Service.UpdateConfig(c.Request().Context(), req)
 	if err != nil {
+		if errors.Is(err, domain.ErrInvalidState) {
+			return respondError(c, http.StatusBadRequest, err)
+		}
 		return respondError(c, http.StatusInternalServerError, err)
 	}
 	return c.JSON(http.StatusOK, cfg)
@@ -225,6 +225,9 @@ func (h *BackofficeHTTPHandler) UpdateBatchStatus(c echo.Context) error {
 		if errors.Is(err, domain.ErrNotFound) {
 			return respondError(c, http.StatusNotFound, err)
 		}
+		if errors.Is(err, domain.ErrInvalidState) {
+			return respondError(c, http.StatusBadRequest, err)
+		}
 		return respondError(c, http.StatusInternalServerError, err)
 	}
 	return c.NoContent(http.StatusNoContent)
@@ -434,6 +437,50 @@ func (h *BackofficeHTTPHandler) Overview(c echo.Context) error {
 	return c.JSON(http.StatusOK, data)
 }
 
+// EkycFunnel serves /api/analytics/ekyc-funnel. from and to take RFC3339 or a plain
+// date; a plain to date includes that whole day.
+func (h *BackofficeHTTPHandler) EkycFunnel(c echo.Context) error {
+	params := domain.EkycFunnelParams{
+		Granularity: c.QueryParam("granularity"),
+		RegionLevel: c.QueryParam("regionLevel"),
+		Region:      c.QueryParam("region"),
+	}
+	if from := strings.TrimSpace(c.QueryParam("from")); from != "" {
+		t, _, err := parseReportTime(from)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, errors.New("invalid from"))
+		}
+		params.From = t
+	}
+	if to := strings.TrimSpace(c.QueryParam("to")); to != "" {
+		t, dateOnly, err := parseReportTime(to)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, errors.New("invalid to"))
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		params.To = t
+	}
+	report, err := h.Service.EkycFunnel(c.Request().Context(), params)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidState) {
+			return respondError(c, http.StatusBadRequest, err)
+		}
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+// parseReportTime accepts RFC3339 or a WIB calendar date and reports which one it got.
+func parseReportTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.FixedZone("WIB", 7*60*60))
+	return t, true, err
+}
+
 func respondError(c echo.Context, status int, err error) error {
 	if err == nil {
 		return c.NoContent(status)
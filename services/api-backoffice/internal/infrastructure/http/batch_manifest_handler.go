@@ -0,0 +1,75 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BatchManifestHTTPHandler struct {
+	svc domain.BatchManifestService
+}
+
+var _ domain.BatchManifestHTTPHandler = (*BatchManifestHTTPHandler)(nil)
+
+func NewBatchManifestHTTPHandler(svc domain.BatchManifestService) *BatchManifestHTTPHandler {
+	return &BatchManifestHTTPHandler{svc: svc}
+}
+
+func (h *BatchManifestHTTPHandler) Get(c echo.Context) error {
+	manifest, err := h.svc.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondBatchManifestError(c, err)
+	}
+	return c.JSON(http.StatusOK, manifest)
+}
+
+// Verify answers 200 with valid=false on a mismatch; the check itself succeeded.
+func (h *BatchManifestHTTPHandler) Verify(c echo.Context) error {
+	var req struct {
+		Actor string `json:"actor"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if req.Actor == "" {
+		return respondError(c, http.StatusBadRequest, errors.New("actor required"))
+	}
+	check, err := h.svc.Verify(c.Request().Context(), c.Param("id"), req.Actor)
+	if err != nil {
+		return respondBatchManifestError(c, err)
+	}
+	return c.JSON(http.StatusOK, check)
+}
+
+func (h *BatchManifestHTTPHandler) ReplaceItems(c echo.Context) error {
+	var req struct {
+		Items []string `json:"items"`
+		Actor string   `json:"actor"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if len(req.Items) == 0 || req.Actor == "" {
+		return respondError(c, http.StatusBadRequest, errors.New("items, actor required"))
+	}
+	batch, err := h.svc.ReplaceItems(c.Request().Context(), c.Param("id"), req.Items, req.Actor)
+	if err != nil {
+		return respondBatchManifestError(c, err)
+	}
+	return c.JSON(http.StatusOK, batch)
+}
+
+func respondBatchManifestError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrInvalidState):
+		return respondError(c, http.StatusBadRequest, err)
+	default:
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+}
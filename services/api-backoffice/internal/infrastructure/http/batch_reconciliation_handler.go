@@ -0,0 +1,77 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type BatchReconciliationHTTPHandler struct {
+	svc domain.BatchReconciliationService
+}
+
+var _ domain.BatchReconciliationHTTPHandler = (*BatchReconciliationHTTPHandler)(nil)
+
+func NewBatchReconciliationHTTPHandler(svc domain.BatchReconciliationService) *BatchReconciliationHTTPHandler {
+	return &BatchReconciliationHTTPHandler{svc: svc}
+}
+
+// Import takes a multipart form with the bank result file in "file", its "format" and
+// the importer in "actor".
+func (h *BatchReconciliationHTTPHandler) Import(c echo.Context) error {
+	actor, format := c.FormValue("actor"), c.FormValue("format")
+	if actor == "" || format == "" {
+		return respondError(c, http.StatusBadRequest, errors.New("format and actor required"))
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("file required"))
+	}
+	if header.Size > domain.MaxBankResultBytes {
+		return respondError(c, http.StatusRequestEntityTooLarge, fmt.Errorf("file exceeds %d bytes", domain.MaxBankResultBytes))
+	}
+	file, err := header.Open()
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, domain.MaxBankResultBytes+1))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	reconciliation, err := h.svc.Import(c.Request().Context(), domain.ImportBatchReconciliationParams{
+		BatchID:  c.Param("id"),
+		Format:   format,
+		Filename: header.Filename,
+		Content:  content,
+		Actor:    actor,
+	})
+	if err != nil {
+		return respondBatchReconciliationError(c, err)
+	}
+	return c.JSON(http.StatusCreated, reconciliation)
+}
+
+func (h *BatchReconciliationHTTPHandler) List(c echo.Context) error {
+	reconciliations, err := h.svc.List(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondBatchReconciliationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": reconciliations})
+}
+
+func respondBatchReconciliationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrInvalidState):
+		return respondError(c, http.StatusBadRequest, err)
+	default:
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CalendarFeedHTTPHandler struct {
+	svc  domain.CalendarFeedService
+	auth domain.AuthService
+}
+
+var _ domain.CalendarFeedHTTPHandler = (*CalendarFeedHTTPHandler)(nil)
+
+func NewCalendarFeedHTTPHandler(svc domain.CalendarFeedService, auth domain.AuthService) *CalendarFeedHTTPHandler {
+	return &CalendarFeedHTTPHandler{svc: svc, auth: auth}
+}
+
+// Issue returns a new feed URL for the user. The token in it is shown only once.
+func (h *CalendarFeedHTTPHandler) Issue(c echo.Context) error {
+	params, unauthorized := h.params(c)
+	if unauthorized != nil {
+		return c.JSON(http.StatusUnauthorized, unauthorized)
+	}
+	feed, err := h.svc.Issue(c.Request().Context(), params)
+	if err != nil {
+		return respondCalendarFeedError(c, err)
+	}
+	return c.JSON(http.StatusCreated, feed)
+}
+
+func (h *CalendarFeedHTTPHandler) Revoke(c echo.Context) error {
+	params, unauthorized := h.params(c)
+	if unauthorized != nil {
+		return c.JSON(http.StatusUnauthorized, unauthorized)
+	}
+	if err := h.svc.Revoke(c.Request().Context(), params); err != nil {
+		return respondCalendarFeedError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Feed serves /api/calendar/<token>.ics to calendar apps.
+func (h *CalendarFeedHTTPHandler) Feed(c echo.Context) error {
+	token := strings.TrimSuffix(c.Param("feed"), ".ics")
+	body, err := h.svc.Render(c.Request().Context(), token)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return c.String(http.StatusNotFound, "calendar feed not found")
+		}
+		return c.String(http.StatusInternalServerError, err.Error())
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="jadwal.ics"`)
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-cache")
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
+
+func (h *CalendarFeedHTTPHandler) params(c echo.Context) (domain.CalendarFeedParams, map[string]string) {
+	token := parseBearer(c.Request().Header.Get("Authorization"))
+	if token == "" {
+		return domain.CalendarFeedParams{}, map[string]string{"error": "missing token"}
+	}
+	sess, ok := h.auth.Validate(token)
+	if !ok {
+		return domain.CalendarFeedParams{}, map[string]string{"error": "invalid token"}
+	}
+	return domain.CalendarFeedParams{
+		UserID:    c.Param("userId"),
+		ActorID:   sess.UserID,
+		ActorRole: sess.Role,
+	}, nil
+}
+
+func respondCalendarFeedError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrForbidden):
+		return respondError(c, http.StatusForbidden, err)
+	case errors.Is(err, domain.ErrInvalidState):
+		return respondError(c, http.StatusBadRequest, err)
+	default:
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+}
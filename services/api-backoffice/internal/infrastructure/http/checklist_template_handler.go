@@ -0,0 +1,107 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ChecklistTemplateHTTPHandler struct {
+	svc domain.ChecklistTemplateService
+}
+
+var _ domain.ChecklistTemplateHTTPHandler = (*ChecklistTemplateHTTPHandler)(nil)
+
+func NewChecklistTemplateHTTPHandler(svc domain.ChecklistTemplateService) *ChecklistTemplateHTTPHandler {
+	return &ChecklistTemplateHTTPHandler{svc: svc}
+}
+
+func (h *ChecklistTemplateHTTPHandler) List(c echo.Context) error {
+	templates, err := h.svc.List(c.Request().Context(), domain.ListChecklistTemplatesParams{
+		Program: c.QueryParam("program"),
+		Period:  c.QueryParam("period"),
+	})
+	if err != nil {
+		return respondChecklistError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": templates})
+}
+
+func (h *ChecklistTemplateHTTPHandler) Get(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid checklist template id"))
+	}
+	template, err := h.svc.Get(c.Request().Context(), id)
+	if err != nil {
+		return respondChecklistError(c, err)
+	}
+	return c.JSON(http.StatusOK, template)
+}
+
+func (h *ChecklistTemplateHTTPHandler) Publish(c echo.Context) error {
+	var req struct {
+		Actor   string                 `json:"actor"`
+		Program string                 `json:"program"`
+		Period  string                 `json:"period"`
+		Schema  domain.ChecklistSchema `json:"schema"`
+		Notes   string                 `json:"notes"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid payload"))
+	}
+	template, err := h.svc.Publish(c.Request().Context(), domain.PublishChecklistTemplateParams{
+		Program: req.Program,
+		Period:  req.Period,
+		Schema:  req.Schema,
+		Notes:   req.Notes,
+		Actor:   req.Actor,
+	})
+	if err != nil {
+		return respondChecklistError(c, err)
+	}
+	return c.JSON(http.StatusCreated, template)
+}
+
+func (h *ChecklistTemplateHTTPHandler) Retire(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid checklist template id"))
+	}
+	var req struct {
+		Actor string `json:"actor"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid payload"))
+	}
+	template, err := h.svc.Retire(c.Request().Context(), id, req.Actor)
+	if err != nil {
+		return respondChecklistError(c, err)
+	}
+	return c.JSON(http.StatusOK, template)
+}
+
+// respondChecklistError answers a checklist that does not match its template with the
+// offending fields, so the form can mark each one.
+func respondChecklistError(c echo.Context, err error) error {
+	var invalid *domain.ChecklistValidationError
+	switch {
+	case errors.As(err, &invalid):
+		return c.JSON(http.StatusUnprocessableEntity, map[string]any{
+			"error":           err.Error(),
+			"templateId":      invalid.TemplateID,
+			"templateVersion": invalid.TemplateVersion,
+			"fields":          invalid.Fields,
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		return respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrInvalidState):
+		return respondError(c, http.StatusBadRequest, err)
+	default:
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+}
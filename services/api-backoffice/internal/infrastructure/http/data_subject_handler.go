@@ -0,0 +1,127 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DataSubjectHTTPHandler struct {
+	svc domain.DataSubjectService
+}
+
+var _ domain.DataSubjectHTTPHandler = (*DataSubjectHTTPHandler)(nil)
+
+func NewDataSubjectHTTPHandler(svc domain.DataSubjectService) *DataSubjectHTTPHandler {
+	return &DataSubjectHTTPHandler{svc: svc}
+}
+
+func (h *DataSubjectHTTPHandler) Export(c echo.Context) error {
+	bundle, err := h.svc.Export(c.Request().Context(), c.Param("userId"), c.QueryParam("actor"))
+	if err != nil {
+		return respondDataSubjectError(c, err)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", bundle.FileName))
+	return c.Blob(http.StatusOK, "application/zip", bundle.Content)
+}
+
+func (h *DataSubjectHTTPHandler) RequestErasure(c echo.Context) error {
+	var payload domain.CreateErasureRequestParams
+	if err := c.Bind(&payload); err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid payload"))
+	}
+	payload.UserID = c.Param("userId")
+	request, err := h.svc.RequestErasure(c.Request().Context(), payload)
+	if err != nil {
+		return respondDataSubjectError(c, err)
+	}
+	return c.JSON(http.StatusCreated, request)
+}
+
+func (h *DataSubjectHTTPHandler) ListErasureRequests(c echo.Context) error {
+	params := domain.ListErasureRequestsParams{Status: c.QueryParam("status"), Limit: queryLimit(c)}
+	requests, err := h.svc.ListErasureRequests(c.Request().Context(), params)
+	if err != nil {
+		return respondDataSubjectError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": requests})
+}
+
+func (h *DataSubjectHTTPHandler) GetErasureRequest(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid erasure request id"))
+	}
+	request, err := h.svc.GetErasureRequest(c.Request().Context(), id)
+	if err != nil {
+		return respondDataSubjectError(c, err)
+	}
+	return c.JSON(http.StatusOK, request)
+}
+
+func (h *DataSubjectHTTPHandler) ApproveErasure(c echo.Context) error {
+	params, err := bindErasureReview(c)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	request, err := h.svc.ApproveErasure(c.Request().Context(), params)
+	if err != nil {
+		return respondDataSubjectError(c, err)
+	}
+	return c.JSON(http.StatusOK, request)
+}
+
+func (h *DataSubjectHTTPHandler) RejectErasure(c echo.Context) error {
+	params, err := bindErasureReview(c)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	request, err := h.svc.RejectErasure(c.Request().Context(), params)
+	if err != nil {
+		return respondDataSubjectError(c, err)
+	}
+	return c.JSON(http.StatusOK, request)
+}
+
+func (h *DataSubjectHTTPHandler) RetryErasureMedia(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid erasure request id"))
+	}
+	request, err := h.svc.RetryErasureMedia(c.Request().Context(), id)
+	if err != nil {
+		return respondDataSubjectError(c, err)
+	}
+	return c.JSON(http.StatusOK, request)
+}
+
+func bindErasureReview(c echo.Context) (domain.ReviewErasureRequestParams, error) {
+	var params domain.ReviewErasureRequestParams
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return params, errors.New("invalid erasure request id")
+	}
+	if err := c.Bind(&params); err != nil {
+		return params, errors.New("invalid payload")
+	}
+	params.ID = id
+	return params, nil
+}
+
+func respondDataSubjectError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrForbidden):
+		return respondError(c, http.StatusForbidden, err)
+	case errors.Is(err, domain.ErrInvalidState):
+		return respondError(c, http.StatusBadRequest, err)
+	default:
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+}
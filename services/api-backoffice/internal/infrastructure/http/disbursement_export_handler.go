@@ -0,0 +1,79 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type DisbursementExportHTTPHandler struct {
+	svc domain.DisbursementExportService
+}
+
+var _ domain.DisbursementExportHTTPHandler = (*DisbursementExportHTTPHandler)(nil)
+
+func NewDisbursementExportHTTPHandler(svc domain.DisbursementExportService) *DisbursementExportHTTPHandler {
+	return &DisbursementExportHTTPHandler{svc: svc}
+}
+
+func (h *DisbursementExportHTTPHandler) Export(c echo.Context) error {
+	var req struct {
+		Format string `json:"format"`
+		Actor  string `json:"actor"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if req.Format == "" || req.Actor == "" {
+		return respondError(c, http.StatusBadRequest, errors.New("format and actor required"))
+	}
+	export, err := h.svc.Export(c.Request().Context(), c.Param("id"), req.Format, req.Actor)
+	if err != nil {
+		return respondDisbursementExportError(c, err)
+	}
+	return c.JSON(http.StatusCreated, export)
+}
+
+func (h *DisbursementExportHTTPHandler) List(c echo.Context) error {
+	exports, err := h.svc.List(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondDisbursementExportError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": exports})
+}
+
+func (h *DisbursementExportHTTPHandler) SetPayout(c echo.Context) error {
+	var req struct {
+		Channel string `json:"channel"`
+		Bank    string `json:"bank"`
+		Account string `json:"account"`
+		Actor   string `json:"actor"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	payout := domain.BeneficiaryPayout{
+		UserID:  c.Param("userId"),
+		Channel: req.Channel,
+		Bank:    req.Bank,
+		Account: req.Account,
+	}
+	if err := h.svc.SetPayout(c.Request().Context(), payout, req.Actor); err != nil {
+		return respondDisbursementExportError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func respondDisbursementExportError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrInvalidState):
+		return respondError(c, http.StatusBadRequest, err)
+	default:
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,168 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	sseHeartbeatInterval = 15 * time.Second
+	// ssePollInterval re-reads the event log even without a notification, covering
+	// notifications lost while the hub was reconnecting.
+	ssePollInterval = 30 * time.Second
+	sseRetryMillis  = 3000
+)
+
+// StreamSessionEvents streams committed session changes as Server-Sent Events. Each
+// event carries the ekyc_session_events id, so a reconnecting client sends it back as
+// Last-Event-ID (or ?lastEventId=) and resumes without gaps. A client starting fresh
+// first receives a "snapshot" event with the current session.
+func (h *EkycHTTPHandler) StreamSessionEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+	sessionID := c.Param("id")
+
+	lastID, resuming, err := lastEventID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid Last-Event-ID"})
+	}
+
+	// Subscribe before reading so nothing committed in between is missed.
+	var wake <-chan struct{}
+	if h.stream != nil {
+		ch, cancel := h.stream.Subscribe(sessionID)
+		defer cancel()
+		wake = ch
+	}
+	if !resuming {
+		// Position before taking the snapshot: a change racing with it is sent again
+		// rather than lost.
+		if lastID, err = h.latestEventID(c, sessionID); err != nil {
+			code, body := mapError(err)
+			return c.JSON(code, body)
+		}
+	}
+	session, err := h.svc.GetSession(ctx, sessionID)
+	if err != nil {
+		code, body := mapError(err)
+		return c.JSON(code, body)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", sseRetryMillis); err != nil {
+		return nil
+	}
+	if !resuming {
+		// The snapshot carries the log position it reflects, so a reconnect resumes
+		// from there.
+		snapshotID := ""
+		if lastID > 0 {
+			snapshotID = strconv.FormatInt(lastID, 10)
+		}
+		if err := writeSSE(res, snapshotID, "snapshot", session); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+	poll := time.NewTicker(ssePollInterval)
+	defer poll.Stop()
+
+	for {
+		next, err := h.flushSessionEvents(c, sessionID, lastID)
+		if err != nil {
+			return nil
+		}
+		lastID = next
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wake:
+		case <-poll.C:
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// flushSessionEvents writes every event after lastID and returns the new position.
+func (h *EkycHTTPHandler) flushSessionEvents(c echo.Context, sessionID string, lastID int64) (int64, error) {
+	res := c.Response()
+	for {
+		events, err := h.svc.ListSessionEvents(c.Request().Context(), sessionID, lastID)
+		if err != nil {
+			return lastID, err
+		}
+		if len(events) == 0 {
+			return lastID, nil
+		}
+		for _, event := range events {
+			if err := writeSSE(res, strconv.FormatInt(event.ID, 10), event.Type, event); err != nil {
+				return lastID, err
+			}
+			lastID = event.ID
+		}
+		res.Flush()
+	}
+}
+
+// latestEventID pages to the end of the log so a fresh stream starts after it.
+func (h *EkycHTTPHandler) latestEventID(c echo.Context, sessionID string) (int64, error) {
+	var lastID int64
+	for {
+		events, err := h.svc.ListSessionEvents(c.Request().Context(), sessionID, lastID)
+		if err != nil {
+			return 0, err
+		}
+		if len(events) == 0 {
+			return lastID, nil
+		}
+		lastID = events[len(events)-1].ID
+	}
+}
+
+func lastEventID(c echo.Context) (int64, bool, error) {
+	raw := strings.TrimSpace(c.Request().Header.Get("Last-Event-ID"))
+	if raw == "" {
+		raw = strings.TrimSpace(c.QueryParam("lastEventId"))
+	}
+	if raw == "" {
+		return 0, false, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, fmt.Errorf("invalid event id %q", raw)
+	}
+	return id, true, nil
+}
+
+func writeSSE(res *echo.Response, id, event string, data any) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	fmt.Fprintf(&b, "event: %s\ndata: %s\n\n", event, body)
+	_, err = res.Write([]byte(b.String()))
+	return err
+}
@@ -13,11 +13,15 @@ import (
 )
 
 type EkycHTTPHandler struct {
-	svc domain.EkycService
+	svc    domain.EkycService
+	auth   domain.AuthService
+	stream domain.EkycSessionEventStream
 }
 
-func NewEkycHTTPHandler(svc domain.EkycService) *EkycHTTPHandler {
-	return &EkycHTTPHandler{svc: svc}
+// NewEkycHTTPHandler wires the eKYC endpoints. stream may be nil, in which case session
+// event streams fall back to polling.
+func NewEkycHTTPHandler(svc domain.EkycService, auth domain.AuthService, stream domain.EkycSessionEventStream) *EkycHTTPHandler {
+	return &EkycHTTPHandler{svc: svc, auth: auth, stream: stream}
 }
 
 func (h *EkycHTTPHandler) CreateSession(c echo.Context) error {
@@ -121,6 +125,12 @@ type applicantPayload struct {
 	Phone     string `json:"phone"`
 	Email     string `json:"email"`
 	Pin       string `json:"pin"`
+	Region    struct {
+		Prov string `json:"prov"`
+		Kab  string `json:"kab"`
+		Kec  string `json:"kec"`
+		Kel  string `json:"kel"`
+	} `json:"region"`
 }
 
 func (h *EkycHTTPHandler) AssignApplicant(c echo.Context) error {
@@ -146,6 +156,12 @@ func (h *EkycHTTPHandler) AssignApplicant(c echo.Context) error {
 		Phone:     payload.Phone,
 		Email:     payload.Email,
 		Pin:       payload.Pin,
+		Region: domain.Region{
+			Prov: payload.Region.Prov,
+			Kab:  payload.Region.Kab,
+			Kec:  payload.Region.Kec,
+			Kel:  payload.Region.Kel,
+		},
 	})
 	if err != nil {
 		code, body := mapError(err)
@@ -187,18 +203,30 @@ func (h *EkycHTTPHandler) Finalize(c echo.Context) error {
 }
 
 func (h *EkycHTTPHandler) OverrideDecision(c echo.Context) error {
+	token := parseBearer(c.Request().Header.Get("Authorization"))
+	if token == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "missing token"})
+	}
+	sess, ok := h.auth.Validate(token)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+	}
 	sessionID := c.Param("id")
 	var payload struct {
-		FinalDecision string  `json:"finalDecision"`
-		Reason        *string `json:"reason"`
+		FinalDecision string `json:"finalDecision"`
+		ReasonCode    string `json:"reasonCode"`
+		ReasonText    string `json:"reasonText"`
 	}
 	if err := c.Bind(&payload); err != nil || payload.FinalDecision == "" {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid payload"})
 	}
-	session, err := h.svc.OverrideDecision(c.Request().Context(), domain.UpdateEkycDecisionParams{
+	session, err := h.svc.OverrideDecision(c.Request().Context(), domain.OverrideEkycDecisionParams{
 		SessionID:     sessionID,
 		FinalDecision: payload.FinalDecision,
-		Reason:        payload.Reason,
+		ReasonCode:    payload.ReasonCode,
+		ReasonText:    payload.ReasonText,
+		Actor:         sess.UserID,
+		ActorRole:     sess.Role,
 	})
 	if err != nil {
 		code, body := mapError(err)
@@ -207,9 +235,214 @@ func (h *EkycHTTPHandler) OverrideDecision(c echo.Context) error {
 	return c.JSON(http.StatusOK, session)
 }
 
+func (h *EkycHTTPHandler) ListOverrideReasons(c echo.Context) error {
+	reasons, err := h.svc.ListOverrideReasons(c.Request().Context())
+	if err != nil {
+		code, body := mapError(err)
+		return c.JSON(code, body)
+	}
+	return c.JSON(http.StatusOK, reasons)
+}
+
+func (h *EkycHTTPHandler) RerunFaceMatch(c echo.Context) error {
+	result, err := h.svc.RerunFaceMatch(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		code, body := mapError(err)
+		return c.JSON(code, body)
+	}
+	return c.JSON(http.StatusAccepted, result)
+}
+
+type ocrPayload struct {
+	Nik         *string            `json:"nik"`
+	Name        *string            `json:"name"`
+	BirthDate   *string            `json:"birthDate"`
+	Address     *string            `json:"address"`
+	Confidence  map[string]float64 `json:"confidence"`
+	RawMetadata map[string]any     `json:"rawMetadata"`
+}
+
+// ocrDateLayouts accepts ISO dates and the DD-MM-YYYY form printed on the KTP.
+var ocrDateLayouts = []string{"2006-01-02", "02-01-2006", "02/01/2006"}
+
+func (h *EkycHTTPHandler) RecordOcrResult(c echo.Context) error {
+	var payload ocrPayload
+	if err := c.Bind(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+	}
+	result := domain.OcrResult{
+		SessionID:   c.Param("id"),
+		Nik:         payload.Nik,
+		Name:        payload.Name,
+		Address:     payload.Address,
+		Confidence:  payload.Confidence,
+		RawMetadata: payload.RawMetadata,
+	}
+	if payload.BirthDate != nil && strings.TrimSpace(*payload.BirthDate) != "" {
+		for _, layout := range ocrDateLayouts {
+			if parsed, err := time.Parse(layout, strings.TrimSpace(*payload.BirthDate)); err == nil {
+				result.BirthDate = &parsed
+				break
+			}
+		}
+		if result.BirthDate == nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "format tanggal lahir tidak valid"})
+		}
+	}
+	saved, err := h.svc.RecordOcrResult(c.Request().Context(), result)
+	if err != nil {
+		code, body := mapError(err)
+		return c.JSON(code, body)
+	}
+	return c.JSON(http.StatusOK, saved)
+}
+
+func (h *EkycHTTPHandler) GetOcrResult(c echo.Context) error {
+	result, err := h.svc.GetOcrResult(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		code, body := mapError(err)
+		return c.JSON(code, body)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
 func mapError(err error) (int, map[string]string) {
 	if errors.Is(err, domain.ErrNotFound) {
 		return http.StatusNotFound, map[string]string{"error": "not found"}
 	}
+	if errors.Is(err, domain.ErrForbidden) {
+		return http.StatusForbidden, map[string]string{"error": err.Error()}
+	}
+	if errors.Is(err, domain.ErrSessionLocked) || errors.Is(err, domain.ErrReviewClaimed) {
+		return http.StatusConflict, map[string]string{"error": err.Error()}
+	}
+	if errors.Is(err, domain.ErrInvalidState) {
+		return http.StatusBadRequest, map[string]string{"error": err.Error()}
+	}
+	if errors.Is(err, domain.ErrAiSupportUnavailable) {
+		return http.StatusServiceUnavailable, map[string]string{"error": err.Error()}
+	}
 	return http.StatusInternalServerError, map[string]string{"error": err.Error()}
 }
+
+// reviewer resolves the bearer token of a review-queue request.
+func (h *EkycHTTPHandler) reviewer(c echo.Context) (*domain.Session, map[string]string) {
+	token := parseBearer(c.Request().Header.Get("Authorization"))
+	if token == "" {
+		return nil, map[string]string{"error": "missing token"}
+	}
+	sess, ok := h.auth.Validate(token)
+	if !ok {
+		return nil, map[string]string{"error": "invalid token"}
+	}
+	return sess, nil
+}
+
+func (h *EkycHTTPHandler) ListReviewQueue(c echo.Context) error {
+	sess, unauthorized := h.reviewer(c)
+	if unauthorized != nil {
+		return c.JSON(http.StatusUnauthorized, unauthorized)
+	}
+	if !roleIn(sess.Role, domain.EkycReviewRoles) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	includeClaimed, _ := strconv.ParseBool(c.QueryParam("includeClaimed"))
+	items, err := h.svc.ListReviewQueue(c.Request().Context(), domain.ListReviewQueueParams{
+		Limit:          limit,
+		Offset:         offset,
+		IncludeClaimed: includeClaimed,
+		Reviewer:       sess.UserID,
+	})
+	if err != nil {
+		code, body := mapError(err)
+		return c.JSON(code, body)
+	}
+	return c.JSON(http.StatusOK, items)
+}
+
+func (h *EkycHTTPHandler) GetReviewCase(c echo.Context) error {
+	sess, unauthorized := h.reviewer(c)
+	if unauthorized != nil {
+		return c.JSON(http.StatusUnauthorized, unauthorized)
+	}
+	if !roleIn(sess.Role, domain.EkycReviewRoles) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+	reviewCase, err := h.svc.GetReviewCase(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		code, body := mapError(err)
+		return c.JSON(code, body)
+	}
+	return c.JSON(http.StatusOK, reviewCase)
+}
+
+func (h *EkycHTTPHandler) ClaimReview(c echo.Context) error {
+	sess, unauthorized := h.reviewer(c)
+	if unauthorized != nil {
+		return c.JSON(http.StatusUnauthorized, unauthorized)
+	}
+	claim, err := h.svc.ClaimReview(c.Request().Context(), domain.ReviewClaimParams{
+		SessionID:    c.Param("id"),
+		Reviewer:     sess.UserID,
+		ReviewerRole: sess.Role,
+	})
+	if err != nil {
+		code, body := mapError(err)
+		return c.JSON(code, body)
+	}
+	return c.JSON(http.StatusOK, claim)
+}
+
+func (h *EkycHTTPHandler) ReleaseReview(c echo.Context) error {
+	sess, unauthorized := h.reviewer(c)
+	if unauthorized != nil {
+		return c.JSON(http.StatusUnauthorized, unauthorized)
+	}
+	err := h.svc.ReleaseReview(c.Request().Context(), domain.ReviewClaimParams{
+		SessionID:    c.Param("id"),
+		Reviewer:     sess.UserID,
+		ReviewerRole: sess.Role,
+	})
+	if err != nil {
+		code, body := mapError(err)
+		return c.JSON(code, body)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *EkycHTTPHandler) SubmitReviewVerdict(c echo.Context) error {
+	sess, unauthorized := h.reviewer(c)
+	if unauthorized != nil {
+		return c.JSON(http.StatusUnauthorized, unauthorized)
+	}
+	var payload struct {
+		Verdict string `json:"verdict"`
+		Reason  string `json:"reason"`
+	}
+	if err := c.Bind(&payload); err != nil || payload.Verdict == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+	}
+	session, err := h.svc.SubmitReviewVerdict(c.Request().Context(), domain.ReviewVerdictParams{
+		SessionID:    c.Param("id"),
+		Verdict:      payload.Verdict,
+		Reason:       payload.Reason,
+		Reviewer:     sess.UserID,
+		ReviewerRole: sess.Role,
+	})
+	if err != nil {
+		code, body := mapError(err)
+		return c.JSON(code, body)
+	}
+	return c.JSON(http.StatusOK, session)
+}
+
+func roleIn(role string, allowed []string) bool {
+	for _, candidate := range allowed {
+		if strings.EqualFold(strings.TrimSpace(role), candidate) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,202 @@
+package http
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderIdempotencyKey    = "Idempotency-Key"
+	HeaderIdempotentReplay  = "Idempotent-Replayed"
+	defaultIdempotencyTTL   = 24 * time.Hour
+	defaultIdempotencyWait  = 10 * time.Second
+	idempotencyPollInterval = 100 * time.Millisecond
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyMiddleware replays the stored response when a POST or PATCH is retried with
+// the same Idempotency-Key, so at-least-once callers (the AI workers) do not apply a
+// result twice. Keys are scoped to the subject of the bearer token, and unauthenticated
+// callers share one scope where the request hash keeps a key from being reused with
+// another payload. Requests without the header pass through untouched, as do requests
+// with a bearer token that does not validate.
+type IdempotencyMiddleware struct {
+	repo domain.IdempotencyRepository
+	auth domain.AuthService
+	ttl  time.Duration
+	wait time.Duration
+}
+
+func NewIdempotencyMiddleware(repo domain.IdempotencyRepository, auth domain.AuthService, ttl time.Duration) *IdempotencyMiddleware {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	return &IdempotencyMiddleware{repo: repo, auth: auth, ttl: ttl, wait: defaultIdempotencyWait}
+}
+
+func (m *IdempotencyMiddleware) Handler(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		if req.Method != http.MethodPost && req.Method != http.MethodPatch {
+			return next(c)
+		}
+		key := strings.TrimSpace(req.Header.Get(HeaderIdempotencyKey))
+		if key == "" {
+			return next(c)
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return respondError(c, http.StatusBadRequest, errors.New("idempotency key too long"))
+		}
+		caller, ok := m.caller(req)
+		if !ok {
+			return next(c)
+		}
+		reservation, err := newReservation()
+		if err != nil {
+			return respondError(c, http.StatusInternalServerError, err)
+		}
+
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := req.Context()
+		path := req.URL.Path
+		record := domain.IdempotencyRecord{
+			IdempotencyKey: domain.IdempotencyKey{Key: key, Method: req.Method, Path: path, Caller: caller},
+			Reservation:    reservation,
+			RequestHash:    hashRequest(req.Method, path, body),
+			ExpiresAt:      time.Now().UTC().Add(m.ttl),
+		}
+
+		existing, reserved, err := m.repo.Reserve(ctx, record)
+		if err != nil {
+			return respondError(c, http.StatusInternalServerError, err)
+		}
+		if !reserved {
+			return m.replay(c, record, existing)
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+		c.Response().Writer = recorder
+		handlerErr := next(c)
+
+		status := c.Response().Status
+		if handlerErr != nil || !c.Response().Committed || status >= http.StatusInternalServerError {
+			// Leave the key free so the caller can retry after a server-side failure.
+			if err := m.repo.Release(ctx, record.IdempotencyKey, record.Reservation); err != nil {
+				c.Logger().Errorf("idempotency: release %s: %v", key, err)
+			}
+			return handlerErr
+		}
+
+		record.StatusCode = status
+		record.ContentType = c.Response().Header().Get(echo.HeaderContentType)
+		record.ResponseBody = recorder.body.Bytes()
+		if err := m.repo.Complete(ctx, record); err != nil {
+			c.Logger().Errorf("idempotency: store response %s: %v", key, err)
+		}
+		return nil
+	}
+}
+
+// replay answers a duplicate request. A key that is still in flight is polled until the
+// first request finishes or the wait budget runs out.
+func (m *IdempotencyMiddleware) replay(c echo.Context, incoming domain.IdempotencyRecord, existing *domain.IdempotencyRecord) error {
+	deadline := time.Now().Add(m.wait)
+	for {
+		if existing == nil {
+			return respondError(c, http.StatusConflict, errors.New("idempotency key is being reset, retry the request"))
+		}
+		if existing.RequestHash != incoming.RequestHash {
+			return respondError(c, http.StatusUnprocessableEntity, errors.New("idempotency key reused with a different request payload"))
+		}
+		if existing.Completed {
+			break
+		}
+		if time.Now().After(deadline) {
+			return respondError(c, http.StatusConflict, errors.New("request with this idempotency key is still processing"))
+		}
+		select {
+		case <-c.Request().Context().Done():
+			return c.Request().Context().Err()
+		case <-time.After(idempotencyPollInterval):
+		}
+		found, err := m.repo.Find(c.Request().Context(), incoming.IdempotencyKey)
+		if err != nil && !errors.Is(err, domain.ErrNotFound) {
+			return respondError(c, http.StatusInternalServerError, err)
+		}
+		existing = found
+	}
+
+	c.Response().Header().Set(HeaderIdempotentReplay, "true")
+	if existing.ContentType != "" {
+		return c.Blob(existing.StatusCode, existing.ContentType, existing.ResponseBody)
+	}
+	if len(existing.ResponseBody) == 0 {
+		return c.NoContent(existing.StatusCode)
+	}
+	return c.Blob(existing.StatusCode, echo.MIMEOctetStream, existing.ResponseBody)
+}
+
+// caller returns the subject of the request's bearer token, or "" without one. ok is false
+// when the token does not validate: the handler will refuse the request, and its answer
+// must not be stored under the unauthenticated scope.
+func (m *IdempotencyMiddleware) caller(req *http.Request) (string, bool) {
+	token := parseBearer(req.Header.Get(echo.HeaderAuthorization))
+	if token == "" || m.auth == nil {
+		return "", true
+	}
+	sess, ok := m.auth.Validate(token)
+	if !ok {
+		return "", false
+	}
+	return sess.UserID, true
+}
+
+func newReservation() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashRequest(method, path string, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(method))
+	sum.Write([]byte{0})
+	sum.Write([]byte(path))
+	sum.Write([]byte{0})
+	sum.Write(body)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,285 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+// memoryIdempotencyRepo follows idempotencyRepository: a key is taken over once it
+// expired or its unfinished reservation is older than abandonedAfter, and Complete and
+// Release only match the reservation that holds the key.
+type memoryIdempotencyRepo struct {
+	mu             sync.Mutex
+	records        map[domain.IdempotencyKey]domain.IdempotencyRecord
+	abandonedAfter time.Duration
+}
+
+func newMemoryIdempotencyRepo() *memoryIdempotencyRepo {
+	return &memoryIdempotencyRepo{records: map[domain.IdempotencyKey]domain.IdempotencyRecord{}, abandonedAfter: time.Minute}
+}
+
+func (r *memoryIdempotencyRepo) Reserve(_ context.Context, record domain.IdempotencyRecord) (*domain.IdempotencyRecord, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	existing, ok := r.records[record.IdempotencyKey]
+	if ok && existing.ExpiresAt.After(now) && (existing.Completed || existing.CreatedAt.After(now.Add(-r.abandonedAfter))) {
+		return &existing, false, nil
+	}
+	record.CreatedAt = now
+	r.records[record.IdempotencyKey] = record
+	return nil, true, nil
+}
+
+func (r *memoryIdempotencyRepo) Find(_ context.Context, key domain.IdempotencyKey) (*domain.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record, ok := r.records[key]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return &record, nil
+}
+
+func (r *memoryIdempotencyRepo) Complete(_ context.Context, record domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.records[record.IdempotencyKey]
+	if !ok || existing.Completed || existing.Reservation != record.Reservation {
+		return domain.ErrInvalidState
+	}
+	record.CreatedAt = existing.CreatedAt
+	record.Completed = true
+	r.records[record.IdempotencyKey] = record
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) Release(_ context.Context, key domain.IdempotencyKey, reservation string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.records[key]; ok && !existing.Completed && existing.Reservation == reservation {
+		delete(r.records, key)
+	}
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) DeleteExpired(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+// tokenAuth accepts "token-<user>" bearer tokens.
+type tokenAuth struct{ domain.AuthService }
+
+func (tokenAuth) Validate(token string) (*domain.Session, bool) {
+	user, ok := strings.CutPrefix(token, "token-")
+	if !ok {
+		return nil, false
+	}
+	return &domain.Session{UserID: user}, true
+}
+
+type idempotencyResult struct {
+	status   int
+	body     string
+	replayed bool
+}
+
+func newIdempotencyServer(t *testing.T, repo domain.IdempotencyRepository, handler echo.HandlerFunc) *httptest.Server {
+	t.Helper()
+	e := echo.New()
+	e.Use(NewIdempotencyMiddleware(repo, tokenAuth{}, time.Hour).Handler)
+	e.POST("/api/things", handler)
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func postIdempotent(t *testing.T, srv *httptest.Server, key, token, body string) idempotencyResult {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/things", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(HeaderIdempotencyKey, key)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Error(err)
+		return idempotencyResult{}
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return idempotencyResult{
+		status:   resp.StatusCode,
+		body:     string(data),
+		replayed: resp.Header.Get(HeaderIdempotentReplay) == "true",
+	}
+}
+
+func TestIdempotencyConcurrentDuplicatesRunOnce(t *testing.T) {
+	var calls atomic.Int32
+	srv := newIdempotencyServer(t, newMemoryIdempotencyRepo(), func(c echo.Context) error {
+		n := calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return c.JSON(http.StatusCreated, map[string]any{"call": n})
+	})
+
+	const requests = 20
+	results := make([]idempotencyResult, requests)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = postIdempotent(t, srv, "key-1", "token-worker", `{"a":1}`)
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("handler ran %d times, want 1", got)
+	}
+	replayed := 0
+	for _, result := range results {
+		if result.status != http.StatusCreated || result.body != results[0].body {
+			t.Errorf("got %d %q, want 201 %q", result.status, result.body, results[0].body)
+		}
+		if result.replayed {
+			replayed++
+		}
+	}
+	if replayed != requests-1 {
+		t.Errorf("%d responses replayed, want %d", replayed, requests-1)
+	}
+}
+
+func TestIdempotencyKeysAreScopedToCaller(t *testing.T) {
+	var calls atomic.Int32
+	srv := newIdempotencyServer(t, newMemoryIdempotencyRepo(), func(c echo.Context) error {
+		return c.JSON(http.StatusCreated, map[string]any{"call": calls.Add(1)})
+	})
+
+	var wg sync.WaitGroup
+	results := map[string]*idempotencyResult{"token-alice": {}, "token-bob": {}, "": {}}
+	for token, result := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			*result = postIdempotent(t, srv, "shared-key", token, `{"a":1}`)
+		}()
+	}
+	wg.Wait()
+
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("handler ran %d times, want once per caller", got)
+	}
+	for token, result := range results {
+		if result.replayed {
+			t.Errorf("caller %q got another caller's response %q", token, result.body)
+		}
+	}
+	if again := postIdempotent(t, srv, "shared-key", "token-alice", `{"a":1}`); !again.replayed || again.body != results["token-alice"].body {
+		t.Errorf("retry by alice got %q replayed=%v, want %q", again.body, again.replayed, results["token-alice"].body)
+	}
+}
+
+func TestIdempotencyRejectsKeyReusedWithDifferentBody(t *testing.T) {
+	var calls atomic.Int32
+	srv := newIdempotencyServer(t, newMemoryIdempotencyRepo(), func(c echo.Context) error {
+		calls.Add(1)
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	if first := postIdempotent(t, srv, "key-2", "", `{"a":1}`); first.status != http.StatusNoContent {
+		t.Fatalf("first request: %d %s", first.status, first.body)
+	}
+	if second := postIdempotent(t, srv, "key-2", "", `{"a":2}`); second.status != http.StatusUnprocessableEntity {
+		t.Fatalf("reused key with another body: %d, want 422", second.status)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("handler ran %d times, want 1", got)
+	}
+}
+
+func TestIdempotencyInvalidTokenIsNotStored(t *testing.T) {
+	repo := newMemoryIdempotencyRepo()
+	srv := newIdempotencyServer(t, repo, func(c echo.Context) error {
+		return c.NoContent(http.StatusUnauthorized)
+	})
+
+	postIdempotent(t, srv, "key-3", "forged", `{}`)
+	if len(repo.records) != 0 {
+		t.Fatalf("stored %d records for a request with an invalid token", len(repo.records))
+	}
+}
+
+func TestIdempotencyServerErrorReleasesKey(t *testing.T) {
+	var calls atomic.Int32
+	srv := newIdempotencyServer(t, newMemoryIdempotencyRepo(), func(c echo.Context) error {
+		if calls.Add(1) == 1 {
+			return c.NoContent(http.StatusServiceUnavailable)
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	if first := postIdempotent(t, srv, "key-4", "", `{}`); first.status != http.StatusServiceUnavailable {
+		t.Fatalf("first request: %d", first.status)
+	}
+	if retry := postIdempotent(t, srv, "key-4", "", `{}`); retry.status != http.StatusNoContent || retry.replayed {
+		t.Fatalf("retry after 503: %d replayed=%v, want a fresh 204", retry.status, retry.replayed)
+	}
+}
+
+// A request that stalls past abandonedAfter loses its key. When it finally fails, its
+// release must leave the request that took the key over alone.
+func TestIdempotencyStaleReleaseKeepsTakeover(t *testing.T) {
+	repo := newMemoryIdempotencyRepo()
+	repo.abandonedAfter = 20 * time.Millisecond
+	stalled, unblock := make(chan struct{}), make(chan struct{})
+	var calls atomic.Int32
+	srv := newIdempotencyServer(t, repo, func(c echo.Context) error {
+		n := calls.Add(1)
+		if n == 1 {
+			close(stalled)
+			<-unblock
+			return c.NoContent(http.StatusInternalServerError)
+		}
+		return c.JSON(http.StatusCreated, map[string]any{"call": n})
+	})
+
+	first := make(chan idempotencyResult)
+	go func() { first <- postIdempotent(t, srv, "key-5", "", `{}`) }()
+	<-stalled
+	time.Sleep(2 * repo.abandonedAfter)
+
+	takeover := postIdempotent(t, srv, "key-5", "", `{}`)
+	if takeover.status != http.StatusCreated || takeover.replayed {
+		t.Fatalf("takeover: %d replayed=%v", takeover.status, takeover.replayed)
+	}
+	close(unblock)
+	if result := <-first; result.status != http.StatusInternalServerError {
+		t.Fatalf("stalled request: %d", result.status)
+	}
+
+	replay := postIdempotent(t, srv, "key-5", "", `{}`)
+	if !replay.replayed || replay.body != takeover.body {
+		t.Fatalf("after stale release got %q replayed=%v, want replay of %q", replay.body, replay.replayed, takeover.body)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("handler ran %d times, want 2", got)
+	}
+}
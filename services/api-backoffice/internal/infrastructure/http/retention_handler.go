@@ -0,0 +1,108 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type RetentionHTTPHandler struct {
+	svc domain.RetentionService
+}
+
+var _ domain.RetentionHTTPHandler = (*RetentionHTTPHandler)(nil)
+
+func NewRetentionHTTPHandler(svc domain.RetentionService) *RetentionHTTPHandler {
+	return &RetentionHTTPHandler{svc: svc}
+}
+
+// Report is the dry run: it lists artifacts due for purge without deleting anything.
+func (h *RetentionHTTPHandler) Report(c echo.Context) error {
+	report, err := h.svc.Report(c.Request().Context(), queryLimit(c))
+	if err != nil {
+		return respondRetentionError(c, err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func (h *RetentionHTTPHandler) Purge(c echo.Context) error {
+	var req struct {
+		Actor string `json:"actor"`
+		Limit int    `json:"limit"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid payload"))
+	}
+	report, err := h.svc.Purge(c.Request().Context(), req.Actor, req.Limit)
+	if err != nil {
+		return respondRetentionError(c, err)
+	}
+	return c.JSON(http.StatusOK, report)
+}
+
+func (h *RetentionHTTPHandler) ListTombstones(c echo.Context) error {
+	tombstones, err := h.svc.ListTombstones(c.Request().Context(), c.QueryParam("sessionId"), queryLimit(c))
+	if err != nil {
+		return respondRetentionError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": tombstones})
+}
+
+func (h *RetentionHTTPHandler) ListLegalHolds(c echo.Context) error {
+	activeOnly := c.QueryParam("active") != "false"
+	holds, err := h.svc.ListLegalHolds(c.Request().Context(), activeOnly)
+	if err != nil {
+		return respondRetentionError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": holds})
+}
+
+func (h *RetentionHTTPHandler) PlaceLegalHold(c echo.Context) error {
+	var payload domain.PlaceLegalHoldParams
+	if err := c.Bind(&payload); err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid payload"))
+	}
+	hold, err := h.svc.PlaceLegalHold(c.Request().Context(), payload)
+	if err != nil {
+		return respondRetentionError(c, err)
+	}
+	return c.JSON(http.StatusCreated, hold)
+}
+
+func (h *RetentionHTTPHandler) ReleaseLegalHold(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid legal hold id"))
+	}
+	var req struct {
+		Actor string `json:"actor"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid payload"))
+	}
+	hold, err := h.svc.ReleaseLegalHold(c.Request().Context(), domain.ReleaseLegalHoldParams{ID: id, Actor: req.Actor})
+	if err != nil {
+		return respondRetentionError(c, err)
+	}
+	return c.JSON(http.StatusOK, hold)
+}
+
+func queryLimit(c echo.Context) int {
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	return limit
+}
+
+func respondRetentionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrInvalidState):
+		return respondError(c, http.StatusBadRequest, err)
+	default:
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+}
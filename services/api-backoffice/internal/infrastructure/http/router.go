@@ -14,6 +14,19 @@ func RegisterRoutes(
 	authHandler *AuthHTTPHandler,
 	ekycHandler *EkycHTTPHandler,
 	portalHandler *PortalHTTPHandler,
+	webhookHandler *WebhookHTTPHandler,
+	retentionHandler *RetentionHTTPHandler,
+	dataSubjectHandler *DataSubjectHTTPHandler,
+	visitScheduleHandler *VisitScheduleHTTPHandler,
+	visitSyncHandler *VisitSyncHTTPHandler,
+	checklistHandler *ChecklistTemplateHTTPHandler,
+	visitRouteHandler *VisitRouteHTTPHandler,
+	visitPhotoHandler *VisitPhotoHTTPHandler,
+	visitReviewHandler *VisitReviewHTTPHandler,
+	calendarFeedHandler *CalendarFeedHTTPHandler,
+	batchManifestHandler *BatchManifestHTTPHandler,
+	disbursementExportHandler *DisbursementExportHTTPHandler,
+	batchReconciliationHandler *BatchReconciliationHTTPHandler,
 ) {
 	e.GET("/api/healthz", healthz)
 
@@ -31,11 +44,31 @@ func RegisterRoutes(
 	app.POST("/status", backofficeHandler.UpdateApplicationStatus)
 	app.POST("/visits", backofficeHandler.CreateVisit)
 	app.PATCH("/visits/:visitId", backofficeHandler.UpdateVisit)
+	app.GET("/visits/:visitId/photos", visitPhotoHandler.List)
+	app.POST("/visits/:visitId/photos", visitPhotoHandler.Upload)
+	app.GET("/visits/:visitId/reviews", visitReviewHandler.List)
+	app.POST("/visits/:visitId/verify", visitReviewHandler.Verify)
+	app.POST("/visits/:visitId/return", visitReviewHandler.Return)
 
 	e.GET("/api/visits", backofficeHandler.ListVisits)
+	e.GET("/api/visit-reviews", visitReviewHandler.Queue)
+	e.GET("/api/tksk/:tkskId/calendar", visitScheduleHandler.Calendar)
+	e.GET("/api/tksk/:tkskId/free-slots", visitScheduleHandler.SuggestSlots)
+	e.POST("/api/tksk/:tkskId/sync", visitSyncHandler.Sync)
+	e.GET("/api/tksk/:tkskId/route", visitRouteHandler.Plan)
+	e.POST("/api/tksk/:tkskId/route/apply", visitRouteHandler.Apply)
+
+	// Visit checklist templates
+	e.GET("/api/checklist-templates", checklistHandler.List)
+	e.POST("/api/checklist-templates", checklistHandler.Publish)
+	e.GET("/api/checklist-templates/:id", checklistHandler.Get)
+	e.POST("/api/checklist-templates/:id/retire", checklistHandler.Retire)
 
 	// Config & users
 	e.GET("/api/users", backofficeHandler.ListUsers)
+	e.POST("/api/users/:userId/calendar-feed", calendarFeedHandler.Issue)
+	e.DELETE("/api/users/:userId/calendar-feed", calendarFeedHandler.Revoke)
+	e.GET("/api/calendar/:feed", calendarFeedHandler.Feed)
 	e.GET("/api/config", backofficeHandler.GetConfig)
 	e.PUT("/api/config", backofficeHandler.UpdateConfig)
 
@@ -43,6 +76,14 @@ func RegisterRoutes(
 	e.GET("/api/batches", backofficeHandler.ListBatches)
 	e.POST("/api/batches", backofficeHandler.CreateBatch)
 	e.POST("/api/batches/:id/status", backofficeHandler.UpdateBatchStatus)
+	e.PUT("/api/batches/:id/items", batchManifestHandler.ReplaceItems)
+	e.GET("/api/batches/:id/manifest", batchManifestHandler.Get)
+	e.POST("/api/batches/:id/verify", batchManifestHandler.Verify)
+	e.POST("/api/batches/:id/export", disbursementExportHandler.Export)
+	e.GET("/api/batches/:id/exports", disbursementExportHandler.List)
+	e.POST("/api/batches/:id/reconciliations", batchReconciliationHandler.Import)
+	e.GET("/api/batches/:id/reconciliations", batchReconciliationHandler.List)
+	e.PUT("/api/beneficiaries/:userId/payout", disbursementExportHandler.SetPayout)
 
 	// Distributions
 	e.GET("/api/distributions", backofficeHandler.ListDistributions)
@@ -62,17 +103,53 @@ func RegisterRoutes(
 
 	// Overview
 	e.GET("/api/overview", backofficeHandler.Overview)
+	e.GET("/api/analytics/ekyc-funnel", backofficeHandler.EkycFunnel)
+
+	// Webhooks
+	e.GET("/api/webhooks/subscriptions", webhookHandler.ListSubscriptions)
+	e.POST("/api/webhooks/subscriptions", webhookHandler.CreateSubscription)
+	e.PATCH("/api/webhooks/subscriptions/:id", webhookHandler.UpdateSubscription)
+	e.DELETE("/api/webhooks/subscriptions/:id", webhookHandler.DeleteSubscription)
+	e.GET("/api/webhooks/deliveries", webhookHandler.ListDeliveries)
+	e.POST("/api/webhooks/deliveries/:id/redeliver", webhookHandler.Redeliver)
+
+	// Retention
+	e.GET("/api/retention/report", retentionHandler.Report)
+	e.POST("/api/retention/purge", retentionHandler.Purge)
+	e.GET("/api/retention/tombstones", retentionHandler.ListTombstones)
+	e.GET("/api/retention/legal-holds", retentionHandler.ListLegalHolds)
+	e.POST("/api/retention/legal-holds", retentionHandler.PlaceLegalHold)
+	e.DELETE("/api/retention/legal-holds/:id", retentionHandler.ReleaseLegalHold)
+
+	// Data subject requests
+	e.GET("/api/data-subjects/:userId/export", dataSubjectHandler.Export)
+	e.POST("/api/data-subjects/:userId/erasure-requests", dataSubjectHandler.RequestErasure)
+	e.GET("/api/erasure-requests", dataSubjectHandler.ListErasureRequests)
+	e.GET("/api/erasure-requests/:id", dataSubjectHandler.GetErasureRequest)
+	e.POST("/api/erasure-requests/:id/approve", dataSubjectHandler.ApproveErasure)
+	e.POST("/api/erasure-requests/:id/reject", dataSubjectHandler.RejectErasure)
+	e.POST("/api/erasure-requests/:id/media/retry", dataSubjectHandler.RetryErasureMedia)
 
 	ekyc := e.Group("/api/ekyc")
 	ekyc.POST("/sessions", ekycHandler.CreateSession)
 	ekyc.GET("/sessions", ekycHandler.ListSessions)
 	ekyc.GET("/sessions/:id", ekycHandler.GetSession)
+	ekyc.GET("/sessions/:id/events", ekycHandler.StreamSessionEvents)
 	ekyc.PATCH("/sessions/:id/artifacts", ekycHandler.UpdateArtifacts)
 	ekyc.POST("/sessions/:id/face-checks", ekycHandler.RecordFaceChecks)
 	ekyc.POST("/sessions/:id/liveness", ekycHandler.RecordLiveness)
+	ekyc.POST("/sessions/:id/ocr", ekycHandler.RecordOcrResult)
+	ekyc.GET("/sessions/:id/ocr", ekycHandler.GetOcrResult)
 	ekyc.POST("/sessions/:id/applicant", ekycHandler.AssignApplicant)
 	ekyc.POST("/sessions/:id/finalize", ekycHandler.Finalize)
 	ekyc.PATCH("/sessions/:id/decision", ekycHandler.OverrideDecision)
+	ekyc.GET("/override-reasons", ekycHandler.ListOverrideReasons)
+	ekyc.POST("/sessions/:id/face-match/rerun", ekycHandler.RerunFaceMatch)
+	ekyc.GET("/review-queue", ekycHandler.ListReviewQueue)
+	ekyc.GET("/review-queue/:id", ekycHandler.GetReviewCase)
+	ekyc.POST("/review-queue/:id/claim", ekycHandler.ClaimReview)
+	ekyc.DELETE("/review-queue/:id/claim", ekycHandler.ReleaseReview)
+	ekyc.POST("/review-queue/:id/verdict", ekycHandler.SubmitReviewVerdict)
 
 	portal := e.Group("/api/portal")
 	portal.GET("/surveys/:id", portalHandler.GetSurvey)
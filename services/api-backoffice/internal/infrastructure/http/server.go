@@ -14,13 +14,32 @@ func NewServer(
 	authHandler *AuthHTTPHandler,
 	ekycHandler *EkycHTTPHandler,
 	portalHandler *PortalHTTPHandler,
+	webhookHandler *WebhookHTTPHandler,
+	retentionHandler *RetentionHTTPHandler,
+	dataSubjectHandler *DataSubjectHTTPHandler,
+	visitScheduleHandler *VisitScheduleHTTPHandler,
+	visitSyncHandler *VisitSyncHTTPHandler,
+	checklistHandler *ChecklistTemplateHTTPHandler,
+	visitRouteHandler *VisitRouteHTTPHandler,
+	visitPhotoHandler *VisitPhotoHTTPHandler,
+	visitReviewHandler *VisitReviewHTTPHandler,
+	calendarFeedHandler *CalendarFeedHTTPHandler,
+	batchManifestHandler *BatchManifestHTTPHandler,
+	disbursementExportHandler *DisbursementExportHTTPHandler,
+	batchReconciliationHandler *BatchReconciliationHTTPHandler,
+	idempotency *IdempotencyMiddleware,
 ) *echo.Echo {
 	e := echo.New()
 	e.HideBanner = true
 	e.Logger.SetLevel(gommonLog.INFO)
 
 	configureMiddleware(e)
-	RegisterRoutes(e, appHandler, backofficeHandler, authHandler, ekycHandler, portalHandler)
+	if idempotency != nil {
+		e.Use(idempotency.Handler)
+	}
+	RegisterRoutes(e, appHandler, backofficeHandler, authHandler, ekycHandler, portalHandler, webhookHandler, retentionHandler, dataSubjectHandler,
+		visitScheduleHandler, visitSyncHandler, checklistHandler, visitRouteHandler, visitPhotoHandler, visitReviewHandler, calendarFeedHandler,
+		batchManifestHandler, disbursementExportHandler, batchReconciliationHandler)
 
 	return e
 }
@@ -41,6 +60,11 @@ func configureMiddleware(e *echo.Echo) {
 			echo.HeaderContentType,
 			echo.HeaderAccept,
 			echo.HeaderAuthorization,
+			HeaderIdempotencyKey,
+			"Last-Event-ID",
+		},
+		ExposeHeaders: []string{
+			HeaderIdempotentReplay,
 		},
 		AllowCredentials: true,
 		MaxAge:           3600,
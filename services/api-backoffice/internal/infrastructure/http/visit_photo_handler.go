@@ -0,0 +1,83 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type VisitPhotoHTTPHandler struct {
+	svc domain.VisitPhotoService
+}
+
+var _ domain.VisitPhotoHTTPHandler = (*VisitPhotoHTTPHandler)(nil)
+
+func NewVisitPhotoHTTPHandler(svc domain.VisitPhotoService) *VisitPhotoHTTPHandler {
+	return &VisitPhotoHTTPHandler{svc: svc}
+}
+
+// Upload takes a multipart form with the JPEG in "file" and the uploader in "actor".
+func (h *VisitPhotoHTTPHandler) Upload(c echo.Context) error {
+	actor := c.FormValue("actor")
+	if actor == "" {
+		return respondError(c, http.StatusBadRequest, errors.New("actor required"))
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("file required"))
+	}
+	if header.Size > domain.MaxVisitPhotoBytes {
+		return respondError(c, http.StatusRequestEntityTooLarge, fmt.Errorf("photo exceeds %d bytes", domain.MaxVisitPhotoBytes))
+	}
+	file, err := header.Open()
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, domain.MaxVisitPhotoBytes+1))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	photo, err := h.svc.Upload(c.Request().Context(), domain.UploadVisitPhotoParams{
+		ApplicationID: c.Param("id"),
+		VisitID:       c.Param("visitId"),
+		Actor:         actor,
+		Filename:      header.Filename,
+		Content:       content,
+	})
+	if err != nil {
+		return respondVisitPhotoError(c, err)
+	}
+	return c.JSON(http.StatusCreated, photo)
+}
+
+func (h *VisitPhotoHTTPHandler) List(c echo.Context) error {
+	photos, err := h.svc.List(c.Request().Context(), c.Param("id"), c.Param("visitId"))
+	if err != nil {
+		return respondVisitPhotoError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": photos})
+}
+
+// respondVisitPhotoError answers a re-used photo with the photo it matches.
+func respondVisitPhotoError(c echo.Context, err error) error {
+	var reused *domain.PhotoReuseError
+	switch {
+	case errors.As(err, &reused):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error": err.Error(),
+			"match": reused.Match,
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		return respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrInvalidState):
+		return respondError(c, http.StatusBadRequest, err)
+	default:
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type VisitReviewHTTPHandler struct {
+	svc  domain.VisitReviewService
+	auth domain.AuthService
+}
+
+var _ domain.VisitReviewHTTPHandler = (*VisitReviewHTTPHandler)(nil)
+
+func NewVisitReviewHTTPHandler(svc domain.VisitReviewService, auth domain.AuthService) *VisitReviewHTTPHandler {
+	return &VisitReviewHTTPHandler{svc: svc, auth: auth}
+}
+
+// Queue lists the submitted visits in the supervisor's region scope, optionally narrowed
+// with ?region=.
+func (h *VisitReviewHTTPHandler) Queue(c echo.Context) error {
+	sess, unauthorized := h.supervisor(c)
+	if unauthorized != nil {
+		return c.JSON(http.StatusUnauthorized, unauthorized)
+	}
+	if !roleIn(sess.Role, domain.VisitReviewRoles) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+	}
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+	items, err := h.svc.Queue(c.Request().Context(), domain.ListVisitReviewQueueParams{
+		Region:      c.QueryParam("region"),
+		RegionScope: sess.RegionScope,
+		Limit:       limit,
+	})
+	if err != nil {
+		return respondVisitReviewError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": items})
+}
+
+func (h *VisitReviewHTTPHandler) Verify(c echo.Context) error {
+	return h.review(c, domain.VisitReviewVerified)
+}
+
+func (h *VisitReviewHTTPHandler) Return(c echo.Context) error {
+	return h.review(c, domain.VisitReviewReturned)
+}
+
+func (h *VisitReviewHTTPHandler) List(c echo.Context) error {
+	reviews, err := h.svc.List(c.Request().Context(), c.Param("id"), c.Param("visitId"))
+	if err != nil {
+		return respondVisitReviewError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": reviews})
+}
+
+func (h *VisitReviewHTTPHandler) review(c echo.Context, decision string) error {
+	sess, unauthorized := h.supervisor(c)
+	if unauthorized != nil {
+		return c.JSON(http.StatusUnauthorized, unauthorized)
+	}
+	var payload struct {
+		Comment string `json:"comment"`
+		Version int    `json:"version"`
+	}
+	if err := c.Bind(&payload); err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid payload"))
+	}
+	review, err := h.svc.Review(c.Request().Context(), domain.ReviewVisitParams{
+		ApplicationID: c.Param("id"),
+		VisitID:       c.Param("visitId"),
+		Decision:      decision,
+		Comment:       payload.Comment,
+		Version:       payload.Version,
+		Reviewer:      sess.UserID,
+		ReviewerRole:  sess.Role,
+		RegionScope:   sess.RegionScope,
+	})
+	if err != nil {
+		return respondVisitReviewError(c, err)
+	}
+	return c.JSON(http.StatusOK, review)
+}
+
+// supervisor resolves the bearer token of a visit review request.
+func (h *VisitReviewHTTPHandler) supervisor(c echo.Context) (*domain.Session, map[string]string) {
+	token := parseBearer(c.Request().Header.Get("Authorization"))
+	if token == "" {
+		return nil, map[string]string{"error": "missing token"}
+	}
+	sess, ok := h.auth.Validate(token)
+	if !ok {
+		return nil, map[string]string{"error": "invalid token"}
+	}
+	return sess, nil
+}
+
+func respondVisitReviewError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrForbidden):
+		return respondError(c, http.StatusForbidden, err)
+	case errors.Is(err, domain.ErrInvalidState):
+		return respondError(c, http.StatusBadRequest, err)
+	default:
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+}
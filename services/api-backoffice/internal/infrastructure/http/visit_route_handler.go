@@ -0,0 +1,65 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type VisitRouteHTTPHandler struct {
+	svc domain.VisitRouteService
+}
+
+var _ domain.VisitRouteHTTPHandler = (*VisitRouteHTTPHandler)(nil)
+
+func NewVisitRouteHTTPHandler(svc domain.VisitRouteService) *VisitRouteHTTPHandler {
+	return &VisitRouteHTTPHandler{svc: svc}
+}
+
+func (h *VisitRouteHTTPHandler) Plan(c echo.Context) error {
+	params := domain.PlanVisitRouteParams{
+		TkskID: c.Param("tkskId"),
+		Date:   c.QueryParam("date"),
+	}
+	lat, lng := strings.TrimSpace(c.QueryParam("startLat")), strings.TrimSpace(c.QueryParam("startLng"))
+	if lat != "" || lng != "" {
+		latV, errLat := strconv.ParseFloat(lat, 64)
+		lngV, errLng := strconv.ParseFloat(lng, 64)
+		if errLat != nil || errLng != nil {
+			return respondError(c, http.StatusBadRequest, errors.New("invalid startLat/startLng"))
+		}
+		params.Start = &domain.VisitGeotag{Lat: latV, Lng: lngV}
+	}
+	route, err := h.svc.Plan(c.Request().Context(), params)
+	if err != nil {
+		return respondVisitScheduleError(c, err)
+	}
+	return c.JSON(http.StatusOK, route)
+}
+
+func (h *VisitRouteHTTPHandler) Apply(c echo.Context) error {
+	var req struct {
+		Actor string                   `json:"actor"`
+		Stops []domain.VisitReschedule `json:"stops"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid payload"))
+	}
+	if req.Actor == "" {
+		return respondError(c, http.StatusBadRequest, errors.New("actor required"))
+	}
+	visits, err := h.svc.Apply(c.Request().Context(), domain.ApplyVisitRouteParams{
+		TkskID: c.Param("tkskId"),
+		Actor:  req.Actor,
+		Stops:  req.Stops,
+	})
+	if err != nil {
+		return respondVisitScheduleError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": visits})
+}
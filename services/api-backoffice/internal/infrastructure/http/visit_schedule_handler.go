@@ -0,0 +1,87 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type VisitScheduleHTTPHandler struct {
+	svc domain.VisitScheduleService
+}
+
+var _ domain.VisitScheduleHTTPHandler = (*VisitScheduleHTTPHandler)(nil)
+
+func NewVisitScheduleHTTPHandler(svc domain.VisitScheduleService) *VisitScheduleHTTPHandler {
+	return &VisitScheduleHTTPHandler{svc: svc}
+}
+
+func (h *VisitScheduleHTTPHandler) SuggestSlots(c echo.Context) error {
+	params := domain.SuggestVisitSlotsParams{TkskID: c.Param("tkskId")}
+	if after := strings.TrimSpace(c.QueryParam("after")); after != "" {
+		t, err := time.Parse(time.RFC3339, after)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, errors.New("invalid after"))
+		}
+		params.After = t
+	}
+	if count := c.QueryParam("count"); count != "" {
+		if v, err := strconv.Atoi(count); err == nil {
+			params.Count = v
+		}
+	}
+	slots, err := h.svc.SuggestSlots(c.Request().Context(), params)
+	if err != nil {
+		return respondVisitScheduleError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": slots})
+}
+
+func (h *VisitScheduleHTTPHandler) Calendar(c echo.Context) error {
+	params := domain.TkskCalendarParams{TkskID: c.Param("tkskId")}
+	if from := strings.TrimSpace(c.QueryParam("from")); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, errors.New("invalid from"))
+		}
+		params.From = t
+	}
+	if to := strings.TrimSpace(c.QueryParam("to")); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, errors.New("invalid to"))
+		}
+		params.To = t
+	}
+	calendar, err := h.svc.Calendar(c.Request().Context(), params)
+	if err != nil {
+		return respondVisitScheduleError(c, err)
+	}
+	return c.JSON(http.StatusOK, calendar)
+}
+
+// respondVisitScheduleError answers a slot conflict with the clashing visits and the
+// next free slots, so the caller can offer them without another request.
+func respondVisitScheduleError(c echo.Context, err error) error {
+	var conflict *domain.ScheduleConflictError
+	switch {
+	case errors.As(err, &conflict):
+		return c.JSON(http.StatusConflict, map[string]any{
+			"error":       err.Error(),
+			"conflicts":   conflict.Conflicts,
+			"suggestions": conflict.Suggestions,
+		})
+	case errors.Is(err, domain.ErrNotFound):
+		return respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrInvalidState):
+		return respondError(c, http.StatusBadRequest, err)
+	default:
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type VisitSyncHTTPHandler struct {
+	svc domain.VisitSyncService
+}
+
+var _ domain.VisitSyncHTTPHandler = (*VisitSyncHTTPHandler)(nil)
+
+func NewVisitSyncHTTPHandler(svc domain.VisitSyncService) *VisitSyncHTTPHandler {
+	return &VisitSyncHTTPHandler{svc: svc}
+}
+
+// Sync answers 200 even when some mutations conflict or are rejected. Their outcome is
+// in the per-mutation results.
+func (h *VisitSyncHTTPHandler) Sync(c echo.Context) error {
+	var req struct {
+		Actor     string                 `json:"actor"`
+		DeviceID  string                 `json:"deviceId"`
+		SyncToken string                 `json:"syncToken"`
+		Mutations []domain.VisitMutation `json:"mutations"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, err)
+	}
+	if req.Actor == "" {
+		return respondError(c, http.StatusBadRequest, errors.New("actor required"))
+	}
+	result, err := h.svc.Sync(c.Request().Context(), domain.VisitSyncParams{
+		TkskID:    c.Param("tkskId"),
+		DeviceID:  req.DeviceID,
+		Actor:     req.Actor,
+		SyncToken: req.SyncToken,
+		Mutations: req.Mutations,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			return respondError(c, http.StatusNotFound, err)
+		case errors.Is(err, domain.ErrInvalidState):
+			return respondError(c, http.StatusBadRequest, err)
+		case errors.Is(err, domain.ErrForbidden):
+			return respondError(c, http.StatusForbidden, err)
+		default:
+			return respondError(c, http.StatusInternalServerError, err)
+		}
+	}
+	return c.JSON(http.StatusOK, result)
+}
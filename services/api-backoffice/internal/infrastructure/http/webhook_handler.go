@@ -0,0 +1,99 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WebhookHTTPHandler struct {
+	svc domain.WebhookService
+}
+
+func NewWebhookHTTPHandler(svc domain.WebhookService) *WebhookHTTPHandler {
+	return &WebhookHTTPHandler{svc: svc}
+}
+
+func (h *WebhookHTTPHandler) ListSubscriptions(c echo.Context) error {
+	subs, err := h.svc.ListSubscriptions(c.Request().Context())
+	if err != nil {
+		return respondWebhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": subs})
+}
+
+func (h *WebhookHTTPHandler) CreateSubscription(c echo.Context) error {
+	var payload domain.CreateWebhookSubscriptionParams
+	if err := c.Bind(&payload); err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid payload"))
+	}
+	sub, err := h.svc.CreateSubscription(c.Request().Context(), payload)
+	if err != nil {
+		return respondWebhookError(c, err)
+	}
+	return c.JSON(http.StatusCreated, sub)
+}
+
+func (h *WebhookHTTPHandler) UpdateSubscription(c echo.Context) error {
+	var payload domain.UpdateWebhookSubscriptionParams
+	if err := c.Bind(&payload); err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid payload"))
+	}
+	payload.ID = c.Param("id")
+	sub, err := h.svc.UpdateSubscription(c.Request().Context(), payload)
+	if err != nil {
+		return respondWebhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, sub)
+}
+
+func (h *WebhookHTTPHandler) DeleteSubscription(c echo.Context) error {
+	if err := h.svc.DeleteSubscription(c.Request().Context(), c.Param("id")); err != nil {
+		return respondWebhookError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *WebhookHTTPHandler) ListDeliveries(c echo.Context) error {
+	params := domain.ListWebhookDeliveriesParams{
+		SubscriptionID: c.QueryParam("subscriptionId"),
+		Status:         c.QueryParam("status"),
+	}
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if v, err := strconv.Atoi(limitStr); err == nil {
+			params.Limit = v
+		}
+	}
+	deliveries, err := h.svc.ListDeliveries(c.Request().Context(), params)
+	if err != nil {
+		return respondWebhookError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]any{"data": deliveries})
+}
+
+func (h *WebhookHTTPHandler) Redeliver(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, errors.New("invalid delivery id"))
+	}
+	delivery, err := h.svc.Redeliver(c.Request().Context(), id)
+	if err != nil {
+		return respondWebhookError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, delivery)
+}
+
+func respondWebhookError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return respondError(c, http.StatusNotFound, err)
+	case errors.Is(err, domain.ErrInvalidState):
+		return respondError(c, http.StatusBadRequest, err)
+	default:
+		return respondError(c, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,165 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	neturl "net/url"
+	"path"
+	"strings"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+const maxDownloadBytes = 20 << 20
+
+// Client fetches artifacts from the media storage service at endpoint. Artifact URLs are
+// absolute and come from clients, so only those under the endpoint's /media/ path are
+// downloaded or deleted.
+type Client struct {
+	http     *http.Client
+	endpoint string
+}
+
+var _ domain.MediaClient = (*Client)(nil)
+
+func NewClient(httpClient *http.Client, endpoint string) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{http: httpClient, endpoint: strings.TrimRight(endpoint, "/")}
+}
+
+// Download fetches an artifact. URLs outside media storage fail with
+// domain.ErrMediaOutsideStorage without a request being made.
+func (c *Client) Download(ctx context.Context, url string) (*domain.BinaryImage, error) {
+	if !c.stores(url) {
+		return nil, domain.ErrMediaOutsideStorage
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download media: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: media %s", domain.ErrNotFound, url)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("download media: status %d", resp.StatusCode)
+	}
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("download media: %w", err)
+	}
+	if len(content) > maxDownloadBytes {
+		return nil, fmt.Errorf("download media: %s exceeds %d bytes", url, maxDownloadBytes)
+	}
+	return &domain.BinaryImage{Content: content, MimeType: resp.Header.Get("Content-Type")}, nil
+}
+
+// Delete removes an artifact from media storage. Media that is already gone counts as
+// deleted, so a purge interrupted after the delete can be retried. Only URLs under the
+// endpoint's /media/ path are sent a DELETE. Artifact URLs come from clients, so any
+// other URL is left alone and counts as deleted, and the caller only drops its reference.
+func (c *Client) Delete(ctx context.Context, url string) error {
+	if !c.stores(url) {
+		shown := "(unparseable url)"
+		if parsed, err := neturl.Parse(url); err == nil {
+			shown = parsed.Redacted()
+		}
+		log.Printf("api-backoffice: media %s is not in media storage, dropping the reference only", shown)
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("delete media: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("delete media: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// stores reports whether raw names a file in the media storage at c.endpoint.
+func (c *Client) stores(raw string) bool {
+	if c.endpoint == "" {
+		return false
+	}
+	base, err := neturl.Parse(c.endpoint)
+	if err != nil {
+		return false
+	}
+	target, err := neturl.Parse(raw)
+	if err != nil || target.User != nil || target.RawPath != "" {
+		return false
+	}
+	if !strings.EqualFold(target.Scheme, base.Scheme) || !strings.EqualFold(target.Host, base.Host) {
+		return false
+	}
+	prefix := strings.TrimRight(base.Path, "/") + "/media/"
+	return len(target.Path) > len(prefix) && strings.HasPrefix(target.Path, prefix) && path.Clean(target.Path) == target.Path
+}
+
+// Upload posts content to the media storage service as a multipart "file" field.
+func (c *Client) Upload(ctx context.Context, filename, mimeType string, content []byte) (string, error) {
+	if c.endpoint == "" {
+		return "", fmt.Errorf("upload media: no media storage endpoint configured")
+	}
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, filename))
+	header.Set("Content-Type", mimeType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(content); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+"/media", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload media: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("upload media: status %d", resp.StatusCode)
+	}
+	var record struct {
+		URL string `json:"url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&record); err != nil {
+		return "", fmt.Errorf("upload media: decode response: %w", err)
+	}
+	if record.URL == "" {
+		return "", fmt.Errorf("upload media: response without url")
+	}
+	return record.URL, nil
+}
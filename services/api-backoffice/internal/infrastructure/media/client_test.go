@@ -0,0 +1,140 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// mediaStore records requests and answers them with status.
+type mediaStore struct {
+	*httptest.Server
+	mu      sync.Mutex
+	deleted []string
+	status  int
+}
+
+func newMediaStore(t *testing.T, status int) *mediaStore {
+	t.Helper()
+	store := &mediaStore{status: status}
+	store.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		store.mu.Lock()
+		store.deleted = append(store.deleted, r.Method+" "+r.URL.Path)
+		store.mu.Unlock()
+		w.WriteHeader(store.status)
+	}))
+	t.Cleanup(store.Close)
+	return store
+}
+
+func TestDeleteOnlyReachesMediaStorage(t *testing.T) {
+	store := newMediaStore(t, http.StatusNoContent)
+	client := NewClient(store.Client(), store.URL+"/storage/")
+	host := strings.TrimPrefix(store.URL, "http://")
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"artifact", store.URL + "/storage/media/abc", "DELETE /storage/media/abc"},
+		{"other host", "http://media.attacker.test/storage/media/abc", ""},
+		{"other scheme", "https://" + host + "/storage/media/abc", ""},
+		{"outside the media path", store.URL + "/storage/admin/abc", ""},
+		{"media collection itself", store.URL + "/storage/media/", ""},
+		{"without the endpoint path", store.URL + "/media/abc", ""},
+		{"path traversal", store.URL + "/storage/media/../admin", ""},
+		{"encoded traversal", store.URL + "/storage/media/..%2fadmin", ""},
+		{"credentials", "http://user:pass@" + host + "/storage/media/abc", ""},
+		{"not a url", "::", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.mu.Lock()
+			store.deleted = nil
+			store.mu.Unlock()
+			if err := client.Delete(context.Background(), tt.url); err != nil {
+				t.Fatalf("Delete(%q) = %v", tt.url, err)
+			}
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			switch {
+			case tt.want == "" && len(store.deleted) != 0:
+				t.Errorf("Delete(%q) sent %v, want no request", tt.url, store.deleted)
+			case tt.want != "" && (len(store.deleted) != 1 || store.deleted[0] != tt.want):
+				t.Errorf("Delete(%q) sent %v, want %s", tt.url, store.deleted, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownloadOnlyReachesMediaStorage(t *testing.T) {
+	store := newMediaStore(t, http.StatusOK)
+	client := NewClient(store.Client(), store.URL+"/storage")
+	host := strings.TrimPrefix(store.URL, "http://")
+
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{"artifact", store.URL + "/storage/media/abc", "GET /storage/media/abc"},
+		{"internal address", "http://169.254.169.254/latest/meta-data/", ""},
+		{"other host", "http://media.attacker.test/storage/media/abc", ""},
+		{"outside the media path", store.URL + "/storage/admin/abc", ""},
+		{"path traversal", store.URL + "/storage/media/../admin", ""},
+		{"encoded traversal", store.URL + "/storage/media/..%2fadmin", ""},
+		{"credentials", "http://user:pass@" + host + "/storage/media/abc", ""},
+		{"file scheme", "file:///etc/passwd", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.mu.Lock()
+			store.deleted = nil
+			store.mu.Unlock()
+			_, err := client.Download(context.Background(), tt.url)
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			if tt.want == "" {
+				if !errors.Is(err, domain.ErrMediaOutsideStorage) || len(store.deleted) != 0 {
+					t.Errorf("Download(%q) = %v after %v, want ErrMediaOutsideStorage and no request", tt.url, err, store.deleted)
+				}
+				return
+			}
+			if err != nil || len(store.deleted) != 1 || store.deleted[0] != tt.want {
+				t.Errorf("Download(%q) = %v after %v, want %s", tt.url, err, store.deleted, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteWithoutEndpointSendsNothing(t *testing.T) {
+	store := newMediaStore(t, http.StatusNoContent)
+	if err := NewClient(store.Client(), "").Delete(context.Background(), store.URL+"/media/abc"); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.deleted) != 0 {
+		t.Errorf("sent %v without a configured endpoint", store.deleted)
+	}
+}
+
+func TestDeleteStatuses(t *testing.T) {
+	for status, wantErr := range map[int]bool{
+		http.StatusNoContent:           false,
+		http.StatusNotFound:            false,
+		http.StatusInternalServerError: true,
+		http.StatusForbidden:           true,
+	} {
+		store := newMediaStore(t, status)
+		err := NewClient(store.Client(), store.URL).Delete(context.Background(), store.URL+"/media/abc")
+		if (err != nil) != wantErr {
+			t.Errorf("status %d: Delete = %v, want error %v", status, err, wantErr)
+		}
+	}
+}
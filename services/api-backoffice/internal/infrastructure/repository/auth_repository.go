@@ -7,59 +7,58 @@ import (
 	"time"
 
 	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/pii"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type authRepository struct {
-	db *pgxpool.Pool
+	db   *pgxpool.Pool
+	keys *pii.Keyring
 }
 
-func NewAuthRepository(db *pgxpool.Pool) domain.AuthRepository {
-	return &authRepository{db: db}
+func NewAuthRepository(db *pgxpool.Pool, keys *pii.Keyring) domain.AuthRepository {
+	return &authRepository{db: db, keys: keys}
 }
 
-func (repo *authRepository) FindAdminByNIK(ctx context.Context, nik string) (*domain.UserCredential, error) {
-	query := `SELECT id, role, nik, name, dob, phone, email, pin_hash,
+const userCredentialColumns = `id, role, COALESCE(nik_enc, nik), name, dob, COALESCE(phone_enc, phone), COALESCE(email_enc, email), pin_hash,
         region_prov, region_kab, region_kec, region_kel,
-        region_scope, metadata
+        region_scope, metadata`
+
+func (repo *authRepository) FindAdminByNIK(ctx context.Context, nik string) (*domain.UserCredential, error) {
+	query := `SELECT ` + userCredentialColumns + `
         FROM users
-        WHERE nik = $1 AND role <> 'BENEFICIARY'
+        WHERE nik_bidx = ANY($1) AND role <> 'BENEFICIARY'
         LIMIT 1`
 
-	row := repo.db.QueryRow(ctx, query, nik)
-	return scanUserCredential(row)
+	row := repo.db.QueryRow(ctx, query, repo.keys.BlindIndexes(pii.FieldNIK, nik))
+	return scanUserCredential(repo.keys, row)
 }
 
 func (repo *authRepository) FindBeneficiaryByPhone(ctx context.Context, phone string) (*domain.UserCredential, error) {
-	phone = normalizePhone(phone)
-	query := `SELECT id, role, nik, name, dob, phone, email, pin_hash,
-        region_prov, region_kab, region_kec, region_kel,
-        region_scope, metadata
+	query := `SELECT ` + userCredentialColumns + `
         FROM users
-        WHERE phone = $1 AND role = 'BENEFICIARY'
+        WHERE phone_bidx = ANY($1) AND role = 'BENEFICIARY'
         LIMIT 1`
 
-	row := repo.db.QueryRow(ctx, query, phone)
-	return scanUserCredential(row)
+	row := repo.db.QueryRow(ctx, query, repo.keys.BlindIndexes(pii.FieldPhone, phone))
+	return scanUserCredential(repo.keys, row)
 }
 
 func (repo *authRepository) FindEligibleBeneficiary(ctx context.Context, name, nik string) (*domain.UserCredential, error) {
-	query := `SELECT id, role, nik, name, dob, phone, email, pin_hash,
-        region_prov, region_kab, region_kec, region_kel,
-        region_scope, metadata
+	query := `SELECT ` + userCredentialColumns + `
         FROM users
         WHERE role = 'BENEFICIARY'
-          AND nik = $1
+          AND nik_bidx = ANY($1)
           AND LOWER(name) = LOWER($2)
-          AND (phone IS NULL OR phone = '')
+          AND phone_bidx IS NULL
           AND dob IS NULL
           AND (pin_hash IS NULL OR pin_hash = '')
         LIMIT 1`
 
-	row := repo.db.QueryRow(ctx, query, nik, name)
-	return scanUserCredential(row)
+	row := repo.db.QueryRow(ctx, query, repo.keys.BlindIndexes(pii.FieldNIK, nik), name)
+	return scanUserCredential(repo.keys, row)
 }
 
 func (repo *authRepository) UpdateLastLogin(ctx context.Context, userID string) error {
@@ -67,7 +66,7 @@ func (repo *authRepository) UpdateLastLogin(ctx context.Context, userID string)
 	return err
 }
 
-func scanUserCredential(row pgx.Row) (*domain.UserCredential, error) {
+func scanUserCredential(keys *pii.Keyring, row pgx.Row) (*domain.UserCredential, error) {
 	var (
 		u             domain.User
 		dob           *time.Time
@@ -94,6 +93,9 @@ func scanUserCredential(row pgx.Row) (*domain.UserCredential, error) {
 	u.DOB = dob
 	u.Phone = phone
 	u.Email = email
+	if err := openUserPII(keys, &u); err != nil {
+		return nil, err
+	}
 	u.RegionScope = regionScope
 	u.Region.Prov = derefString(regionProv)
 	u.Region.Kab = derefString(regionKab)
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/pii"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -20,11 +21,12 @@ import (
 )
 
 type backofficeRepository struct {
-	db *pgxpool.Pool
+	db   *pgxpool.Pool
+	keys *pii.Keyring
 }
 
-func NewBackofficeRepository(db *pgxpool.Pool) domain.BackofficeRepository {
-	return &backofficeRepository{db: db}
+func NewBackofficeRepository(db *pgxpool.Pool, keys *pii.Keyring) domain.BackofficeRepository {
+	return &backofficeRepository{db: db, keys: keys}
 }
 
 // EnsureApplicationFromSession is exposed to satisfy the EkycRepository interface and allow service-level calls.
@@ -274,6 +276,12 @@ func (repo *backofficeRepository) GetApplication(ctx context.Context, id string)
 		app.Survey = survey
 	}
 
+	ocr, err := repo.GetOcrResult(ctx, id)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	app.Ocr = ocr
+
 	return &app, nil
 }
 
@@ -303,7 +311,7 @@ func (repo *backofficeRepository) GetApplicationsByIDs(ctx context.Context, ids
 
 func (repo *backofficeRepository) ListUsers(ctx context.Context) ([]domain.User, error) {
 	rows, err := repo.db.Query(ctx, `
-        SELECT id, role, nik, name, dob, phone, email,
+        SELECT id, role, COALESCE(nik_enc, nik), name, dob, COALESCE(phone_enc, phone), COALESCE(email_enc, email),
                region_prov, region_kab, region_kec, region_kel,
                region_scope, metadata, created_at, updated_at
         FROM users
@@ -335,6 +343,9 @@ func (repo *backofficeRepository) ListUsers(ctx context.Context) ([]domain.User,
 		user.DOB = dob
 		user.Phone = phone
 		user.Email = email
+		if err := openUserPII(repo.keys, &user); err != nil {
+			return nil, err
+		}
 		user.RegionScope = regionScope
 		user.Region.Prov = derefString(regionProv)
 		user.Region.Kab = derefString(regionKab)
@@ -355,7 +366,7 @@ func (repo *backofficeRepository) ListBeneficiaries(ctx context.Context, limit i
 		limit = 200
 	}
 	rows, err := repo.db.Query(ctx, `
-        SELECT u.id, u.role, u.nik, u.name, u.dob, u.phone, u.email,
+        SELECT u.id, u.role, COALESCE(u.nik_enc, u.nik), u.name, u.dob, COALESCE(u.phone_enc, u.phone), COALESCE(u.email_enc, u.email),
                u.region_prov, u.region_kab, u.region_kec, u.region_kel,
                u.region_scope, u.metadata, u.created_at, u.updated_at,
                b.bansos_utama, b.ranking_bansos_utama,
@@ -400,6 +411,9 @@ func (repo *backofficeRepository) ListBeneficiaries(ctx context.Context, limit i
 		bene.User.DOB = dob
 		bene.User.Phone = phone
 		bene.User.Email = email
+		if err := openUserPII(repo.keys, &bene.User); err != nil {
+			return nil, err
+		}
 		bene.User.RegionScope = regionScope
 		if len(metadataJSON) > 0 {
 			if err := json.Unmarshal(metadataJSON, &bene.User.Metadata); err != nil {
@@ -424,14 +438,14 @@ func (repo *backofficeRepository) ListBeneficiaries(ctx context.Context, limit i
 
 func (repo *backofficeRepository) GetConfig(ctx context.Context) (*domain.SystemConfig, error) {
 	row := repo.db.QueryRow(ctx, `
-        SELECT period, thresholds, features, updated_at
+        SELECT period, thresholds, features, retention, scheduling, disbursement, updated_at
         FROM system_config
         WHERE id = 1`)
 
 	var cfg domain.SystemConfig
-	var thresholds, features []byte
+	var thresholds, features, retention, scheduling, disbursement []byte
 
-	if err := row.Scan(&cfg.Period, &thresholds, &features, &cfg.UpdatedAt); err != nil {
+	if err := row.Scan(&cfg.Period, &thresholds, &features, &retention, &scheduling, &disbursement, &cfg.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrNotFound
 		}
@@ -439,22 +453,41 @@ func (repo *backofficeRepository) GetConfig(ctx context.Context) (*domain.System
 	}
 	cfg.Thresholds = decodeJSON(thresholds)
 	cfg.Features = decodeJSON(features)
+	cfg.Retention = decodeJSON(retention)
+	cfg.Scheduling = decodeJSON(scheduling)
+	cfg.Disbursement = decodeJSON(disbursement)
 	return &cfg, nil
 }
 
 func (repo *backofficeRepository) UpsertConfig(ctx context.Context, cfg domain.SystemConfig) (*domain.SystemConfig, error) {
 	thresholdsBytes, _ := json.Marshal(cfg.Thresholds)
 	featuresBytes, _ := json.Marshal(cfg.Features)
+	// Clients that predate retention, scheduling or disbursement omit them; keep the
+	// stored values then.
+	var retentionBytes, schedulingBytes, disbursementBytes []byte
+	if cfg.Retention != nil {
+		retentionBytes, _ = json.Marshal(cfg.Retention)
+	}
+	if cfg.Scheduling != nil {
+		schedulingBytes, _ = json.Marshal(cfg.Scheduling)
+	}
+	if cfg.Disbursement != nil {
+		disbursementBytes, _ = json.Marshal(cfg.Disbursement)
+	}
 
 	if _, err := repo.db.Exec(ctx, `
-        INSERT INTO system_config (id, period, thresholds, features)
-        VALUES (1, $1, $2::jsonb, $3::jsonb)
+        INSERT INTO system_config (id, period, thresholds, features, retention, scheduling, disbursement)
+        VALUES (1, $1, $2::jsonb, $3::jsonb, COALESCE($4::jsonb, '{}'::jsonb), COALESCE($5::jsonb, '{}'::jsonb),
+                COALESCE($6::jsonb, '{}'::jsonb))
         ON CONFLICT (id) DO UPDATE
         SET period = EXCLUDED.period,
             thresholds = EXCLUDED.thresholds,
             features = EXCLUDED.features,
+            retention = COALESCE($4::jsonb, system_config.retention),
+            scheduling = COALESCE($5::jsonb, system_config.scheduling),
+            disbursement = COALESCE($6::jsonb, system_config.disbursement),
             updated_at = NOW()`,
-		cfg.Period, thresholdsBytes, featuresBytes,
+		cfg.Period, thresholdsBytes, featuresBytes, retentionBytes, schedulingBytes, disbursementBytes,
 	); err != nil {
 		return nil, err
 	}
@@ -463,29 +496,47 @@ func (repo *backofficeRepository) UpsertConfig(ctx context.Context, cfg domain.S
 
 func (repo *backofficeRepository) UpdateApplicationStatus(ctx context.Context, params domain.UpdateApplicationStatusParams) error {
 	return repo.withTx(ctx, func(tx pgx.Tx) error {
-		tag, err := tx.Exec(ctx, `UPDATE applications SET status=$1, updated_at=NOW() WHERE id=$2`, params.Status, params.AppID)
-		if err != nil {
-			return err
-		}
-		if tag.RowsAffected() == 0 {
-			return domain.ErrNotFound
-		}
-		if err := repo.insertTimeline(ctx, tx, params.Timeline); err != nil {
-			return err
-		}
-		return repo.insertAudit(ctx, tx, params.Audit)
+		return repo.updateApplicationStatus(ctx, tx, params)
 	})
 }
 
-func (repo *backofficeRepository) CreateVisit(ctx context.Context, visit *domain.Visit, timeline domain.TimelineEntry) error {
-	return repo.withTx(ctx, func(tx pgx.Tx) error {
-		if _, err := tx.Exec(ctx, `
-            INSERT INTO application_visits (id, application_id, scheduled_at, status, tksk_id)
-            VALUES ($1,$2,$3,$4,$5)`,
-			visit.ID, visit.ApplicationID, visit.ScheduledAt, visit.Status, visit.TkskID); err != nil {
+func (repo *backofficeRepository) updateApplicationStatus(ctx context.Context, tx pgx.Tx, params domain.UpdateApplicationStatusParams) error {
+	if strings.EqualFold(params.Status, "FINAL_APPROVED") {
+		waits, err := awaitsVerifiedVisit(ctx, tx, params.AppID)
+		if err != nil {
 			return err
 		}
-		return repo.insertTimeline(ctx, tx, timeline)
+		if waits {
+			return fmt.Errorf("%w: belum ada kunjungan TKSK yang diverifikasi supervisor", domain.ErrInvalidState)
+		}
+	}
+	var previous string
+	err := tx.QueryRow(ctx, `
+        UPDATE applications a SET status=$1, updated_at=NOW()
+        FROM (SELECT id, status FROM applications WHERE id=$2 FOR UPDATE) prev
+        WHERE a.id = prev.id
+        RETURNING prev.status`, params.Status, params.AppID).Scan(&previous)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	if err := repo.insertTimeline(ctx, tx, params.Timeline); err != nil {
+		return err
+	}
+	if err := repo.insertAudit(ctx, tx, params.Audit); err != nil {
+		return err
+	}
+	if previous == params.Status {
+		return nil
+	}
+	return enqueueWebhookEvent(ctx, tx, domain.WebhookEventApplicationStatusChanged, map[string]any{
+		"applicationId":  params.AppID,
+		"previousStatus": previous,
+		"status":         params.Status,
+		"actor":          params.Timeline.Actor,
+		"reason":         params.Timeline.Reason,
 	})
 }
 
@@ -520,6 +571,33 @@ func (repo *backofficeRepository) UpdateVisit(ctx context.Context, params domain
 		if len(setParts) == 0 {
 			return nil
 		}
+		var tkskID *string
+		if err := tx.QueryRow(ctx, `SELECT tksk_id::text FROM application_visits WHERE id=$1 AND application_id=$2`,
+			params.VisitID, params.AppID).Scan(&tkskID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+		if tkskID != nil {
+			if err := lockTkskVisits(ctx, tx, *tkskID); err != nil {
+				return err
+			}
+		}
+		var current string
+		if err := tx.QueryRow(ctx, `SELECT status FROM application_visits WHERE id=$1 FOR UPDATE`, params.VisitID).Scan(&current); err != nil {
+			return err
+		}
+		if current == domain.VisitStatusVerified {
+			return fmt.Errorf("%w: kunjungan %s sudah diverifikasi", domain.ErrInvalidState, params.VisitID)
+		}
+		submitting := params.Status != nil && *params.Status == domain.VisitStatusSubmitted
+		if params.ChecklistCheck != nil && (params.Checklist != nil || submitting) {
+			if err := checkVisitChecklist(ctx, tx, params.VisitID, params.Checklist, submitting, params.ChecklistCheck); err != nil {
+				return err
+			}
+		}
+		setParts = append(setParts, visitChangeBump)
 		args = append(args, params.VisitID, params.AppID)
 		query := fmt.Sprintf(`UPDATE application_visits SET %s WHERE id=$%d AND application_id=$%d`, strings.Join(setParts, ", "), argIdx, argIdx+1)
 
@@ -530,13 +608,19 @@ func (repo *backofficeRepository) UpdateVisit(ctx context.Context, params domain
 		if tag.RowsAffected() == 0 {
 			return domain.ErrNotFound
 		}
-		return repo.insertTimeline(ctx, tx, params.Timeline)
+		if err := repo.insertTimeline(ctx, tx, params.Timeline); err != nil {
+			return err
+		}
+		if params.Geofence != nil && submitting {
+			return repo.recordVisitGeofence(ctx, tx, params.VisitID, params.Timeline.Actor, params.Geofence)
+		}
+		return nil
 	})
 }
 
 func (repo *backofficeRepository) ListBatches(ctx context.Context) ([]domain.Batch, error) {
 	rows, err := repo.db.Query(ctx, `
-        SELECT id, code, status, checksum, created_at, updated_at
+        SELECT id, code, status, checksum, locked_at, created_at, updated_at
         FROM batches
         ORDER BY created_at DESC`)
 	if err != nil {
@@ -547,7 +631,7 @@ func (repo *backofficeRepository) ListBatches(ctx context.Context) ([]domain.Bat
 	var batches []domain.Batch
 	for rows.Next() {
 		var batch domain.Batch
-		if err := rows.Scan(&batch.ID, &batch.Code, &batch.Status, &batch.Checksum, &batch.CreatedAt, &batch.UpdatedAt); err != nil {
+		if err := rows.Scan(&batch.ID, &batch.Code, &batch.Status, &batch.Checksum, &batch.LockedAt, &batch.CreatedAt, &batch.UpdatedAt); err != nil {
 			return nil, err
 		}
 		items, err := repo.fetchBatchItems(ctx, batch.ID)
@@ -576,7 +660,7 @@ func (repo *backofficeRepository) ListBatchesByUser(ctx context.Context, userID
             FROM batch_items bi
             JOIN app_ids ai ON ai.id = bi.application_id
         )
-        SELECT b.id, b.code, b.status, b.checksum, b.created_at, b.updated_at
+        SELECT b.id, b.code, b.status, b.checksum, b.locked_at, b.created_at, b.updated_at
         FROM batches b
         JOIN matched_batches mb ON mb.batch_id = b.id
         ORDER BY b.created_at DESC`, userID)
@@ -588,7 +672,7 @@ func (repo *backofficeRepository) ListBatchesByUser(ctx context.Context, userID
 	var batches []domain.Batch
 	for rows.Next() {
 		var batch domain.Batch
-		if err := rows.Scan(&batch.ID, &batch.Code, &batch.Status, &batch.Checksum, &batch.CreatedAt, &batch.UpdatedAt); err != nil {
+		if err := rows.Scan(&batch.ID, &batch.Code, &batch.Status, &batch.Checksum, &batch.LockedAt, &batch.CreatedAt, &batch.UpdatedAt); err != nil {
 			return nil, err
 		}
 		items, err := repo.fetchBatchItems(ctx, batch.ID)
@@ -603,7 +687,7 @@ func (repo *backofficeRepository) ListBatchesByUser(ctx context.Context, userID
 
 func (repo *backofficeRepository) ListBatchesByApplication(ctx context.Context, appID string) ([]domain.Batch, error) {
 	rows, err := repo.db.Query(ctx, `
-        SELECT b.id, b.code, b.status, b.checksum, b.created_at, b.updated_at
+        SELECT b.id, b.code, b.status, b.checksum, b.locked_at, b.created_at, b.updated_at
         FROM batches b
         JOIN batch_items bi ON bi.batch_id = b.id
         WHERE bi.application_id = $1
@@ -616,7 +700,7 @@ func (repo *backofficeRepository) ListBatchesByApplication(ctx context.Context,
 	var batches []domain.Batch
 	for rows.Next() {
 		var batch domain.Batch
-		if err := rows.Scan(&batch.ID, &batch.Code, &batch.Status, &batch.Checksum, &batch.CreatedAt, &batch.UpdatedAt); err != nil {
+		if err := rows.Scan(&batch.ID, &batch.Code, &batch.Status, &batch.Checksum, &batch.LockedAt, &batch.CreatedAt, &batch.UpdatedAt); err != nil {
 			return nil, err
 		}
 		items, err := repo.fetchBatchItems(ctx, batch.ID)
@@ -665,12 +749,27 @@ func (repo *backofficeRepository) CreateBatch(ctx context.Context, batch *domain
 
 func (repo *backofficeRepository) UpdateBatchStatus(ctx context.Context, params domain.UpdateBatchStatusParams) error {
 	return repo.withTx(ctx, func(tx pgx.Tx) error {
-		tag, err := tx.Exec(ctx, `UPDATE batches SET status=$1, updated_at=NOW() WHERE id=$2`, params.Status, params.BatchID)
-		if err != nil {
+		var current string
+		if err := tx.QueryRow(ctx, `SELECT status FROM batches WHERE id=$1 FOR UPDATE`, params.BatchID).Scan(&current); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrNotFound
+			}
 			return err
 		}
-		if tag.RowsAffected() == 0 {
-			return domain.ErrNotFound
+		if params.From != "" && current != params.From {
+			return fmt.Errorf("%w: status batch sudah berubah menjadi %s", domain.ErrInvalidState, current)
+		}
+		if params.Manifest != nil {
+			if err := lockBatchItems(ctx, tx, params.BatchID, params.Manifest); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, `
+                UPDATE batches SET status=$1, checksum=$3, locked_at=NOW(), updated_at=NOW()
+                WHERE id=$2`, params.Status, params.BatchID, params.Checksum); err != nil {
+				return err
+			}
+		} else if _, err := tx.Exec(ctx, `UPDATE batches SET status=$1, updated_at=NOW() WHERE id=$2`, params.Status, params.BatchID); err != nil {
+			return err
 		}
 		return repo.insertAudit(ctx, tx, params.Audit)
 	})
@@ -1036,7 +1135,7 @@ func (repo *backofficeRepository) GetClusteringRun(ctx context.Context, runID st
 
 func (repo *backofficeRepository) fetchClusteringCandidates(ctx context.Context, runID string) ([]domain.ClusteringCandidate, error) {
 	rows, err := repo.db.Query(ctx, `
-        SELECT c.id, c.user_id, c.run_id, u.name, COALESCE(u.nik, ''), c.region, c.cluster, c.priority, c.score, c.household_size, c.status,
+        SELECT c.id, c.user_id, c.run_id, u.name, COALESCE(u.nik_enc, u.nik, ''), c.region, c.cluster, c.priority, c.score, c.household_size, c.status,
                c.assigned_to, c.reviewer, c.reviewed_at, c.notes
         FROM clustering_candidates c
         JOIN users u ON u.id = c.user_id
@@ -1057,6 +1156,10 @@ func (repo *backofficeRepository) fetchClusteringCandidates(ctx context.Context,
 			&candidate.Score, &candidate.HouseholdSize, &candidate.Status, &candidate.AssignedTo, &candidate.Reviewer, &candidate.ReviewedAt, &candidate.Notes); err != nil {
 			return nil, err
 		}
+		nik, err := repo.keys.Open(pii.FieldNIK, nik)
+		if err != nil {
+			return nil, err
+		}
 		candidate.NikMask = maskNikValue(nik)
 		candidate.Region = decodeRegion(regionBytes)
 		candidates = append(candidates, candidate)
@@ -1178,6 +1281,140 @@ func (repo *backofficeRepository) Overview(ctx context.Context) (map[string]any,
 	}, nil
 }
 
+// EkycFunnel aggregates sessions created in [From, To). The bucket query returns the
+// per-period rows and, through the empty grouping set, the totals row in one pass.
+func (repo *backofficeRepository) EkycFunnel(ctx context.Context, params domain.EkycFunnelParams) (*domain.EkycFunnelReport, error) {
+	args := []any{params.From, params.To, params.Granularity}
+	regionExpr, regionFilter := "NULL::text", "TRUE"
+	if params.RegionLevel != "" {
+		// RegionLevel is validated against FunnelRegionLevels by the service.
+		column := "u.region_" + params.RegionLevel
+		regionExpr = fmt.Sprintf("COALESCE(NULLIF(%s, ''), 'UNKNOWN')", column)
+		regionFilter = fmt.Sprintf("($4 = '' OR %s = $4)", column)
+		args = append(args, params.Region)
+	}
+	scoped := fmt.Sprintf(`
+        WITH scoped AS (
+            SELECT s.id,
+                   date_trunc($3, s.created_at AT TIME ZONE 'Asia/Jakarta') AT TIME ZONE 'Asia/Jakarta' AS period,
+                   %s AS region,
+                   (s.id_card_url IS NOT NULL AND s.selfie_with_id_url IS NOT NULL) AS uploaded,
+                   UPPER(s.face_match_overall) AS face,
+                   UPPER(s.liveness_overall) AS liveness,
+                   s.final_decision, s.rejection_reason, s.created_at,
+                   CASE WHEN s.final_decision <> 'PENDING' THEN COALESCE(
+                       (SELECT MIN(e.created_at) FROM ekyc_session_events e
+                         WHERE e.ekyc_session_id = s.id AND e.event_type = 'decision.made'),
+                       s.updated_at) END AS decided_at
+            FROM ekyc_sessions s
+            LEFT JOIN users u ON u.id = s.user_id
+            WHERE s.created_at >= $1 AND s.created_at < $2
+              AND %s
+        )`, regionExpr, regionFilter)
+
+	report := &domain.EkycFunnelReport{
+		From:                params.From,
+		To:                  params.To,
+		Granularity:         params.Granularity,
+		RegionLevel:         params.RegionLevel,
+		Region:              params.Region,
+		Buckets:             []domain.EkycFunnelBucket{},
+		Gestures:            []domain.GesturePassRate{},
+		TopRejectionReasons: []domain.RejectionReasonCount{},
+	}
+
+	rows, err := repo.db.Query(ctx, scoped+`
+        SELECT GROUPING(period, region) <> 0, period, region,
+               COUNT(*),
+               COUNT(*) FILTER (WHERE uploaded),
+               COUNT(face), COUNT(*) FILTER (WHERE face = 'PASS'),
+               COUNT(liveness), COUNT(*) FILTER (WHERE liveness = 'PASS'),
+               COUNT(*) FILTER (WHERE final_decision <> 'PENDING'),
+               COUNT(*) FILTER (WHERE final_decision = 'APPROVED'),
+               COUNT(*) FILTER (WHERE final_decision = 'REJECTED'),
+               percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM decided_at - created_at))
+                   FILTER (WHERE decided_at IS NOT NULL)
+        FROM scoped
+        GROUP BY GROUPING SETS ((period, region), ())
+        ORDER BY 1, period, region`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			bucket  domain.EkycFunnelBucket
+			isTotal bool
+			period  *time.Time
+		)
+		if err := rows.Scan(&isTotal, &period, &bucket.Region,
+			&bucket.SessionsCreated, &bucket.ArtifactsUploaded,
+			&bucket.FaceChecked, &bucket.FacePassed,
+			&bucket.LivenessChecked, &bucket.LivenessPassed,
+			&bucket.Decided, &bucket.Approved, &bucket.Rejected,
+			&bucket.MedianDecisionSeconds,
+		); err != nil {
+			return nil, err
+		}
+		if isTotal {
+			bucket.Region = nil
+			report.Totals = bucket
+			continue
+		}
+		if period != nil {
+			bucket.Period = *period
+		}
+		report.Buckets = append(report.Buckets, bucket)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	gestureRows, err := repo.db.Query(ctx, scoped+`
+        SELECT g.key,
+               COUNT(*),
+               COUNT(*) FILTER (WHERE UPPER(g.value #>> '{}') IN ('PASS', 'PASSED', 'TRUE'))
+        FROM scoped
+        JOIN liveness_checks l ON l.ekyc_session_id = scoped.id
+        CROSS JOIN LATERAL jsonb_each(l.per_gesture_result) g
+        GROUP BY g.key
+        ORDER BY g.key`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer gestureRows.Close()
+	for gestureRows.Next() {
+		var gesture domain.GesturePassRate
+		if err := gestureRows.Scan(&gesture.Gesture, &gesture.Attempts, &gesture.Passed); err != nil {
+			return nil, err
+		}
+		report.Gestures = append(report.Gestures, gesture)
+	}
+	if err := gestureRows.Err(); err != nil {
+		return nil, err
+	}
+
+	reasonRows, err := repo.db.Query(ctx, scoped+`
+        SELECT COALESCE(NULLIF(TRIM(rejection_reason), ''), '(tanpa alasan)'), COUNT(*)
+        FROM scoped
+        WHERE final_decision = 'REJECTED'
+        GROUP BY 1
+        ORDER BY 2 DESC, 1
+        LIMIT 10`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer reasonRows.Close()
+	for reasonRows.Next() {
+		var reason domain.RejectionReasonCount
+		if err := reasonRows.Scan(&reason.Reason, &reason.Count); err != nil {
+			return nil, err
+		}
+		report.TopRejectionReasons = append(report.TopRejectionReasons, reason)
+	}
+	return report, reasonRows.Err()
+}
+
 func (repo *backofficeRepository) NormalizeUserIDs(ctx context.Context, ids []string) ([]string, error) {
 	if len(ids) == 0 {
 		return ids, nil
@@ -1254,7 +1491,8 @@ func (repo *backofficeRepository) fetchEkycDocuments(ctx context.Context, appID
 
 func (repo *backofficeRepository) fetchVisits(ctx context.Context, appID string) ([]domain.Visit, error) {
 	rows, err := repo.db.Query(ctx, `
-        SELECT id, application_id, scheduled_at, geotag_lat, geotag_lng, photos, checklist, status, COALESCE(tksk_id::text, ''), created_at
+        SELECT id, application_id, scheduled_at, geotag_lat, geotag_lng, photos, checklist, status, COALESCE(tksk_id::text, ''), created_at,
+               checklist_template_id, `+visitGeofenceColumns+`
         FROM application_visits
         WHERE application_id = $1
         ORDER BY scheduled_at DESC`, appID)
@@ -1271,13 +1509,19 @@ func (repo *backofficeRepository) fetchVisits(ctx context.Context, appID string)
 			checklist []byte
 			geotagLat *float64
 			geotagLng *float64
+			flags     []string
+			kelurahan *string
+			distance  *float64
+			checkedAt *time.Time
 		)
 
-		if err := rows.Scan(&visit.ID, &visit.ApplicationID, &visit.ScheduledAt, &geotagLat, &geotagLng, &photos, &checklist, &visit.Status, &visit.TkskID, &visit.CreatedAt); err != nil {
+		if err := rows.Scan(&visit.ID, &visit.ApplicationID, &visit.ScheduledAt, &geotagLat, &geotagLng, &photos, &checklist, &visit.Status, &visit.TkskID, &visit.CreatedAt,
+			&visit.ChecklistTemplateID, &flags, &kelurahan, &distance, &checkedAt); err != nil {
 			return nil, err
 		}
 		visit.GeotagLat = geotagLat
 		visit.GeotagLng = geotagLng
+		visit.Geofence = visitGeofence(flags, kelurahan, distance, checkedAt)
 		visit.Photos = decodeStringArray(photos)
 		visit.Checklist = decodeJSON(checklist)
 		visits = append(visits, visit)
@@ -1290,7 +1534,8 @@ func (repo *backofficeRepository) fetchVisitsByApplications(ctx context.Context,
 		return map[string][]domain.Visit{}, nil
 	}
 	rows, err := repo.db.Query(ctx, `
-        SELECT application_id, id, scheduled_at, geotag_lat, geotag_lng, photos, checklist, status, COALESCE(tksk_id::text, ''), created_at
+        SELECT application_id, id, scheduled_at, geotag_lat, geotag_lng, photos, checklist, status, COALESCE(tksk_id::text, ''), created_at,
+               checklist_template_id, `+visitGeofenceColumns+`
         FROM application_visits
         WHERE application_id = ANY($1::text[])
         ORDER BY scheduled_at DESC`, appIDs)
@@ -1308,13 +1553,19 @@ func (repo *backofficeRepository) fetchVisitsByApplications(ctx context.Context,
 			checklist     []byte
 			geotagLat     *float64
 			geotagLng     *float64
+			flags         []string
+			kelurahan     *string
+			distance      *float64
+			checkedAt     *time.Time
 		)
-		if err := rows.Scan(&applicationID, &visit.ID, &visit.ScheduledAt, &geotagLat, &geotagLng, &photos, &checklist, &visit.Status, &visit.TkskID, &visit.CreatedAt); err != nil {
+		if err := rows.Scan(&applicationID, &visit.ID, &visit.ScheduledAt, &geotagLat, &geotagLng, &photos, &checklist, &visit.Status, &visit.TkskID, &visit.CreatedAt,
+			&visit.ChecklistTemplateID, &flags, &kelurahan, &distance, &checkedAt); err != nil {
 			return nil, err
 		}
 		visit.ApplicationID = applicationID
 		visit.GeotagLat = geotagLat
 		visit.GeotagLng = geotagLng
+		visit.Geofence = visitGeofence(flags, kelurahan, distance, checkedAt)
 		visit.Photos = decodeStringArray(photos)
 		visit.Checklist = decodeJSON(checklist)
 		result[applicationID] = append(result[applicationID], visit)
@@ -1329,7 +1580,8 @@ func (repo *backofficeRepository) ListVisits(ctx context.Context, params domain.
 		idx     = 1
 	)
 	builder.WriteString(`
-        SELECT id, application_id, scheduled_at, geotag_lat, geotag_lng, photos, checklist, status, COALESCE(tksk_id::text, ''), created_at
+        SELECT id, application_id, scheduled_at, geotag_lat, geotag_lng, photos, checklist, status, COALESCE(tksk_id::text, ''), created_at,
+               checklist_template_id, ` + visitGeofenceColumns + `
         FROM application_visits
         WHERE 1=1`)
 	if params.ApplicationID != "" {
@@ -1357,6 +1609,9 @@ func (repo *backofficeRepository) ListVisits(ctx context.Context, params domain.
 		args = append(args, params.To)
 		idx++
 	}
+	if params.GeofenceFlagged {
+		builder.WriteString(" AND cardinality(geofence_flags) > 0")
+	}
 	limit := params.Limit
 	if limit <= 0 || limit > 500 {
 		limit = 200
@@ -1377,12 +1632,18 @@ func (repo *backofficeRepository) ListVisits(ctx context.Context, params domain.
 			checklist []byte
 			geotagLat *float64
 			geotagLng *float64
+			flags     []string
+			kelurahan *string
+			distance  *float64
+			checkedAt *time.Time
 		)
-		if err := rows.Scan(&visit.ID, &visit.ApplicationID, &visit.ScheduledAt, &geotagLat, &geotagLng, &photos, &checklist, &visit.Status, &visit.TkskID, &visit.CreatedAt); err != nil {
+		if err := rows.Scan(&visit.ID, &visit.ApplicationID, &visit.ScheduledAt, &geotagLat, &geotagLng, &photos, &checklist, &visit.Status, &visit.TkskID, &visit.CreatedAt,
+			&visit.ChecklistTemplateID, &flags, &kelurahan, &distance, &checkedAt); err != nil {
 			return nil, err
 		}
 		visit.GeotagLat = geotagLat
 		visit.GeotagLng = geotagLng
+		visit.Geofence = visitGeofence(flags, kelurahan, distance, checkedAt)
 		visit.Photos = decodeStringArray(photos)
 		visit.Checklist = decodeJSON(checklist)
 		visits = append(visits, visit)
@@ -1560,7 +1821,7 @@ func (repo *backofficeRepository) CreateEkycSession(ctx context.Context, params
                   metadata, created_at, updated_at`,
 		params.UserID,
 	)
-	session, err := scanEkycSessionRow(row)
+	session, err := repo.scanEkycSessionRow(row)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if params.UserID != nil && errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -1572,30 +1833,60 @@ func (repo *backofficeRepository) CreateEkycSession(ctx context.Context, params
 }
 
 func (repo *backofficeRepository) UpdateEkycSession(ctx context.Context, params domain.UpdateEkycArtifactsParams) (*domain.EkycSession, error) {
-	row := repo.db.QueryRow(ctx, `
-        UPDATE ekyc_sessions
-        SET id_card_url = COALESCE($2, id_card_url),
-            selfie_with_id_url = COALESCE($3, selfie_with_id_url),
-            recorded_video_url = COALESCE($4, recorded_video_url),
-            face_matching_status = COALESCE($5, face_matching_status),
-            liveness_status = COALESCE($6, liveness_status),
-            status = COALESCE($7, status),
-            updated_at = NOW()
-        WHERE id = $1
-        RETURNING id, user_id, status, face_matching_status, liveness_status, final_decision,
-                  id_card_url, selfie_with_id_url, recorded_video_url,
-                  face_match_overall, liveness_overall, rejection_reason,
-                  metadata, created_at, updated_at`,
-		params.SessionID, params.IDCardURL, params.SelfieWithIDURL, params.RecordedVideoURL,
-		params.FaceMatchingStatus, params.LivenessStatus, params.Status,
-	)
-	return scanEkycSessionRow(row)
+	var session *domain.EkycSession
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, `
+            UPDATE ekyc_sessions
+            SET id_card_url = COALESCE($2, id_card_url),
+                selfie_with_id_url = COALESCE($3, selfie_with_id_url),
+                recorded_video_url = COALESCE($4, recorded_video_url),
+                face_matching_status = COALESCE($5, face_matching_status),
+                liveness_status = COALESCE($6, liveness_status),
+                status = COALESCE($7, status),
+                updated_at = NOW()
+            WHERE id = $1
+            RETURNING id, user_id, status, face_matching_status, liveness_status, final_decision,
+                      id_card_url, selfie_with_id_url, recorded_video_url,
+                      face_match_overall, liveness_overall, rejection_reason,
+                      metadata, created_at, updated_at`,
+			params.SessionID, params.IDCardURL, params.SelfieWithIDURL, params.RecordedVideoURL,
+			params.FaceMatchingStatus, params.LivenessStatus, params.Status,
+		)
+		result, err := repo.scanEkycSessionRow(row)
+		if err != nil {
+			return err
+		}
+		session = result
+
+		eventType := domain.EkycEventStatusChanged
+		artifacts := map[string]*string{
+			"idCard":        params.IDCardURL,
+			"selfieWithId":  params.SelfieWithIDURL,
+			"recordedVideo": params.RecordedVideoURL,
+		}
+		extra := map[string]any{}
+		for name, url := range artifacts {
+			if url != nil {
+				eventType = domain.EkycEventArtifactUploaded
+				extra[name] = *url
+			}
+		}
+		return repo.insertSessionEvent(ctx, tx, session, eventType, extra)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
 }
 
 func (repo *backofficeRepository) SaveFaceChecks(ctx context.Context, params domain.SaveFaceChecksParams) (*domain.EkycSession, error) {
 	var session *domain.EkycSession
 	err := repo.withTx(ctx, func(tx pgx.Tx) error {
-		if _, err := tx.Exec(ctx, `DELETE FROM face_checks WHERE ekyc_session_id=$1`, params.SessionID); err != nil {
+		attemptNo, duplicate, err := repo.nextCheckAttempt(ctx, tx, "face_checks", params.SessionID, params.JobID, params.MaxAttempts)
+		if err != nil || duplicate {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE face_checks SET is_current = FALSE WHERE ekyc_session_id=$1 AND is_current`, params.SessionID); err != nil {
 			return err
 		}
 		for _, check := range params.Checks {
@@ -1604,39 +1895,53 @@ func (repo *backofficeRepository) SaveFaceChecks(ctx context.Context, params dom
 				meta, _ = json.Marshal(check.Metadata)
 			}
 			if _, err := tx.Exec(ctx, `
-                INSERT INTO face_checks (ekyc_session_id, step, similarity_score, threshold, result, raw_metadata)
-                VALUES ($1,$2,$3,$4,$5,$6::jsonb)`,
-				params.SessionID, check.Step, check.Similarity, check.Threshold, check.Result, meta,
+                INSERT INTO face_checks (ekyc_session_id, step, similarity_score, threshold, result, raw_metadata, attempt_no, is_current)
+                VALUES ($1,$2,$3,$4,$5,$6::jsonb,$7,TRUE)`,
+				params.SessionID, check.Step, check.Similarity, check.Threshold, check.Result, meta, attemptNo,
 			); err != nil {
 				return err
 			}
 		}
+		lock := attemptsExhausted(attemptNo, params.MaxAttempts, params.Overall)
 		row := tx.QueryRow(ctx, `
             UPDATE ekyc_sessions
             SET face_match_overall = $2,
                 face_matching_status = $3,
+                status = CASE WHEN $4 THEN 'MANUAL_REVIEW' ELSE status END,
                 updated_at = NOW()
             WHERE id = $1
             RETURNING id, user_id, status, face_matching_status, liveness_status, final_decision,
                       id_card_url, selfie_with_id_url, recorded_video_url,
                       face_match_overall, liveness_overall, rejection_reason,
                       metadata, created_at, updated_at`,
-			params.SessionID, params.Overall, params.Status,
+			params.SessionID, params.Overall, params.Status, lock,
 		)
-		result, err := scanEkycSessionRow(row)
+		result, err := repo.scanEkycSessionRow(row)
 		if err != nil {
 			return err
 		}
 		session = result
 
+		if lock {
+			if err := repo.insertAudit(ctx, tx, lockedAuditEntry(params.SessionID, "FACE_MATCH", attemptNo, params.MaxAttempts)); err != nil {
+				return err
+			}
+		}
 		if err := repo.updateApplicationProgress(ctx, tx, params.SessionID, params.Overall, nil); err != nil {
 			return err
 		}
-		return nil
+		return repo.insertSessionEvent(ctx, tx, session, domain.EkycEventFaceCheckRecorded, map[string]any{
+			"attemptNo": attemptNo,
+			"locked":    lock,
+		})
 	})
 	if err != nil {
 		return nil, err
 	}
+	if session == nil {
+		// The job was already recorded through the other delivery path.
+		return repo.GetEkycSession(ctx, params.SessionID)
+	}
 	_ = repo.ensureApplicationFromSession(ctx, params.SessionID)
 	if err := repo.enrichEkycSession(ctx, session); err != nil {
 		return nil, err
@@ -1647,7 +1952,11 @@ func (repo *backofficeRepository) SaveFaceChecks(ctx context.Context, params dom
 func (repo *backofficeRepository) SaveLivenessResult(ctx context.Context, params domain.SaveLivenessResultParams) (*domain.EkycSession, error) {
 	var session *domain.EkycSession
 	err := repo.withTx(ctx, func(tx pgx.Tx) error {
-		if _, err := tx.Exec(ctx, `DELETE FROM liveness_checks WHERE ekyc_session_id=$1`, params.SessionID); err != nil {
+		attemptNo, duplicate, err := repo.nextCheckAttempt(ctx, tx, "liveness_checks", params.SessionID, params.JobID, params.MaxAttempts)
+		if err != nil || duplicate {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE liveness_checks SET is_current = FALSE WHERE ekyc_session_id=$1 AND is_current`, params.SessionID); err != nil {
 			return err
 		}
 		meta := []byte(`{}`)
@@ -1659,39 +1968,53 @@ func (repo *backofficeRepository) SaveLivenessResult(ctx context.Context, params
 			perGesture, _ = json.Marshal(params.PerGesture)
 		}
 		if _, err := tx.Exec(ctx, `
-            INSERT INTO liveness_checks (ekyc_session_id, overall_result, per_gesture_result, recorded_video_url, raw_metadata)
-            VALUES ($1,$2,$3::jsonb,$4,$5::jsonb)`,
-			params.SessionID, params.Overall, perGesture, params.VideoURL, meta,
+            INSERT INTO liveness_checks (ekyc_session_id, overall_result, per_gesture_result, recorded_video_url, raw_metadata, attempt_no, is_current)
+            VALUES ($1,$2,$3::jsonb,$4,$5::jsonb,$6,TRUE)`,
+			params.SessionID, params.Overall, perGesture, params.VideoURL, meta, attemptNo,
 		); err != nil {
 			return err
 		}
+		lock := attemptsExhausted(attemptNo, params.MaxAttempts, params.Overall)
 		row := tx.QueryRow(ctx, `
             UPDATE ekyc_sessions
             SET liveness_overall = $2,
                 liveness_status = $3,
                 recorded_video_url = COALESCE($4, recorded_video_url),
+                status = CASE WHEN $5 THEN 'MANUAL_REVIEW' ELSE status END,
                 updated_at = NOW()
             WHERE id = $1
             RETURNING id, user_id, status, face_matching_status, liveness_status, final_decision,
                       id_card_url, selfie_with_id_url, recorded_video_url,
                       face_match_overall, liveness_overall, rejection_reason,
                       metadata, created_at, updated_at`,
-			params.SessionID, params.Overall, params.Status, params.VideoURL,
+			params.SessionID, params.Overall, params.Status, params.VideoURL, lock,
 		)
-		result, err := scanEkycSessionRow(row)
+		result, err := repo.scanEkycSessionRow(row)
 		if err != nil {
 			return err
 		}
 		session = result
 
+		if lock {
+			if err := repo.insertAudit(ctx, tx, lockedAuditEntry(params.SessionID, "LIVENESS", attemptNo, params.MaxAttempts)); err != nil {
+				return err
+			}
+		}
 		if err := repo.updateApplicationProgress(ctx, tx, params.SessionID, "", &params.Overall); err != nil {
 			return err
 		}
-		return nil
+		return repo.insertSessionEvent(ctx, tx, session, domain.EkycEventLivenessRecorded, map[string]any{
+			"attemptNo": attemptNo,
+			"locked":    lock,
+		})
 	})
 	if err != nil {
 		return nil, err
 	}
+	if session == nil {
+		// The job was already recorded through the other delivery path.
+		return repo.GetEkycSession(ctx, params.SessionID)
+	}
 	_ = repo.ensureApplicationFromSession(ctx, params.SessionID)
 	if err := repo.enrichEkycSession(ctx, session); err != nil {
 		return nil, err
@@ -1699,6 +2022,64 @@ func (repo *backofficeRepository) SaveLivenessResult(ctx context.Context, params
 	return session, nil
 }
 
+// nextCheckAttempt locks the session row and returns the attempt number for the next
+// face-match or liveness result. Locked sessions and sessions over the limit are refused.
+// A result whose AI job id is already stored is reported as a duplicate, because the same
+// job reaches us both through the HTTP callback and the result queue.
+func (repo *backofficeRepository) nextCheckAttempt(ctx context.Context, tx pgx.Tx, table, sessionID, jobID string, maxAttempts int) (int, bool, error) {
+	var status string
+	if err := tx.QueryRow(ctx, `SELECT status FROM ekyc_sessions WHERE id = $1 FOR UPDATE`, sessionID).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, false, domain.ErrNotFound
+		}
+		return 0, false, err
+	}
+	if jobID != "" {
+		var seen bool
+		query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE ekyc_session_id = $1 AND raw_metadata->>'jobId' = $2)`, table)
+		if err := tx.QueryRow(ctx, query, sessionID, jobID).Scan(&seen); err != nil {
+			return 0, false, err
+		}
+		if seen {
+			return 0, true, nil
+		}
+	}
+	if strings.EqualFold(status, "MANUAL_REVIEW") {
+		return 0, false, domain.ErrSessionLocked
+	}
+	var last int
+	query := fmt.Sprintf(`SELECT COALESCE(MAX(attempt_no), 0) FROM %s WHERE ekyc_session_id = $1`, table)
+	if err := tx.QueryRow(ctx, query, sessionID).Scan(&last); err != nil {
+		return 0, false, err
+	}
+	next := last + 1
+	if maxAttempts > 0 && next > maxAttempts {
+		return 0, false, domain.ErrSessionLocked
+	}
+	return next, false, nil
+}
+
+func attemptsExhausted(attemptNo, maxAttempts int, overall string) bool {
+	if maxAttempts <= 0 || attemptNo < maxAttempts {
+		return false
+	}
+	return !strings.EqualFold(strings.TrimSpace(overall), "PASS")
+}
+
+func lockedAuditEntry(sessionID, check string, attemptNo, maxAttempts int) domain.AuditEntry {
+	return domain.AuditEntry{
+		Actor:  "system",
+		Entity: sessionID,
+		Action: "EKYC:LOCKED",
+		Reason: fmt.Sprintf("%s gagal setelah %d percobaan", check, attemptNo),
+		Metadata: map[string]any{
+			"check":       check,
+			"attemptNo":   attemptNo,
+			"maxAttempts": maxAttempts,
+		},
+	}
+}
+
 func (repo *backofficeRepository) AssignUserToSession(ctx context.Context, params domain.ApplicantSubmission) (*domain.EkycSession, error) {
 	var session *domain.EkycSession
 	params.Phone = normalizePhone(params.Phone)
@@ -1715,12 +2096,17 @@ func (repo *backofficeRepository) AssignUserToSession(ctx context.Context, param
 			"nik":         params.Nik,
 			"birthDate":   params.BirthDate,
 			"address":     params.Address,
+			"region":      params.Region,
 			"phone":       params.Phone,
 			"email":       params.Email,
 			"userId":      userID,
 			"submittedAt": time.Now().UTC(),
 		}
-		metadataPatch, _ := json.Marshal(map[string]any{"applicant": applicantData})
+		sealedApplicant, err := sealApplicant(repo.keys, applicantData)
+		if err != nil {
+			return err
+		}
+		metadataPatch, _ := json.Marshal(map[string]any{"applicant": sealedApplicant})
 		row := tx.QueryRow(ctx, `
             UPDATE ekyc_sessions
             SET user_id = $2,
@@ -1733,7 +2119,7 @@ func (repo *backofficeRepository) AssignUserToSession(ctx context.Context, param
                       metadata, created_at, updated_at`,
 			params.SessionID, userID, metadataPatch,
 		)
-		result, err := scanEkycSessionRow(row)
+		result, err := repo.scanEkycSessionRow(row)
 		if err != nil {
 			return err
 		}
@@ -1777,7 +2163,7 @@ func (repo *backofficeRepository) ListEkycSessions(ctx context.Context, params d
 
 	var sessions []domain.EkycSession
 	for rows.Next() {
-		session, err := scanEkycSessionRow(rows)
+		session, err := repo.scanEkycSessionRow(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -1794,7 +2180,7 @@ func (repo *backofficeRepository) GetEkycSession(ctx context.Context, id string)
                metadata, created_at, updated_at
         FROM ekyc_sessions
         WHERE id = $1`, id)
-	session, err := scanEkycSessionRow(row)
+	session, err := repo.scanEkycSessionRow(row)
 	if err != nil {
 		return nil, err
 	}
@@ -1805,111 +2191,795 @@ func (repo *backofficeRepository) GetEkycSession(ctx context.Context, id string)
 }
 
 func (repo *backofficeRepository) UpdateEkycDecision(ctx context.Context, params domain.UpdateEkycDecisionParams) (*domain.EkycSession, error) {
-	row := repo.db.QueryRow(ctx, `
-        UPDATE ekyc_sessions
-        SET final_decision = $2,
-            status = CASE WHEN $2 = 'PENDING' THEN status ELSE 'COMPLETED' END,
-            rejection_reason = $3,
-            updated_at = NOW()
-        WHERE id = $1
-        RETURNING id, user_id, status, face_matching_status, liveness_status, final_decision,
-                  id_card_url, selfie_with_id_url, recorded_video_url,
-                  face_match_overall, liveness_overall, rejection_reason,
-                  metadata, created_at, updated_at`,
-		params.SessionID, params.FinalDecision, params.Reason,
-	)
-	session, err := scanEkycSessionRow(row)
+	_ = repo.ensureApplicationFromSession(ctx, params.SessionID)
+	var session *domain.EkycSession
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		row := tx.QueryRow(ctx, `
+            UPDATE ekyc_sessions
+            SET final_decision = $2,
+                status = CASE WHEN $2 = 'PENDING' THEN status ELSE 'COMPLETED' END,
+                rejection_reason = $3,
+                updated_at = NOW()
+            WHERE id = $1
+            RETURNING id, user_id, status, face_matching_status, liveness_status, final_decision,
+                      id_card_url, selfie_with_id_url, recorded_video_url,
+                      face_match_overall, liveness_overall, rejection_reason,
+                      metadata, created_at, updated_at`,
+			params.SessionID, params.FinalDecision, params.Reason,
+		)
+		result, err := repo.scanEkycSessionRow(row)
+		if err != nil {
+			return err
+		}
+		session = result
+		if err := repo.insertSessionEvent(ctx, tx, session, domain.EkycEventDecisionMade, nil); err != nil {
+			return err
+		}
+		// sync application status to reflect latest decision
+		if err := repo.syncApplicationStatus(ctx, tx, session.ID, params.FinalDecision); err != nil {
+			return err
+		}
+		if strings.EqualFold(session.FinalDecision, "PENDING") {
+			return nil
+		}
+		return enqueueWebhookEvent(ctx, tx, domain.WebhookEventEkycFinalized, finalizedWebhookData(session, nil))
+	})
 	if err != nil {
 		return nil, err
 	}
-	// sync application status to reflect latest decision
-	_ = repo.ensureApplicationFromSession(ctx, session.ID)
-	_ = repo.syncApplicationStatus(ctx, session.ID, params.FinalDecision)
 	if err := repo.enrichEkycSession(ctx, session); err != nil {
 		return nil, err
 	}
 	return session, nil
 }
 
-func (repo *backofficeRepository) enrichEkycSession(ctx context.Context, session *domain.EkycSession) error {
-	checks, err := repo.fetchFaceChecks(ctx, session.ID)
-	if err != nil {
-		return err
+// finalizedWebhookData is the data of an ekyc.session.finalized webhook. override is
+// set when a reviewer replaced an earlier decision.
+func finalizedWebhookData(session *domain.EkycSession, override map[string]any) map[string]any {
+	data := map[string]any{
+		"sessionId":        session.ID,
+		"userId":           session.UserID,
+		"applicationId":    session.ID,
+		"finalDecision":    session.FinalDecision,
+		"rejectionReason":  session.RejectionReason,
+		"faceMatchOverall": session.FaceMatchOverall,
+		"livenessOverall":  session.LivenessOverall,
+		"decidedAt":        session.UpdatedAt,
 	}
-	session.FaceChecks = checks
-
-	live, err := repo.fetchLiveness(ctx, session.ID)
-	if err != nil {
-		return err
+	if override != nil {
+		data["override"] = override
 	}
-	session.LivenessCheck = live
-	return nil
+	return data
 }
 
-func (repo *backofficeRepository) fetchFaceChecks(ctx context.Context, sessionID string) ([]domain.FaceCheck, error) {
-	rows, err := repo.db.Query(ctx, `
-        SELECT id, ekyc_session_id, step, similarity_score, threshold, result, raw_metadata, created_at
-        FROM face_checks
-        WHERE ekyc_session_id = $1
-        ORDER BY created_at ASC`, sessionID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+// OverrideEkycDecision changes the final decision of a session and keeps the previous
+// value in ekyc_decision_overrides. The linked application follows the new decision.
+func (repo *backofficeRepository) OverrideEkycDecision(ctx context.Context, params domain.OverrideEkycDecisionParams) (*domain.EkycSession, error) {
+	_ = repo.ensureApplicationFromSession(ctx, params.SessionID)
+	var session *domain.EkycSession
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		var previous string
+		if err := tx.QueryRow(ctx, `SELECT final_decision FROM ekyc_sessions WHERE id = $1 FOR UPDATE`, params.SessionID).Scan(&previous); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+		if strings.EqualFold(previous, params.FinalDecision) {
+			return fmt.Errorf("%w: keputusan sudah %s", domain.ErrInvalidState, previous)
+		}
+		var known bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM decision_override_reasons WHERE code = $1 AND active)`, params.ReasonCode).Scan(&known); err != nil {
+			return err
+		}
+		if !known {
+			return fmt.Errorf("%w: kode alasan %s tidak dikenal", domain.ErrInvalidState, params.ReasonCode)
+		}
 
-	var checks []domain.FaceCheck
-	for rows.Next() {
-		var (
-			check      domain.FaceCheck
-			meta       []byte
-			similarity sql.NullFloat64
-			threshold  sql.NullFloat64
+		reason := params.ReasonText
+		row := tx.QueryRow(ctx, `
+            UPDATE ekyc_sessions
+            SET final_decision = $2,
+                status = CASE WHEN $2 = 'PENDING' THEN status ELSE 'COMPLETED' END,
+                rejection_reason = $3,
+                updated_at = NOW()
+            WHERE id = $1
+            RETURNING id, user_id, status, face_matching_status, liveness_status, final_decision,
+                      id_card_url, selfie_with_id_url, recorded_video_url,
+                      face_match_overall, liveness_overall, rejection_reason,
+                      metadata, created_at, updated_at`,
+			params.SessionID, params.FinalDecision, reason,
 		)
-		if err := rows.Scan(&check.ID, &check.SessionID, &check.Step, &similarity, &threshold, &check.Result, &meta, &check.CreatedAt); err != nil {
-			return nil, err
+		result, err := repo.scanEkycSessionRow(row)
+		if err != nil {
+			return err
 		}
-		if similarity.Valid {
-			value := similarity.Float64
-			check.Similarity = &value
+		session = result
+
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO ekyc_decision_overrides (ekyc_session_id, previous_decision, new_decision, reason_code, reason_text, actor, actor_role)
+            VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+			params.SessionID, previous, params.FinalDecision, params.ReasonCode, params.ReasonText, params.Actor, params.ActorRole,
+		); err != nil {
+			return err
 		}
-		if threshold.Valid {
-			value := threshold.Float64
-			check.Threshold = &value
+		if err := repo.syncApplicationStatus(ctx, tx, params.SessionID, params.FinalDecision); err != nil {
+			return err
 		}
-		check.RawMetadata = decodeJSON(meta)
-		checks = append(checks, check)
-	}
-	return checks, rows.Err()
-}
 
-func (repo *backofficeRepository) fetchLiveness(ctx context.Context, sessionID string) (*domain.LivenessCheck, error) {
+		var hasApplication bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM applications WHERE id = $1)`, params.SessionID).Scan(&hasApplication); err != nil {
+			return err
+		}
+		params.Timeline.Metadata["previousDecision"] = previous
+		params.Audit.Metadata["previousDecision"] = previous
+		if hasApplication {
+			if err := repo.insertTimeline(ctx, tx, params.Timeline); err != nil {
+				return err
+			}
+		}
+		if err := repo.insertAudit(ctx, tx, params.Audit); err != nil {
+			return err
+		}
+		if err := repo.insertSessionEvent(ctx, tx, session, domain.EkycEventDecisionMade, map[string]any{
+			"previousDecision": previous,
+			"override":         true,
+			"reasonCode":       params.ReasonCode,
+		}); err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(ctx, tx, domain.WebhookEventEkycFinalized, finalizedWebhookData(session, map[string]any{
+			"previousDecision": previous,
+			"reasonCode":       params.ReasonCode,
+			"actor":            params.Actor,
+		}))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.enrichEkycSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// insertSessionEvent appends a state change to ekyc_session_events and announces it on
+// EkycSessionEventChannel. NOTIFY is transactional, so listeners only hear about events
+// whose transaction committed.
+func (repo *backofficeRepository) insertSessionEvent(ctx context.Context, tx pgx.Tx, session *domain.EkycSession, eventType string, extra map[string]any) error {
+	payload := map[string]any{
+		"status":             session.Status,
+		"faceMatchingStatus": session.FaceMatchingStatus,
+		"livenessStatus":     session.LivenessStatus,
+		"finalDecision":      session.FinalDecision,
+		"faceMatchOverall":   session.FaceMatchOverall,
+		"livenessOverall":    session.LivenessOverall,
+	}
+	for key, value := range extra {
+		payload[key] = value
+	}
+	payloadBytes, _ := json.Marshal(payload)
+	var id int64
+	if err := tx.QueryRow(ctx, `
+        INSERT INTO ekyc_session_events (ekyc_session_id, event_type, payload)
+        VALUES ($1,$2,$3::jsonb)
+        RETURNING id`,
+		session.ID, eventType, payloadBytes,
+	).Scan(&id); err != nil {
+		return err
+	}
+	notice, _ := json.Marshal(map[string]any{"id": id, "sessionId": session.ID})
+	_, err := tx.Exec(ctx, `SELECT pg_notify($1, $2)`, domain.EkycSessionEventChannel, string(notice))
+	return err
+}
+
+func (repo *backofficeRepository) ListEkycSessionEvents(ctx context.Context, sessionID string, afterID int64, limit int) ([]domain.EkycSessionEvent, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := repo.db.Query(ctx, `
+        SELECT id, ekyc_session_id, event_type, payload, created_at
+        FROM ekyc_session_events
+        WHERE ekyc_session_id = $1 AND id > $2
+        ORDER BY id
+        LIMIT $3`, sessionID, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []domain.EkycSessionEvent
+	for rows.Next() {
+		var (
+			event   domain.EkycSessionEvent
+			payload []byte
+		)
+		if err := rows.Scan(&event.ID, &event.SessionID, &event.Type, &payload, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		event.Payload = decodeJSON(payload)
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// reviewScore is the SQL twin of the service's rankReviewCandidate: the sum of the
+// weights of the review signals a session shows. $1 is the default face threshold and
+// $2 the borderline margin below it, $3 to $8 the weights.
+const reviewScore = `
+    CASE WHEN upper(s.status) = 'MANUAL_REVIEW' THEN $3 ELSE 0 END
+  + CASE WHEN upper(s.face_match_overall) <> upper(s.liveness_overall) THEN $4 ELSE 0 END
+  + CASE WHEN EXISTS (
+        SELECT 1 FROM face_checks f
+        CROSS JOIN LATERAL (SELECT CASE WHEN f.threshold > 0 THEN f.threshold ELSE $1 END AS threshold) t
+        WHERE f.ekyc_session_id = s.id AND f.is_current
+          AND f.similarity_score < t.threshold AND f.similarity_score >= t.threshold - $2)
+      THEN $5 ELSE 0 END
+  + CASE WHEN jsonb_typeof(a.flags->'nikMismatches') = 'array' AND jsonb_array_length(a.flags->'nikMismatches') > 0 THEN $6 ELSE 0 END
+  + CASE WHEN jsonb_typeof(a.flags->'ocrDiscrepancies') = 'array' AND jsonb_array_length(a.flags->'ocrDiscrepancies') > 0 THEN $7 ELSE 0 END
+  + $8 * GREATEST(0, GREATEST(
+        (SELECT MAX(attempt_no) FROM face_checks f WHERE f.ekyc_session_id = s.id),
+        (SELECT MAX(attempt_no) FROM liveness_checks l WHERE l.ekyc_session_id = s.id)) - 1)`
+
+// ListReviewCandidates ranks the undecided sessions that already have a verification
+// result in SQL, so a long backlog cannot hide newer high-risk sessions, and loads the
+// page's check histories in one batch.
+func (repo *backofficeRepository) ListReviewCandidates(ctx context.Context, params domain.ListReviewCandidatesParams) ([]domain.ReviewCandidate, error) {
+	limit := params.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	rows, err := repo.db.Query(ctx, `
+        SELECT id, user_id, status, face_matching_status, liveness_status, final_decision,
+               id_card_url, selfie_with_id_url, recorded_video_url,
+               face_match_overall, liveness_overall, rejection_reason,
+               metadata, created_at, updated_at,
+               flags, reviewer, claimed_at, expires_at
+        FROM (
+            SELECT s.id, s.user_id, s.status, s.face_matching_status, s.liveness_status, s.final_decision,
+                   s.id_card_url, s.selfie_with_id_url, s.recorded_video_url,
+                   s.face_match_overall, s.liveness_overall, s.rejection_reason,
+                   s.metadata, s.created_at, s.updated_at,
+                   a.flags, c.reviewer, c.claimed_at, c.expires_at, `+reviewScore+` AS score
+            FROM ekyc_sessions s
+            LEFT JOIN applications a ON a.id = s.id::text
+            LEFT JOIN ekyc_review_claims c ON c.ekyc_session_id = s.id AND c.expires_at > NOW()
+            WHERE s.final_decision = 'PENDING'
+              AND (s.status = 'MANUAL_REVIEW' OR s.face_match_overall IS NOT NULL OR s.liveness_overall IS NOT NULL)
+              AND ($9 OR c.reviewer IS NULL OR c.reviewer = $10)
+        ) ranked
+        WHERE score > 0
+        ORDER BY score DESC, updated_at, id
+        LIMIT $11 OFFSET $12`,
+		params.FaceThreshold, domain.DefaultReviewFaceMargin,
+		domain.ReviewWeightLocked, domain.ReviewWeightResultConflict, domain.ReviewWeightFaceBorderline,
+		domain.ReviewWeightNikMismatch, domain.ReviewWeightOcrDiscrepancy, domain.ReviewWeightRepeatedAttempts,
+		params.IncludeClaimed, params.Reviewer, limit, max(params.Offset, 0))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []domain.ReviewCandidate
+	for rows.Next() {
+		candidate, err := scanReviewCandidate(rows)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, *candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sessions := make([]*domain.EkycSession, len(candidates))
+	for i := range candidates {
+		sessions[i] = &candidates[i].Session
+	}
+	if err := repo.enrichEkycSessions(ctx, sessions); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+func (repo *backofficeRepository) GetReviewCandidate(ctx context.Context, sessionID string) (*domain.ReviewCandidate, error) {
 	row := repo.db.QueryRow(ctx, `
-        SELECT id, ekyc_session_id, overall_result, per_gesture_result, recorded_video_url, raw_metadata, created_at
-        FROM liveness_checks
+        SELECT s.id, s.user_id, s.status, s.face_matching_status, s.liveness_status, s.final_decision,
+               s.id_card_url, s.selfie_with_id_url, s.recorded_video_url,
+               s.face_match_overall, s.liveness_overall, s.rejection_reason,
+               s.metadata, s.created_at, s.updated_at,
+               a.flags, c.reviewer, c.claimed_at, c.expires_at
+        FROM ekyc_sessions s
+        LEFT JOIN applications a ON a.id = s.id::text
+        LEFT JOIN ekyc_review_claims c ON c.ekyc_session_id = s.id AND c.expires_at > NOW()
+        WHERE s.id = $1`, sessionID)
+	candidate, err := scanReviewCandidate(row)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if err := repo.enrichEkycSession(ctx, &candidate.Session); err != nil {
+		return nil, err
+	}
+	return candidate, nil
+}
+
+func scanReviewCandidate(row pgx.Row) (*domain.ReviewCandidate, error) {
+	var (
+		candidate domain.ReviewCandidate
+		session   = &candidate.Session
+		metadata  []byte
+		flags     []byte
+		reviewer  *string
+		claimedAt *time.Time
+		expiresAt *time.Time
+	)
+	if err := row.Scan(
+		&session.ID, &session.UserID, &session.Status, &session.FaceMatchingStatus, &session.LivenessStatus, &session.FinalDecision,
+		&session.IDCardURL, &session.SelfieWithIDURL, &session.RecordedVideoURL,
+		&session.FaceMatchOverall, &session.LivenessOverall, &session.RejectionReason,
+		&metadata, &session.CreatedAt, &session.UpdatedAt,
+		&flags, &reviewer, &claimedAt, &expiresAt,
+	); err != nil {
+		return nil, err
+	}
+	session.Metadata = decodeJSON(metadata)
+	candidate.Flags = decodeJSON(flags)
+	if reviewer != nil && claimedAt != nil && expiresAt != nil {
+		candidate.Claim = &domain.ReviewClaim{
+			SessionID: session.ID,
+			Reviewer:  *reviewer,
+			ClaimedAt: *claimedAt,
+			ExpiresAt: *expiresAt,
+		}
+	}
+	return &candidate, nil
+}
+
+// ClaimReviewSession takes or renews the claim on a session. A claim held by another
+// reviewer blocks until it expires.
+func (repo *backofficeRepository) ClaimReviewSession(ctx context.Context, sessionID, reviewer string, ttl time.Duration) (*domain.ReviewClaim, error) {
+	claim := domain.ReviewClaim{SessionID: sessionID}
+	err := repo.db.QueryRow(ctx, `
+        INSERT INTO ekyc_review_claims (ekyc_session_id, reviewer, claimed_at, expires_at)
+        VALUES ($1, $2, NOW(), NOW() + make_interval(secs => $3))
+        ON CONFLICT (ekyc_session_id) DO UPDATE
+            SET reviewer = EXCLUDED.reviewer,
+                claimed_at = CASE WHEN ekyc_review_claims.reviewer = EXCLUDED.reviewer
+                                  THEN ekyc_review_claims.claimed_at ELSE EXCLUDED.claimed_at END,
+                expires_at = EXCLUDED.expires_at
+            WHERE ekyc_review_claims.reviewer = EXCLUDED.reviewer
+               OR ekyc_review_claims.expires_at <= NOW()
+        RETURNING reviewer, claimed_at, expires_at`,
+		sessionID, reviewer, ttl.Seconds(),
+	).Scan(&claim.Reviewer, &claim.ClaimedAt, &claim.ExpiresAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, domain.ErrReviewClaimed
+		case errors.As(err, &pgErr) && pgErr.Code == "23503":
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &claim, nil
+}
+
+func (repo *backofficeRepository) ReleaseReviewClaim(ctx context.Context, sessionID, reviewer string) error {
+	tag, err := repo.db.Exec(ctx, `
+        DELETE FROM ekyc_review_claims
+        WHERE ekyc_session_id = $1 AND (reviewer = $2 OR expires_at <= NOW())`,
+		sessionID, reviewer)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		var claimed bool
+		if err := repo.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM ekyc_review_claims WHERE ekyc_session_id = $1)`, sessionID).Scan(&claimed); err != nil {
+			return err
+		}
+		if claimed {
+			return domain.ErrReviewClaimed
+		}
+	}
+	return nil
+}
+
+// RecordReviewVerdict decides a PENDING session the way UpdateEkycDecision does, closes the
+// reviewer's claim and stores the verdict with its timeline and audit entries, all in one
+// transaction. The claim must still be held by the reviewer.
+func (repo *backofficeRepository) RecordReviewVerdict(ctx context.Context, params domain.ReviewVerdictParams) (*domain.EkycSession, error) {
+	signals := params.Signals
+	if signals == nil {
+		signals = []domain.ReviewSignal{}
+	}
+	signalsJSON, _ := json.Marshal(signals)
+	_ = repo.ensureApplicationFromSession(ctx, params.SessionID)
+	var session *domain.EkycSession
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		var previous string
+		if err := tx.QueryRow(ctx, `SELECT final_decision FROM ekyc_sessions WHERE id = $1 FOR UPDATE`, params.SessionID).Scan(&previous); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+		if !strings.EqualFold(previous, "PENDING") {
+			return fmt.Errorf("%w: sesi sudah diputuskan %s", domain.ErrInvalidState, previous)
+		}
+		tag, err := tx.Exec(ctx, `
+            DELETE FROM ekyc_review_claims
+            WHERE ekyc_session_id = $1 AND reviewer = $2 AND expires_at > NOW()`,
+			params.SessionID, params.Reviewer)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("%w: klaim sesi tidak aktif", domain.ErrReviewClaimed)
+		}
+
+		row := tx.QueryRow(ctx, `
+            UPDATE ekyc_sessions
+            SET final_decision = $2,
+                status = 'COMPLETED',
+                rejection_reason = $3,
+                updated_at = NOW()
+            WHERE id = $1
+            RETURNING id, user_id, status, face_matching_status, liveness_status, final_decision,
+                      id_card_url, selfie_with_id_url, recorded_video_url,
+                      face_match_overall, liveness_overall, rejection_reason,
+                      metadata, created_at, updated_at`,
+			params.SessionID, params.Verdict, params.Reason,
+		)
+		result, err := repo.scanEkycSessionRow(row)
+		if err != nil {
+			return err
+		}
+		session = result
+		if err := repo.insertSessionEvent(ctx, tx, session, domain.EkycEventDecisionMade, map[string]any{
+			"reviewer": params.Reviewer,
+		}); err != nil {
+			return err
+		}
+		if err := repo.syncApplicationStatus(ctx, tx, params.SessionID, params.Verdict); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO ekyc_review_verdicts (ekyc_session_id, verdict, reason, reviewer, reviewer_role, signals)
+            VALUES ($1,$2,$3,$4,$5,$6::jsonb)`,
+			params.SessionID, params.Verdict, params.Reason, params.Reviewer, params.ReviewerRole, signalsJSON,
+		); err != nil {
+			return err
+		}
+		var hasApplication bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM applications WHERE id = $1)`, params.SessionID).Scan(&hasApplication); err != nil {
+			return err
+		}
+		if hasApplication {
+			if err := repo.insertTimeline(ctx, tx, params.Timeline); err != nil {
+				return err
+			}
+		}
+		if err := repo.insertAudit(ctx, tx, params.Audit); err != nil {
+			return err
+		}
+		return enqueueWebhookEvent(ctx, tx, domain.WebhookEventEkycFinalized, finalizedWebhookData(session, nil))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.enrichEkycSession(ctx, session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (repo *backofficeRepository) ListReviewVerdicts(ctx context.Context, sessionID string) ([]domain.ReviewVerdict, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT id, ekyc_session_id, verdict, reason, reviewer, reviewer_role, signals, created_at
+        FROM ekyc_review_verdicts
         WHERE ekyc_session_id = $1
-        ORDER BY created_at DESC
-        LIMIT 1`, sessionID)
+        ORDER BY created_at DESC`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	verdicts := []domain.ReviewVerdict{}
+	for rows.Next() {
+		var (
+			verdict domain.ReviewVerdict
+			signals []byte
+		)
+		if err := rows.Scan(&verdict.ID, &verdict.SessionID, &verdict.Verdict, &verdict.Reason,
+			&verdict.Reviewer, &verdict.ReviewerRole, &signals, &verdict.CreatedAt); err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal(signals, &verdict.Signals)
+		verdicts = append(verdicts, verdict)
+	}
+	return verdicts, rows.Err()
+}
+
+func (repo *backofficeRepository) ListDecisionOverrideReasons(ctx context.Context) ([]domain.DecisionOverrideReason, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT code, label, active
+        FROM decision_override_reasons
+        WHERE active
+        ORDER BY code`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var reasons []domain.DecisionOverrideReason
+	for rows.Next() {
+		var reason domain.DecisionOverrideReason
+		if err := rows.Scan(&reason.Code, &reason.Label, &reason.Active); err != nil {
+			return nil, err
+		}
+		reasons = append(reasons, reason)
+	}
+	return reasons, rows.Err()
+}
+
+func (repo *backofficeRepository) fetchDecisionOverrides(ctx context.Context, sessionIDs []string) (map[string][]domain.DecisionOverride, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT id, ekyc_session_id, previous_decision, new_decision, reason_code, reason_text, actor, actor_role, created_at
+        FROM ekyc_decision_overrides
+        WHERE ekyc_session_id = ANY($1::uuid[])
+        ORDER BY ekyc_session_id, created_at ASC, id ASC`, sessionIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	overrides := map[string][]domain.DecisionOverride{}
+	for rows.Next() {
+		var o domain.DecisionOverride
+		if err := rows.Scan(&o.ID, &o.SessionID, &o.PreviousDecision, &o.NewDecision, &o.ReasonCode,
+			&o.ReasonText, &o.Actor, &o.ActorRole, &o.CreatedAt); err != nil {
+			return nil, err
+		}
+		overrides[o.SessionID] = append(overrides[o.SessionID], o)
+	}
+	return overrides, rows.Err()
+}
+
+// SaveOcrResult replaces the session's OCR result and copies the comparison score and
+// discrepant fields onto the linked application.
+func (repo *backofficeRepository) SaveOcrResult(ctx context.Context, result domain.OcrResult) (*domain.OcrResult, error) {
+	confidence, _ := json.Marshal(result.Confidence)
+	if result.Confidence == nil {
+		confidence = []byte(`{}`)
+	}
+	raw := []byte(`{}`)
+	if result.RawMetadata != nil {
+		raw, _ = json.Marshal(result.RawMetadata)
+	}
+	fields := result.Fields
+	if fields == nil {
+		fields = []domain.OcrFieldComparison{}
+	}
+	comparison, _ := json.Marshal(fields)
+	discrepancies := []string{}
+	for _, field := range fields {
+		if !field.Match {
+			discrepancies = append(discrepancies, field.Field)
+		}
+	}
+	flagPatch, _ := json.Marshal(map[string]any{"ocrDiscrepancies": discrepancies})
+	sealed, err := sealOcrResult(repo.keys, result)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.withTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+            INSERT INTO ocr_results (ekyc_session_id, nik_enc, name_enc, birth_date_enc, address_enc,
+                                     confidence, raw_metadata, comparison, score)
+            SELECT id, $2, $3, $4, $5, $6::jsonb, $7::jsonb, $8::jsonb, $9
+            FROM ekyc_sessions WHERE id = $1
+            ON CONFLICT (ekyc_session_id) DO UPDATE SET
+                nik = NULL, name = NULL, birth_date = NULL, address = NULL,
+                nik_enc = EXCLUDED.nik_enc,
+                name_enc = EXCLUDED.name_enc,
+                birth_date_enc = EXCLUDED.birth_date_enc,
+                address_enc = EXCLUDED.address_enc,
+                confidence = EXCLUDED.confidence,
+                raw_metadata = EXCLUDED.raw_metadata,
+                comparison = EXCLUDED.comparison,
+                score = EXCLUDED.score,
+                updated_at = NOW()`,
+			result.SessionID, sealed.nik, sealed.name, sealed.birthDate, sealed.address,
+			confidence, raw, comparison, result.Score,
+		)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrNotFound
+		}
+		_, err = tx.Exec(ctx, `
+            UPDATE applications
+               SET score_ocr = COALESCE($2, score_ocr),
+                   flags = (flags - 'ocrDiscrepancies') || $3::jsonb,
+                   updated_at = NOW()
+             WHERE id = $1`,
+			result.SessionID, result.Score, flagPatch,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetOcrResult(ctx, result.SessionID)
+}
+
+func (repo *backofficeRepository) GetOcrResult(ctx context.Context, sessionID string) (*domain.OcrResult, error) {
 	var (
-		check domain.LivenessCheck
-		per   []byte
-		raw   []byte
-		video sql.NullString
+		result     domain.OcrResult
+		sealed     sealedOcr
+		confidence []byte
+		raw        []byte
+		comparison []byte
+	)
+	err := repo.db.QueryRow(ctx, `
+        SELECT ekyc_session_id, COALESCE(nik_enc, nik), COALESCE(name_enc, name),
+               COALESCE(birth_date_enc, to_char(birth_date, 'YYYY-MM-DD')), COALESCE(address_enc, address),
+               confidence, raw_metadata, comparison, score, created_at, updated_at
+        FROM ocr_results
+        WHERE ekyc_session_id::text = $1`, sessionID).Scan(
+		&result.SessionID, &sealed.nik, &sealed.name, &sealed.birthDate, &sealed.address,
+		&confidence, &raw, &comparison, &result.Score, &result.CreatedAt, &result.UpdatedAt,
 	)
-	if err := row.Scan(&check.ID, &check.SessionID, &check.OverallResult, &per, &video, &raw, &check.CreatedAt); err != nil {
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, nil
+			return nil, domain.ErrNotFound
 		}
 		return nil, err
 	}
-	check.PerGestureResult = decodeJSON(per)
-	if video.Valid {
-		check.RecordedVideoURL = &video.String
+	if err := openOcrResult(repo.keys, sealed, &result); err != nil {
+		return nil, err
 	}
-	check.RawMetadata = decodeJSON(raw)
-	return &check, nil
+	result.Confidence = map[string]float64{}
+	_ = json.Unmarshal(confidence, &result.Confidence)
+	result.RawMetadata = decodeJSON(raw)
+	_ = json.Unmarshal(comparison, &result.Fields)
+	return &result, nil
 }
 
-func scanEkycSessionRow(row pgx.Row) (*domain.EkycSession, error) {
+func (repo *backofficeRepository) enrichEkycSession(ctx context.Context, session *domain.EkycSession) error {
+	return repo.enrichEkycSessions(ctx, []*domain.EkycSession{session})
+}
+
+// enrichEkycSessions loads the check histories and overrides of sessions, one query per
+// kind however many sessions there are.
+func (repo *backofficeRepository) enrichEkycSessions(ctx context.Context, sessions []*domain.EkycSession) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	ids := make([]string, len(sessions))
+	for i, session := range sessions {
+		ids[i] = session.ID
+	}
+	faceHistory, err := repo.fetchFaceCheckHistory(ctx, ids)
+	if err != nil {
+		return err
+	}
+	liveHistory, err := repo.fetchLivenessHistory(ctx, ids)
+	if err != nil {
+		return err
+	}
+	overrides, err := repo.fetchDecisionOverrides(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		history := faceHistory[session.ID]
+		session.FaceCheckHistory = history
+		session.FaceChecks = nil
+		session.FaceAttempts = 0
+		for _, check := range history {
+			if check.IsCurrent {
+				session.FaceChecks = append(session.FaceChecks, check)
+			}
+			if check.AttemptNo > session.FaceAttempts {
+				session.FaceAttempts = check.AttemptNo
+			}
+		}
+
+		live := liveHistory[session.ID]
+		session.LivenessHistory = live
+		session.LivenessCheck = nil
+		session.LivenessAttempts = 0
+		for i := range live {
+			if live[i].IsCurrent && session.LivenessCheck == nil {
+				current := live[i]
+				session.LivenessCheck = &current
+			}
+			if live[i].AttemptNo > session.LivenessAttempts {
+				session.LivenessAttempts = live[i].AttemptNo
+			}
+		}
+
+		session.OverrideHistory = overrides[session.ID]
+	}
+	return nil
+}
+
+func (repo *backofficeRepository) fetchFaceCheckHistory(ctx context.Context, sessionIDs []string) (map[string][]domain.FaceCheck, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT id, ekyc_session_id, step, similarity_score, threshold, result, raw_metadata, attempt_no, is_current, created_at
+        FROM face_checks
+        WHERE ekyc_session_id = ANY($1::uuid[])
+        ORDER BY ekyc_session_id, attempt_no ASC, created_at ASC`, sessionIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := map[string][]domain.FaceCheck{}
+	for rows.Next() {
+		var (
+			check      domain.FaceCheck
+			meta       []byte
+			similarity sql.NullFloat64
+			threshold  sql.NullFloat64
+		)
+		if err := rows.Scan(&check.ID, &check.SessionID, &check.Step, &similarity, &threshold, &check.Result, &meta, &check.AttemptNo, &check.IsCurrent, &check.CreatedAt); err != nil {
+			return nil, err
+		}
+		if similarity.Valid {
+			value := similarity.Float64
+			check.Similarity = &value
+		}
+		if threshold.Valid {
+			value := threshold.Float64
+			check.Threshold = &value
+		}
+		check.RawMetadata = decodeJSON(meta)
+		checks[check.SessionID] = append(checks[check.SessionID], check)
+	}
+	return checks, rows.Err()
+}
+
+func (repo *backofficeRepository) fetchLivenessHistory(ctx context.Context, sessionIDs []string) (map[string][]domain.LivenessCheck, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT id, ekyc_session_id, overall_result, per_gesture_result, recorded_video_url, raw_metadata, attempt_no, is_current, created_at
+        FROM liveness_checks
+        WHERE ekyc_session_id = ANY($1::uuid[])
+        ORDER BY ekyc_session_id, attempt_no ASC, created_at ASC`, sessionIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := map[string][]domain.LivenessCheck{}
+	for rows.Next() {
+		var (
+			check domain.LivenessCheck
+			per   []byte
+			raw   []byte
+			video sql.NullString
+		)
+		if err := rows.Scan(&check.ID, &check.SessionID, &check.OverallResult, &per, &video, &raw, &check.AttemptNo, &check.IsCurrent, &check.CreatedAt); err != nil {
+			return nil, err
+		}
+		check.PerGestureResult = decodeJSON(per)
+		if video.Valid {
+			check.RecordedVideoURL = &video.String
+		}
+		check.RawMetadata = decodeJSON(raw)
+		checks[check.SessionID] = append(checks[check.SessionID], check)
+	}
+	return checks, rows.Err()
+}
+
+// scanEkycSessionRow scans a session and opens the sealed applicant in its metadata.
+func (repo *backofficeRepository) scanEkycSessionRow(row pgx.Row) (*domain.EkycSession, error) {
 	var (
 		session       domain.EkycSession
 		userID        sql.NullString
@@ -1953,6 +3023,9 @@ func scanEkycSessionRow(row pgx.Row) (*domain.EkycSession, error) {
 		session.RejectionReason = &rejection.String
 	}
 	session.Metadata = decodeJSON(metadataBytes)
+	if err := openApplicant(repo.keys, session.Metadata); err != nil {
+		return nil, fmt.Errorf("session %s: %w", session.ID, err)
+	}
 	return &session, nil
 }
 
@@ -1964,22 +3037,32 @@ func (repo *backofficeRepository) upsertBeneficiaryUser(ctx context.Context, tx
 	if params.Pin == "" {
 		params.Pin = "123456"
 	}
+	sealed, err := repo.keys.SealUser(params.Nik, params.Phone, params.Email)
+	if err != nil {
+		return "", err
+	}
 	var existingID string
 	if params.Phone != "" {
-		var existingNik sql.NullString
-		if err := tx.QueryRow(ctx, `SELECT id, nik FROM users WHERE phone=$1 LIMIT 1`, params.Phone).Scan(&existingID, &existingNik); err == nil {
-			if params.Nik != "" && existingNik.Valid && !strings.EqualFold(existingNik.String, params.Nik) {
+		var existingNik *string
+		if err := tx.QueryRow(ctx, `SELECT id, COALESCE(nik_enc, nik) FROM users WHERE phone_bidx = ANY($1) LIMIT 1`,
+			repo.keys.BlindIndexes(pii.FieldPhone, params.Phone)).Scan(&existingID, &existingNik); err == nil {
+			existingNik, err = openColumn(repo.keys, pii.FieldNIK, existingNik)
+			if err != nil {
+				return "", err
+			}
+			if params.Nik != "" && existingNik != nil && !strings.EqualFold(*existingNik, params.Nik) {
 				return "", fmt.Errorf("nomor HP sudah digunakan oleh pengguna lain")
 			}
-			if err := repo.updateBeneficiaryUser(ctx, tx, existingID, params); err != nil {
+			if err := repo.updateBeneficiaryUser(ctx, tx, existingID, params, sealed); err != nil {
 				return "", err
 			}
 			return existingID, nil
 		}
 	}
 	if params.Nik != "" && existingID == "" {
-		if err := tx.QueryRow(ctx, `SELECT id FROM users WHERE nik=$1 LIMIT 1`, params.Nik).Scan(&existingID); err == nil {
-			if err := repo.updateBeneficiaryUser(ctx, tx, existingID, params); err != nil {
+		if err := tx.QueryRow(ctx, `SELECT id FROM users WHERE nik_bidx = ANY($1) LIMIT 1`,
+			repo.keys.BlindIndexes(pii.FieldNIK, params.Nik)).Scan(&existingID); err == nil {
+			if err := repo.updateBeneficiaryUser(ctx, tx, existingID, params, sealed); err != nil {
 				return "", err
 			}
 			return existingID, nil
@@ -1996,18 +3079,22 @@ func (repo *backofficeRepository) upsertBeneficiaryUser(ctx context.Context, tx
 	meta, _ := json.Marshal(map[string]any{
 		"address": params.Address,
 	})
-	_, err := tx.Exec(ctx, `
+	_, err = tx.Exec(ctx, `
         INSERT INTO users (
-            id, role, nik, name, dob, phone, email, pin_hash,
+            id, role, name, dob, pin_hash,
             region_prov, region_kab, region_kec, region_kel,
-            region_scope, metadata
+            region_scope, metadata,
+            nik_enc, nik_bidx, phone_enc, phone_bidx, email_enc, email_bidx
         ) VALUES (
-            $1,'BENEFICIARY',$2,$3,$4,$5,$6,$7,
-            '', '', '', '',
-            ARRAY[]::text[], $8::jsonb
+            $1,'BENEFICIARY',$2,$3,$4,
+            $6, $7, $8, $9,
+            ARRAY[]::text[], $5::jsonb,
+            $10, $11, $12, $13, $14, $15
         )`,
-		id, nullableString(params.Nik), params.FullName, dob, nullableString(params.Phone), nullableString(params.Email),
+		id, params.FullName, dob,
 		fmt.Sprintf("plain:%s", params.Pin), meta,
+		params.Region.Prov, params.Region.Kab, params.Region.Kec, params.Region.Kel,
+		sealed.NIK.Sealed, sealed.NIK.Index, sealed.Phone.Sealed, sealed.Phone.Index, sealed.Email.Sealed, sealed.Email.Index,
 	)
 	if err != nil {
 		return "", err
@@ -2015,7 +3102,7 @@ func (repo *backofficeRepository) upsertBeneficiaryUser(ctx context.Context, tx
 	return id, nil
 }
 
-func (repo *backofficeRepository) updateBeneficiaryUser(ctx context.Context, tx pgx.Tx, id string, params domain.ApplicantSubmission) error {
+func (repo *backofficeRepository) updateBeneficiaryUser(ctx context.Context, tx pgx.Tx, id string, params domain.ApplicantSubmission, sealed pii.UserColumns) error {
 	var dob interface{}
 	if params.BirthDate != nil {
 		dob = params.BirthDate
@@ -2028,33 +3115,43 @@ func (repo *backofficeRepository) updateBeneficiaryUser(ctx context.Context, tx
 	_, err := tx.Exec(ctx, `
         UPDATE users
            SET name = COALESCE(NULLIF($2, ''), name),
-               nik = COALESCE(NULLIF($3, ''), nik),
+               nik_enc = COALESCE($3, nik_enc),
+               nik_bidx = COALESCE($13, nik_bidx),
+               nik = CASE WHEN $3::text IS NULL THEN nik END,
                dob = COALESCE($4, dob),
-               phone = COALESCE(NULLIF($5, ''), phone),
-               email = COALESCE(NULLIF($6, ''), email),
+               phone_enc = COALESCE($5, phone_enc),
+               phone_bidx = COALESCE($14, phone_bidx),
+               phone = CASE WHEN $5::text IS NULL THEN phone END,
+               email_enc = COALESCE($6, email_enc),
+               email_bidx = COALESCE($15, email_bidx),
+               email = CASE WHEN $6::text IS NULL THEN email END,
                pin_hash = $7,
                metadata = COALESCE(metadata, '{}'::jsonb) || $8::jsonb,
+               region_prov = COALESCE(NULLIF($9, ''), region_prov),
+               region_kab = COALESCE(NULLIF($10, ''), region_kab),
+               region_kec = COALESCE(NULLIF($11, ''), region_kec),
+               region_kel = COALESCE(NULLIF($12, ''), region_kel),
                updated_at = NOW()
          WHERE id = $1`,
 		id,
 		params.FullName,
-		params.Nik,
+		sealed.NIK.Sealed,
 		dob,
-		params.Phone,
-		params.Email,
+		sealed.Phone.Sealed,
+		sealed.Email.Sealed,
 		fmt.Sprintf("plain:%s", params.Pin),
 		meta,
+		params.Region.Prov,
+		params.Region.Kab,
+		params.Region.Kec,
+		params.Region.Kel,
+		sealed.NIK.Index,
+		sealed.Phone.Index,
+		sealed.Email.Index,
 	)
 	return err
 }
 
-func nullableString(value string) interface{} {
-	if strings.TrimSpace(value) == "" {
-		return nil
-	}
-	return value
-}
-
 func (repo *backofficeRepository) findLatestSessionByUser(ctx context.Context, userID string) (*domain.EkycSession, error) {
 	row := repo.db.QueryRow(ctx, `
         SELECT id, user_id, status, face_matching_status, liveness_status, final_decision,
@@ -2065,7 +3162,7 @@ func (repo *backofficeRepository) findLatestSessionByUser(ctx context.Context, u
         WHERE user_id = $1
         ORDER BY updated_at DESC
         LIMIT 1`, userID)
-	return scanEkycSessionRow(row)
+	return repo.scanEkycSessionRow(row)
 }
 
 var phoneDigits = regexp.MustCompile(`\D+`)
@@ -2105,6 +3202,9 @@ func (repo *backofficeRepository) ensureApplicationFromSession(ctx context.Conte
 	var app applicantMeta
 	if len(metadata) > 0 {
 		metaMap := decodeJSON(metadata)
+		if err := openApplicant(repo.keys, metaMap); err != nil {
+			return err
+		}
 		if raw, ok := metaMap["applicant"]; ok {
 			if b, err := json.Marshal(raw); err == nil {
 				_ = json.Unmarshal(b, &app)
@@ -2175,7 +3275,11 @@ func (repo *backofficeRepository) upsertApplicationFromSession(ctx context.Conte
 	}
 	nikMask := maskNikValue(params.Nik)
 	phoneMask := maskPhoneValue(params.Phone)
-	flags := []byte(`{}`)
+	// A nil flags patch keeps whatever NIK findings the application already has.
+	var flags []byte
+	if params.Flags != nil {
+		flags, _ = json.Marshal(params.Flags)
+	}
 
 	execFn := repo.db.Exec
 	if tx != nil {
@@ -2190,9 +3294,11 @@ func (repo *backofficeRepository) upsertApplicationFromSession(ctx context.Conte
         ) VALUES (
             $1, $2, $3, $4, $5,
             $6, 'DESK_REVIEW', 'KYC', NULL, 0,
-            0, 0, '', $7::jsonb, NOW(), NOW()
+            0, 0, '', COALESCE($7::jsonb, '{}'::jsonb), NOW(), NOW()
         )
         ON CONFLICT (id) DO UPDATE SET
+            flags = CASE WHEN $7::jsonb IS NULL THEN applications.flags
+                         ELSE (applications.flags - 'nikMismatches') || $7::jsonb END,
             beneficiary_user_id = EXCLUDED.beneficiary_user_id,
             applicant_name = EXCLUDED.applicant_name,
             applicant_nik_mask = EXCLUDED.applicant_nik_mask,
@@ -2207,7 +3313,7 @@ func (repo *backofficeRepository) upsertApplicationFromSession(ctx context.Conte
 	return err
 }
 
-func (repo *backofficeRepository) syncApplicationStatus(ctx context.Context, sessionID, finalDecision string) error {
+func (repo *backofficeRepository) syncApplicationStatus(ctx context.Context, tx pgx.Tx, sessionID, finalDecision string) error {
 	status := "DESK_REVIEW"
 	stage := "KYC"
 	switch strings.ToUpper(finalDecision) {
@@ -2218,13 +3324,46 @@ func (repo *backofficeRepository) syncApplicationStatus(ctx context.Context, ses
 		status = "REJECTED"
 		stage = "CLOSED"
 	}
-	_, err := repo.db.Exec(ctx, `
-        UPDATE applications
+	var q execer = repo.db
+	var rq rowQueryer = repo.db
+	if tx != nil {
+		q = tx
+		rq = tx
+	}
+	if status == "FINAL_APPROVED" {
+		// With requireVerifiedVisit on, an approved eKYC leaves the application at the
+		// field visit and staff finalize it once a supervisor verified the visit.
+		waits, err := awaitsVerifiedVisit(ctx, rq, sessionID)
+		if err != nil {
+			return err
+		}
+		if waits {
+			status = "FIELD_VISIT"
+			stage = "FIELD"
+		}
+	}
+	var previous string
+	err := rq.QueryRow(ctx, `
+        UPDATE applications a
            SET status = $2,
                stage = $3,
                updated_at = NOW()
-         WHERE id = $1`, sessionID, status, stage)
-	return err
+          FROM (SELECT id, status FROM applications WHERE id = $1 FOR UPDATE) prev
+         WHERE a.id = prev.id
+        RETURNING prev.status`, sessionID, status, stage).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && previous == status) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return enqueueWebhookEvent(ctx, q, domain.WebhookEventApplicationStatusChanged, map[string]any{
+		"applicationId":  sessionID,
+		"previousStatus": previous,
+		"status":         status,
+		"stage":          stage,
+		"finalDecision":  strings.ToUpper(finalDecision),
+	})
 }
 
 type userBasics struct {
@@ -2244,7 +3383,7 @@ func (repo *backofficeRepository) fetchUserBasics(ctx context.Context, userID st
 		email *string
 	)
 	err := repo.db.QueryRow(ctx, `
-        SELECT id, name, nik, dob, phone, email
+        SELECT id, name, COALESCE(nik_enc, nik), dob, COALESCE(phone_enc, phone), COALESCE(email_enc, email)
           FROM users
          WHERE id = $1
          LIMIT 1`, userID).Scan(&u.ID, &u.Name, &u.NIK, &dob, &phone, &email)
@@ -2252,7 +3391,14 @@ func (repo *backofficeRepository) fetchUserBasics(ctx context.Context, userID st
 		return nil, err
 	}
 	u.DOB = dob
-	u.Phone = phone
-	u.Email = email
+	if u.NIK, err = openColumn(repo.keys, pii.FieldNIK, u.NIK); err != nil {
+		return nil, err
+	}
+	if u.Phone, err = openColumn(repo.keys, pii.FieldPhone, phone); err != nil {
+		return nil, err
+	}
+	if u.Email, err = openColumn(repo.keys, pii.FieldEmail, email); err != nil {
+		return nil, err
+	}
 	return &u, nil
 }
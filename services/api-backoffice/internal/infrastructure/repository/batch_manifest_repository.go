@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (repo *backofficeRepository) GetBatch(ctx context.Context, batchID string) (*domain.Batch, error) {
+	var batch domain.Batch
+	if err := repo.db.QueryRow(ctx, `
+        SELECT id, code, status, checksum, locked_at, created_at, updated_at
+        FROM batches
+        WHERE id = $1`, batchID,
+	).Scan(&batch.ID, &batch.Code, &batch.Status, &batch.Checksum, &batch.LockedAt, &batch.CreatedAt, &batch.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	items, err := repo.fetchBatchItems(ctx, batch.ID)
+	if err != nil {
+		return nil, err
+	}
+	batch.Items = items
+	return &batch, nil
+}
+
+func (repo *backofficeRepository) ListBatchManifestSources(ctx context.Context, batchID string) ([]domain.BatchManifestItem, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT bi.application_id, COALESCE(a.applicant_nik_mask, ''), COALESCE(b.bansos_utama, '')
+        FROM batch_items bi
+        JOIN applications a ON a.id = bi.application_id
+        LEFT JOIN beneficiaries b ON b.user_id = a.beneficiary_user_id
+        WHERE bi.batch_id = $1
+        ORDER BY bi.application_id`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []domain.BatchManifestItem
+	for rows.Next() {
+		var item domain.BatchManifestItem
+		if err := rows.Scan(&item.ApplicationID, &item.NikMask, &item.Program); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (repo *backofficeRepository) GetBatchManifest(ctx context.Context, batchID string) (*domain.BatchManifest, error) {
+	var manifest domain.BatchManifest
+	if err := repo.db.QueryRow(ctx, `
+        SELECT id, code, status, checksum, locked_at
+        FROM batches
+        WHERE id = $1`, batchID,
+	).Scan(&manifest.BatchID, &manifest.Code, &manifest.Status, &manifest.Checksum, &manifest.LockedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	rows, err := repo.db.Query(ctx, `
+        SELECT application_id, COALESCE(nik_mask, ''), COALESCE(program, ''), COALESCE(amount, 0), COALESCE(currency, '')
+        FROM batch_items
+        WHERE batch_id = $1
+        ORDER BY application_id`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	manifest.Items = []domain.BatchManifestItem{}
+	for rows.Next() {
+		var item domain.BatchManifestItem
+		if err := rows.Scan(&item.ApplicationID, &item.NikMask, &item.Program, &item.Amount, &item.Currency); err != nil {
+			return nil, err
+		}
+		manifest.Items = append(manifest.Items, item)
+	}
+	return &manifest, rows.Err()
+}
+
+func (repo *backofficeRepository) ReplaceBatchItems(ctx context.Context, params domain.ReplaceBatchItemsParams) error {
+	return repo.withTx(ctx, func(tx pgx.Tx) error {
+		if err := lockDraftBatch(ctx, tx, params.BatchID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM batch_items WHERE batch_id = $1`, params.BatchID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO batch_items (batch_id, application_id)
+            SELECT $1, unnest($2::text[])`, params.BatchID, params.Items); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE batches SET updated_at = NOW() WHERE id = $1`, params.BatchID); err != nil {
+			return err
+		}
+		return repo.insertAudit(ctx, tx, params.Audit)
+	})
+}
+
+func (repo *backofficeRepository) RecordBatchAudit(ctx context.Context, audit domain.AuditEntry) error {
+	return repo.withTx(ctx, func(tx pgx.Tx) error {
+		return repo.insertAudit(ctx, tx, audit)
+	})
+}
+
+// lockDraftBatch takes the batch row lock and fails unless the batch is still an
+// unlocked DRAFT, the only state in which its items may change.
+func lockDraftBatch(ctx context.Context, tx pgx.Tx, batchID string) error {
+	var (
+		code, status string
+		locked       bool
+	)
+	if err := tx.QueryRow(ctx, `
+        SELECT code, status, locked_at IS NOT NULL
+        FROM batches
+        WHERE id = $1
+        FOR UPDATE`, batchID,
+	).Scan(&code, &status, &locked); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	if locked || status != domain.BatchStatusDraft {
+		return fmt.Errorf("%w: batch %s sudah dikunci", domain.ErrInvalidState, code)
+	}
+	return nil
+}
+
+// lockBatchItems snapshots the manifest onto the batch items. The manifest must cover
+// exactly the items the batch holds, otherwise they changed while it was being built.
+func lockBatchItems(ctx context.Context, tx pgx.Tx, batchID string, manifest []domain.BatchManifestItem) error {
+	n := len(manifest)
+	var (
+		appIDs     = make([]string, n)
+		nikMasks   = make([]string, n)
+		programs   = make([]string, n)
+		amounts    = make([]int64, n)
+		currencies = make([]string, n)
+	)
+	for i, item := range manifest {
+		appIDs[i], nikMasks[i], programs[i], amounts[i], currencies[i] = item.ApplicationID, item.NikMask, item.Program, item.Amount, item.Currency
+	}
+	tag, err := tx.Exec(ctx, `
+        UPDATE batch_items bi
+        SET nik_mask = t.nik_mask, program = t.program, amount = t.amount, currency = t.currency
+        FROM unnest($2::text[], $3::text[], $4::text[], $5::bigint[], $6::text[])
+            AS t(application_id, nik_mask, program, amount, currency)
+        WHERE bi.batch_id = $1 AND bi.application_id = t.application_id`,
+		batchID, appIDs, nikMasks, programs, amounts, currencies)
+	if err != nil {
+		return err
+	}
+	var total int
+	if err := tx.QueryRow(ctx, `SELECT count(*) FROM batch_items WHERE batch_id = $1`, batchID).Scan(&total); err != nil {
+		return err
+	}
+	if int(tag.RowsAffected()) != n || total != n {
+		return fmt.Errorf("%w: item batch berubah saat dikunci, ulangi", domain.ErrInvalidState)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (repo *backofficeRepository) ListBatchItemResults(ctx context.Context, batchID string) (map[string]domain.BatchItemResult, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT application_id, result, COALESCE(result_reason, ''), COALESCE(bank_reference, '')
+        FROM batch_items
+        WHERE batch_id = $1 AND result IS NOT NULL`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := map[string]domain.BatchItemResult{}
+	for rows.Next() {
+		var (
+			appID  string
+			result domain.BatchItemResult
+		)
+		if err := rows.Scan(&appID, &result.Status, &result.Reason, &result.BankReference); err != nil {
+			return nil, err
+		}
+		results[appID] = result
+	}
+	return results, rows.Err()
+}
+
+func (repo *backofficeRepository) RecordBatchReconciliation(ctx context.Context, params domain.RecordBatchReconciliationParams) error {
+	reconciliation := params.Reconciliation
+	report, err := json.Marshal(reconciliation.Report)
+	if err != nil {
+		return err
+	}
+	return repo.withTx(ctx, func(tx pgx.Tx) error {
+		var status string
+		if err := tx.QueryRow(ctx, `SELECT status FROM batches WHERE id = $1 FOR UPDATE`, reconciliation.BatchID).Scan(&status); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+		if status != domain.BatchStatusSent {
+			return fmt.Errorf("%w: status batch sudah berubah menjadi %s", domain.ErrInvalidState, status)
+		}
+		for _, outcome := range params.Outcomes {
+			result := outcome.Result
+			// Another import may have got there first, in which case this report is stale.
+			tag, err := tx.Exec(ctx, `
+                UPDATE batch_items
+                SET result = $3, result_reason = NULLIF($4, ''), bank_reference = NULLIF($5, ''), reconciled_at = NOW()
+                WHERE batch_id = $1 AND application_id = $2 AND COALESCE(result, '') = $6`,
+				reconciliation.BatchID, result.ApplicationID, result.Status, result.Reason, result.BankReference, outcome.Previous)
+			if err != nil {
+				return err
+			}
+			if tag.RowsAffected() == 0 {
+				return fmt.Errorf("%w: hasil %s berubah saat impor, ulangi impor", domain.ErrInvalidState, result.ApplicationID)
+			}
+			if err := repo.updateApplicationStatus(ctx, tx, domain.UpdateApplicationStatusParams{
+				AppID:    result.ApplicationID,
+				Status:   result.Status,
+				Timeline: outcome.Timeline,
+				Audit:    outcome.Audit,
+			}); err != nil {
+				return err
+			}
+		}
+		if err := tx.QueryRow(ctx, `
+            INSERT INTO batch_reconciliations (batch_id, format, filename, media_url, checksum, report, created_by)
+            VALUES ($1,$2,$3,$4,$5,$6::jsonb,$7)
+            RETURNING id, created_at`,
+			reconciliation.BatchID, reconciliation.Format, reconciliation.Filename, reconciliation.URL,
+			reconciliation.Checksum, report, reconciliation.CreatedBy,
+		).Scan(&reconciliation.ID, &reconciliation.CreatedAt); err != nil {
+			return err
+		}
+		return repo.insertAudit(ctx, tx, params.Audit)
+	})
+}
+
+func (repo *backofficeRepository) ListBatchReconciliations(ctx context.Context, batchID string) ([]domain.BatchReconciliation, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT id, batch_id, format, filename, media_url, checksum, report, created_by, created_at
+        FROM batch_reconciliations
+        WHERE batch_id = $1
+        ORDER BY created_at DESC, id DESC`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reconciliations := []domain.BatchReconciliation{}
+	for rows.Next() {
+		var (
+			reconciliation domain.BatchReconciliation
+			report         []byte
+		)
+		if err := rows.Scan(&reconciliation.ID, &reconciliation.BatchID, &reconciliation.Format, &reconciliation.Filename,
+			&reconciliation.URL, &reconciliation.Checksum, &report, &reconciliation.CreatedBy, &reconciliation.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(report, &reconciliation.Report); err != nil {
+			return nil, fmt.Errorf("reconciliation %d report: %w", reconciliation.ID, err)
+		}
+		reconciliations = append(reconciliations, reconciliation)
+	}
+	return reconciliations, rows.Err()
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (repo *backofficeRepository) ListVisitHouseholds(ctx context.Context, appIDs []string) (map[string]domain.VisitHousehold, error) {
+	households := make(map[string]domain.VisitHousehold, len(appIDs))
+	if len(appIDs) == 0 {
+		return households, nil
+	}
+	// Located like route stops, by the latest visit geotag the geofence did not flag.
+	rows, err := repo.db.Query(ctx, `
+        SELECT a.id, a.applicant_name, u.region_prov, u.region_kab, u.region_kec, u.region_kel,
+               h.geotag_lat, h.geotag_lng
+        FROM applications a
+        LEFT JOIN users u ON u.id = a.beneficiary_user_id
+        LEFT JOIN LATERAL (
+            SELECT p.geotag_lat, p.geotag_lng
+            FROM application_visits p
+            WHERE p.application_id = a.id AND p.status IN ($2, $3)
+              AND p.geotag_lat IS NOT NULL AND p.geotag_lng IS NOT NULL
+              AND cardinality(p.geofence_flags) = 0
+            ORDER BY p.scheduled_at DESC
+            LIMIT 1
+        ) h ON TRUE
+        WHERE a.id = ANY($1::text[])`, appIDs, domain.VisitStatusSubmitted, domain.VisitStatusVerified)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			household           domain.VisitHousehold
+			prov, kab, kec, kel *string
+		)
+		if err := rows.Scan(&household.ApplicationID, &household.ApplicantName, &prov, &kab, &kec, &kel,
+			&household.Lat, &household.Lng); err != nil {
+			return nil, err
+		}
+		household.Region = domain.Region{
+			Prov: derefString(prov),
+			Kab:  derefString(kab),
+			Kec:  derefString(kec),
+			Kel:  derefString(kel),
+		}
+		households[household.ApplicationID] = household
+	}
+	return households, rows.Err()
+}
+
+func (repo *backofficeRepository) ListRegionDistributions(ctx context.Context, scope []string, from time.Time) ([]domain.Distribution, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT d.id, d.name, d.scheduled_at, d.channel, d.location, d.status, d.notes, d.created_by, d.created_at, d.updated_by, d.updated_at
+        FROM distributions d
+        WHERE d.scheduled_at >= $2
+          AND (cardinality($1::text[]) = 0
+            OR EXISTS (
+                SELECT 1 FROM unnest($1::text[]) s
+                WHERE trim(s) <> '' AND position(lower(trim(s)) IN lower(d.location)) > 0)
+            OR EXISTS (
+                SELECT 1
+                FROM distribution_beneficiaries db
+                JOIN applications a ON a.id = db.application_id
+                JOIN users u ON u.id = a.beneficiary_user_id
+                WHERE db.distribution_id = d.id AND `+regionScopeFilter(1)+`))
+        ORDER BY d.scheduled_at, d.id`, append([]string{}, scope...), from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []domain.Distribution{}
+	for rows.Next() {
+		var dist domain.Distribution
+		if err := rows.Scan(&dist.ID, &dist.Name, &dist.ScheduledAt, &dist.Channel, &dist.Location, &dist.Status,
+			&dist.Notes, &dist.CreatedBy, &dist.CreatedAt, &dist.UpdatedBy, &dist.UpdatedAt); err != nil {
+			return nil, err
+		}
+		dist.BatchCodes, err = repo.fetchDistributionCodes(ctx, dist.ID)
+		if err != nil {
+			return nil, err
+		}
+		dist.Beneficiaries, dist.Notified, err = repo.fetchDistributionBeneficiaries(ctx, dist.ID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dist)
+	}
+	return result, rows.Err()
+}
+
+func (repo *backofficeRepository) GetCalendarFeedOwner(ctx context.Context, userID string) (*domain.CalendarFeedOwner, error) {
+	var owner domain.CalendarFeedOwner
+	if err := repo.db.QueryRow(ctx, `
+        SELECT id::text, name, role, region_scope
+        FROM users
+        WHERE id::text = $1`, userID,
+	).Scan(&owner.UserID, &owner.Name, &owner.Role, &owner.RegionScope); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &owner, nil
+}
+
+func (repo *backofficeRepository) SaveCalendarFeed(ctx context.Context, userID, tokenHash string) (*domain.CalendarFeed, error) {
+	feed := domain.CalendarFeed{UserID: userID}
+	if err := repo.db.QueryRow(ctx, `
+        INSERT INTO calendar_feeds (user_id, token_hash)
+        VALUES ($1::uuid, $2)
+        ON CONFLICT (user_id) DO UPDATE
+        SET token_hash = EXCLUDED.token_hash, created_at = NOW(), last_fetched_at = NULL
+        RETURNING created_at`, userID, tokenHash,
+	).Scan(&feed.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+func (repo *backofficeRepository) DeleteCalendarFeed(ctx context.Context, userID string) error {
+	tag, err := repo.db.Exec(ctx, `DELETE FROM calendar_feeds WHERE user_id::text = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (repo *backofficeRepository) FindCalendarFeedOwner(ctx context.Context, tokenHash string) (*domain.CalendarFeedOwner, error) {
+	var owner domain.CalendarFeedOwner
+	if err := repo.db.QueryRow(ctx, `
+        SELECT u.id::text, u.name, u.role, u.region_scope
+        FROM calendar_feeds f
+        JOIN users u ON u.id = f.user_id
+        WHERE f.token_hash = $1`, tokenHash,
+	).Scan(&owner.UserID, &owner.Name, &owner.Role, &owner.RegionScope); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &owner, nil
+}
+
+func (repo *backofficeRepository) PublishCalendarFeed(ctx context.Context, userID string, reconcile domain.CalendarFeedReconcile) ([]domain.CalendarFeedEvent, error) {
+	var events []domain.CalendarFeedEvent
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		// Two fetches at once would otherwise both bump the same sequence.
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('calendar_feed:' || $1::text))`, userID); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, `
+            SELECT uid, fingerprint, sequence, label, starts_at, ends_at, cancelled_at
+            FROM calendar_feed_events
+            WHERE user_id::text = $1`, userID)
+		if err != nil {
+			return err
+		}
+		var published []domain.CalendarFeedEvent
+		for rows.Next() {
+			var event domain.CalendarFeedEvent
+			if err := rows.Scan(&event.UID, &event.Fingerprint, &event.Sequence, &event.Label,
+				&event.StartsAt, &event.EndsAt, &event.CancelledAt); err != nil {
+				rows.Close()
+				return err
+			}
+			published = append(published, event)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		events = reconcile(published)
+		n := len(events)
+		var (
+			uids         = make([]string, n)
+			fingerprints = make([]string, n)
+			sequences    = make([]int32, n)
+			labels       = make([]string, n)
+			starts       = make([]time.Time, n)
+			ends         = make([]time.Time, n)
+			cancelled    = make([]*time.Time, n)
+		)
+		for i, event := range events {
+			uids[i], fingerprints[i], sequences[i], labels[i] = event.UID, event.Fingerprint, int32(event.Sequence), event.Label
+			starts[i], ends[i], cancelled[i] = event.StartsAt, event.EndsAt, event.CancelledAt
+		}
+		if _, err := tx.Exec(ctx, `
+            DELETE FROM calendar_feed_events
+            WHERE user_id::text = $1 AND uid <> ALL($2::text[])`, userID, uids); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO calendar_feed_events AS e (user_id, uid, fingerprint, sequence, label, starts_at, ends_at, cancelled_at)
+            SELECT $1::uuid, t.* FROM unnest($2::text[], $3::text[], $4::int[], $5::text[], $6::timestamptz[], $7::timestamptz[], $8::timestamptz[]) AS t
+            ON CONFLICT (user_id, uid) DO UPDATE
+            SET fingerprint = EXCLUDED.fingerprint, sequence = EXCLUDED.sequence, label = EXCLUDED.label,
+                starts_at = EXCLUDED.starts_at, ends_at = EXCLUDED.ends_at, cancelled_at = EXCLUDED.cancelled_at,
+                updated_at = NOW()
+            WHERE (e.fingerprint, e.sequence, e.label, e.cancelled_at)
+                IS DISTINCT FROM (EXCLUDED.fingerprint, EXCLUDED.sequence, EXCLUDED.label, EXCLUDED.cancelled_at)`,
+			userID, uids, fingerprints, sequences, labels, starts, ends, cancelled); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `UPDATE calendar_feeds SET last_fetched_at = NOW() WHERE user_id::text = $1`, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
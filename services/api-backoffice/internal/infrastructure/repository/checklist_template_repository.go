@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const checklistTemplateColumns = `id, program, period, version, status, schema, notes, published_by, published_at, retired_by, retired_at`
+
+func scanChecklistTemplate(row pgx.Row) (*domain.ChecklistTemplate, error) {
+	var (
+		template domain.ChecklistTemplate
+		schema   []byte
+	)
+	if err := row.Scan(&template.ID, &template.Program, &template.Period, &template.Version, &template.Status, &schema,
+		&template.Notes, &template.PublishedBy, &template.PublishedAt, &template.RetiredBy, &template.RetiredAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(schema, &template.Schema); err != nil {
+		return nil, fmt.Errorf("checklist template %d: decode schema: %w", template.ID, err)
+	}
+	return &template, nil
+}
+
+func (repo *backofficeRepository) ListChecklistTemplates(ctx context.Context, params domain.ListChecklistTemplatesParams) ([]domain.ChecklistTemplate, error) {
+	var (
+		builder strings.Builder
+		args    []any
+	)
+	builder.WriteString(`SELECT ` + checklistTemplateColumns + ` FROM checklist_templates WHERE 1=1`)
+	if params.Program != "" {
+		args = append(args, params.Program)
+		builder.WriteString(fmt.Sprintf(" AND program = $%d", len(args)))
+	}
+	if params.Period != "" {
+		args = append(args, params.Period)
+		builder.WriteString(fmt.Sprintf(" AND period = $%d", len(args)))
+	}
+	builder.WriteString(" ORDER BY period DESC, program, version DESC")
+	rows, err := repo.db.Query(ctx, builder.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []domain.ChecklistTemplate{}
+	for rows.Next() {
+		template, err := scanChecklistTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+	return templates, rows.Err()
+}
+
+func (repo *backofficeRepository) GetChecklistTemplate(ctx context.Context, id int64) (*domain.ChecklistTemplate, error) {
+	template, err := scanChecklistTemplate(repo.db.QueryRow(ctx, `
+        SELECT `+checklistTemplateColumns+` FROM checklist_templates WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+func (repo *backofficeRepository) PublishChecklistTemplate(ctx context.Context, params domain.PublishChecklistTemplateParams) (*domain.ChecklistTemplate, error) {
+	schema, err := json.Marshal(params.Schema)
+	if err != nil {
+		return nil, err
+	}
+	var template *domain.ChecklistTemplate
+	err = repo.withTx(ctx, func(tx pgx.Tx) error {
+		// Versions are numbered per program and period, one publisher at a time.
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('checklist_templates:' || $1 || '/' || $2))`,
+			params.Program, params.Period); err != nil {
+			return err
+		}
+		created, err := scanChecklistTemplate(tx.QueryRow(ctx, `
+            INSERT INTO checklist_templates (program, period, version, schema, notes, published_by)
+            SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3::jsonb, NULLIF($4, ''), $5
+            FROM checklist_templates WHERE program = $1 AND period = $2
+            RETURNING `+checklistTemplateColumns,
+			params.Program, params.Period, schema, params.Notes, params.Actor))
+		if err != nil {
+			return err
+		}
+		template = created
+		return repo.insertAudit(ctx, tx, domain.AuditEntry{
+			Actor:  params.Actor,
+			Entity: fmt.Sprintf("CHECKLIST_TEMPLATE:%d", created.ID),
+			Action: "CHECKLIST_TEMPLATE:PUBLISHED",
+			Reason: params.Notes,
+			Metadata: map[string]any{
+				"program": created.Program,
+				"period":  created.Period,
+				"version": created.Version,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (repo *backofficeRepository) RetireChecklistTemplate(ctx context.Context, id int64, actor string) (*domain.ChecklistTemplate, error) {
+	var template *domain.ChecklistTemplate
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		retired, err := scanChecklistTemplate(tx.QueryRow(ctx, `
+            UPDATE checklist_templates SET status = $2, retired_by = $3, retired_at = NOW()
+            WHERE id = $1 AND status = $4
+            RETURNING `+checklistTemplateColumns,
+			id, domain.ChecklistTemplateRetired, actor, domain.ChecklistTemplatePublished))
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+			var exists bool
+			if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM checklist_templates WHERE id = $1)`, id).Scan(&exists); err != nil {
+				return err
+			}
+			if exists {
+				return fmt.Errorf("%w: template %d sudah tidak berlaku", domain.ErrInvalidState, id)
+			}
+			return domain.ErrNotFound
+		}
+		template = retired
+		return repo.insertAudit(ctx, tx, domain.AuditEntry{
+			Actor:  actor,
+			Entity: fmt.Sprintf("CHECKLIST_TEMPLATE:%d", retired.ID),
+			Action: "CHECKLIST_TEMPLATE:RETIRED",
+			Metadata: map[string]any{
+				"program": retired.Program,
+				"period":  retired.Period,
+				"version": retired.Version,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (repo *backofficeRepository) CurrentChecklistTemplate(ctx context.Context, appID string) (*domain.ChecklistTemplate, error) {
+	template, err := scanChecklistTemplate(repo.db.QueryRow(ctx, `
+        SELECT `+checklistTemplateColumns+`
+        FROM checklist_templates
+        WHERE status = $2
+          AND period = (SELECT period FROM system_config WHERE id = 1)
+          AND program IN (
+              COALESCE((SELECT b.bansos_utama FROM applications a
+                        JOIN beneficiaries b ON b.user_id = a.beneficiary_user_id
+                        WHERE a.id = $1), ''),
+              $3)
+        ORDER BY program = $3, version DESC
+        LIMIT 1`, appID, domain.ChecklistTemplatePublished, domain.ChecklistAnyProgram))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+// checkVisitChecklist validates the checklist a write leaves on the visit: checklist if
+// the write replaces it, the stored one otherwise. Visits scheduled before templates
+// existed have none bound and are not checked.
+func checkVisitChecklist(ctx context.Context, tx pgx.Tx, visitID string, checklist map[string]any, submitting bool, check domain.ChecklistCheck) error {
+	var (
+		templateID *int64
+		stored     []byte
+	)
+	if err := tx.QueryRow(ctx, `
+        SELECT checklist_template_id, checklist FROM application_visits WHERE id = $1`, visitID,
+	).Scan(&templateID, &stored); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	if templateID == nil {
+		return nil
+	}
+	template, err := scanChecklistTemplate(tx.QueryRow(ctx, `
+        SELECT `+checklistTemplateColumns+` FROM checklist_templates WHERE id = $1`, *templateID))
+	if err != nil {
+		return err
+	}
+	if checklist == nil {
+		checklist = decodeJSON(stored)
+	}
+	if fields := check(template, checklist, submitting); len(fields) > 0 {
+		return &domain.ChecklistValidationError{TemplateID: template.ID, TemplateVersion: template.Version, Fields: fields}
+	}
+	return nil
+}
@@ -0,0 +1,618 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/pii"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// A data subject's rows are found through the user id: applications by beneficiary, and
+// eKYC sessions either assigned to the user or sharing the id of one of their applications.
+const (
+	subjectApplications = `SELECT id FROM applications WHERE beneficiary_user_id = $1`
+	subjectVisits       = `SELECT id FROM application_visits WHERE application_id IN (` + subjectApplications + `)`
+	subjectSessions     = `SELECT id FROM ekyc_sessions WHERE user_id = $1 OR id::text IN (` + subjectApplications + `)`
+)
+
+// dataSubjectTables lists what an export contains, in bundle order. users is read
+// separately so its PII can be decrypted.
+var dataSubjectTables = []struct {
+	name  string
+	query string
+}{
+	{"beneficiaries", `SELECT to_jsonb(t) FROM beneficiaries t WHERE user_id = $1`},
+	{"applications", `SELECT to_jsonb(t) FROM applications t WHERE beneficiary_user_id = $1 ORDER BY created_at`},
+	{"application_documents", `SELECT to_jsonb(t) FROM application_documents t WHERE application_id IN (` + subjectApplications + `) ORDER BY created_at`},
+	{"application_visits", `SELECT to_jsonb(t) FROM application_visits t WHERE application_id IN (` + subjectApplications + `) ORDER BY scheduled_at`},
+	{"visit_photos", `SELECT to_jsonb(t) FROM visit_photos t WHERE visit_id IN (` + subjectVisits + `) ORDER BY uploaded_at, id`},
+	{"visit_reviews", `SELECT to_jsonb(t) FROM visit_reviews t WHERE visit_id IN (` + subjectVisits + `) ORDER BY created_at, id`},
+	{"application_timeline", `SELECT to_jsonb(t) FROM application_timeline t WHERE application_id IN (` + subjectApplications + `) ORDER BY occurred_at, id`},
+	{"survey_responses", `SELECT to_jsonb(t) FROM survey_responses t WHERE beneficiary_user_id = $1`},
+	{"ekyc_sessions", `SELECT to_jsonb(t) FROM ekyc_sessions t WHERE id IN (` + subjectSessions + `) ORDER BY created_at`},
+	{"face_checks", `SELECT to_jsonb(t) FROM face_checks t WHERE ekyc_session_id IN (` + subjectSessions + `) ORDER BY created_at, id`},
+	{"liveness_checks", `SELECT to_jsonb(t) FROM liveness_checks t WHERE ekyc_session_id IN (` + subjectSessions + `) ORDER BY created_at, id`},
+	{"ocr_results", `SELECT to_jsonb(t) FROM ocr_results t WHERE ekyc_session_id IN (` + subjectSessions + `)`},
+	{"ekyc_artifact_tombstones", `SELECT to_jsonb(t) FROM ekyc_artifact_tombstones t WHERE ekyc_session_id IN (` + subjectSessions + `) ORDER BY purged_at, id`},
+	{"notifications", `SELECT to_jsonb(t) FROM notifications t WHERE user_id = $1 ORDER BY created_at`},
+	{"clustering_candidates", `SELECT to_jsonb(t) FROM clustering_candidates t WHERE user_id = $1 ORDER BY created_at`},
+	{"erasure_requests", `SELECT to_jsonb(t) FROM erasure_requests t WHERE user_id = $1 ORDER BY requested_at`},
+}
+
+// userSecretColumns never leave the database, not even to the subject.
+var userSecretColumns = []string{
+	"pin_hash", "nik", "phone", "email",
+	"nik_enc", "nik_bidx", "phone_enc", "phone_bidx", "email_enc", "email_bidx",
+}
+
+func (repo *backofficeRepository) ExportDataSubject(ctx context.Context, userID, actor string) (*domain.DataSubjectExport, error) {
+	export := &domain.DataSubjectExport{
+		UserID:      userID,
+		GeneratedAt: time.Now().UTC(),
+		GeneratedBy: actor,
+		Records:     map[string][]map[string]any{},
+	}
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		user, err := repo.exportUser(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		export.Records["users"] = []map[string]any{user}
+		for _, table := range dataSubjectTables {
+			records, err := queryJSONRecords(ctx, tx, table.query, userID)
+			if err != nil {
+				return fmt.Errorf("export %s: %w", table.name, err)
+			}
+			export.Records[table.name] = records
+		}
+		for _, beneficiary := range export.Records["beneficiaries"] {
+			if err := openPayoutAccount(repo.keys, beneficiary); err != nil {
+				return fmt.Errorf("beneficiary %s: %w", userID, err)
+			}
+		}
+		for _, ocr := range export.Records["ocr_results"] {
+			if err := openOcrRecord(repo.keys, ocr); err != nil {
+				return fmt.Errorf("ocr %v: %w", ocr["ekyc_session_id"], err)
+			}
+		}
+		for _, session := range export.Records["ekyc_sessions"] {
+			if metadata, ok := session["metadata"].(map[string]any); ok {
+				if err := openApplicant(repo.keys, metadata); err != nil {
+					return fmt.Errorf("session %v: %w", session["id"], err)
+				}
+			}
+		}
+		export.Media = dataSubjectMedia(export.Records)
+
+		counts := make(map[string]any, len(export.Records))
+		for name, records := range export.Records {
+			counts[name] = len(records)
+		}
+		return repo.insertAudit(ctx, tx, domain.AuditEntry{
+			Actor:    actor,
+			Entity:   userID,
+			Action:   "DATA_SUBJECT:EXPORTED",
+			Metadata: map[string]any{"records": counts, "media": len(export.Media)},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+func (repo *backofficeRepository) exportUser(ctx context.Context, tx pgx.Tx, userID string) (map[string]any, error) {
+	var (
+		raw               []byte
+		nik, phone, email *string
+	)
+	err := tx.QueryRow(ctx, `
+        SELECT to_jsonb(t) - $2::text[], COALESCE(nik_enc, nik), COALESCE(phone_enc, phone), COALESCE(email_enc, email)
+        FROM users t
+        WHERE id::text = $1`, userID, userSecretColumns).Scan(&raw, &nik, &phone, &email)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	user := decodeJSON(raw)
+	if nik, err = openColumn(repo.keys, pii.FieldNIK, nik); err != nil {
+		return nil, fmt.Errorf("user %s: %w", userID, err)
+	}
+	if phone, err = openColumn(repo.keys, pii.FieldPhone, phone); err != nil {
+		return nil, fmt.Errorf("user %s: %w", userID, err)
+	}
+	if email, err = openColumn(repo.keys, pii.FieldEmail, email); err != nil {
+		return nil, fmt.Errorf("user %s: %w", userID, err)
+	}
+	user["nik"], user["phone"], user["email"] = nik, phone, email
+	return user, nil
+}
+
+// openPayoutAccount replaces the sealed payout account with the account itself.
+func openPayoutAccount(keys *pii.Keyring, beneficiary map[string]any) error {
+	sealed, _ := beneficiary["payout_account_enc"].(string)
+	delete(beneficiary, "payout_account_enc")
+	if sealed == "" {
+		return nil
+	}
+	account, err := openColumn(keys, pii.FieldPayoutAccount, &sealed)
+	if err != nil {
+		return err
+	}
+	beneficiary["payout_account"] = account
+	return nil
+}
+
+// openOcrRecord replaces the sealed KTP columns of an exported ocr_results row with
+// their plaintext.
+func openOcrRecord(keys *pii.Keyring, ocr map[string]any) error {
+	fields := map[string]string{
+		"nik":        pii.FieldOcrNIK,
+		"name":       pii.FieldOcrName,
+		"birth_date": pii.FieldOcrBirthDate,
+		"address":    pii.FieldOcrAddress,
+	}
+	for column, field := range fields {
+		sealed, _ := ocr[column+"_enc"].(string)
+		delete(ocr, column+"_enc")
+		if sealed == "" {
+			continue
+		}
+		value, err := openColumn(keys, field, &sealed)
+		if err != nil {
+			return err
+		}
+		ocr[column] = value
+	}
+	return nil
+}
+
+func queryJSONRecords(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]map[string]any, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []map[string]any{}
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return nil, err
+		}
+		var record map[string]any
+		if err := json.Unmarshal(raw, &record); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// dataSubjectMedia lists the media referenced by the exported records, each URL once.
+func dataSubjectMedia(records map[string][]map[string]any) []domain.DataSubjectMedia {
+	media := []domain.DataSubjectMedia{}
+	seen := map[string]bool{}
+	add := func(table string, record map[string]any, field string, value any) {
+		url, _ := value.(string)
+		if url == "" || seen[url] {
+			return
+		}
+		seen[url] = true
+		media = append(media, domain.DataSubjectMedia{
+			Table:    table,
+			RecordID: fmt.Sprint(record["id"]),
+			Field:    field,
+			URL:      url,
+		})
+	}
+	for _, record := range records["ekyc_sessions"] {
+		for _, field := range []string{"id_card_url", "selfie_with_id_url", "recorded_video_url"} {
+			add("ekyc_sessions", record, field, record[field])
+		}
+	}
+	for _, record := range records["liveness_checks"] {
+		add("liveness_checks", record, "recorded_video_url", record["recorded_video_url"])
+	}
+	for _, record := range records["application_documents"] {
+		add("application_documents", record, "url", record["url"])
+	}
+	for _, record := range records["application_visits"] {
+		photos, _ := record["photos"].([]any)
+		for i, photo := range photos {
+			add("application_visits", record, fmt.Sprintf("photos[%d]", i), photo)
+		}
+	}
+	for _, record := range records["notifications"] {
+		add("notifications", record, "attachment_url", record["attachment_url"])
+	}
+	return media
+}
+
+const erasureRequestColumns = `id, user_id, reason, status, requested_by, requested_at, reviewed_by, reviewed_at, review_note, summary,
+    (SELECT COUNT(*) FROM erasure_media m WHERE m.erasure_request_id = erasure_requests.id AND m.deleted_at IS NULL)`
+
+func scanErasureRequest(row pgx.Row) (*domain.ErasureRequest, error) {
+	var (
+		request domain.ErasureRequest
+		summary []byte
+	)
+	if err := row.Scan(&request.ID, &request.UserID, &request.Reason, &request.Status, &request.RequestedBy,
+		&request.RequestedAt, &request.ReviewedBy, &request.ReviewedAt, &request.ReviewNote, &summary, &request.MediaPending); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	request.Summary = decodeJSON(summary)
+	return &request, nil
+}
+
+func (repo *backofficeRepository) CreateErasureRequest(ctx context.Context, params domain.CreateErasureRequestParams) (*domain.ErasureRequest, error) {
+	var request *domain.ErasureRequest
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		var (
+			role   string
+			erased bool
+		)
+		if err := tx.QueryRow(ctx, `
+            SELECT role, COALESCE((metadata->>'erased')::boolean, FALSE)
+            FROM users
+            WHERE id::text = $1`, params.UserID).Scan(&role, &erased); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+		if role != "BENEFICIARY" {
+			return fmt.Errorf("%w: hanya data penerima manfaat yang dapat dihapus", domain.ErrInvalidState)
+		}
+		if erased {
+			return fmt.Errorf("%w: data subjek sudah dihapus", domain.ErrInvalidState)
+		}
+		created, err := scanErasureRequest(tx.QueryRow(ctx, `
+            INSERT INTO erasure_requests (user_id, reason, requested_by)
+            VALUES ($1,$2,$3)
+            RETURNING `+erasureRequestColumns,
+			params.UserID, params.Reason, params.Actor))
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return fmt.Errorf("%w: permintaan penghapusan sudah menunggu persetujuan", domain.ErrInvalidState)
+			}
+			return err
+		}
+		request = created
+		return repo.insertAudit(ctx, tx, domain.AuditEntry{
+			Actor:    params.Actor,
+			Entity:   params.UserID,
+			Action:   "DATA_SUBJECT:ERASURE_REQUESTED",
+			Reason:   params.Reason,
+			Metadata: map[string]any{"requestId": created.ID},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (repo *backofficeRepository) ListErasureRequests(ctx context.Context, params domain.ListErasureRequestsParams) ([]domain.ErasureRequest, error) {
+	limit := params.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	rows, err := repo.db.Query(ctx, `
+        SELECT `+erasureRequestColumns+`
+        FROM erasure_requests
+        WHERE ($1 = '' OR status = $1)
+        ORDER BY requested_at DESC, id DESC
+        LIMIT $2`, params.Status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []domain.ErasureRequest{}
+	for rows.Next() {
+		request, err := scanErasureRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+	return requests, rows.Err()
+}
+
+func (repo *backofficeRepository) GetErasureRequest(ctx context.Context, id int64) (*domain.ErasureRequest, error) {
+	return scanErasureRequest(repo.db.QueryRow(ctx, `
+        SELECT `+erasureRequestColumns+` FROM erasure_requests WHERE id = $1`, id))
+}
+
+// lockPendingErasureRequest loads a request for review, failing unless it is still pending.
+func lockPendingErasureRequest(ctx context.Context, tx pgx.Tx, id int64) (*domain.ErasureRequest, error) {
+	request, err := scanErasureRequest(tx.QueryRow(ctx, `
+        SELECT `+erasureRequestColumns+` FROM erasure_requests WHERE id = $1 FOR UPDATE`, id))
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != domain.ErasureStatusPending {
+		return nil, fmt.Errorf("%w: permintaan penghapusan sudah %s", domain.ErrInvalidState, request.Status)
+	}
+	return request, nil
+}
+
+func (repo *backofficeRepository) RejectErasureRequest(ctx context.Context, params domain.ReviewErasureRequestParams) (*domain.ErasureRequest, error) {
+	var request *domain.ErasureRequest
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		if _, err := lockPendingErasureRequest(ctx, tx, params.ID); err != nil {
+			return err
+		}
+		rejected, err := scanErasureRequest(tx.QueryRow(ctx, `
+            UPDATE erasure_requests
+            SET status = $2, reviewed_by = $3, reviewed_at = NOW(), review_note = $4
+            WHERE id = $1
+            RETURNING `+erasureRequestColumns,
+			params.ID, domain.ErasureStatusRejected, params.Actor, params.Note))
+		if err != nil {
+			return err
+		}
+		request = rejected
+		return repo.insertAudit(ctx, tx, domain.AuditEntry{
+			Actor:    params.Actor,
+			Entity:   rejected.UserID,
+			Action:   "DATA_SUBJECT:ERASURE_REJECTED",
+			Reason:   params.Note,
+			Metadata: map[string]any{"requestId": rejected.ID},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// erasureStatements pseudonymize a data subject. Rows are kept so counts, statuses,
+// scores and regions still add up in reports; audit_logs are left untouched. $1 is the
+// user id, $2 the pseudonym and $3 the erasure request id.
+var erasureStatements = []struct {
+	table string
+	query string
+}{
+	{"users", `
+        UPDATE users
+        SET name = $2, dob = NULL, pin_hash = NULL,
+            nik = NULL, nik_enc = NULL, nik_bidx = NULL,
+            phone = NULL, phone_enc = NULL, phone_bidx = NULL,
+            email = NULL, email_enc = NULL, email_bidx = NULL,
+            metadata = jsonb_build_object('erased', TRUE, 'erasureRequestId', $3::bigint),
+            updated_at = NOW()
+        WHERE id = $1`},
+	{"beneficiaries", `
+        UPDATE beneficiaries
+        SET portal_flags = '{}'::jsonb, payout_channel = NULL, payout_bank = NULL, payout_account_enc = NULL, updated_at = NOW()
+        WHERE user_id = $1`},
+	{"applications", `
+        UPDATE applications
+        SET applicant_name = $2, applicant_nik_mask = NULL, applicant_dob = NULL, applicant_phone_mask = NULL
+        WHERE beneficiary_user_id = $1`},
+	{"application_documents", `UPDATE application_documents SET url = '' WHERE application_id IN (` + subjectApplications + `)`},
+	{"application_visits", `
+        UPDATE application_visits SET geotag_lat = NULL, geotag_lng = NULL, photos = '[]'::jsonb, ` + visitChangeBump + `
+        WHERE application_id IN (` + subjectApplications + `)`},
+	// The perceptual hash stays so a re-used photo of the household is still caught.
+	{"visit_photos", `
+        UPDATE visit_photos SET url = '', gps_lat = NULL, gps_lng = NULL, camera = NULL
+        WHERE visit_id IN (` + subjectVisits + `)`},
+	{"survey_responses", `UPDATE survey_responses SET answers = '{}'::jsonb WHERE beneficiary_user_id = $1`},
+	{"face_checks", `UPDATE face_checks SET raw_metadata = '{}'::jsonb WHERE ekyc_session_id IN (` + subjectSessions + `)`},
+	{"liveness_checks", `
+        UPDATE liveness_checks SET recorded_video_url = NULL, raw_metadata = '{}'::jsonb
+        WHERE ekyc_session_id IN (` + subjectSessions + `)`},
+	{"ocr_results", `
+        UPDATE ocr_results
+        SET nik = NULL, name = NULL, birth_date = NULL, address = NULL,
+            nik_enc = NULL, name_enc = NULL, birth_date_enc = NULL, address_enc = NULL,
+            raw_metadata = '{}'::jsonb, comparison = '[]'::jsonb, updated_at = NOW()
+        WHERE ekyc_session_id IN (` + subjectSessions + `)`},
+	{"ekyc_sessions", `
+        UPDATE ekyc_sessions
+        SET id_card_url = NULL, selfie_with_id_url = NULL, recorded_video_url = NULL,
+            metadata = metadata - 'applicant'
+        WHERE id IN (` + subjectSessions + `)`},
+	// Attachments are shared by every recipient of a notification, so only the link goes.
+	{"notifications", `UPDATE notifications SET message = '[dihapus]', attachment_url = NULL WHERE user_id = $1`},
+	{"clustering_candidates", `UPDATE clustering_candidates SET notes = NULL WHERE user_id = $1`},
+}
+
+func (repo *backofficeRepository) ApproveErasureRequest(ctx context.Context, params domain.ReviewErasureRequestParams) (*domain.ErasureRequest, error) {
+	var request *domain.ErasureRequest
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		pending, err := lockPendingErasureRequest(ctx, tx, params.ID)
+		if err != nil {
+			return err
+		}
+		if pending.RequestedBy == params.Actor {
+			return fmt.Errorf("%w: penyetuju harus berbeda dari pemohon", domain.ErrForbidden)
+		}
+		userID := pending.UserID
+
+		var held bool
+		if err := tx.QueryRow(ctx, `
+            SELECT EXISTS (
+                SELECT 1 FROM legal_holds
+                WHERE released_at IS NULL
+                  AND (target_id IN (`+subjectApplications+`)
+                       OR target_id IN (SELECT id::text FROM ekyc_sessions WHERE user_id = $1)))`,
+			userID).Scan(&held); err != nil {
+			return err
+		}
+		if held {
+			return fmt.Errorf("%w: data subjek dalam legal hold", domain.ErrInvalidState)
+		}
+
+		urls, err := erasureMediaURLs(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		// Session artifacts get the same tombstones as a retention purge, with no period.
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO ekyc_artifact_tombstones (ekyc_session_id, artifact, media_url, retention_days, purged_by)
+            SELECT s.id, a.artifact, a.url, 0, $2
+            FROM ekyc_sessions s
+            CROSS JOIN LATERAL (VALUES
+                ('id_card', s.id_card_url),
+                ('selfie_with_id', s.selfie_with_id_url),
+                ('recorded_video', s.recorded_video_url)) a(artifact, url)
+            WHERE s.id IN (`+subjectSessions+`) AND a.url IS NOT NULL`,
+			userID, params.Actor); err != nil {
+			return err
+		}
+
+		// The cleared visits reach the TKSK devices as a change, see lockTkskVisits.
+		if _, err := tx.Exec(ctx, `
+            SELECT pg_advisory_xact_lock(hashtext('application_visits:' || t.tksk_id::text))
+            FROM (SELECT DISTINCT tksk_id FROM application_visits
+                  WHERE tksk_id IS NOT NULL AND application_id IN (`+subjectApplications+`)
+                  ORDER BY tksk_id) t`, userID); err != nil {
+			return err
+		}
+
+		// Queued before the request is completed, so its mediaPending counts them.
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO erasure_media (erasure_request_id, media_url)
+            SELECT $1, url FROM unnest($2::text[]) url
+            ON CONFLICT (erasure_request_id, media_url) DO NOTHING`,
+			pending.ID, urls); err != nil {
+			return err
+		}
+
+		pseudonym := fmt.Sprintf("ERASED-%06d", pending.ID)
+		summary := map[string]any{"media": len(urls)}
+		for _, stmt := range erasureStatements {
+			args := []any{userID}
+			if stmt.table == "users" {
+				args = append(args, pseudonym, pending.ID)
+			} else if stmt.table == "applications" {
+				args = append(args, pseudonym)
+			}
+			tag, err := tx.Exec(ctx, stmt.query, args...)
+			if err != nil {
+				return fmt.Errorf("erase %s: %w", stmt.table, err)
+			}
+			summary[stmt.table] = tag.RowsAffected()
+		}
+
+		summaryJSON, _ := json.Marshal(summary)
+		completed, err := scanErasureRequest(tx.QueryRow(ctx, `
+            UPDATE erasure_requests
+            SET status = $2, reviewed_by = $3, reviewed_at = NOW(), review_note = $4, summary = $5::jsonb
+            WHERE id = $1
+            RETURNING `+erasureRequestColumns,
+			pending.ID, domain.ErasureStatusCompleted, params.Actor, params.Note, summaryJSON))
+		if err != nil {
+			return err
+		}
+		request = completed
+		if err := repo.insertAudit(ctx, tx, domain.AuditEntry{
+			Actor:  params.Actor,
+			Entity: userID,
+			Action: "DATA_SUBJECT:ERASED",
+			Reason: pending.Reason,
+			Metadata: map[string]any{
+				"requestId":   pending.ID,
+				"requestedBy": pending.RequestedBy,
+				"summary":     summary,
+			},
+		}); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (repo *backofficeRepository) ListPendingErasureMedia(ctx context.Context, requestID int64) ([]domain.ErasureMedia, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT id, erasure_request_id, media_url, attempts, last_error
+        FROM erasure_media
+        WHERE erasure_request_id = $1 AND deleted_at IS NULL
+        ORDER BY id`, requestID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var media []domain.ErasureMedia
+	for rows.Next() {
+		var item domain.ErasureMedia
+		if err := rows.Scan(&item.ID, &item.RequestID, &item.URL, &item.Attempts, &item.LastError); err != nil {
+			return nil, err
+		}
+		media = append(media, item)
+	}
+	return media, rows.Err()
+}
+
+func (repo *backofficeRepository) MarkErasureMediaDeleted(ctx context.Context, id int64) error {
+	_, err := repo.db.Exec(ctx, `
+        UPDATE erasure_media
+        SET deleted_at = NOW(), attempts = attempts + 1, last_error = NULL
+        WHERE id = $1`, id)
+	return err
+}
+
+func (repo *backofficeRepository) MarkErasureMediaFailed(ctx context.Context, id int64, reason string) error {
+	_, err := repo.db.Exec(ctx, `
+        UPDATE erasure_media
+        SET attempts = attempts + 1, last_error = $2
+        WHERE id = $1`, id, reason)
+	return err
+}
+
+// erasureMediaURLs lists the media files owned by the subject. Notification attachments
+// are shared with other recipients and are not included.
+func erasureMediaURLs(ctx context.Context, tx pgx.Tx, userID string) ([]string, error) {
+	rows, err := tx.Query(ctx, `
+        SELECT DISTINCT url FROM (
+            SELECT unnest(ARRAY[s.id_card_url, s.selfie_with_id_url, s.recorded_video_url]) AS url
+            FROM ekyc_sessions s WHERE s.id IN (`+subjectSessions+`)
+            UNION ALL
+            SELECT l.recorded_video_url FROM liveness_checks l WHERE l.ekyc_session_id IN (`+subjectSessions+`)
+            UNION ALL
+            SELECT d.url FROM application_documents d WHERE d.application_id IN (`+subjectApplications+`)
+            UNION ALL
+            SELECT jsonb_array_elements_text(v.photos) FROM application_visits v
+            WHERE v.application_id IN (`+subjectApplications+`) AND jsonb_typeof(v.photos) = 'array'
+            UNION ALL
+            SELECT p.url FROM visit_photos p WHERE p.visit_id IN (`+subjectVisits+`)
+        ) m
+        WHERE url IS NOT NULL AND url <> ''
+        ORDER BY url`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var urls []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		urls = append(urls, url)
+	}
+	return urls, rows.Err()
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/pii"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (repo *backofficeRepository) ListDisbursementPayees(ctx context.Context, batchID string) (map[string]domain.DisbursementPayee, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT bi.application_id, a.applicant_name,
+               COALESCE(b.payout_channel, ''), COALESCE(b.payout_bank, ''), b.payout_account_enc
+        FROM batch_items bi
+        JOIN applications a ON a.id = bi.application_id
+        LEFT JOIN beneficiaries b ON b.user_id = a.beneficiary_user_id
+        WHERE bi.batch_id = $1`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payees := map[string]domain.DisbursementPayee{}
+	for rows.Next() {
+		var (
+			payee   domain.DisbursementPayee
+			account *string
+		)
+		if err := rows.Scan(&payee.ApplicationID, &payee.Name, &payee.Channel, &payee.Bank, &account); err != nil {
+			return nil, err
+		}
+		if account, err = openColumn(repo.keys, pii.FieldPayoutAccount, account); err != nil {
+			return nil, fmt.Errorf("payout account of %s: %w", payee.ApplicationID, err)
+		}
+		payee.Account = derefString(account)
+		payees[payee.ApplicationID] = payee
+	}
+	return payees, rows.Err()
+}
+
+func (repo *backofficeRepository) RecordBatchExport(ctx context.Context, params domain.RecordBatchExportParams) error {
+	export := params.Export
+	return repo.withTx(ctx, func(tx pgx.Tx) error {
+		var current string
+		if err := tx.QueryRow(ctx, `SELECT status FROM batches WHERE id = $1 FOR UPDATE`, export.BatchID).Scan(&current); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+		if current != params.From {
+			return fmt.Errorf("%w: status batch sudah berubah menjadi %s", domain.ErrInvalidState, current)
+		}
+		if err := tx.QueryRow(ctx, `
+            INSERT INTO batch_exports (batch_id, format, filename, media_url, checksum, manifest_checksum,
+                                       items, total_amount, currency, created_by)
+            VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+            RETURNING id, created_at`,
+			export.BatchID, export.Format, export.Filename, export.URL, export.Checksum, export.ManifestChecksum,
+			export.Items, export.TotalAmount, export.Currency, export.CreatedBy,
+		).Scan(&export.ID, &export.CreatedAt); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `UPDATE batches SET status = $1, updated_at = NOW() WHERE id = $2`,
+			domain.BatchStatusSent, export.BatchID); err != nil {
+			return err
+		}
+		return repo.insertAudit(ctx, tx, params.Audit)
+	})
+}
+
+func (repo *backofficeRepository) ListBatchExports(ctx context.Context, batchID string) ([]domain.BatchExport, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT id, batch_id, format, filename, media_url, checksum, manifest_checksum,
+               items, total_amount, currency, created_by, created_at
+        FROM batch_exports
+        WHERE batch_id = $1
+        ORDER BY created_at DESC, id DESC`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exports := []domain.BatchExport{}
+	for rows.Next() {
+		var export domain.BatchExport
+		if err := rows.Scan(&export.ID, &export.BatchID, &export.Format, &export.Filename, &export.URL, &export.Checksum,
+			&export.ManifestChecksum, &export.Items, &export.TotalAmount, &export.Currency, &export.CreatedBy,
+			&export.CreatedAt); err != nil {
+			return nil, err
+		}
+		exports = append(exports, export)
+	}
+	return exports, rows.Err()
+}
+
+func (repo *backofficeRepository) SetBeneficiaryPayout(ctx context.Context, params domain.SetBeneficiaryPayoutParams) error {
+	payout := params.Payout
+	var account *string
+	if payout.Account != "" {
+		sealed, err := repo.keys.Seal(pii.FieldPayoutAccount, payout.Account)
+		if err != nil {
+			return err
+		}
+		account = &sealed
+	}
+	return repo.withTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+            UPDATE beneficiaries
+            SET payout_channel = $2, payout_bank = NULLIF($3, ''), payout_account_enc = $4, updated_at = NOW()
+            WHERE user_id::text = $1`, payout.UserID, payout.Channel, payout.Bank, account)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return domain.ErrNotFound
+		}
+		return repo.insertAudit(ctx, tx, params.Audit)
+	})
+}
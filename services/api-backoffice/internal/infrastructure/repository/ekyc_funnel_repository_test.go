@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/services/api-backoffice/internal/service"
+	shareddb "e-kyc/shared/db"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testDSNEnv names a disposable Postgres database. The tests drop its public schema and
+// apply every migration, and they are skipped when it is unset.
+const testDSNEnv = "BACKOFFICE_TEST_DB_DSN"
+
+func openTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set", testDSNEnv)
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	if err := shareddb.CleanDatabase(ctx, pool); err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob("../../../../../shared/db/migrations/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		blob, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := shareddb.ApplySchema(ctx, pool, string(blob)); err != nil {
+			t.Fatalf("%s: %v", filepath.Base(file), err)
+		}
+	}
+	return pool
+}
+
+var wib = time.FixedZone("WIB", 7*60*60)
+
+func wibTime(day, hour int) time.Time {
+	return time.Date(2025, time.March, day, hour, 0, 0, 0, wib)
+}
+
+// funnelSession is one seeded session. decidedAfter is the delay of its decision.made
+// event, or of updated_at when noEvent is set.
+type funnelSession struct {
+	region       string
+	created      time.Time
+	uploaded     bool
+	face         string
+	liveness     string
+	decision     string
+	reason       string
+	decidedAfter time.Duration
+	noEvent      bool
+	gestures     []string
+}
+
+func seedFunnel(t *testing.T, pool *pgxpool.Pool, sessions []funnelSession) {
+	t.Helper()
+	ctx := context.Background()
+	for i, s := range sessions {
+		var userID, sessionID string
+		if err := pool.QueryRow(ctx, `
+            INSERT INTO users (role, name, region_prov) VALUES ('beneficiary', $1, NULLIF($2, ''))
+            RETURNING id`, "user", s.region).Scan(&userID); err != nil {
+			t.Fatalf("session %d: %v", i, err)
+		}
+		var idCard, selfie *string
+		if s.uploaded {
+			url := "https://media.test/media/artifact.jpg"
+			idCard, selfie = &url, &url
+		}
+		if err := pool.QueryRow(ctx, `
+            INSERT INTO ekyc_sessions (user_id, final_decision, id_card_url, selfie_with_id_url,
+                                       face_match_overall, liveness_overall, rejection_reason, created_at, updated_at)
+            VALUES ($1,$2,$3,$4,NULLIF($5,''),NULLIF($6,''),NULLIF($7,''),$8,$9)
+            RETURNING id`,
+			userID, s.decision, idCard, selfie, s.face, s.liveness, s.reason, s.created, s.created.Add(s.decidedAfter),
+		).Scan(&sessionID); err != nil {
+			t.Fatalf("session %d: %v", i, err)
+		}
+		if s.decision != "PENDING" && !s.noEvent {
+			if _, err := pool.Exec(ctx, `
+                INSERT INTO ekyc_session_events (ekyc_session_id, event_type, created_at) VALUES ($1,$2,$3)`,
+				sessionID, domain.EkycEventDecisionMade, s.created.Add(s.decidedAfter)); err != nil {
+				t.Fatalf("session %d: %v", i, err)
+			}
+		}
+		for _, gestures := range s.gestures {
+			if _, err := pool.Exec(ctx, `
+                INSERT INTO liveness_checks (ekyc_session_id, overall_result, per_gesture_result)
+                VALUES ($1, 'DONE', $2::jsonb)`, sessionID, gestures); err != nil {
+				t.Fatalf("session %d: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestEkycFunnel(t *testing.T) {
+	pool := openTestDB(t)
+	seedFunnel(t, pool, []funnelSession{
+		{region: "31", created: wibTime(3, 10), uploaded: true, face: "PASS", liveness: "PASS",
+			decision: "APPROVED", decidedAfter: time.Hour, gestures: []string{`{"BLINK":"PASS","TURN_LEFT":"PASS"}`}},
+		// 06:00 WIB on the 4th is still the 3rd in UTC.
+		{region: "31", created: wibTime(4, 6), uploaded: true, face: "fail", liveness: "PASS",
+			decision: "REJECTED", reason: "Wajah tidak cocok", decidedAfter: 3 * time.Hour,
+			gestures: []string{`{"BLINK":"FAIL","TURN_LEFT":"PASS"}`}},
+		{region: "32", created: wibTime(4, 9), decision: "PENDING"},
+		{region: "32", created: wibTime(10, 9), uploaded: true, face: "PASS", liveness: "FAIL",
+			decision: "REJECTED", reason: "Wajah tidak cocok", decidedAfter: 5 * time.Hour, noEvent: true,
+			gestures: []string{`{"BLINK":"PASS","TURN_LEFT":"FAIL"}`, `{"BLINK":true}`}},
+		{created: wibTime(11, 9), uploaded: true, face: "PASS", liveness: "PASS",
+			decision: "APPROVED", decidedAfter: 2 * time.Hour},
+		// Outside the window.
+		{region: "31", created: wibTime(1, 9), uploaded: true, face: "PASS", liveness: "PASS",
+			decision: "APPROVED", decidedAfter: time.Hour, gestures: []string{`{"BLINK":"PASS"}`}},
+	})
+	svc := service.NewBackofficeService(NewBackofficeRepository(pool, nil), nil)
+	ctx := context.Background()
+	from, to := wibTime(3, 0), wibTime(17, 0)
+
+	t.Run("totals", func(t *testing.T) {
+		report, err := svc.EkycFunnel(ctx, domain.EkycFunnelParams{From: from, To: to})
+		if err != nil {
+			t.Fatal(err)
+		}
+		total := report.Totals
+		assertCounts(t, "totals", total, bucketCounts{created: 5, uploaded: 4, faceChecked: 4, facePassed: 3,
+			livenessChecked: 4, livenessPassed: 3, decided: 4, approved: 2, rejected: 2})
+		// 1h, 2h, 3h and 5h, the last from updated_at.
+		assertFloat(t, "median decision seconds", total.MedianDecisionSeconds, 2.5*3600)
+		assertFloat(t, "face pass rate", total.FacePassRate, 0.75)
+		assertFloat(t, "approval rate", total.ApprovalRate, 0.5)
+
+		gestures := map[string]domain.GesturePassRate{}
+		for _, g := range report.Gestures {
+			gestures[g.Gesture] = g
+		}
+		if g := gestures["BLINK"]; g.Attempts != 4 || g.Passed != 3 || g.PassRate != 0.75 {
+			t.Errorf("BLINK %+v, want 3 of 4", g)
+		}
+		if g := gestures["TURN_LEFT"]; g.Attempts != 3 || g.Passed != 2 || math.Abs(g.PassRate-2.0/3) > 1e-9 {
+			t.Errorf("TURN_LEFT %+v, want 2 of 3", g)
+		}
+		if len(report.TopRejectionReasons) != 1 || report.TopRejectionReasons[0] != (domain.RejectionReasonCount{Reason: "Wajah tidak cocok", Count: 2}) {
+			t.Errorf("rejection reasons %+v", report.TopRejectionReasons)
+		}
+	})
+
+	t.Run("days in WIB", func(t *testing.T) {
+		report, err := svc.EkycFunnel(ctx, domain.EkycFunnelParams{From: from, To: to, Granularity: "day"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]int{"2025-03-03": 1, "2025-03-04": 2, "2025-03-10": 1, "2025-03-11": 1}
+		if got := createdByKey(report.Buckets); !maps.Equal(got, want) {
+			t.Errorf("sessions per day %v, want %v", got, want)
+		}
+		for _, bucket := range report.Buckets {
+			if bucket.Period.Equal(wibTime(4, 0)) {
+				assertFloat(t, "median on the 4th", bucket.MedianDecisionSeconds, 3*3600)
+			}
+		}
+	})
+
+	t.Run("weeks by province", func(t *testing.T) {
+		report, err := svc.EkycFunnel(ctx, domain.EkycFunnelParams{From: from, To: to, Granularity: "week", RegionLevel: "prov"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]int{"2025-03-03/31": 2, "2025-03-03/32": 1, "2025-03-10/32": 1, "2025-03-10/UNKNOWN": 1}
+		if got := createdByKey(report.Buckets); !maps.Equal(got, want) {
+			t.Errorf("sessions per week and province %v, want %v", got, want)
+		}
+		if report.Totals.SessionsCreated != 5 {
+			t.Errorf("totals counted %d sessions, want 5", report.Totals.SessionsCreated)
+		}
+	})
+
+	t.Run("one province", func(t *testing.T) {
+		report, err := svc.EkycFunnel(ctx, domain.EkycFunnelParams{From: from, To: to, Granularity: "week", RegionLevel: "prov", Region: "31"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertCounts(t, "province 31", report.Totals, bucketCounts{created: 2, uploaded: 2, faceChecked: 2, facePassed: 1,
+			livenessChecked: 2, livenessPassed: 2, decided: 2, approved: 1, rejected: 1})
+		assertFloat(t, "median in province 31", report.Totals.MedianDecisionSeconds, 2*3600)
+		if got := createdByKey(report.Buckets); !maps.Equal(got, map[string]int{"2025-03-03/31": 2}) {
+			t.Errorf("buckets %v", got)
+		}
+	})
+}
+
+type bucketCounts struct {
+	created, uploaded, faceChecked, facePassed, livenessChecked, livenessPassed, decided, approved, rejected int
+}
+
+func assertCounts(t *testing.T, name string, bucket domain.EkycFunnelBucket, want bucketCounts) {
+	t.Helper()
+	got := bucketCounts{bucket.SessionsCreated, bucket.ArtifactsUploaded, bucket.FaceChecked, bucket.FacePassed,
+		bucket.LivenessChecked, bucket.LivenessPassed, bucket.Decided, bucket.Approved, bucket.Rejected}
+	if got != want {
+		t.Errorf("%s: counts %+v, want %+v", name, got, want)
+	}
+}
+
+func assertFloat(t *testing.T, name string, got *float64, want float64) {
+	t.Helper()
+	if got == nil || math.Abs(*got-want) > 1e-6 {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+// createdByKey keys buckets by their WIB date, followed by "/region" when split.
+func createdByKey(buckets []domain.EkycFunnelBucket) map[string]int {
+	counts := map[string]int{}
+	for _, bucket := range buckets {
+		key := bucket.Period.In(wib).Format(time.DateOnly)
+		if bucket.Region != nil {
+			key += "/" + *bucket.Region
+		}
+		counts[key] += bucket.SessionsCreated
+	}
+	return counts
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/services/api-backoffice/internal/service"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// reviewSession is one seeded undecided session. faceAttempts adds that many face
+// checks, the last one current.
+type reviewSession struct {
+	name         string
+	status       string
+	face         string
+	liveness     string
+	faceAttempts int
+	updated      time.Time
+	claimedBy    string
+}
+
+func seedReviewQueue(t *testing.T, pool *pgxpool.Pool, sessions []reviewSession) map[string]string {
+	t.Helper()
+	ctx := context.Background()
+	names := map[string]string{}
+	for _, s := range sessions {
+		var sessionID string
+		if err := pool.QueryRow(ctx, `
+            INSERT INTO ekyc_sessions (status, face_match_overall, liveness_overall, created_at, updated_at)
+            VALUES ($1, NULLIF($2,''), NULLIF($3,''), $4, $4)
+            RETURNING id`, s.status, s.face, s.liveness, s.updated).Scan(&sessionID); err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		names[sessionID] = s.name
+		for attempt := 1; attempt <= s.faceAttempts; attempt++ {
+			if _, err := pool.Exec(ctx, `
+                INSERT INTO face_checks (ekyc_session_id, step, similarity_score, threshold, result, attempt_no, is_current)
+                VALUES ($1, 'SELFIE_VS_ID', 0.9, 0.8, 'PASS', $2, $3)`,
+				sessionID, attempt, attempt == s.faceAttempts); err != nil {
+				t.Fatalf("%s: %v", s.name, err)
+			}
+		}
+		if s.claimedBy != "" {
+			if _, err := pool.Exec(ctx, `
+                INSERT INTO ekyc_review_claims (ekyc_session_id, reviewer, expires_at)
+                VALUES ($1, $2, NOW() + INTERVAL '1 hour')`, sessionID, s.claimedBy); err != nil {
+				t.Fatalf("%s: %v", s.name, err)
+			}
+		}
+	}
+	return names
+}
+
+func TestListReviewQueueRanksAcrossTheBacklog(t *testing.T) {
+	pool := openTestDB(t)
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	names := seedReviewQueue(t, pool, []reviewSession{
+		{name: "old conflict 1", status: "COMPLETED", face: "PASS", liveness: "FAIL", updated: old},
+		{name: "old conflict 2", status: "COMPLETED", face: "PASS", liveness: "FAIL", updated: old.Add(time.Hour)},
+		{name: "old conflict 3", status: "COMPLETED", face: "PASS", liveness: "FAIL", updated: old.Add(2 * time.Hour)},
+		{name: "old retried", status: "COMPLETED", face: "PASS", liveness: "PASS", faceAttempts: 2, updated: old},
+		{name: "clean", status: "COMPLETED", face: "PASS", liveness: "PASS", updated: old},
+		{name: "claimed", status: "MANUAL_REVIEW", face: "PASS", liveness: "FAIL", updated: old, claimedBy: "other"},
+		{name: "new locked", status: "MANUAL_REVIEW", face: "PASS", liveness: "FAIL", faceAttempts: 3, updated: time.Now()},
+	})
+	svc := service.NewEkycService(NewBackofficeRepository(pool, nil), nil, nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		params domain.ListReviewQueueParams
+		want   []string
+	}{
+		{"first page", domain.ListReviewQueueParams{Reviewer: "me", Limit: 2},
+			[]string{"new locked", "old conflict 1"}},
+		{"second page", domain.ListReviewQueueParams{Reviewer: "me", Limit: 2, Offset: 2},
+			[]string{"old conflict 2", "old conflict 3"}},
+		{"last page", domain.ListReviewQueueParams{Reviewer: "me", Limit: 2, Offset: 4},
+			[]string{"old retried"}},
+		{"claims by others included", domain.ListReviewQueueParams{Reviewer: "me", IncludeClaimed: true, Limit: 2},
+			[]string{"new locked", "claimed"}},
+		{"own claims included", domain.ListReviewQueueParams{Reviewer: "other", Limit: 2},
+			[]string{"new locked", "claimed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := svc.ListReviewQueue(ctx, tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for i, item := range items {
+				got = append(got, names[item.Session.ID])
+				if i > 0 && item.Score > items[i-1].Score {
+					t.Errorf("%s scored %d ranked below %d", got[i], item.Score, items[i-1].Score)
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+
+	items, err := svc.ListReviewQueue(ctx, domain.ListReviewQueueParams{Reviewer: "me", Limit: 1})
+	if err != nil || len(items) != 1 {
+		t.Fatalf("got %d items, %v", len(items), err)
+	}
+	// Locked, conflicting and two retries beyond the first.
+	if want := domain.ReviewWeightLocked + domain.ReviewWeightResultConflict + 2*domain.ReviewWeightRepeatedAttempts; items[0].Score != want {
+		t.Errorf("score %d, want %d", items[0].Score, want)
+	}
+	if session := items[0].Session; session.FaceAttempts != 3 || len(session.FaceChecks) != 1 || len(session.FaceCheckHistory) != 3 {
+		t.Errorf("enriched with %d attempts, %d current and %d past checks", session.FaceAttempts, len(session.FaceChecks), len(session.FaceCheckHistory))
+	}
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// abandonedAfter is how long an unfinished reservation is honoured before another
+// request may take the key over, e.g. when the replica holding it crashed.
+const abandonedAfter = time.Minute
+
+type idempotencyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(db *pgxpool.Pool) domain.IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func (repo *idempotencyRepository) Reserve(ctx context.Context, record domain.IdempotencyRecord) (*domain.IdempotencyRecord, bool, error) {
+	tag, err := repo.db.Exec(ctx, `
+        INSERT INTO idempotency_keys (idempotency_key, method, path, caller, reservation, request_hash, created_at, expires_at)
+        VALUES ($1,$2,$3,$4,$5,$6,NOW(),$7)
+        ON CONFLICT (idempotency_key, method, path, caller) DO UPDATE SET
+            reservation = EXCLUDED.reservation,
+            request_hash = EXCLUDED.request_hash,
+            status_code = NULL,
+            content_type = NULL,
+            response_body = NULL,
+            created_at = NOW(),
+            completed_at = NULL,
+            expires_at = EXCLUDED.expires_at
+        WHERE idempotency_keys.expires_at < NOW()
+           OR (idempotency_keys.completed_at IS NULL AND idempotency_keys.created_at < $8)`,
+		record.Key, record.Method, record.Path, record.Caller, record.Reservation, record.RequestHash, record.ExpiresAt,
+		time.Now().Add(-abandonedAfter),
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	if tag.RowsAffected() > 0 {
+		return nil, true, nil
+	}
+	existing, err := repo.Find(ctx, record.IdempotencyKey)
+	if err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+func (repo *idempotencyRepository) Find(ctx context.Context, key domain.IdempotencyKey) (*domain.IdempotencyRecord, error) {
+	var (
+		record      domain.IdempotencyRecord
+		statusCode  *int32
+		contentType *string
+		completedAt *time.Time
+	)
+	err := repo.db.QueryRow(ctx, `
+        SELECT idempotency_key, method, path, caller, reservation, request_hash, status_code, content_type,
+               response_body, completed_at, created_at, expires_at
+        FROM idempotency_keys
+        WHERE idempotency_key = $1 AND method = $2 AND path = $3 AND caller = $4`,
+		key.Key, key.Method, key.Path, key.Caller).
+		Scan(&record.Key, &record.Method, &record.Path, &record.Caller, &record.Reservation, &record.RequestHash, &statusCode,
+			&contentType, &record.ResponseBody, &completedAt, &record.CreatedAt, &record.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if statusCode != nil {
+		record.StatusCode = int(*statusCode)
+	}
+	record.ContentType = derefString(contentType)
+	record.Completed = completedAt != nil
+	return &record, nil
+}
+
+func (repo *idempotencyRepository) Complete(ctx context.Context, record domain.IdempotencyRecord) error {
+	tag, err := repo.db.Exec(ctx, `
+        UPDATE idempotency_keys
+           SET status_code = $6,
+               content_type = $7,
+               response_body = $8,
+               completed_at = NOW()
+         WHERE idempotency_key = $1 AND method = $2 AND path = $3 AND caller = $4
+           AND reservation = $5 AND completed_at IS NULL`,
+		record.Key, record.Method, record.Path, record.Caller, record.Reservation,
+		record.StatusCode, record.ContentType, record.ResponseBody,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: idempotency key %s was taken over by another request", domain.ErrInvalidState, record.Key)
+	}
+	return nil
+}
+
+func (repo *idempotencyRepository) Release(ctx context.Context, key domain.IdempotencyKey, reservation string) error {
+	_, err := repo.db.Exec(ctx, `
+        DELETE FROM idempotency_keys
+         WHERE idempotency_key = $1 AND method = $2 AND path = $3 AND caller = $4
+           AND reservation = $5 AND completed_at IS NULL`,
+		key.Key, key.Method, key.Path, key.Caller, reservation,
+	)
+	return err
+}
+
+func (repo *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := repo.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/pii"
+)
+
+// users.nik/phone/email are stored sealed in *_enc with a blind index in *_bidx (see the
+// 20251026_pii_encryption migration). Reads select COALESCE(x_enc, x) so a row the clutch
+// encryption pass has not reached yet still reads as plaintext.
+
+// openColumn decrypts one scanned PII column in place.
+func openColumn(keys *pii.Keyring, field string, value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	plain, err := keys.Open(field, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &plain, nil
+}
+
+// openUserPII decrypts the NIK, phone and email of a scanned user.
+func openUserPII(keys *pii.Keyring, user *domain.User) error {
+	var err error
+	if user.NIK, err = openColumn(keys, pii.FieldNIK, user.NIK); err != nil {
+		return fmt.Errorf("user %s: %w", user.ID, err)
+	}
+	if user.Phone, err = openColumn(keys, pii.FieldPhone, user.Phone); err != nil {
+		return fmt.Errorf("user %s: %w", user.ID, err)
+	}
+	if user.Email, err = openColumn(keys, pii.FieldEmail, user.Email); err != nil {
+		return fmt.Errorf("user %s: %w", user.ID, err)
+	}
+	return nil
+}
+
+// sealApplicant seals the applicant form. ekyc_sessions.metadata.applicant holds the
+// result as a JSON string in place of the object.
+func sealApplicant(keys *pii.Keyring, applicant map[string]any) (string, error) {
+	raw, err := json.Marshal(applicant)
+	if err != nil {
+		return "", err
+	}
+	return keys.Seal(pii.FieldApplicant, string(raw))
+}
+
+// openApplicant replaces a sealed metadata.applicant with the decoded object. Unsealed
+// objects are left as they are.
+func openApplicant(keys *pii.Keyring, metadata map[string]any) error {
+	sealed, ok := metadata["applicant"].(string)
+	if !ok || !pii.IsSealed(sealed) {
+		return nil
+	}
+	plain, err := keys.Open(pii.FieldApplicant, sealed)
+	if err != nil {
+		return err
+	}
+	var applicant map[string]any
+	if err := json.Unmarshal([]byte(plain), &applicant); err != nil {
+		return err
+	}
+	metadata["applicant"] = applicant
+	return nil
+}
+
+// sealedOcr holds the sealed KTP fields of an OCR result, nil where the field is unset.
+type sealedOcr struct {
+	nik, name, birthDate, address *string
+}
+
+// sealOcrResult seals the KTP fields of result. The birth date is sealed as YYYY-MM-DD.
+func sealOcrResult(keys *pii.Keyring, result domain.OcrResult) (sealedOcr, error) {
+	var birthDate *string
+	if result.BirthDate != nil {
+		value := result.BirthDate.Format(time.DateOnly)
+		birthDate = &value
+	}
+	var (
+		sealed sealedOcr
+		err    error
+	)
+	if sealed.nik, err = sealOptional(keys, pii.FieldOcrNIK, result.Nik); err != nil {
+		return sealedOcr{}, err
+	}
+	if sealed.name, err = sealOptional(keys, pii.FieldOcrName, result.Name); err != nil {
+		return sealedOcr{}, err
+	}
+	if sealed.birthDate, err = sealOptional(keys, pii.FieldOcrBirthDate, birthDate); err != nil {
+		return sealedOcr{}, err
+	}
+	if sealed.address, err = sealOptional(keys, pii.FieldOcrAddress, result.Address); err != nil {
+		return sealedOcr{}, err
+	}
+	return sealed, nil
+}
+
+// openOcrResult decrypts scanned KTP fields into result. Reads select
+// COALESCE(x_enc, x) like the users columns, so plaintext rows open as they are.
+func openOcrResult(keys *pii.Keyring, scanned sealedOcr, result *domain.OcrResult) error {
+	var err error
+	if result.Nik, err = openColumn(keys, pii.FieldOcrNIK, scanned.nik); err != nil {
+		return fmt.Errorf("ocr %s: %w", result.SessionID, err)
+	}
+	if result.Name, err = openColumn(keys, pii.FieldOcrName, scanned.name); err != nil {
+		return fmt.Errorf("ocr %s: %w", result.SessionID, err)
+	}
+	if result.Address, err = openColumn(keys, pii.FieldOcrAddress, scanned.address); err != nil {
+		return fmt.Errorf("ocr %s: %w", result.SessionID, err)
+	}
+	birthDate, err := openColumn(keys, pii.FieldOcrBirthDate, scanned.birthDate)
+	if err != nil {
+		return fmt.Errorf("ocr %s: %w", result.SessionID, err)
+	}
+	result.BirthDate = nil
+	if birthDate != nil {
+		date, err := time.Parse(time.DateOnly, *birthDate)
+		if err != nil {
+			return fmt.Errorf("ocr %s: birth date: %w", result.SessionID, err)
+		}
+		result.BirthDate = &date
+	}
+	return nil
+}
+
+// sealOptional seals value for field, leaving nil as nil.
+func sealOptional(keys *pii.Keyring, field string, value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	sealed, err := keys.Seal(field, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
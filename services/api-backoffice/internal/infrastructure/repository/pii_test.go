@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/pii"
+)
+
+func testKeyring(t *testing.T) *pii.Keyring {
+	t.Helper()
+	entry, err := pii.GenerateKey("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := pii.ParseKeyring(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestOcrResultSealRoundTrip(t *testing.T) {
+	keys := testKeyring(t)
+	nik, name, address := "3171012501900001", "BUDI SANTOSO", "JL. MERDEKA NO. 1"
+	birthDate := time.Date(1990, time.January, 25, 0, 0, 0, 0, time.UTC)
+	result := domain.OcrResult{SessionID: "sess-1", Nik: &nik, Name: &name, BirthDate: &birthDate, Address: &address}
+
+	sealed, err := sealOcrResult(keys, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for column, value := range map[string]*string{"nik": sealed.nik, "name": sealed.name, "birth_date": sealed.birthDate, "address": sealed.address} {
+		if value == nil || !pii.IsSealed(*value) || strings.Contains(*value, "3171") || strings.Contains(*value, "BUDI") {
+			t.Errorf("%s stored as %v", column, value)
+		}
+	}
+
+	var opened domain.OcrResult
+	opened.SessionID = result.SessionID
+	if err := openOcrResult(keys, sealed, &opened); err != nil {
+		t.Fatal(err)
+	}
+	if *opened.Nik != nik || *opened.Name != name || *opened.Address != address || !opened.BirthDate.Equal(birthDate) {
+		t.Errorf("opened %+v", opened)
+	}
+
+	// A sealed value cannot be opened as another field.
+	swapped := sealedOcr{nik: sealed.name}
+	if err := openOcrResult(keys, swapped, &opened); err == nil {
+		t.Error("sealed name opened as a NIK")
+	}
+}
+
+func TestOcrResultOpensPlaintextRows(t *testing.T) {
+	keys := testKeyring(t)
+	nik, birthDate := "3171012501900001", "1990-01-25"
+	var opened domain.OcrResult
+	if err := openOcrResult(keys, sealedOcr{nik: &nik, birthDate: &birthDate}, &opened); err != nil {
+		t.Fatal(err)
+	}
+	if *opened.Nik != nik || opened.BirthDate.Format(time.DateOnly) != birthDate || opened.Name != nil || opened.Address != nil {
+		t.Errorf("opened %+v", opened)
+	}
+}
+
+func TestOpenOcrRecordForExport(t *testing.T) {
+	keys := testKeyring(t)
+	sealed, err := keys.Seal(pii.FieldOcrAddress, "JL. MERDEKA NO. 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := map[string]any{"address": nil, "address_enc": sealed, "nik_enc": nil, "score": 0.9}
+	if err := openOcrRecord(keys, record); err != nil {
+		t.Fatal(err)
+	}
+	if address, _ := record["address"].(*string); address == nil || *address != "JL. MERDEKA NO. 1" {
+		t.Errorf("address = %v", record["address"])
+	}
+	if _, ok := record["address_enc"]; ok {
+		t.Error("sealed column left in the export")
+	}
+	if _, ok := record["nik_enc"]; ok {
+		t.Error("empty sealed column left in the export")
+	}
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// artifactColumns maps a retention artifact to its ekyc_sessions column. Only these
+// names are ever interpolated into SQL.
+var artifactColumns = map[string]string{
+	domain.ArtifactIDCard:        "id_card_url",
+	domain.ArtifactSelfieWithID:  "selfie_with_id_url",
+	domain.ArtifactRecordedVideo: "recorded_video_url",
+}
+
+// activeHoldExists matches holds of either scope: an application shares the id of the
+// session it was built from.
+const activeHoldExists = `EXISTS (
+    SELECT 1 FROM legal_holds h
+    WHERE h.released_at IS NULL AND h.target_id = s.id::text)`
+
+// dueArtifacts lists every artifact due at $4 under the periods in days $1..$3, with
+// whether its session is held. Retention is measured from the latest decision on the
+// session, falling back to updated_at for sessions decided before decision events existed.
+const dueArtifacts = `
+    WITH periods AS (
+        SELECT artifact, days
+        FROM (VALUES ('id_card', $1::int), ('selfie_with_id', $2::int), ('recorded_video', $3::int)) p(artifact, days)
+        WHERE days > 0
+    ), decided AS (
+        SELECT s.id, s.id_card_url, s.selfie_with_id_url, s.recorded_video_url,
+               COALESCE((SELECT MAX(e.created_at) FROM ekyc_session_events e
+                          WHERE e.ekyc_session_id = s.id AND e.event_type = 'decision.made'),
+                        s.updated_at) AS decided_at,
+               ` + activeHoldExists + ` AS held
+        FROM ekyc_sessions s
+        WHERE s.final_decision <> 'PENDING'
+          AND (s.id_card_url IS NOT NULL OR s.selfie_with_id_url IS NOT NULL OR s.recorded_video_url IS NOT NULL)
+    ), due AS (
+        SELECT d.id, a.artifact, a.url, d.decided_at, p.days,
+               d.decided_at + make_interval(days => p.days) AS due_at, d.held
+        FROM decided d
+        CROSS JOIN LATERAL (VALUES
+            ('id_card', d.id_card_url),
+            ('selfie_with_id', d.selfie_with_id_url),
+            ('recorded_video', d.recorded_video_url)) a(artifact, url)
+        JOIN periods p ON p.artifact = a.artifact
+        WHERE a.url IS NOT NULL AND d.decided_at + make_interval(days => p.days) <= $4
+    )`
+
+func dueArtifactArgs(periods map[string]int, now time.Time) []any {
+	return []any{periods[domain.ArtifactIDCard], periods[domain.ArtifactSelfieWithID], periods[domain.ArtifactRecordedVideo], now}
+}
+
+// ListRetentionCandidates lists purgeable artifacts before held ones, so holds never push
+// a purgeable artifact past limit, and drops the held ones unless includeHeld is set.
+func (repo *backofficeRepository) ListRetentionCandidates(ctx context.Context, periods map[string]int, now time.Time, limit int, includeHeld bool) ([]domain.RetentionCandidate, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+	args := append(dueArtifactArgs(periods, now), includeHeld, limit)
+	rows, err := repo.db.Query(ctx, dueArtifacts+`
+        SELECT id, artifact, url, decided_at, days, due_at, held
+        FROM due
+        WHERE $5 OR NOT held
+        ORDER BY held, due_at, id
+        LIMIT $6`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []domain.RetentionCandidate{}
+	for rows.Next() {
+		var c domain.RetentionCandidate
+		if err := rows.Scan(&c.SessionID, &c.Artifact, &c.URL, &c.DecidedAt, &c.RetentionDays, &c.DueAt, &c.Held); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+func (repo *backofficeRepository) CountHeldRetentionCandidates(ctx context.Context, periods map[string]int, now time.Time) (int, error) {
+	var held int
+	err := repo.db.QueryRow(ctx, dueArtifacts+`
+        SELECT COUNT(*) FROM due WHERE held`, dueArtifactArgs(periods, now)...).Scan(&held)
+	return held, err
+}
+
+// TombstoneArtifact leaves updated_at alone: clearing a URL is not a change to the
+// session and must not restart the retention clock of its other artifacts.
+func (repo *backofficeRepository) TombstoneArtifact(ctx context.Context, candidate domain.RetentionCandidate, actor string, deleteMedia func(context.Context) error) (*domain.ArtifactTombstone, error) {
+	column, ok := artifactColumns[candidate.Artifact]
+	if !ok {
+		return nil, fmt.Errorf("%w: artefak %q tidak dikenal", domain.ErrInvalidState, candidate.Artifact)
+	}
+	var tombstone domain.ArtifactTombstone
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, fmt.Sprintf(`
+            UPDATE ekyc_sessions s
+            SET %[1]s = NULL
+            WHERE s.id = $1 AND s.%[1]s = $2 AND NOT `+activeHoldExists, column),
+			candidate.SessionID, candidate.URL)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("%w: artefak berubah atau sesi dalam legal hold", domain.ErrInvalidState)
+		}
+		if candidate.Artifact == domain.ArtifactRecordedVideo {
+			if _, err := tx.Exec(ctx, `
+                UPDATE liveness_checks SET recorded_video_url = NULL
+                WHERE ekyc_session_id = $1 AND recorded_video_url = $2`,
+				candidate.SessionID, candidate.URL); err != nil {
+				return err
+			}
+		}
+		if err := tx.QueryRow(ctx, `
+            INSERT INTO ekyc_artifact_tombstones (ekyc_session_id, artifact, media_url, retention_days, purged_by)
+            VALUES ($1,$2,$3,$4,$5)
+            RETURNING id, ekyc_session_id, artifact, media_url, retention_days, purged_by, purged_at`,
+			candidate.SessionID, candidate.Artifact, candidate.URL, candidate.RetentionDays, actor,
+		).Scan(&tombstone.ID, &tombstone.SessionID, &tombstone.Artifact, &tombstone.MediaURL,
+			&tombstone.RetentionDays, &tombstone.PurgedBy, &tombstone.PurgedAt); err != nil {
+			return err
+		}
+		if err := repo.insertAudit(ctx, tx, domain.AuditEntry{
+			Actor:  actor,
+			Entity: candidate.SessionID,
+			Action: "EKYC:ARTIFACT_PURGED",
+			Metadata: map[string]any{
+				"artifact":      candidate.Artifact,
+				"retentionDays": candidate.RetentionDays,
+				"decidedAt":     candidate.DecidedAt,
+			},
+		}); err != nil {
+			return err
+		}
+		// Last, so a failed delete rolls the tombstone back and the next run retries.
+		return deleteMedia(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tombstone, nil
+}
+
+func (repo *backofficeRepository) ListArtifactTombstones(ctx context.Context, sessionID string, limit int) ([]domain.ArtifactTombstone, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	rows, err := repo.db.Query(ctx, `
+        SELECT id, ekyc_session_id, artifact, media_url, retention_days, purged_by, purged_at
+        FROM ekyc_artifact_tombstones
+        WHERE ($1 = '' OR ekyc_session_id::text = $1)
+        ORDER BY purged_at DESC, id DESC
+        LIMIT $2`, sessionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tombstones := []domain.ArtifactTombstone{}
+	for rows.Next() {
+		var t domain.ArtifactTombstone
+		if err := rows.Scan(&t.ID, &t.SessionID, &t.Artifact, &t.MediaURL, &t.RetentionDays, &t.PurgedBy, &t.PurgedAt); err != nil {
+			return nil, err
+		}
+		tombstones = append(tombstones, t)
+	}
+	return tombstones, rows.Err()
+}
+
+const legalHoldColumns = `id, scope, target_id, reason, placed_by, placed_at, released_by, released_at`
+
+func scanLegalHold(row pgx.Row) (*domain.LegalHold, error) {
+	var hold domain.LegalHold
+	if err := row.Scan(&hold.ID, &hold.Scope, &hold.TargetID, &hold.Reason, &hold.PlacedBy, &hold.PlacedAt,
+		&hold.ReleasedBy, &hold.ReleasedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &hold, nil
+}
+
+func (repo *backofficeRepository) ListLegalHolds(ctx context.Context, activeOnly bool) ([]domain.LegalHold, error) {
+	rows, err := repo.db.Query(ctx, `
+        SELECT `+legalHoldColumns+`
+        FROM legal_holds
+        WHERE NOT $1 OR released_at IS NULL
+        ORDER BY placed_at DESC`, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	holds := []domain.LegalHold{}
+	for rows.Next() {
+		hold, err := scanLegalHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, *hold)
+	}
+	return holds, rows.Err()
+}
+
+func (repo *backofficeRepository) PlaceLegalHold(ctx context.Context, params domain.PlaceLegalHoldParams) (*domain.LegalHold, error) {
+	var hold *domain.LegalHold
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		target := `SELECT EXISTS (SELECT 1 FROM ekyc_sessions WHERE id::text = $1)`
+		if params.Scope == domain.LegalHoldScopeApplication {
+			target = `SELECT EXISTS (SELECT 1 FROM applications WHERE id = $1)`
+		}
+		var exists bool
+		if err := tx.QueryRow(ctx, target, params.TargetID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrNotFound
+		}
+		row := tx.QueryRow(ctx, `
+            INSERT INTO legal_holds (scope, target_id, reason, placed_by)
+            VALUES ($1,$2,$3,$4)
+            RETURNING `+legalHoldColumns,
+			params.Scope, params.TargetID, params.Reason, params.Actor)
+		placed, err := scanLegalHold(row)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return fmt.Errorf("%w: legal hold aktif sudah ada", domain.ErrInvalidState)
+			}
+			return err
+		}
+		hold = placed
+		return repo.insertAudit(ctx, tx, domain.AuditEntry{
+			Actor:    params.Actor,
+			Entity:   params.TargetID,
+			Action:   "LEGAL_HOLD:PLACED",
+			Reason:   params.Reason,
+			Metadata: map[string]any{"holdId": placed.ID, "scope": placed.Scope},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (repo *backofficeRepository) ReleaseLegalHold(ctx context.Context, params domain.ReleaseLegalHoldParams) (*domain.LegalHold, error) {
+	var hold *domain.LegalHold
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		current, err := scanLegalHold(tx.QueryRow(ctx, `
+            SELECT `+legalHoldColumns+` FROM legal_holds WHERE id = $1 FOR UPDATE`, params.ID))
+		if err != nil {
+			return err
+		}
+		if current.ReleasedAt != nil {
+			return fmt.Errorf("%w: legal hold sudah dilepas", domain.ErrInvalidState)
+		}
+		hold, err = scanLegalHold(tx.QueryRow(ctx, `
+            UPDATE legal_holds
+            SET released_by = $2, released_at = NOW()
+            WHERE id = $1
+            RETURNING `+legalHoldColumns, params.ID, params.Actor))
+		if err != nil {
+			return err
+		}
+		return repo.insertAudit(ctx, tx, domain.AuditEntry{
+			Actor:    params.Actor,
+			Entity:   hold.TargetID,
+			Action:   "LEGAL_HOLD:RELEASED",
+			Metadata: map[string]any{"holdId": hold.ID, "scope": hold.Scope},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const visitGeofenceColumns = `geofence_flags, geofence_kelurahan, geofence_distance_m, geofence_checked_at`
+
+// visitGeofence builds the geofence of a visit from its columns, nil if never checked.
+func visitGeofence(flags []string, kelurahan *string, distance *float64, checkedAt *time.Time) *domain.VisitGeofence {
+	if checkedAt == nil {
+		return nil
+	}
+	if flags == nil {
+		flags = []string{}
+	}
+	return &domain.VisitGeofence{
+		Flags:          flags,
+		Kelurahan:      derefString(kelurahan),
+		DistanceMeters: distance,
+		CheckedAt:      *checkedAt,
+	}
+}
+
+// recordVisitGeofence checks the visit's stored geotag against the region of the
+// application's beneficiary and keeps the result. A flagged visit also gets a timeline
+// entry.
+func (repo *backofficeRepository) recordVisitGeofence(ctx context.Context, tx pgx.Tx, visitID, actor string, check domain.GeofenceCheck) error {
+	var (
+		appID               string
+		lat, lng            *float64
+		prov, kab, kec, kel *string
+	)
+	if err := tx.QueryRow(ctx, `
+        SELECT v.application_id, v.geotag_lat, v.geotag_lng,
+               u.region_prov, u.region_kab, u.region_kec, u.region_kel
+        FROM application_visits v
+        JOIN applications a ON a.id = v.application_id
+        LEFT JOIN users u ON u.id = a.beneficiary_user_id
+        WHERE v.id = $1`, visitID,
+	).Scan(&appID, &lat, &lng, &prov, &kab, &kec, &kel); err != nil {
+		return err
+	}
+	result := check(lat, lng, domain.Region{
+		Prov: derefString(prov),
+		Kab:  derefString(kab),
+		Kec:  derefString(kec),
+		Kel:  derefString(kel),
+	})
+	if result.Flags == nil {
+		result.Flags = []string{}
+	}
+	if _, err := tx.Exec(ctx, `
+        UPDATE application_visits
+        SET geofence_flags = $2, geofence_kelurahan = NULLIF($3, ''), geofence_distance_m = $4, geofence_checked_at = $5
+        WHERE id = $1`,
+		visitID, result.Flags, result.Kelurahan, result.DistanceMeters, result.CheckedAt); err != nil {
+		return err
+	}
+	if !result.Flagged() {
+		return nil
+	}
+	metadata := map[string]any{"visitId": visitID, "flags": result.Flags}
+	if result.Kelurahan != "" {
+		metadata["kelurahan"] = result.Kelurahan
+	}
+	if result.DistanceMeters != nil {
+		metadata["distanceMeters"] = *result.DistanceMeters
+	}
+	return repo.insertTimeline(ctx, tx, domain.TimelineEntry{
+		ApplicationID: appID,
+		Actor:         actor,
+		Action:        "VISIT:GEOFENCE_FLAGGED",
+		Reason:        strings.Join(result.Flags, ", "),
+		Metadata:      metadata,
+	})
+}
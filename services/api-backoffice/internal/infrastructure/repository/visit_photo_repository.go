@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type rowQueryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+const visitPhotoColumns = `p.id, p.visit_id, p.url, p.sha256, p.phash, p.captured_at, p.gps_lat, p.gps_lng,
+    COALESCE(p.camera, ''), p.flags, p.time_delta_minutes, p.distance_m, p.uploaded_by, p.uploaded_at`
+
+func scanVisitPhoto(row pgx.Row) (*domain.VisitPhoto, error) {
+	var (
+		photo domain.VisitPhoto
+		hash  *int64
+	)
+	if err := row.Scan(&photo.ID, &photo.VisitID, &photo.URL, &photo.SHA256, &hash, &photo.CapturedAt,
+		&photo.GpsLat, &photo.GpsLng, &photo.Camera, &photo.Verification.Flags,
+		&photo.Verification.TimeDeltaMinutes, &photo.Verification.DistanceMeters,
+		&photo.UploadedBy, &photo.UploadedAt); err != nil {
+		return nil, err
+	}
+	if hash != nil {
+		photo.PerceptualHash = fmt.Sprintf("%016x", uint64(*hash))
+	}
+	if photo.Verification.Flags == nil {
+		photo.Verification.Flags = []string{}
+	}
+	return &photo, nil
+}
+
+func (repo *backofficeRepository) FindSimilarVisitPhoto(ctx context.Context, hash uint64, maxDistance int) (*domain.VisitPhotoMatch, error) {
+	return querySimilarVisitPhoto(ctx, repo.db, hash, maxDistance)
+}
+
+// querySimilarVisitPhoto finds the stored photo nearest to hash. Hashes are kept as
+// BIGINT, so the XOR is cast to bit(64) to count the bits that differ.
+func querySimilarVisitPhoto(ctx context.Context, q rowQueryer, hash uint64, maxDistance int) (*domain.VisitPhotoMatch, error) {
+	var match domain.VisitPhotoMatch
+	if err := q.QueryRow(ctx, `
+        SELECT p.id, p.visit_id, v.application_id, p.url, bit_count((p.phash # $1)::bit(64))::int AS distance
+        FROM visit_photos p
+        JOIN application_visits v ON v.id = p.visit_id
+        WHERE p.phash IS NOT NULL AND bit_count((p.phash # $1)::bit(64)) <= $2
+        ORDER BY distance, p.id
+        LIMIT 1`, int64(hash), maxDistance,
+	).Scan(&match.PhotoID, &match.VisitID, &match.ApplicationID, &match.URL, &match.HashDistance); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &match, nil
+}
+
+func (repo *backofficeRepository) AddVisitPhoto(ctx context.Context, params domain.AddVisitPhotoParams) (*domain.VisitPhoto, error) {
+	var stored *domain.VisitPhoto
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		photo := params.Photo
+		var (
+			tkskID      *string
+			status      string
+			scheduledAt time.Time
+			lat, lng    *float64
+		)
+		if err := tx.QueryRow(ctx, `
+            SELECT tksk_id::text FROM application_visits WHERE id = $1 AND application_id = $2`,
+			photo.VisitID, params.ApplicationID).Scan(&tkskID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+		if tkskID != nil {
+			if err := lockTkskVisits(ctx, tx, *tkskID); err != nil {
+				return err
+			}
+		}
+		// Reuse checks see every photo stored before them.
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('visit_photos'))`); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(ctx, `
+            SELECT status, scheduled_at, geotag_lat, geotag_lng FROM application_visits WHERE id = $1 FOR UPDATE`,
+			photo.VisitID).Scan(&status, &scheduledAt, &lat, &lng); err != nil {
+			return err
+		}
+		if status == domain.VisitStatusVerified {
+			return fmt.Errorf("%w: kunjungan %s sudah diverifikasi", domain.ErrInvalidState, photo.VisitID)
+		}
+		var hash *int64
+		if params.Hash != nil {
+			match, err := querySimilarVisitPhoto(ctx, tx, *params.Hash, params.ReuseDistance)
+			switch {
+			case err == nil:
+				return &domain.PhotoReuseError{Match: *match}
+			case !errors.Is(err, domain.ErrNotFound):
+				return err
+			}
+			hash = new(int64)
+			*hash = int64(*params.Hash)
+		}
+		if params.Check != nil {
+			photo.Verification = params.Check(&photo, scheduledAt, lat, lng)
+		}
+		if photo.Verification.Flags == nil {
+			photo.Verification.Flags = []string{}
+		}
+
+		var err error
+		stored, err = scanVisitPhoto(tx.QueryRow(ctx, `
+            INSERT INTO visit_photos AS p (visit_id, url, sha256, phash, captured_at, gps_lat, gps_lng, camera,
+                flags, time_delta_minutes, distance_m, uploaded_by)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11, $12)
+            RETURNING `+visitPhotoColumns,
+			photo.VisitID, photo.URL, photo.SHA256, hash, photo.CapturedAt, photo.GpsLat, photo.GpsLng,
+			photo.Camera, photo.Verification.Flags, photo.Verification.TimeDeltaMinutes,
+			photo.Verification.DistanceMeters, params.Actor))
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+            UPDATE application_visits SET photos = photos || jsonb_build_array($2::text), `+visitChangeBump+`
+            WHERE id = $1`, photo.VisitID, photo.URL); err != nil {
+			return err
+		}
+		action := "VISIT:PHOTO_ADDED"
+		if stored.Verification.Flagged() {
+			action = "VISIT:PHOTO_FLAGGED"
+		}
+		return repo.insertTimeline(ctx, tx, domain.TimelineEntry{
+			ApplicationID: params.ApplicationID,
+			Actor:         params.Actor,
+			Action:        action,
+			Metadata: map[string]any{
+				"visitId": photo.VisitID,
+				"photoId": stored.ID,
+				"url":     stored.URL,
+				"flags":   stored.Verification.Flags,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (repo *backofficeRepository) ListVisitPhotos(ctx context.Context, appID, visitID string) ([]domain.VisitPhoto, error) {
+	var exists bool
+	if err := repo.db.QueryRow(ctx, `
+        SELECT EXISTS (SELECT 1 FROM application_visits WHERE id = $1 AND application_id = $2)`,
+		visitID, appID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+	// Photos removed from the visit by a later update keep their row for reuse checks
+	// but are no longer listed.
+	rows, err := repo.db.Query(ctx, `
+        SELECT `+visitPhotoColumns+`
+        FROM visit_photos p
+        JOIN application_visits v ON v.id = p.visit_id
+        WHERE p.visit_id = $1 AND v.photos ? p.url
+        ORDER BY p.uploaded_at, p.id`, visitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	photos := []domain.VisitPhoto{}
+	for rows.Next() {
+		photo, err := scanVisitPhoto(rows)
+		if err != nil {
+			return nil, err
+		}
+		photos = append(photos, *photo)
+	}
+	return photos, rows.Err()
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// regionScopeFilter matches the beneficiary (aliased u) when the text[] parameter is
+// empty or names their province, regency, district or village, as coversRegion does.
+func regionScopeFilter(param int) string {
+	return fmt.Sprintf(`(cardinality($%[1]d::text[]) = 0 OR EXISTS (
+            SELECT 1 FROM unnest($%[1]d::text[]) s
+            WHERE lower(trim(s)) IN (lower(u.region_prov), lower(u.region_kab), lower(u.region_kec), lower(u.region_kel))))`, param)
+}
+
+func (repo *backofficeRepository) ListVisitReviewQueue(ctx context.Context, params domain.ListVisitReviewQueueParams) ([]domain.VisitReviewQueueItem, error) {
+	region := []string{}
+	if params.Region != "" {
+		region = append(region, params.Region)
+	}
+	rows, err := repo.db.Query(ctx, `
+        SELECT v.id, v.application_id, a.applicant_name, u.region_prov, u.region_kab, u.region_kec, u.region_kel,
+               COALESCE(v.tksk_id::text, ''), COALESCE(t.name, ''), v.scheduled_at, v.updated_at, v.version,
+               v.geofence_flags,
+               CASE WHEN jsonb_typeof(v.photos) = 'array' THEN jsonb_array_length(v.photos) ELSE 0 END,
+               (SELECT COUNT(*) FROM visit_photos p
+                WHERE p.visit_id = v.id AND cardinality(p.flags) > 0 AND v.photos ? p.url),
+               (SELECT COUNT(*) FROM visit_reviews r WHERE r.visit_id = v.id AND r.decision = $2)
+        FROM application_visits v
+        JOIN applications a ON a.id = v.application_id
+        JOIN users u ON u.id = a.beneficiary_user_id
+        LEFT JOIN users t ON t.id = v.tksk_id
+        WHERE v.status = $1 AND `+regionScopeFilter(3)+` AND `+regionScopeFilter(4)+`
+        ORDER BY v.updated_at, v.id
+        LIMIT $5`,
+		domain.VisitStatusSubmitted, domain.VisitReviewReturned,
+		append([]string{}, params.RegionScope...), region, params.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []domain.VisitReviewQueueItem{}
+	for rows.Next() {
+		var (
+			item                domain.VisitReviewQueueItem
+			prov, kab, kec, kel *string
+		)
+		if err := rows.Scan(&item.VisitID, &item.ApplicationID, &item.ApplicantName, &prov, &kab, &kec, &kel,
+			&item.TkskID, &item.TkskName, &item.ScheduledAt, &item.UpdatedAt, &item.Version,
+			&item.GeofenceFlags, &item.Photos, &item.FlaggedPhotos, &item.Returns); err != nil {
+			return nil, err
+		}
+		item.Region = domain.Region{
+			Prov: derefString(prov),
+			Kab:  derefString(kab),
+			Kec:  derefString(kec),
+			Kel:  derefString(kel),
+		}
+		if item.GeofenceFlags == nil {
+			item.GeofenceFlags = []string{}
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (repo *backofficeRepository) GetVisitReviewTarget(ctx context.Context, appID, visitID string) (*domain.VisitReviewTarget, error) {
+	var (
+		target              domain.VisitReviewTarget
+		prov, kab, kec, kel *string
+	)
+	if err := repo.db.QueryRow(ctx, `
+        SELECT COALESCE(v.tksk_id::text, ''), v.status,
+               u.region_prov, u.region_kab, u.region_kec, u.region_kel
+        FROM application_visits v
+        JOIN applications a ON a.id = v.application_id
+        JOIN users u ON u.id = a.beneficiary_user_id
+        WHERE v.id = $1 AND v.application_id = $2`, visitID, appID,
+	).Scan(&target.TkskID, &target.Status, &prov, &kab, &kec, &kel); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	target.Region = domain.Region{
+		Prov: derefString(prov),
+		Kab:  derefString(kab),
+		Kec:  derefString(kec),
+		Kel:  derefString(kel),
+	}
+	return &target, nil
+}
+
+func (repo *backofficeRepository) RecordVisitReview(ctx context.Context, params domain.ReviewVisitParams) (*domain.VisitReview, error) {
+	status := domain.VisitStatusVerified
+	if params.Decision == domain.VisitReviewReturned {
+		status = domain.VisitStatusInProgress
+	}
+	review := domain.VisitReview{
+		VisitID:      params.VisitID,
+		Decision:     params.Decision,
+		Comment:      params.Comment,
+		Reviewer:     params.Reviewer,
+		ReviewerRole: params.ReviewerRole,
+	}
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		var tkskID *string
+		if err := tx.QueryRow(ctx, `SELECT tksk_id::text FROM application_visits WHERE id = $1 AND application_id = $2`,
+			params.VisitID, params.ApplicationID).Scan(&tkskID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+		if tkskID != nil {
+			if err := lockTkskVisits(ctx, tx, *tkskID); err != nil {
+				return err
+			}
+		}
+		var current string
+		if err := tx.QueryRow(ctx, `SELECT status, version FROM application_visits WHERE id = $1 FOR UPDATE`,
+			params.VisitID).Scan(&current, &review.VisitVersion); err != nil {
+			return err
+		}
+		if current != domain.VisitStatusSubmitted {
+			return fmt.Errorf("%w: kunjungan %s berstatus %s, bukan %s", domain.ErrInvalidState, params.VisitID, current, domain.VisitStatusSubmitted)
+		}
+		if params.Version > 0 && params.Version != review.VisitVersion {
+			return fmt.Errorf("%w: kunjungan %s sudah berubah sejak ditinjau (versi %d, sekarang %d)",
+				domain.ErrInvalidState, params.VisitID, params.Version, review.VisitVersion)
+		}
+		if _, err := tx.Exec(ctx, `
+            UPDATE application_visits SET status = $2, `+visitChangeBump+`
+            WHERE id = $1`, params.VisitID, status); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(ctx, `
+            INSERT INTO visit_reviews (visit_id, decision, comment, reviewer, reviewer_role, visit_version)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            RETURNING id, created_at`,
+			review.VisitID, review.Decision, review.Comment, review.Reviewer, review.ReviewerRole, review.VisitVersion,
+		).Scan(&review.ID, &review.CreatedAt); err != nil {
+			return err
+		}
+		if params.Timeline.Metadata != nil {
+			params.Timeline.Metadata["reviewId"] = review.ID
+		}
+		if err := repo.insertTimeline(ctx, tx, params.Timeline); err != nil {
+			return err
+		}
+		return repo.insertAudit(ctx, tx, params.Audit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (repo *backofficeRepository) ListVisitReviews(ctx context.Context, appID, visitID string) ([]domain.VisitReview, error) {
+	var exists bool
+	if err := repo.db.QueryRow(ctx, `
+        SELECT EXISTS (SELECT 1 FROM application_visits WHERE id = $1 AND application_id = $2)`,
+		visitID, appID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, domain.ErrNotFound
+	}
+	rows, err := repo.db.Query(ctx, `
+        SELECT id, visit_id, decision, comment, reviewer, reviewer_role, visit_version, created_at
+        FROM visit_reviews
+        WHERE visit_id = $1
+        ORDER BY created_at, id`, visitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []domain.VisitReview{}
+	for rows.Next() {
+		var review domain.VisitReview
+		if err := rows.Scan(&review.ID, &review.VisitID, &review.Decision, &review.Comment, &review.Reviewer,
+			&review.ReviewerRole, &review.VisitVersion, &review.CreatedAt); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+	return reviews, rows.Err()
+}
+
+// awaitsVerifiedVisit reports whether FINAL_APPROVED must wait for the application's
+// visit: the requireVerifiedVisit flag is on and no visit is verified yet. Callers run
+// it in the transaction that writes the status, so every path to FINAL_APPROVED obeys
+// the flag and not only the status endpoint.
+func awaitsVerifiedVisit(ctx context.Context, q rowQueryer, appID string) (bool, error) {
+	var waits bool
+	err := q.QueryRow(ctx, `
+        SELECT COALESCE((SELECT features->'`+domain.FeatureRequireVerifiedVisit+`' = 'true'::jsonb
+                           FROM system_config WHERE id = 1), false)
+           AND NOT EXISTS (SELECT 1 FROM application_visits
+                            WHERE application_id = $1 AND upper(status) = $2)`,
+		appID, domain.VisitStatusVerified).Scan(&waits)
+	return waits, err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+func TestFinalApprovalWaitsForVerifiedVisit(t *testing.T) {
+	pool := openTestDB(t)
+	ctx := context.Background()
+	repo := NewBackofficeRepository(pool, nil).(*backofficeRepository)
+
+	var userID, appID string
+	if err := pool.QueryRow(ctx, `
+        INSERT INTO users (role, name) VALUES ('beneficiary', 'Siti')
+        RETURNING id`).Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.QueryRow(ctx, `
+        INSERT INTO applications (id, beneficiary_user_id, applicant_name, status, stage)
+        VALUES (gen_random_uuid()::text, $1, 'Siti', 'DESK_REVIEW', 'KYC')
+        RETURNING id`, userID).Scan(&appID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, `
+        INSERT INTO application_visits (id, application_id, scheduled_at, status)
+        VALUES ('VST-1', $1, NOW(), $2)`, appID, domain.VisitStatusSubmitted); err != nil {
+		t.Fatal(err)
+	}
+	setFlag := func(on bool) {
+		t.Helper()
+		if _, err := pool.Exec(ctx, `
+            INSERT INTO system_config (id, period, features) VALUES (1, '2025', jsonb_build_object($1::text, $2::boolean))
+            ON CONFLICT (id) DO UPDATE SET features = EXCLUDED.features`,
+			domain.FeatureRequireVerifiedVisit, on); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status := func() (string, string) {
+		t.Helper()
+		var status, stage string
+		if err := pool.QueryRow(ctx, `SELECT status, stage FROM applications WHERE id = $1`, appID).Scan(&status, &stage); err != nil {
+			t.Fatal(err)
+		}
+		return status, stage
+	}
+	approve := func() error {
+		return repo.UpdateApplicationStatus(ctx, domain.UpdateApplicationStatusParams{AppID: appID, Status: "FINAL_APPROVED"})
+	}
+
+	setFlag(true)
+	if err := approve(); !errors.Is(err, domain.ErrInvalidState) {
+		t.Errorf("approved with a submitted visit only: %v", err)
+	}
+	if err := repo.syncApplicationStatus(ctx, nil, appID, "APPROVED"); err != nil {
+		t.Fatal(err)
+	}
+	if status, stage := status(); status != "FIELD_VISIT" || stage != "FIELD" {
+		t.Errorf("eKYC approval moved the application to %s/%s, want FIELD_VISIT/FIELD", status, stage)
+	}
+
+	if _, err := pool.Exec(ctx, `UPDATE application_visits SET status = $1 WHERE id = 'VST-1'`, domain.VisitStatusVerified); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.syncApplicationStatus(ctx, nil, appID, "APPROVED"); err != nil {
+		t.Fatal(err)
+	}
+	if status, stage := status(); status != "FINAL_APPROVED" || stage != "DISBURSEMENT" {
+		t.Errorf("eKYC approval with a verified visit moved the application to %s/%s", status, stage)
+	}
+
+	setFlag(false)
+	if _, err := pool.Exec(ctx, `UPDATE application_visits SET status = $1 WHERE id = 'VST-1'`, domain.VisitStatusSubmitted); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.syncApplicationStatus(ctx, nil, appID, "REJECTED"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.syncApplicationStatus(ctx, nil, appID, "APPROVED"); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ := status(); status != "FINAL_APPROVED" {
+		t.Errorf("eKYC approval without the flag moved the application to %s", status)
+	}
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func (repo *backofficeRepository) ListRouteVisits(ctx context.Context, tkskID string, from, to time.Time) ([]domain.RouteVisit, error) {
+	// The household is where an earlier visit to the application was geotagged, as long
+	// as the geofence check did not flag that geotag.
+	rows, err := repo.db.Query(ctx, `
+        SELECT v.id, v.application_id, a.applicant_name, v.scheduled_at,
+               u.region_prov, u.region_kab, u.region_kec, u.region_kel,
+               h.geotag_lat, h.geotag_lng
+        FROM application_visits v
+        JOIN applications a ON a.id = v.application_id
+        LEFT JOIN users u ON u.id = a.beneficiary_user_id
+        LEFT JOIN LATERAL (
+            SELECT p.geotag_lat, p.geotag_lng
+            FROM application_visits p
+            WHERE p.application_id = v.application_id AND p.id <> v.id
+              AND p.status IN ($4, $5)
+              AND p.geotag_lat IS NOT NULL AND p.geotag_lng IS NOT NULL
+              AND cardinality(p.geofence_flags) = 0
+            ORDER BY p.scheduled_at DESC
+            LIMIT 1
+        ) h ON TRUE
+        WHERE v.tksk_id = $1 AND v.status = $6
+          AND v.scheduled_at >= $2 AND v.scheduled_at < $3
+        ORDER BY v.scheduled_at, v.id`,
+		tkskID, from, to, domain.VisitStatusSubmitted, domain.VisitStatusVerified, domain.VisitStatusPlanned)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	visits := []domain.RouteVisit{}
+	for rows.Next() {
+		var (
+			visit               domain.RouteVisit
+			prov, kab, kec, kel *string
+		)
+		if err := rows.Scan(&visit.VisitID, &visit.ApplicationID, &visit.ApplicantName, &visit.ScheduledAt,
+			&prov, &kab, &kec, &kel, &visit.HouseholdLat, &visit.HouseholdLng); err != nil {
+			return nil, err
+		}
+		visit.Region = domain.Region{
+			Prov: derefString(prov),
+			Kab:  derefString(kab),
+			Kec:  derefString(kec),
+			Kel:  derefString(kel),
+		}
+		visits = append(visits, visit)
+	}
+	return visits, rows.Err()
+}
+
+func (repo *backofficeRepository) RescheduleVisits(ctx context.Context, params domain.RescheduleVisitsParams) ([]domain.CalendarVisit, error) {
+	var moved []domain.CalendarVisit
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		if err := lockTkskVisits(ctx, tx, params.TkskID); err != nil {
+			return err
+		}
+		current := make(map[string]domain.CalendarVisit, len(params.Stops))
+		for _, stop := range params.Stops {
+			var (
+				visit  domain.CalendarVisit
+				tkskID string
+			)
+			if err := tx.QueryRow(ctx, `
+                SELECT v.id, v.application_id, a.applicant_name, v.status, v.scheduled_at, COALESCE(v.tksk_id::text, '')
+                FROM application_visits v
+                JOIN applications a ON a.id = v.application_id
+                WHERE v.id = $1
+                FOR UPDATE OF v`, stop.VisitID,
+			).Scan(&visit.VisitID, &visit.ApplicationID, &visit.ApplicantName, &visit.Status, &visit.Start, &tkskID); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return fmt.Errorf("%w: kunjungan %s", domain.ErrNotFound, stop.VisitID)
+				}
+				return err
+			}
+			if tkskID != params.TkskID {
+				return fmt.Errorf("%w: kunjungan %s bukan milik TKSK ini", domain.ErrInvalidState, stop.VisitID)
+			}
+			if visit.Status != domain.VisitStatusPlanned {
+				return fmt.Errorf("%w: kunjungan %s berstatus %s", domain.ErrInvalidState, stop.VisitID, visit.Status)
+			}
+			current[stop.VisitID] = visit
+		}
+
+		// The visits being moved no longer block their old slots, only the others do.
+		// As in CreateScheduledVisit, starts less than one duration apart overlap.
+		first, last := params.Stops[0].ScheduledAt, params.Stops[0].ScheduledAt
+		for _, stop := range params.Stops[1:] {
+			if stop.ScheduledAt.Before(first) {
+				first = stop.ScheduledAt
+			}
+			if stop.ScheduledAt.After(last) {
+				last = stop.ScheduledAt
+			}
+		}
+		others, err := queryCalendarVisits(ctx, tx, params.TkskID,
+			first.Add(-params.Duration).Add(time.Microsecond), last.Add(params.Duration), blockingVisitStatuses)
+		if err != nil {
+			return err
+		}
+		var conflicts []domain.CalendarVisit
+		for _, other := range others {
+			if _, moving := current[other.VisitID]; moving {
+				continue
+			}
+			for _, stop := range params.Stops {
+				if gap := stop.ScheduledAt.Sub(other.Start); gap < params.Duration && -gap < params.Duration {
+					other.End = other.Start.Add(params.Duration)
+					conflicts = append(conflicts, other)
+					break
+				}
+			}
+		}
+		if len(conflicts) > 0 {
+			return &domain.ScheduleConflictError{Conflicts: conflicts}
+		}
+
+		moved = make([]domain.CalendarVisit, 0, len(params.Stops))
+		for _, stop := range params.Stops {
+			visit := current[stop.VisitID]
+			from := visit.Start
+			visit.Start = stop.ScheduledAt
+			visit.End = stop.ScheduledAt.Add(params.Duration)
+			moved = append(moved, visit)
+			if from.Equal(stop.ScheduledAt) {
+				continue
+			}
+			if _, err := tx.Exec(ctx, `
+                UPDATE application_visits SET scheduled_at = $2, `+visitChangeBump+`
+                WHERE id = $1`, stop.VisitID, stop.ScheduledAt); err != nil {
+				return err
+			}
+			if err := repo.insertTimeline(ctx, tx, domain.TimelineEntry{
+				ApplicationID: visit.ApplicationID,
+				Actor:         params.Actor,
+				Action:        "VISIT:RESCHEDULED",
+				Metadata: map[string]any{
+					"visitId": stop.VisitID,
+					"from":    from,
+					"to":      stop.ScheduledAt,
+					"route":   true,
+				},
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return moved, nil
+}
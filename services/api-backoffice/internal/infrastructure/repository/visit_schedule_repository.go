@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// blockingVisitStatuses occupy a TKSK's time; submitted and verified visits are done.
+var blockingVisitStatuses = []string{domain.VisitStatusPlanned, domain.VisitStatusInProgress}
+
+type queryer interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func (repo *backofficeRepository) GetVisitAssignee(ctx context.Context, userID string) (*domain.VisitAssignee, error) {
+	var assignee domain.VisitAssignee
+	if err := repo.db.QueryRow(ctx, `
+        SELECT id, name, role, region_scope
+        FROM users
+        WHERE id::text = $1`, userID,
+	).Scan(&assignee.ID, &assignee.Name, &assignee.Role, &assignee.RegionScope); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &assignee, nil
+}
+
+func (repo *backofficeRepository) GetApplicationRegion(ctx context.Context, appID string) (*domain.Region, error) {
+	var prov, kab, kec, kel *string
+	if err := repo.db.QueryRow(ctx, `
+        SELECT u.region_prov, u.region_kab, u.region_kec, u.region_kel
+        FROM applications a
+        JOIN users u ON u.id = a.beneficiary_user_id
+        WHERE a.id = $1`, appID,
+	).Scan(&prov, &kab, &kec, &kel); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &domain.Region{
+		Prov: derefString(prov),
+		Kab:  derefString(kab),
+		Kec:  derefString(kec),
+		Kel:  derefString(kel),
+	}, nil
+}
+
+func (repo *backofficeRepository) ListTkskVisits(ctx context.Context, tkskID string, from, to time.Time, statuses []string) ([]domain.CalendarVisit, error) {
+	return queryCalendarVisits(ctx, repo.db, tkskID, from, to, statuses)
+}
+
+func queryCalendarVisits(ctx context.Context, q queryer, tkskID string, from, to time.Time, statuses []string) ([]domain.CalendarVisit, error) {
+	rows, err := q.Query(ctx, `
+        SELECT v.id, v.application_id, a.applicant_name, v.status, v.scheduled_at
+        FROM application_visits v
+        JOIN applications a ON a.id = v.application_id
+        WHERE v.tksk_id = $1 AND v.status = ANY($2)
+          AND v.scheduled_at >= $3 AND v.scheduled_at < $4
+        ORDER BY v.scheduled_at, v.id`, tkskID, statuses, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	visits := []domain.CalendarVisit{}
+	for rows.Next() {
+		var visit domain.CalendarVisit
+		if err := rows.Scan(&visit.VisitID, &visit.ApplicationID, &visit.ApplicantName, &visit.Status, &visit.Start); err != nil {
+			return nil, err
+		}
+		visits = append(visits, visit)
+	}
+	return visits, rows.Err()
+}
+
+func (repo *backofficeRepository) CreateScheduledVisit(ctx context.Context, visit *domain.Visit, duration time.Duration, timeline domain.TimelineEntry) error {
+	return repo.withTx(ctx, func(tx pgx.Tx) error {
+		if err := lockTkskVisits(ctx, tx, visit.TkskID); err != nil {
+			return err
+		}
+		// Visits overlap when their starts are less than one duration apart. Postgres keeps
+		// microseconds, so the lower bound is nudged to make it exclusive.
+		conflicts, err := queryCalendarVisits(ctx, tx, visit.TkskID,
+			visit.ScheduledAt.Add(-duration).Add(time.Microsecond), visit.ScheduledAt.Add(duration), blockingVisitStatuses)
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			for i := range conflicts {
+				conflicts[i].End = conflicts[i].Start.Add(duration)
+			}
+			return &domain.ScheduleConflictError{Conflicts: conflicts}
+		}
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO application_visits (id, application_id, scheduled_at, status, tksk_id, checklist_template_id)
+            VALUES ($1,$2,$3,$4,$5,$6)`,
+			visit.ID, visit.ApplicationID, visit.ScheduledAt, visit.Status, visit.TkskID, visit.ChecklistTemplateID); err != nil {
+			return err
+		}
+		return repo.insertTimeline(ctx, tx, timeline)
+	})
+}
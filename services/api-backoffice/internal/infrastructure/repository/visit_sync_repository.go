@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// visitChangeBump goes into the SET list of every UPDATE of application_visits, so
+// offline devices see the change as a new version in their next delta.
+const visitChangeBump = `version = version + 1, updated_at = NOW(), change_seq = nextval('application_visits_change_seq')`
+
+const syncedVisitColumns = `v.id, v.application_id, a.applicant_name, v.scheduled_at, v.status,
+        v.geotag_lat, v.geotag_lng, v.photos, v.checklist, v.version, v.updated_at,
+        v.geofence_flags, v.geofence_kelurahan, v.geofence_distance_m, v.geofence_checked_at, v.checklist_template_id`
+
+// lockTkskVisits serializes writes to one TKSK's visits. Sync tokens rely on it: a
+// change_seq taken under the lock is committed before anyone else reads the maximum.
+func lockTkskVisits(ctx context.Context, tx execer, tkskID string) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('application_visits:' || $1::text))`, tkskID)
+	return err
+}
+
+// versionStep records that a device's base version of a visit was moved to a newer one
+// earlier in the batch, so its follow-up mutations on that visit do not conflict.
+type versionStep struct {
+	from, to int
+}
+
+func (repo *backofficeRepository) ApplyVisitSync(ctx context.Context, params domain.ApplyVisitSyncParams) ([]domain.VisitMutationResult, []domain.SyncedVisit, int64, error) {
+	var (
+		results []domain.VisitMutationResult
+		changes []domain.SyncedVisit
+		token   int64
+	)
+	err := repo.withTx(ctx, func(tx pgx.Tx) error {
+		if err := lockTkskVisits(ctx, tx, params.TkskID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO tksk_devices (device_id, tksk_id) VALUES ($1, $2)
+            ON CONFLICT (device_id) DO NOTHING`, params.DeviceID, params.TkskID); err != nil {
+			return err
+		}
+		var owner string
+		if err := tx.QueryRow(ctx, `SELECT tksk_id::text FROM tksk_devices WHERE device_id = $1`, params.DeviceID).Scan(&owner); err != nil {
+			return err
+		}
+		if owner != params.TkskID {
+			return fmt.Errorf("%w: perangkat %s terdaftar untuk TKSK lain", domain.ErrForbidden, params.DeviceID)
+		}
+
+		rebased := map[string]versionStep{}
+		results = make([]domain.VisitMutationResult, 0, len(params.Mutations))
+		for _, mutation := range params.Mutations {
+			result, err := repo.applyVisitMutation(ctx, tx, params, mutation, rebased)
+			if err != nil {
+				return fmt.Errorf("mutation %s: %w", mutation.MutationID, err)
+			}
+			results = append(results, result)
+		}
+
+		rows, err := tx.Query(ctx, `
+            SELECT `+syncedVisitColumns+`
+            FROM application_visits v
+            JOIN applications a ON a.id = v.application_id
+            WHERE v.tksk_id = $1 AND v.change_seq > $2
+            ORDER BY v.change_seq`, params.TkskID, params.Since)
+		if err != nil {
+			return err
+		}
+		changes = []domain.SyncedVisit{}
+		for rows.Next() {
+			visit, err := scanSyncedVisit(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			changes = append(changes, *visit)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if err := tx.QueryRow(ctx, `
+            SELECT COALESCE(MAX(change_seq), 0) FROM application_visits WHERE tksk_id = $1`,
+			params.TkskID).Scan(&token); err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `
+            UPDATE tksk_devices SET last_sync_token = $2, last_synced_at = NOW()
+            WHERE device_id = $1`, params.DeviceID, token)
+		return err
+	})
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return results, changes, token, nil
+}
+
+func (repo *backofficeRepository) applyVisitMutation(ctx context.Context, tx pgx.Tx, params domain.ApplyVisitSyncParams, mutation domain.VisitMutation, rebased map[string]versionStep) (domain.VisitMutationResult, error) {
+	result := domain.VisitMutationResult{MutationID: mutation.MutationID, VisitID: mutation.VisitID}
+	rejected := func(msg string) (domain.VisitMutationResult, error) {
+		result.Result = domain.VisitSyncRejected
+		result.Error = msg
+		return result, nil
+	}
+
+	var prior struct {
+		visitID       string
+		base, version int
+	}
+	err := tx.QueryRow(ctx, `
+        SELECT visit_id, base_version, version FROM visit_sync_mutations
+        WHERE device_id = $1 AND mutation_id = $2`, params.DeviceID, mutation.MutationID,
+	).Scan(&prior.visitID, &prior.base, &prior.version)
+	switch {
+	case err == nil:
+		if prior.visitID != mutation.VisitID {
+			return rejected(fmt.Sprintf("mutationId sudah dipakai untuk kunjungan %s", prior.visitID))
+		}
+		rebased[mutation.VisitID] = versionStep{from: prior.base, to: prior.version}
+		result.Result = domain.VisitSyncDuplicate
+		result.Version = prior.version
+		return result, nil
+	case !errors.Is(err, pgx.ErrNoRows):
+		return result, err
+	}
+
+	var (
+		tkskID, status string
+		version        int
+	)
+	if err := tx.QueryRow(ctx, `
+        SELECT COALESCE(tksk_id::text, ''), status, version FROM application_visits
+        WHERE id = $1 FOR UPDATE`, mutation.VisitID,
+	).Scan(&tkskID, &status, &version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return rejected("kunjungan tidak ditemukan")
+		}
+		return result, err
+	}
+	if tkskID != params.TkskID {
+		return rejected("kunjungan ditugaskan ke TKSK lain")
+	}
+	if status == domain.VisitStatusVerified {
+		return rejected("kunjungan sudah diverifikasi")
+	}
+
+	base := mutation.BaseVersion
+	if step, ok := rebased[mutation.VisitID]; ok && step.from == base {
+		base = step.to
+	}
+	if version != base {
+		visit, err := scanSyncedVisit(tx.QueryRow(ctx, `
+            SELECT `+syncedVisitColumns+`
+            FROM application_visits v
+            JOIN applications a ON a.id = v.application_id
+            WHERE v.id = $1`, mutation.VisitID))
+		if err != nil {
+			return result, err
+		}
+		result.Result = domain.VisitSyncConflict
+		result.Version = version
+		result.Error = fmt.Sprintf("kunjungan berubah di server (versi %d, perangkat %d)", version, mutation.BaseVersion)
+		result.Visit = visit
+		return result, nil
+	}
+
+	submitting := mutation.Status != nil && *mutation.Status == domain.VisitStatusSubmitted
+	if params.ChecklistCheck != nil && (mutation.Checklist != nil || submitting) {
+		if err := checkVisitChecklist(ctx, tx, mutation.VisitID, mutation.Checklist, submitting, params.ChecklistCheck); err != nil {
+			var invalid *domain.ChecklistValidationError
+			if !errors.As(err, &invalid) {
+				return result, err
+			}
+			result.FieldErrors = invalid.Fields
+			return rejected(invalid.Error())
+		}
+	}
+
+	var lat, lng *float64
+	if mutation.Geotag != nil {
+		lat, lng = &mutation.Geotag.Lat, &mutation.Geotag.Lng
+	}
+	var photos, checklist []byte
+	if mutation.Photos != nil {
+		photos, _ = json.Marshal(mutation.Photos)
+	}
+	if mutation.Checklist != nil {
+		checklist, _ = json.Marshal(mutation.Checklist)
+	}
+	var appID string
+	if err := tx.QueryRow(ctx, `
+        UPDATE application_visits
+        SET status = COALESCE($2, status),
+            geotag_lat = COALESCE($3, geotag_lat), geotag_lng = COALESCE($4, geotag_lng),
+            photos = COALESCE($5::jsonb, photos), checklist = COALESCE($6::jsonb, checklist),
+            `+visitChangeBump+`
+        WHERE id = $1
+        RETURNING application_id, version`,
+		mutation.VisitID, mutation.Status, lat, lng, photos, checklist,
+	).Scan(&appID, &version); err != nil {
+		return result, err
+	}
+	if _, err := tx.Exec(ctx, `
+        INSERT INTO visit_sync_mutations (device_id, mutation_id, visit_id, base_version, version, client_recorded_at)
+        VALUES ($1, $2, $3, $4, $5, $6)`,
+		params.DeviceID, mutation.MutationID, mutation.VisitID, mutation.BaseVersion, version, mutation.ClientRecordedAt); err != nil {
+		return result, err
+	}
+	action := "VISIT:UPDATED"
+	if mutation.Status != nil {
+		action = fmt.Sprintf("VISIT:%s", *mutation.Status)
+	}
+	if err := repo.insertTimeline(ctx, tx, domain.TimelineEntry{
+		ApplicationID: appID,
+		Actor:         params.Actor,
+		Action:        action,
+		Metadata: map[string]any{
+			"visitId":          mutation.VisitID,
+			"version":          version,
+			"offline":          true,
+			"deviceId":         params.DeviceID,
+			"mutationId":       mutation.MutationID,
+			"clientRecordedAt": mutation.ClientRecordedAt,
+		},
+	}); err != nil {
+		return result, err
+	}
+	if params.Geofence != nil && submitting {
+		if err := repo.recordVisitGeofence(ctx, tx, mutation.VisitID, params.Actor, params.Geofence); err != nil {
+			return result, err
+		}
+	}
+
+	if step, ok := rebased[mutation.VisitID]; !ok || step.from == mutation.BaseVersion {
+		rebased[mutation.VisitID] = versionStep{from: mutation.BaseVersion, to: version}
+	}
+	result.Result = domain.VisitSyncApplied
+	result.Version = version
+	return result, nil
+}
+
+func scanSyncedVisit(row pgx.Row) (*domain.SyncedVisit, error) {
+	var (
+		visit     domain.SyncedVisit
+		photos    []byte
+		checklist []byte
+		flags     []string
+		kelurahan *string
+		distance  *float64
+		checkedAt *time.Time
+	)
+	if err := row.Scan(&visit.ID, &visit.ApplicationID, &visit.ApplicantName, &visit.ScheduledAt, &visit.Status,
+		&visit.GeotagLat, &visit.GeotagLng, &photos, &checklist, &visit.Version, &visit.UpdatedAt,
+		&flags, &kelurahan, &distance, &checkedAt, &visit.ChecklistTemplateID); err != nil {
+		return nil, err
+	}
+	visit.Photos = decodeStringArray(photos)
+	visit.Checklist = decodeJSON(checklist)
+	visit.Geofence = visitGeofence(flags, kelurahan, distance, checkedAt)
+	return &visit, nil
+}
@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type webhookRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewWebhookRepository(db *pgxpool.Pool) domain.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// execer is satisfied by both the pool and a transaction.
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// enqueueWebhookEvent queues one delivery per active subscription listening for
+// eventType. Called inside the transaction of the change, so a rolled back change never
+// reaches a partner and a committed one always does.
+func enqueueWebhookEvent(ctx context.Context, q execer, eventType string, data map[string]any) error {
+	event := domain.WebhookEvent{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = q.Exec(ctx, `
+        INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+        SELECT id, $1, $2, $3::jsonb
+        FROM webhook_subscriptions
+        WHERE active AND ($2 = ANY(event_types) OR '*' = ANY(event_types))`,
+		event.ID, eventType, payload)
+	return err
+}
+
+const webhookSubscriptionColumns = `id, name, url, event_types, active, created_at, updated_at`
+
+func scanWebhookSubscription(row pgx.Row) (*domain.WebhookSubscription, error) {
+	var sub domain.WebhookSubscription
+	if err := row.Scan(&sub.ID, &sub.Name, &sub.URL, &sub.EventTypes, &sub.Active, &sub.CreatedAt, &sub.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if sub.EventTypes == nil {
+		sub.EventTypes = []string{}
+	}
+	return &sub, nil
+}
+
+func (repo *webhookRepository) ListWebhookSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	rows, err := repo.db.Query(ctx, `SELECT `+webhookSubscriptionColumns+` FROM webhook_subscriptions ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []domain.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+	return subs, rows.Err()
+}
+
+func (repo *webhookRepository) CreateWebhookSubscription(ctx context.Context, sub domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	row := repo.db.QueryRow(ctx, `
+        INSERT INTO webhook_subscriptions (name, url, secret, event_types, active)
+        VALUES ($1,$2,$3,$4,TRUE)
+        RETURNING `+webhookSubscriptionColumns,
+		sub.Name, sub.URL, sub.Secret, sub.EventTypes)
+	created, err := scanWebhookSubscription(row)
+	if err != nil {
+		return nil, err
+	}
+	created.Secret = sub.Secret
+	return created, nil
+}
+
+func (repo *webhookRepository) UpdateWebhookSubscription(ctx context.Context, params domain.UpdateWebhookSubscriptionParams) (*domain.WebhookSubscription, error) {
+	var eventTypes []string
+	if params.EventTypes != nil {
+		eventTypes = *params.EventTypes
+	}
+	row := repo.db.QueryRow(ctx, `
+        UPDATE webhook_subscriptions
+        SET name = COALESCE($2, name),
+            url = COALESCE($3, url),
+            event_types = CASE WHEN $4 THEN $5 ELSE event_types END,
+            active = COALESCE($6, active),
+            updated_at = NOW()
+        WHERE id = $1
+        RETURNING `+webhookSubscriptionColumns,
+		params.ID, params.Name, params.URL, params.EventTypes != nil, eventTypes, params.Active)
+	return scanWebhookSubscription(row)
+}
+
+func (repo *webhookRepository) DeleteWebhookSubscription(ctx context.Context, id string) error {
+	tag, err := repo.db.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+const webhookDeliveryColumns = `d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+               d.next_attempt_at, d.last_error, d.last_status_code, d.delivered_at, d.created_at, d.updated_at`
+
+func scanWebhookDelivery(row pgx.Row, extra ...any) (*domain.WebhookDelivery, error) {
+	var (
+		delivery   domain.WebhookDelivery
+		payload    []byte
+		statusCode *int32
+	)
+	dest := []any{
+		&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &payload, &delivery.Status, &delivery.Attempts,
+		&delivery.NextAttemptAt, &delivery.LastError, &statusCode, &delivery.DeliveredAt, &delivery.CreatedAt, &delivery.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	delivery.Payload = payload
+	if statusCode != nil {
+		code := int(*statusCode)
+		delivery.LastStatusCode = &code
+	}
+	return &delivery, nil
+}
+
+func (repo *webhookRepository) ListWebhookDeliveries(ctx context.Context, params domain.ListWebhookDeliveriesParams) ([]domain.WebhookDelivery, error) {
+	limit := params.Limit
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	rows, err := repo.db.Query(ctx, `
+        SELECT `+webhookDeliveryColumns+`
+        FROM webhook_deliveries d
+        WHERE ($1 = '' OR d.subscription_id::text = $1)
+          AND ($2 = '' OR d.status = $2)
+        ORDER BY d.created_at DESC
+        LIMIT $3`,
+		params.SubscriptionID, params.Status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []domain.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (repo *webhookRepository) ClaimDueWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	rows, err := repo.db.Query(ctx, `
+        WITH due AS (
+            SELECT d.id
+            FROM webhook_deliveries d
+            JOIN webhook_subscriptions s ON s.id = d.subscription_id AND s.active
+            WHERE d.status = 'PENDING' AND d.next_attempt_at <= NOW()
+            ORDER BY d.next_attempt_at
+            LIMIT $1
+            FOR UPDATE OF d SKIP LOCKED
+        )
+        UPDATE webhook_deliveries d
+        SET attempts = d.attempts + 1,
+            next_attempt_at = NOW() + make_interval(secs => $2),
+            updated_at = NOW()
+        FROM due, webhook_subscriptions s
+        WHERE d.id = due.id AND s.id = d.subscription_id
+        RETURNING `+webhookDeliveryColumns+`, s.url, s.secret`,
+		limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []domain.WebhookDelivery
+	for rows.Next() {
+		var url, secret string
+		delivery, err := scanWebhookDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		delivery.URL = url
+		delivery.Secret = secret
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func (repo *webhookRepository) MarkWebhookDelivered(ctx context.Context, id int64, statusCode int) error {
+	_, err := repo.db.Exec(ctx, `
+        UPDATE webhook_deliveries
+        SET status = 'DELIVERED',
+            last_status_code = $2,
+            last_error = NULL,
+            delivered_at = NOW(),
+            updated_at = NOW()
+        WHERE id = $1`, id, statusCode)
+	return err
+}
+
+func (repo *webhookRepository) MarkWebhookFailed(ctx context.Context, failure domain.WebhookFailure) error {
+	_, err := repo.db.Exec(ctx, `
+        UPDATE webhook_deliveries
+        SET status = CASE WHEN $4::timestamptz IS NULL THEN 'DEAD' ELSE 'PENDING' END,
+            last_status_code = $2,
+            last_error = $3,
+            next_attempt_at = COALESCE($4, next_attempt_at),
+            updated_at = NOW()
+        WHERE id = $1`,
+		failure.DeliveryID, failure.StatusCode, failure.Error, failure.NextAttemptAt)
+	return err
+}
+
+// RedeliverWebhook puts a delivery back in the queue with a fresh attempt budget.
+func (repo *webhookRepository) RedeliverWebhook(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	row := repo.db.QueryRow(ctx, `
+        UPDATE webhook_deliveries d
+        SET status = 'PENDING',
+            attempts = 0,
+            next_attempt_at = NOW(),
+            delivered_at = NULL,
+            updated_at = NOW()
+        WHERE d.id = $1
+        RETURNING `+webhookDeliveryColumns, id)
+	return scanWebhookDelivery(row)
+}
@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+func TestSignVerify(t *testing.T) {
+	secret := "whsec_test"
+	body := []byte(`{"id":"evt-1","type":"ekyc.session.finalized"}`)
+	sentAt := time.Unix(1_760_000_000, 0)
+	header := Sign(secret, sentAt, body)
+	ts := strconv.FormatInt(sentAt.Unix(), 10)
+	_, v1, _ := strings.Cut(header, ",v1=")
+
+	tests := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		want   bool
+	}{
+		{"valid", secret, header, body, sentAt.Add(time.Minute), true},
+		{"fields reordered with spaces", secret, "v1=" + v1 + ", t=" + ts, body, sentAt, true},
+		{"at tolerance", secret, header, body, sentAt.Add(5 * time.Minute), true},
+		{"wrong secret", "whsec_other", header, body, sentAt, false},
+		{"tampered body", secret, header, []byte(`{"id":"evt-2","type":"ekyc.session.finalized"}`), sentAt, false},
+		{"replayed too late", secret, header, body, sentAt.Add(5*time.Minute + time.Second), false},
+		{"timestamp from the future", secret, header, body, sentAt.Add(-6 * time.Minute), false},
+		{"timestamp swapped", secret, "t=" + strconv.FormatInt(sentAt.Unix()+1, 10) + ",v1=" + v1, body, sentAt, false},
+		{"missing signature", secret, "t=" + ts, body, sentAt, false},
+		{"missing timestamp", secret, "v1=" + v1, body, sentAt, false},
+		{"malformed timestamp", secret, "t=yesterday,v1=" + v1, body, sentAt, false},
+		{"empty header", secret, "", body, sentAt, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.header, tt.body, tt.now, 5*time.Minute); got != tt.want {
+				t.Errorf("Verify(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSenderPostsSignedDelivery(t *testing.T) {
+	var (
+		got     *http.Request
+		gotBody []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	sentAt := time.Unix(1_760_000_000, 0)
+	sender := NewSender(srv.Client())
+	sender.now = func() time.Time { return sentAt }
+	delivery := domain.WebhookDelivery{
+		ID:        42,
+		EventID:   "evt-1",
+		EventType: domain.WebhookEventEkycFinalized,
+		Payload:   json.RawMessage(`{"id":"evt-1"}`),
+		URL:       srv.URL + "/hooks",
+		Secret:    "whsec_test",
+	}
+	status, err := sender.Send(context.Background(), delivery)
+	if err != nil || status != http.StatusAccepted {
+		t.Fatalf("Send = %d, %v", status, err)
+	}
+	if got.Method != http.MethodPost || got.URL.Path != "/hooks" || string(gotBody) != `{"id":"evt-1"}` {
+		t.Errorf("received %s %s %s", got.Method, got.URL.Path, gotBody)
+	}
+	headers := map[string]string{
+		"Content-Type":  "application/json",
+		HeaderEvent:     domain.WebhookEventEkycFinalized,
+		HeaderEventID:   "evt-1",
+		HeaderDelivery:  "42",
+		HeaderSignature: Sign("whsec_test", sentAt, gotBody),
+		"User-Agent":    "ekyc-backoffice-webhooks/1",
+	}
+	for name, want := range headers {
+		if value := got.Header.Get(name); value != want {
+			t.Errorf("%s = %q, want %q", name, value, want)
+		}
+	}
+	if !Verify("whsec_test", got.Header.Get(HeaderSignature), gotBody, sentAt, time.Minute) {
+		t.Error("receiver cannot verify the delivery")
+	}
+}
+
+func TestSenderFailsOnNon2xx(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "endpoint down for maintenance", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	status, err := NewSender(srv.Client()).Send(context.Background(), domain.WebhookDelivery{
+		ID: 1, Payload: json.RawMessage(`{}`), URL: srv.URL, Secret: "s",
+	})
+	if status != http.StatusServiceUnavailable || err == nil || !strings.Contains(err.Error(), "endpoint down for maintenance") {
+		t.Fatalf("Send = %d, %v", status, err)
+	}
+
+	srv.Close()
+	if status, err := NewSender(nil).Send(context.Background(), domain.WebhookDelivery{
+		ID: 1, Payload: json.RawMessage(`{}`), URL: srv.URL, Secret: "s",
+	}); status != 0 || err == nil {
+		t.Fatalf("Send to a closed server = %d, %v", status, err)
+	}
+}
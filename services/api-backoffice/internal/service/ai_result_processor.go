@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// AiResultProcessor records face-match and liveness results read from the AI result
+// queues. It mirrors what the Python workers send through the HTTP callbacks; the job id
+// keeps the two paths from recording the same result twice.
+type AiResultProcessor struct {
+	ekyc          domain.EkycService
+	faceQueue     string
+	livenessQueue string
+}
+
+func NewAiResultProcessor(ekyc domain.EkycService, faceQueue, livenessQueue string) *AiResultProcessor {
+	if faceQueue == "" {
+		faceQueue = domain.FaceMatchResultQueue
+	}
+	if livenessQueue == "" {
+		livenessQueue = domain.LivenessResultQueue
+	}
+	return &AiResultProcessor{ekyc: ekyc, faceQueue: faceQueue, livenessQueue: livenessQueue}
+}
+
+// Run consumes both result queues until ctx is done.
+func (p *AiResultProcessor) Run(ctx context.Context, consumer domain.EventConsumer) {
+	var wg sync.WaitGroup
+	consume := func(queue string, handler domain.EventHandler) {
+		defer wg.Done()
+		if err := consumer.Consume(ctx, queue, handler); err != nil {
+			log.Printf("api-backoffice: consume %s: %v", queue, err)
+		}
+	}
+	wg.Add(2)
+	go consume(p.faceQueue, p.HandleFaceMatchResult)
+	go consume(p.livenessQueue, p.HandleLivenessResult)
+	wg.Wait()
+}
+
+type faceMatchResultEvent struct {
+	JobID      string   `json:"job_id"`
+	SessionID  string   `json:"session_id"`
+	Matched    bool     `json:"matched"`
+	Similarity *float64 `json:"similarity"`
+	Threshold  *float64 `json:"threshold"`
+	Error      *string  `json:"error"`
+}
+
+type livenessResultEvent struct {
+	JobID            string   `json:"job_id"`
+	SessionID        string   `json:"session_id"`
+	Passed           bool     `json:"passed"`
+	Matched          []string `json:"matched"`
+	Missing          []string `json:"missing"`
+	Error            *string  `json:"error"`
+	RecordedVideoURL *string  `json:"recorded_video_url"`
+}
+
+func (p *AiResultProcessor) HandleFaceMatchResult(ctx context.Context, event domain.Event) error {
+	var payload faceMatchResultEvent
+	if err := json.Unmarshal(event.Body, &payload); err != nil {
+		return fmt.Errorf("%w: decode face match result: %v", domain.ErrPoisonEvent, err)
+	}
+	if payload.SessionID == "" || payload.JobID == "" {
+		return fmt.Errorf("%w: face match result without session or job id", domain.ErrPoisonEvent)
+	}
+	result := resultLabel(payload.Matched)
+	_, err := p.ekyc.RecordFaceChecks(ctx, domain.SaveFaceChecksParams{
+		SessionID: payload.SessionID,
+		JobID:     payload.JobID,
+		Overall:   result,
+		Status:    jobStatus(payload.Error),
+		Checks: []domain.FaceCheckInput{{
+			Step:       "ID_VS_SELFIE",
+			Similarity: payload.Similarity,
+			Threshold:  payload.Threshold,
+			Result:     result,
+			Metadata:   map[string]any{"jobId": payload.JobID, "error": payload.Error},
+		}},
+	})
+	return p.classify(event, payload.SessionID, err)
+}
+
+func (p *AiResultProcessor) HandleLivenessResult(ctx context.Context, event domain.Event) error {
+	var payload livenessResultEvent
+	if err := json.Unmarshal(event.Body, &payload); err != nil {
+		return fmt.Errorf("%w: decode liveness result: %v", domain.ErrPoisonEvent, err)
+	}
+	if payload.SessionID == "" || payload.JobID == "" {
+		return fmt.Errorf("%w: liveness result without session or job id", domain.ErrPoisonEvent)
+	}
+	perGesture := map[string]any{}
+	for _, gesture := range payload.Matched {
+		perGesture[gesture] = "PASS"
+	}
+	for _, gesture := range payload.Missing {
+		perGesture[gesture] = "MISSING"
+	}
+	_, err := p.ekyc.RecordLiveness(ctx, domain.SaveLivenessResultParams{
+		SessionID:  payload.SessionID,
+		JobID:      payload.JobID,
+		Overall:    resultLabel(payload.Passed),
+		PerGesture: perGesture,
+		VideoURL:   payload.RecordedVideoURL,
+		Status:     jobStatus(payload.Error),
+		Metadata:   map[string]any{"jobId": payload.JobID, "error": payload.Error},
+	})
+	return p.classify(event, payload.SessionID, err)
+}
+
+// classify decides what the consumer does with a failed event: locked sessions are
+// acknowledged and dropped, unknown sessions are poison, the rest is retried.
+func (p *AiResultProcessor) classify(event domain.Event, sessionID string, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, domain.ErrSessionLocked):
+		log.Printf("api-backoffice: drop %s result for locked session %s", event.Queue, sessionID)
+		return nil
+	case errors.Is(err, domain.ErrNotFound), errors.Is(err, domain.ErrInvalidState):
+		return fmt.Errorf("%w: %v", domain.ErrPoisonEvent, err)
+	default:
+		return err
+	}
+}
+
+func resultLabel(passed bool) string {
+	if passed {
+		return "PASS"
+	}
+	return "FAIL"
+}
+
+func jobStatus(jobErr *string) string {
+	if jobErr != nil && strings.TrimSpace(*jobErr) != "" {
+		return "FAILED"
+	}
+	return "DONE"
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,13 +18,15 @@ import (
 var ErrNotFound = domain.ErrNotFound
 
 type BackofficeService struct {
-	repo domain.BackofficeRepository
+	repo     domain.BackofficeRepository
+	visits   *VisitScheduler
+	geofence *VisitGeofence
 }
 
 var _ domain.BackofficeService = (*BackofficeService)(nil)
 
-func NewBackofficeService(repo domain.BackofficeRepository) *BackofficeService {
-	return &BackofficeService{repo: repo}
+func NewBackofficeService(repo domain.BackofficeRepository, geofence *VisitGeofence) *BackofficeService {
+	return &BackofficeService{repo: repo, visits: NewVisitScheduler(repo), geofence: geofence}
 }
 
 func (s *BackofficeService) ListApplications(ctx context.Context, limit int) ([]domain.Application, error) {
@@ -52,6 +55,21 @@ func (s *BackofficeService) UpdateConfig(ctx context.Context, cfg domain.SystemC
 	if cfg.Features == nil {
 		cfg.Features = map[string]any{}
 	}
+	if cfg.Retention != nil {
+		if _, err := retentionPeriods(cfg.Retention); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Scheduling != nil {
+		if _, err := visitSchedule(cfg.Scheduling); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Disbursement != nil {
+		if _, err := disbursementConfig(cfg.Disbursement); err != nil {
+			return nil, err
+		}
+	}
 	return s.repo.UpsertConfig(ctx, cfg)
 }
 
@@ -72,19 +90,7 @@ func (s *BackofficeService) UpdateApplicationStatus(ctx context.Context, appID,
 }
 
 func (s *BackofficeService) CreateVisit(ctx context.Context, appID, actor string, scheduledAt time.Time, tkskID string) (*domain.Visit, error) {
-	visit := domain.Visit{
-		ID:            fmt.Sprintf("VST-%d", time.Now().UnixNano()),
-		ApplicationID: appID,
-		ScheduledAt:   scheduledAt.UTC(),
-		Status:        "PLANNED",
-		TkskID:        tkskID,
-		CreatedAt:     time.Now().UTC(),
-	}
-
-	if err := s.repo.CreateVisit(ctx, &visit, timelineEntry(appID, actor, "VISIT:CREATED", "", map[string]any{"visitId": visit.ID})); err != nil {
-		return nil, err
-	}
-	return &visit, nil
+	return s.visits.ScheduleVisit(ctx, appID, actor, scheduledAt, tkskID)
 }
 
 func (s *BackofficeService) UpdateVisit(ctx context.Context, appID, visitID, actor string, payload domain.UpdateVisitPayload) error {
@@ -93,19 +99,28 @@ func (s *BackofficeService) UpdateVisit(ctx context.Context, appID, visitID, act
 		lat = &payload.Geotag.Lat
 		lng = &payload.Geotag.Lng
 	}
+	if payload.Status != nil && *payload.Status == domain.VisitStatusVerified {
+		return fmt.Errorf("%w: kunjungan diverifikasi lewat tinjauan supervisor", domain.ErrInvalidState)
+	}
 	action := "VISIT:UPDATED"
 	if payload.Status != nil && *payload.Status != "" {
 		action = fmt.Sprintf("VISIT:%s", *payload.Status)
 	}
+	geofence, err := s.geofence.Check(ctx)
+	if err != nil {
+		return err
+	}
 	params := domain.UpdateVisitParams{
-		AppID:     appID,
-		VisitID:   visitID,
-		Status:    payload.Status,
-		GeotagLat: lat,
-		GeotagLng: lng,
-		Photos:    payload.Photos,
-		Checklist: payload.Checklist,
-		Timeline:  timelineEntry(appID, actor, action, payload.Reason, map[string]any{"visitId": visitID}),
+		AppID:          appID,
+		VisitID:        visitID,
+		Status:         payload.Status,
+		GeotagLat:      lat,
+		GeotagLng:      lng,
+		Photos:         payload.Photos,
+		Checklist:      payload.Checklist,
+		Timeline:       timelineEntry(appID, actor, action, payload.Reason, map[string]any{"visitId": visitID}),
+		Geofence:       geofence,
+		ChecklistCheck: validateVisitChecklist,
 	}
 	return s.repo.UpdateVisit(ctx, params)
 }
@@ -122,7 +137,7 @@ func (s *BackofficeService) CreateBatch(ctx context.Context, code string, applic
 	if len(applicationIDs) == 0 {
 		return nil, fmt.Errorf("%w: items required", domain.ErrInvalidState)
 	}
-	if err := s.ensureDisbursementReady(ctx, applicationIDs); err != nil {
+	if err := ensureDisbursementReady(ctx, s.repo, applicationIDs); err != nil {
 		return nil, err
 	}
 	batch := domain.Batch{
@@ -141,13 +156,33 @@ func (s *BackofficeService) CreateBatch(ctx context.Context, code string, applic
 	return &batch, nil
 }
 
+// UpdateBatchStatus moves the batch one step along BatchTransitions. Leaving DRAFT locks
+// the batch: its manifest is snapshotted and its checksum stored.
 func (s *BackofficeService) UpdateBatchStatus(ctx context.Context, batchID, status, actor string) error {
-	action := fmt.Sprintf("BATCH:%s", status)
+	status = strings.ToUpper(strings.TrimSpace(status))
+	batch, err := s.repo.GetBatch(ctx, batchID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(domain.BatchTransitions[batch.Status], status) {
+		return fmt.Errorf("%w: batch %s tidak bisa berpindah dari %s ke %s", domain.ErrInvalidState, batch.Code, batch.Status, status)
+	}
 	params := domain.UpdateBatchStatusParams{
 		BatchID: batchID,
 		Status:  status,
-		Audit:   auditEntry(actor, batchID, action, "", nil),
+		From:    batch.Status,
 	}
+	metadata := map[string]any{"from": batch.Status}
+	if batch.Status == domain.BatchStatusDraft {
+		params.Manifest, params.Checksum, err = sealBatchManifest(ctx, s.repo, batch)
+		if err != nil {
+			return err
+		}
+		metadata["checksum"] = params.Checksum
+		metadata["items"] = len(params.Manifest)
+	}
+	action := fmt.Sprintf("BATCH:%s", status)
+	params.Audit = auditEntry(actor, batchID, action, "", metadata)
 	return s.repo.UpdateBatchStatus(ctx, params)
 }
 
@@ -189,7 +224,7 @@ func (s *BackofficeService) CreateDistribution(ctx context.Context, dist *domain
 	if len(dist.Beneficiaries) == 0 {
 		return nil, fmt.Errorf("%w: beneficiaries required", domain.ErrInvalidState)
 	}
-	if err := s.ensureDisbursementReady(ctx, dist.Beneficiaries); err != nil {
+	if err := ensureDisbursementReady(ctx, s.repo, dist.Beneficiaries); err != nil {
 		return nil, err
 	}
 
@@ -413,6 +448,65 @@ func (s *BackofficeService) Overview(ctx context.Context) (map[string]any, error
 	return s.repo.Overview(ctx)
 }
 
+// EkycFunnel reports the eKYC funnel for sessions created in the requested window,
+// defaulting to the last DefaultFunnelWindow in daily buckets.
+func (s *BackofficeService) EkycFunnel(ctx context.Context, params domain.EkycFunnelParams) (*domain.EkycFunnelReport, error) {
+	if params.To.IsZero() {
+		params.To = time.Now()
+	}
+	if params.From.IsZero() {
+		params.From = params.To.Add(-domain.DefaultFunnelWindow)
+	}
+	if !params.From.Before(params.To) {
+		return nil, fmt.Errorf("%w: from harus sebelum to", domain.ErrInvalidState)
+	}
+	params.Granularity = strings.ToLower(strings.TrimSpace(params.Granularity))
+	if params.Granularity == "" {
+		params.Granularity = "day"
+	}
+	if !slices.Contains(domain.FunnelGranularities, params.Granularity) {
+		return nil, fmt.Errorf("%w: granularity harus day atau week", domain.ErrInvalidState)
+	}
+	params.RegionLevel = strings.ToLower(strings.TrimSpace(params.RegionLevel))
+	if params.RegionLevel != "" && !slices.Contains(domain.FunnelRegionLevels, params.RegionLevel) {
+		return nil, fmt.Errorf("%w: regionLevel harus prov, kab, kec atau kel", domain.ErrInvalidState)
+	}
+	params.Region = strings.TrimSpace(params.Region)
+	if params.Region != "" && params.RegionLevel == "" {
+		return nil, fmt.Errorf("%w: region membutuhkan regionLevel", domain.ErrInvalidState)
+	}
+
+	report, err := s.repo.EkycFunnel(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	fillFunnelRates(&report.Totals)
+	for i := range report.Buckets {
+		fillFunnelRates(&report.Buckets[i])
+	}
+	for i := range report.Gestures {
+		if rate := ratio(report.Gestures[i].Passed, report.Gestures[i].Attempts); rate != nil {
+			report.Gestures[i].PassRate = *rate
+		}
+	}
+	return report, nil
+}
+
+func fillFunnelRates(bucket *domain.EkycFunnelBucket) {
+	bucket.FacePassRate = ratio(bucket.FacePassed, bucket.FaceChecked)
+	bucket.LivenessPassRate = ratio(bucket.LivenessPassed, bucket.LivenessChecked)
+	bucket.ApprovalRate = ratio(bucket.Approved, bucket.Decided)
+}
+
+// ratio returns part/total, or nil when total is zero.
+func ratio(part, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	value := float64(part) / float64(total)
+	return &value
+}
+
 func (s *BackofficeService) ListVisits(ctx context.Context, params domain.ListVisitsParams) ([]domain.Visit, error) {
 	if params.Limit <= 0 {
 		params.Limit = 200
@@ -484,12 +578,16 @@ func uniqueIDs(ids []string) []string {
 	return normalized
 }
 
-func (s *BackofficeService) ensureDisbursementReady(ctx context.Context, ids []string) error {
+type applicationsGetter interface {
+	GetApplicationsByIDs(ctx context.Context, ids []string) ([]domain.Application, error)
+}
+
+func ensureDisbursementReady(ctx context.Context, repo applicationsGetter, ids []string) error {
 	normalized := uniqueIDs(ids)
 	if len(normalized) == 0 {
 		return fmt.Errorf("%w: minimal satu aplikasi diperlukan", domain.ErrInvalidState)
 	}
-	apps, err := s.repo.GetApplicationsByIDs(ctx, normalized)
+	apps, err := repo.GetApplicationsByIDs(ctx, normalized)
 	if err != nil {
 		return err
 	}
@@ -513,6 +611,14 @@ func (s *BackofficeService) ensureVisitCompleted(ctx context.Context, appID stri
 	if appID == "" {
 		return fmt.Errorf("%w: application id required", domain.ErrInvalidState)
 	}
+	requireVerified := false
+	cfg, err := s.repo.GetConfig(ctx)
+	switch {
+	case err == nil:
+		requireVerified, _ = cfg.Features[domain.FeatureRequireVerifiedVisit].(bool)
+	case !errors.Is(err, domain.ErrNotFound):
+		return err
+	}
 	visits, err := s.repo.ListVisits(ctx, domain.ListVisitsParams{
 		ApplicationID: appID,
 		Limit:         100,
@@ -522,10 +628,13 @@ func (s *BackofficeService) ensureVisitCompleted(ctx context.Context, appID stri
 	}
 	for _, visit := range visits {
 		status := strings.ToUpper(strings.TrimSpace(visit.Status))
-		if status == "SUBMITTED" || status == "VERIFIED" {
+		if status == domain.VisitStatusVerified || (status == domain.VisitStatusSubmitted && !requireVerified) {
 			return nil
 		}
 	}
+	if requireVerified {
+		return fmt.Errorf("%w: belum ada kunjungan TKSK yang diverifikasi supervisor", domain.ErrInvalidState)
+	}
 	return fmt.Errorf("%w: belum ada kunjungan TKSK yang disubmit", domain.ErrInvalidState)
 }
 
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// batchStatusRepo serves one batch and keeps the status update it was asked for.
+type batchStatusRepo struct {
+	domain.BackofficeRepository
+	batch   domain.Batch
+	updated *domain.UpdateBatchStatusParams
+}
+
+func (r *batchStatusRepo) GetBatch(context.Context, string) (*domain.Batch, error) {
+	batch := r.batch
+	return &batch, nil
+}
+
+func (r *batchStatusRepo) UpdateBatchStatus(_ context.Context, params domain.UpdateBatchStatusParams) error {
+	r.updated = &params
+	return nil
+}
+
+func TestUpdateBatchStatusLeavesSentToExports(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{domain.BatchStatusSigned, domain.BatchStatusExported, true},
+		{domain.BatchStatusSigned, domain.BatchStatusSent, false},
+		{domain.BatchStatusExported, domain.BatchStatusSent, false},
+		{domain.BatchStatusExported, domain.BatchStatusSigned, false},
+		{domain.BatchStatusSent, domain.BatchStatusExported, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			repo := &batchStatusRepo{batch: domain.Batch{ID: "batch-1", Code: "BATCH-1", Status: tt.from}}
+			err := NewBackofficeService(repo, nil).UpdateBatchStatus(context.Background(), "batch-1", tt.to, "admin")
+			if tt.allowed {
+				if err != nil || repo.updated == nil || repo.updated.Status != tt.to {
+					t.Errorf("refused: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, domain.ErrInvalidState) || repo.updated != nil {
+				t.Errorf("moved the batch: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// BatchManifestService shows and verifies the manifests that batches are locked with, and
+// edits the items of batches that are not locked yet.
+type BatchManifestService struct {
+	repo domain.BatchManifestRepository
+}
+
+var _ domain.BatchManifestService = (*BatchManifestService)(nil)
+
+func NewBatchManifestService(repo domain.BatchManifestRepository) *BatchManifestService {
+	return &BatchManifestService{repo: repo}
+}
+
+func (s *BatchManifestService) Get(ctx context.Context, batchID string) (*domain.BatchManifest, error) {
+	return s.repo.GetBatchManifest(ctx, strings.TrimSpace(batchID))
+}
+
+// Verify recomputes the checksum from the stored snapshot. The outcome is audited either
+// way, so a mismatch leaves a trace even if nobody acts on the response.
+func (s *BatchManifestService) Verify(ctx context.Context, batchID, actor string) (*domain.BatchManifestCheck, error) {
+	manifest, err := s.repo.GetBatchManifest(ctx, strings.TrimSpace(batchID))
+	if err != nil {
+		return nil, err
+	}
+	if manifest.LockedAt == nil {
+		return nil, fmt.Errorf("%w: batch %s belum dikunci", domain.ErrInvalidState, manifest.Code)
+	}
+	check := &domain.BatchManifestCheck{
+		BatchID:   manifest.BatchID,
+		Code:      manifest.Code,
+		Status:    manifest.Status,
+		Computed:  batchManifestChecksum(manifest.Code, manifest.Items),
+		Items:     len(manifest.Items),
+		CheckedAt: time.Now().UTC(),
+	}
+	if manifest.Checksum != nil {
+		check.Stored = *manifest.Checksum
+	}
+	check.Valid = check.Stored != "" && check.Stored == check.Computed
+
+	action := "BATCH:MANIFEST_VERIFIED"
+	if !check.Valid {
+		action = "BATCH:MANIFEST_MISMATCH"
+	}
+	audit := auditEntry(actor, manifest.BatchID, action, "", map[string]any{
+		"stored":   check.Stored,
+		"computed": check.Computed,
+		"items":    check.Items,
+	})
+	if err := s.repo.RecordBatchAudit(ctx, audit); err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// ReplaceItems sets the applications in a DRAFT batch. Once the batch is locked its items
+// are part of the signed manifest and cannot change.
+func (s *BatchManifestService) ReplaceItems(ctx context.Context, batchID string, applicationIDs []string, actor string) (*domain.Batch, error) {
+	batchID = strings.TrimSpace(batchID)
+	applicationIDs = sanitizeIDs(applicationIDs)
+	if len(applicationIDs) == 0 {
+		return nil, fmt.Errorf("%w: items required", domain.ErrInvalidState)
+	}
+	batch, err := s.repo.GetBatch(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+	if batch.LockedAt != nil || batch.Status != domain.BatchStatusDraft {
+		return nil, fmt.Errorf("%w: batch %s sudah dikunci", domain.ErrInvalidState, batch.Code)
+	}
+	if err := ensureDisbursementReady(ctx, s.repo, applicationIDs); err != nil {
+		return nil, err
+	}
+	params := domain.ReplaceBatchItemsParams{
+		BatchID: batchID,
+		Items:   applicationIDs,
+		Audit: auditEntry(actor, batchID, "BATCH:ITEMS_UPDATED", "", map[string]any{
+			"before": batch.Items,
+			"after":  applicationIDs,
+		}),
+	}
+	if err := s.repo.ReplaceBatchItems(ctx, params); err != nil {
+		return nil, err
+	}
+	return s.repo.GetBatch(ctx, batchID)
+}
+
+type batchManifestSource interface {
+	configGetter
+	ListBatchManifestSources(ctx context.Context, batchID string) ([]domain.BatchManifestItem, error)
+}
+
+// sealBatchManifest builds the manifest a DRAFT batch is locked with and its checksum.
+// Every item needs a masked NIK and a program with a configured amount.
+func sealBatchManifest(ctx context.Context, repo batchManifestSource, batch *domain.Batch) ([]domain.BatchManifestItem, string, error) {
+	cfg, err := loadDisbursementConfig(ctx, repo)
+	if err != nil {
+		return nil, "", err
+	}
+	items, err := repo.ListBatchManifestSources(ctx, batch.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(items) == 0 {
+		return nil, "", fmt.Errorf("%w: batch %s tidak punya item", domain.ErrInvalidState, batch.Code)
+	}
+	var problems []string
+	for i := range items {
+		item := &items[i]
+		amount, ok := cfg.Amounts[item.Program]
+		switch {
+		case item.NikMask == "":
+			problems = append(problems, item.ApplicationID+" (NIK tersamar kosong)")
+		case item.Program == "":
+			problems = append(problems, item.ApplicationID+" (program bansos kosong)")
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s (nominal program %s belum diatur)", item.ApplicationID, item.Program))
+		}
+		item.Amount = amount
+		item.Currency = cfg.Currency
+	}
+	if len(problems) > 0 {
+		return nil, "", fmt.Errorf("%w: manifes batch tidak lengkap: %s", domain.ErrInvalidState, strings.Join(problems, ", "))
+	}
+	return items, batchManifestChecksum(batch.Code, items), nil
+}
+
+// batchManifestChecksum is the SHA-256 hex digest of the canonical manifest: the JSON
+// object {"code", "items"} with items sorted by application id, each item holding
+// applicationId, nikMask, program, amount and currency in that order.
+func batchManifestChecksum(code string, items []domain.BatchManifestItem) string {
+	sorted := slices.Clone(items)
+	slices.SortFunc(sorted, func(a, b domain.BatchManifestItem) int {
+		return strings.Compare(a.ApplicationID, b.ApplicationID)
+	})
+	if sorted == nil {
+		sorted = []domain.BatchManifestItem{}
+	}
+	payload, _ := json.Marshal(struct {
+		Code  string                     `json:"code"`
+		Items []domain.BatchManifestItem `json:"items"`
+	}{code, sorted})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// loadDisbursementConfig reads the disbursement config, or the default when none is stored.
+func loadDisbursementConfig(ctx context.Context, repo configGetter) (domain.DisbursementConfig, error) {
+	cfg, err := repo.GetConfig(ctx)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return disbursementConfig(nil)
+		}
+		return domain.DisbursementConfig{}, err
+	}
+	return disbursementConfig(cfg.Disbursement)
+}
+
+// disbursementConfig parses SystemConfig.Disbursement. Unknown keys and malformed values
+// are rejected rather than ignored.
+func disbursementConfig(raw map[string]any) (domain.DisbursementConfig, error) {
+	cfg := domain.DisbursementConfig{
+		Currency: domain.DefaultDisbursementCurrency,
+		Amounts:  map[string]int64{},
+		CSV:      domain.DefaultDisbursementCSVLayout(),
+	}
+	invalid := func(key, want string) (domain.DisbursementConfig, error) {
+		return domain.DisbursementConfig{}, fmt.Errorf("%w: disbursement %q harus %s", domain.ErrInvalidState, key, want)
+	}
+	for key, value := range raw {
+		switch key {
+		case domain.DisbursementKeyCurrency:
+			code, _ := value.(string)
+			if len(code) != 3 || strings.ToUpper(code) != code || strings.Trim(code, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+				return invalid(key, "kode mata uang ISO 4217")
+			}
+			cfg.Currency = code
+		case domain.DisbursementKeyAmounts:
+			amounts, ok := value.(map[string]any)
+			if !ok {
+				return invalid(key, "peta program ke nominal")
+			}
+			for program, v := range amounts {
+				amount, ok := v.(float64)
+				if strings.TrimSpace(program) == "" || !ok || amount <= 0 || amount > 1e12 || amount != math.Trunc(amount) {
+					return invalid(key+"."+program, "nominal bilangan bulat > 0")
+				}
+				cfg.Amounts[program] = int64(amount)
+			}
+		case domain.DisbursementKeyDebtorName, domain.DisbursementKeyDebtorAccount:
+			text, ok := value.(string)
+			if !ok || strings.TrimSpace(text) == "" {
+				return invalid(key, "teks")
+			}
+			if key == domain.DisbursementKeyDebtorName {
+				cfg.DebtorName = strings.TrimSpace(text)
+			} else {
+				cfg.DebtorAccount = strings.TrimSpace(text)
+			}
+		case domain.DisbursementKeyDebtorBIC:
+			bic, _ := value.(string)
+			if !domain.IsBIC(bic) {
+				return invalid(key, "BIC 8 atau 11 karakter")
+			}
+			cfg.DebtorBIC = bic
+		case domain.DisbursementKeyCSVColumns:
+			list, ok := value.([]any)
+			if !ok || len(list) == 0 {
+				return invalid(key, "daftar kolom")
+			}
+			columns := make([]string, 0, len(list))
+			for _, item := range list {
+				column, _ := item.(string)
+				if !slices.Contains(domain.DisbursementCSVColumns, column) {
+					return invalid(key, "kolom dari "+strings.Join(domain.DisbursementCSVColumns, ", "))
+				}
+				columns = append(columns, column)
+			}
+			cfg.CSV.Columns = columns
+		case domain.DisbursementKeyCSVDelimiter:
+			text, _ := value.(string)
+			delimiter := []rune(text)
+			if len(delimiter) != 1 || delimiter[0] == '"' || delimiter[0] == '\r' || delimiter[0] == '\n' {
+				return invalid(key, "satu karakter")
+			}
+			cfg.CSV.Delimiter = delimiter[0]
+		case domain.DisbursementKeyCSVHeader:
+			header, ok := value.(bool)
+			if !ok {
+				return invalid(key, "boolean")
+			}
+			cfg.CSV.Header = header
+		default:
+			return domain.DisbursementConfig{}, fmt.Errorf("%w: disbursement %q tidak dikenal", domain.ErrInvalidState, key)
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// defaultFailureReason is recorded when the bank rejects a transfer without saying why.
+const defaultFailureReason = "Ditolak bank tanpa alasan"
+
+// BatchReconciliationService imports the result files banks return for a SENT batch. Each
+// transfer is matched to its batch item by application id and the application is marked
+// DISBURSED or DISBURSEMENT_FAILED. An item's outcome is only written when it changes, so
+// importing the same file twice leaves everything as the first import did. DISBURSED is
+// final: a later failure for it is reported as a conflict, while a failed item can still
+// be disbursed when the bank retries.
+type BatchReconciliationService struct {
+	repo    domain.BatchReconciliationRepository
+	media   domain.MediaClient
+	parsers map[string]domain.DisbursementResultParser
+}
+
+var _ domain.BatchReconciliationService = (*BatchReconciliationService)(nil)
+
+func NewBatchReconciliationService(repo domain.BatchReconciliationRepository, media domain.MediaClient, parsers ...domain.DisbursementResultParser) *BatchReconciliationService {
+	registry := make(map[string]domain.DisbursementResultParser, len(parsers))
+	for _, parser := range parsers {
+		registry[parser.Format()] = parser
+	}
+	return &BatchReconciliationService{repo: repo, media: media, parsers: registry}
+}
+
+func (s *BatchReconciliationService) Import(ctx context.Context, params domain.ImportBatchReconciliationParams) (*domain.BatchReconciliation, error) {
+	actor := strings.TrimSpace(params.Actor)
+	if actor == "" {
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	}
+	parser, ok := s.parsers[strings.ToLower(strings.TrimSpace(params.Format))]
+	if !ok {
+		return nil, fmt.Errorf("%w: format %q tidak dikenal, pilih %s", domain.ErrInvalidState, params.Format, strings.Join(s.formats(), ", "))
+	}
+	if len(params.Content) == 0 {
+		return nil, fmt.Errorf("%w: file hasil bank kosong", domain.ErrInvalidState)
+	}
+	manifest, err := s.repo.GetBatchManifest(ctx, strings.TrimSpace(params.BatchID))
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Status != domain.BatchStatusSent {
+		return nil, fmt.Errorf("%w: batch %s berstatus %s, rekonsiliasi hanya untuk batch %s", domain.ErrInvalidState,
+			manifest.Code, manifest.Status, domain.BatchStatusSent)
+	}
+	file, err := parser.Parse(params.Content)
+	if err != nil {
+		return nil, err
+	}
+	// pain.001 message ids are the batch code cut to 35 characters.
+	if id := file.OriginalMessageID; id != "" && id != manifest.Code && !(len(id) == 35 && strings.HasPrefix(manifest.Code, id)) {
+		return nil, fmt.Errorf("%w: file hasil bank untuk %s, bukan batch %s", domain.ErrInvalidState, id, manifest.Code)
+	}
+	current, err := s.repo.ListBatchItemResults(ctx, manifest.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	report, applied := reconcileBatch(manifest, current, file.Results)
+
+	filename := filepath.Base(strings.TrimSpace(params.Filename))
+	if filename == "." || filename == string(filepath.Separator) {
+		filename = fmt.Sprintf("%s-%s.%s", manifest.Code, strings.ReplaceAll(parser.Format(), ".", ""), parser.Extension())
+	}
+	sum := sha256.Sum256(params.Content)
+	reconciliation := &domain.BatchReconciliation{
+		BatchID:   manifest.BatchID,
+		Format:    parser.Format(),
+		Filename:  filename,
+		Checksum:  hex.EncodeToString(sum[:]),
+		Report:    report,
+		CreatedBy: actor,
+	}
+	outcomes := make([]domain.ReconciliationOutcome, 0, len(applied))
+	for _, result := range applied {
+		outcomes = append(outcomes, reconciliationOutcome(manifest, result, current[result.ApplicationID].Status, actor, reconciliation.Checksum))
+	}
+
+	reconciliation.URL, err = s.media.Upload(ctx, reconciliation.Filename, parser.ContentType(), params.Content)
+	if err != nil {
+		return nil, err
+	}
+	audit := auditEntry(actor, manifest.BatchID, "BATCH:RECONCILED", "", map[string]any{
+		"format":      reconciliation.Format,
+		"filename":    reconciliation.Filename,
+		"checksum":    reconciliation.Checksum,
+		"rows":        report.Rows,
+		"disbursed":   report.Disbursed,
+		"failed":      report.Failed,
+		"unchanged":   report.Unchanged,
+		"pending":     len(report.Pending),
+		"unmatched":   len(report.Unmatched),
+		"duplicates":  len(report.Duplicates),
+		"conflicts":   len(report.Conflicts),
+		"outstanding": len(report.Outstanding),
+	})
+	err = s.repo.RecordBatchReconciliation(ctx, domain.RecordBatchReconciliationParams{
+		Reconciliation: reconciliation,
+		Outcomes:       outcomes,
+		Audit:          audit,
+	})
+	if err != nil {
+		if delErr := s.media.Delete(ctx, reconciliation.URL); delErr != nil {
+			log.Printf("api-backoffice: remove unrecorded bank result %s: %v", reconciliation.URL, delErr)
+		}
+		return nil, err
+	}
+	return reconciliation, nil
+}
+
+func (s *BatchReconciliationService) List(ctx context.Context, batchID string) ([]domain.BatchReconciliation, error) {
+	return s.repo.ListBatchReconciliations(ctx, strings.TrimSpace(batchID))
+}
+
+func (s *BatchReconciliationService) formats() []string {
+	formats := make([]string, 0, len(s.parsers))
+	for format := range s.parsers {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// reconcileBatch matches results to the batch items and returns the report with the
+// results that change an item's outcome. A result names its item by application id or by
+// the EndToEndID the bank file carried for it. An application reported more than once is
+// applied once when every row agrees, and not at all when they contradict each other.
+func reconcileBatch(manifest *domain.BatchManifest, current map[string]domain.BatchItemResult, results []domain.DisbursementResult) (domain.ReconciliationReport, []domain.DisbursementResult) {
+	report := domain.ReconciliationReport{
+		Rows:        len(results),
+		Pending:     []domain.DisbursementResult{},
+		Unmatched:   []domain.ReconciliationIssue{},
+		Duplicates:  []domain.ReconciliationIssue{},
+		Conflicts:   []domain.ReconciliationIssue{},
+		Outstanding: []string{},
+	}
+	items := make(map[string]domain.BatchManifestItem, len(manifest.Items))
+	byEndToEndID := make(map[string]domain.BatchManifestItem, len(manifest.Items))
+	for _, item := range manifest.Items {
+		items[item.ApplicationID] = item
+		if ref, err := domain.EndToEndID(item.ApplicationID); err == nil {
+			byEndToEndID[ref] = item
+		}
+	}
+	rows := map[string][]domain.DisbursementResult{}
+	var order []string
+	for _, result := range results {
+		if result.Status == domain.DisbursementFailed && result.Reason == "" {
+			result.Reason = defaultFailureReason
+		}
+		item, ok := items[result.ApplicationID]
+		if !ok {
+			if item, ok = byEndToEndID[result.ApplicationID]; ok {
+				result.ApplicationID = item.ApplicationID
+			}
+		}
+		switch {
+		case !ok:
+			report.Unmatched = append(report.Unmatched, domain.ReconciliationIssue{DisbursementResult: result, Issue: "tidak ada di batch"})
+			continue
+		case result.Amount != nil && *result.Amount != item.Amount:
+			report.Unmatched = append(report.Unmatched, domain.ReconciliationIssue{DisbursementResult: result,
+				Issue: fmt.Sprintf("nominal %d, manifes %d", *result.Amount, item.Amount)})
+			continue
+		}
+		if _, seen := rows[result.ApplicationID]; !seen {
+			order = append(order, result.ApplicationID)
+		}
+		rows[result.ApplicationID] = append(rows[result.ApplicationID], result)
+	}
+
+	var applied []domain.DisbursementResult
+	outcome := map[string]string{}
+	for appID, result := range current {
+		outcome[appID] = result.Status
+	}
+	for _, appID := range order {
+		group := rows[appID]
+		result := group[0]
+		if len(group) > 1 {
+			agree := true
+			for _, other := range group[1:] {
+				agree = agree && other.Status == result.Status && other.Reason == result.Reason
+			}
+			if !agree {
+				for _, duplicate := range group {
+					report.Duplicates = append(report.Duplicates, domain.ReconciliationIssue{DisbursementResult: duplicate, Issue: "status bertentangan, tidak diterapkan"})
+				}
+				continue
+			}
+			for _, duplicate := range group[1:] {
+				report.Duplicates = append(report.Duplicates, domain.ReconciliationIssue{DisbursementResult: duplicate, Issue: "baris ganda, diterapkan sekali"})
+			}
+		}
+		previous := current[appID]
+		switch {
+		case result.Status == "":
+			report.Pending = append(report.Pending, result)
+		case previous.Status == result.Status && (result.Status == domain.DisbursementDisbursed || previous.Reason == result.Reason):
+			report.Unchanged++
+		case previous.Status == domain.DisbursementDisbursed:
+			report.Conflicts = append(report.Conflicts, domain.ReconciliationIssue{DisbursementResult: result, Issue: "sudah DISBURSED"})
+		default:
+			applied = append(applied, result)
+			outcome[appID] = result.Status
+			if result.Status == domain.DisbursementDisbursed {
+				report.Disbursed++
+			} else {
+				report.Failed++
+			}
+		}
+	}
+	for _, item := range manifest.Items {
+		if outcome[item.ApplicationID] == "" {
+			report.Outstanding = append(report.Outstanding, item.ApplicationID)
+		}
+	}
+	return report, applied
+}
+
+func reconciliationOutcome(manifest *domain.BatchManifest, result domain.DisbursementResult, previous, actor, checksum string) domain.ReconciliationOutcome {
+	reason := fmt.Sprintf("Transfer batch %s diterima bank", manifest.Code)
+	if result.Status == domain.DisbursementFailed {
+		reason = fmt.Sprintf("Transfer batch %s gagal: %s", manifest.Code, result.Reason)
+	}
+	meta := map[string]any{
+		"batchId":       manifest.BatchID,
+		"bankStatus":    result.BankStatus,
+		"bankReference": result.BankReference,
+		"fileChecksum":  checksum,
+	}
+	action := fmt.Sprintf("STATUS:%s", result.Status)
+	return domain.ReconciliationOutcome{
+		Result:   result,
+		Previous: previous,
+		Timeline: timelineEntry(result.ApplicationID, actor, action, reason, meta),
+		Audit:    auditEntry(actor, result.ApplicationID, action, reason, meta),
+	}
+}
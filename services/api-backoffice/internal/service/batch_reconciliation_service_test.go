@@ -0,0 +1,108 @@
+package service
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/services/api-backoffice/internal/infrastructure/bankfile"
+)
+
+// pain002For answers every transfer of a pain.001 the way a bank does: the first one
+// rejected for a closed account, the rest settled.
+func pain002For(t *testing.T, pain001 []byte) []byte {
+	t.Helper()
+	var doc struct {
+		MessageID string `xml:"CstmrCdtTrfInitn>GrpHdr>MsgId"`
+		Transfers []struct {
+			EndToEndID string `xml:"PmtId>EndToEndId"`
+			Amount     string `xml:"Amt>InstdAmt"`
+		} `xml:"CstmrCdtTrfInitn>PmtInf>CdtTrfTxInf"`
+	}
+	if err := xml.Unmarshal(pain001, &doc); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.002.001.03"><CstmrPmtStsRpt>
+<OrgnlGrpInfAndSts><OrgnlMsgId>%s</OrgnlMsgId><GrpSts>PART</GrpSts></OrgnlGrpInfAndSts>
+<OrgnlPmtInfAndSts>`, doc.MessageID)
+	for i, transfer := range doc.Transfers {
+		status, reason := "ACSC", ""
+		if i == 0 {
+			status, reason = "RJCT", "<StsRsnInf><Rsn><Cd>AC04</Cd></Rsn></StsRsnInf>"
+		}
+		fmt.Fprintf(&b, `<TxInfAndSts><StsId>BANK-%d</StsId><OrgnlEndToEndId>%s</OrgnlEndToEndId><TxSts>%s</TxSts>%s<OrgnlTxRef><Amt><InstdAmt Ccy="IDR">%s</InstdAmt></Amt></OrgnlTxRef></TxInfAndSts>`,
+			i+1, transfer.EndToEndID, status, reason, transfer.Amount)
+	}
+	b.WriteString(`</OrgnlPmtInfAndSts></CstmrPmtStsRpt></Document>`)
+	return []byte(b.String())
+}
+
+func TestReconcilePain002ForUUIDApplications(t *testing.T) {
+	appIDs := []string{
+		"3f2b8c1e-7a4d-4e9b-b1c2-9d8e7f6a5b4c",
+		"3f2b8c1e-7a4d-4e9b-b1c2-9d8e7f6a5b4d",
+		"APP-2025-0001",
+	}
+	manifest := &domain.BatchManifest{BatchID: "batch-1", Code: "BATCH-2025-11-001", Status: domain.BatchStatusSent}
+	file := domain.DisbursementFile{
+		BatchID:   manifest.BatchID,
+		BatchCode: manifest.Code,
+		CreatedAt: time.Date(2025, time.November, 3, 9, 0, 0, 0, time.UTC),
+		Currency:  "IDR",
+		Config:    domain.DisbursementConfig{Currency: "IDR", DebtorName: "Dinas Sosial", DebtorAccount: "1234567890"},
+	}
+	for i, appID := range appIDs {
+		amount := int64(300000 + i)
+		manifest.Items = append(manifest.Items, domain.BatchManifestItem{ApplicationID: appID, Program: "PKH", Amount: amount, Currency: "IDR"})
+		file.Lines = append(file.Lines, domain.DisbursementLine{
+			ApplicationID: appID, Name: "Penerima", Amount: amount, Currency: "IDR",
+			Channel: domain.PayoutChannelBankTransfer, Bank: "BMRIIDJA", Account: fmt.Sprintf("98765%d", i),
+		})
+		file.Total += amount
+	}
+
+	pain001, err := bankfile.Pain001Exporter{}.Render(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := bankfile.Pain002Parser{}.Parse(pain002For(t, pain001))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, applied := reconcileBatch(manifest, map[string]domain.BatchItemResult{}, results.Results)
+
+	if len(report.Unmatched) != 0 || len(report.Outstanding) != 0 {
+		t.Fatalf("unmatched %+v, outstanding %v", report.Unmatched, report.Outstanding)
+	}
+	if report.Disbursed != 2 || report.Failed != 1 || len(applied) != 3 {
+		t.Fatalf("report %+v, applied %+v", report, applied)
+	}
+	want := map[string]string{
+		appIDs[0]: domain.DisbursementFailed,
+		appIDs[1]: domain.DisbursementDisbursed,
+		appIDs[2]: domain.DisbursementDisbursed,
+	}
+	for _, result := range applied {
+		if want[result.ApplicationID] != result.Status {
+			t.Errorf("applied %s as %s, want %s", result.ApplicationID, result.Status, want[result.ApplicationID])
+		}
+	}
+	if applied[0].Reason != "AC04" {
+		t.Errorf("rejection reason %q", applied[0].Reason)
+	}
+
+	// Results already applied are unchanged when the bank sends the report again.
+	current := map[string]domain.BatchItemResult{}
+	for _, result := range applied {
+		current[result.ApplicationID] = domain.BatchItemResult{Status: result.Status, Reason: result.Reason}
+	}
+	again, reapplied := reconcileBatch(manifest, current, results.Results)
+	if again.Unchanged != 3 || len(reapplied) != 0 {
+		t.Errorf("second import: report %+v, applied %+v", again, reapplied)
+	}
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/ical"
+)
+
+const (
+	calendarProdID = "-//e-kyc//api-backoffice//ID"
+	// calendarUIDDomain makes event UIDs globally unique, as RFC 5545 asks.
+	calendarUIDDomain = "api-backoffice.e-kyc"
+	// calendarFeedLookback keeps recent past events in the feed.
+	calendarFeedLookback = 14 * 24 * time.Hour
+	// calendarCancelledTTL is how long a dropped event is published as cancelled, long
+	// enough for every subscribed device to have refreshed.
+	calendarCancelledTTL   = 30 * 24 * time.Hour
+	calendarRefresh        = time.Hour
+	distributionDuration   = 2 * time.Hour
+	maxCalendarFeedVisits  = 500
+	calendarFeedTokenBytes = 32
+)
+
+// CalendarFeedService publishes per-user iCalendar feeds: the TKSK's own visits and the
+// distributions in the user's region scope. Calendar apps cannot send headers, so the
+// feed is authenticated by a token in its URL. Every fetch is compared with what the
+// feed published last, so a changed event is sent with a higher SEQUENCE and an event
+// that dropped out (reassigned, deleted) is sent as cancelled for a while.
+type CalendarFeedService struct {
+	repo domain.CalendarFeedRepository
+}
+
+var _ domain.CalendarFeedService = (*CalendarFeedService)(nil)
+
+func NewCalendarFeedService(repo domain.CalendarFeedRepository) *CalendarFeedService {
+	return &CalendarFeedService{repo: repo}
+}
+
+// Issue creates a new feed token for the user, revoking the previous one. Users issue
+// their own feeds, admins anyone's.
+func (s *CalendarFeedService) Issue(ctx context.Context, params domain.CalendarFeedParams) (*domain.CalendarFeed, error) {
+	owner, err := s.authorize(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if !roleAllowed(owner.Role, domain.CalendarFeedRoles) {
+		return nil, fmt.Errorf("%w: peran %s tidak punya kalender", domain.ErrInvalidState, owner.Role)
+	}
+	buf := make([]byte, calendarFeedTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	feed, err := s.repo.SaveCalendarFeed(ctx, owner.UserID, hashFeedToken(token))
+	if err != nil {
+		return nil, err
+	}
+	feed.Token = token
+	feed.URL = "/api/calendar/" + token + ".ics"
+	return feed, nil
+}
+
+func (s *CalendarFeedService) Revoke(ctx context.Context, params domain.CalendarFeedParams) error {
+	owner, err := s.authorize(ctx, params)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteCalendarFeed(ctx, owner.UserID)
+}
+
+func (s *CalendarFeedService) authorize(ctx context.Context, params domain.CalendarFeedParams) (*domain.CalendarFeedOwner, error) {
+	if params.ActorID != params.UserID && !roleAllowed(params.ActorRole, []string{"ADMIN"}) {
+		return nil, fmt.Errorf("%w: kalender pengguna lain", domain.ErrForbidden)
+	}
+	return s.repo.GetCalendarFeedOwner(ctx, params.UserID)
+}
+
+func (s *CalendarFeedService) Render(ctx context.Context, token string) ([]byte, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, domain.ErrNotFound
+	}
+	owner, err := s.repo.FindCalendarFeedOwner(ctx, hashFeedToken(token))
+	if err != nil {
+		return nil, err
+	}
+	schedule, err := loadVisitSchedule(ctx, s.repo)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	windowStart := now.Add(-calendarFeedLookback)
+
+	visits, err := s.repo.ListVisits(ctx, domain.ListVisitsParams{
+		TkskID: owner.UserID,
+		From:   &windowStart,
+		Limit:  maxCalendarFeedVisits,
+	})
+	if err != nil {
+		return nil, err
+	}
+	visits, windowStart = capCalendarVisits(visits, windowStart)
+	appIDs := make([]string, 0, len(visits))
+	for _, visit := range visits {
+		appIDs = append(appIDs, visit.ApplicationID)
+	}
+	households, err := s.repo.ListVisitHouseholds(ctx, uniqueIDs(appIDs))
+	if err != nil {
+		return nil, err
+	}
+	distributions, err := s.repo.ListRegionDistributions(ctx, owner.RegionScope, windowStart)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]ical.Event, len(visits)+len(distributions))
+	var entries []domain.CalendarFeedEvent
+	add := func(event ical.Event, label string) {
+		current[event.UID] = event
+		entries = append(entries, domain.CalendarFeedEvent{
+			UID:         event.UID,
+			Fingerprint: calendarFingerprint(event),
+			Label:       label,
+			StartsAt:    event.Start,
+			EndsAt:      event.End,
+		})
+	}
+	for _, visit := range visits {
+		add(visitCalendarEvent(visit, households[visit.ApplicationID], schedule.VisitDuration), "Kunjungan "+visit.ID)
+	}
+	for _, dist := range distributions {
+		if dist.ScheduledAt.Before(windowStart) {
+			continue
+		}
+		add(distributionCalendarEvent(dist), "Distribusi "+dist.Name)
+	}
+
+	published, err := s.repo.PublishCalendarFeed(ctx, owner.UserID, func(previous []domain.CalendarFeedEvent) []domain.CalendarFeedEvent {
+		return reconcileCalendarFeed(previous, entries, windowStart, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	calendar := ical.Calendar{
+		ProdID:          calendarProdID,
+		Name:            "Jadwal " + owner.Name,
+		RefreshInterval: calendarRefresh,
+	}
+	for _, entry := range published {
+		if entry.CancelledAt != nil {
+			calendar.Events = append(calendar.Events, ical.Event{
+				UID:      entry.UID,
+				Sequence: entry.Sequence,
+				Start:    entry.StartsAt,
+				End:      entry.EndsAt,
+				Summary:  "Dibatalkan: " + entry.Label,
+				Status:   ical.StatusCancelled,
+			})
+			continue
+		}
+		event := current[entry.UID]
+		event.Sequence = entry.Sequence
+		calendar.Events = append(calendar.Events, event)
+	}
+	sort.SliceStable(calendar.Events, func(i, j int) bool { return calendar.Events[i].Start.Before(calendar.Events[j].Start) })
+	return calendar.Marshal(now)
+}
+
+// capCalendarVisits narrows the window when ListVisits hit maxCalendarFeedVisits. The
+// latest visits come first, so the cap drops the earliest ones: the window then starts
+// just after the oldest visit returned, whose ties may have been dropped too. Visits
+// before it fall out of the window instead of being published as cancelled.
+func capCalendarVisits(visits []domain.Visit, windowStart time.Time) ([]domain.Visit, time.Time) {
+	if len(visits) < maxCalendarFeedVisits {
+		return visits, windowStart
+	}
+	oldest := visits[0].ScheduledAt
+	for _, visit := range visits[1:] {
+		if visit.ScheduledAt.Before(oldest) {
+			oldest = visit.ScheduledAt
+		}
+	}
+	windowStart = oldest.Add(time.Nanosecond)
+	kept := make([]domain.Visit, 0, len(visits))
+	for _, visit := range visits {
+		if !visit.ScheduledAt.Before(windowStart) {
+			kept = append(kept, visit)
+		}
+	}
+	return kept, windowStart
+}
+
+// reconcileCalendarFeed works out what to publish from what was published before. An
+// event keeps its sequence while it is unchanged and gets the next one when it changes
+// or comes back. Events no longer produced are cancelled, unless they simply fell out
+// of the window, and cancelled events are forgotten after calendarCancelledTTL.
+func reconcileCalendarFeed(previous, current []domain.CalendarFeedEvent, windowStart, now time.Time) []domain.CalendarFeedEvent {
+	before := make(map[string]domain.CalendarFeedEvent, len(previous))
+	for _, event := range previous {
+		before[event.UID] = event
+	}
+	result := make([]domain.CalendarFeedEvent, 0, len(current)+len(previous))
+	seen := make(map[string]bool, len(current))
+	for _, event := range current {
+		if seen[event.UID] {
+			continue
+		}
+		seen[event.UID] = true
+		if old, ok := before[event.UID]; ok {
+			event.Sequence = old.Sequence
+			if old.Fingerprint != event.Fingerprint || old.CancelledAt != nil {
+				event.Sequence++
+			}
+		}
+		event.CancelledAt = nil
+		result = append(result, event)
+	}
+	for _, old := range previous {
+		if seen[old.UID] {
+			continue
+		}
+		if old.CancelledAt == nil {
+			if old.StartsAt.Before(windowStart) {
+				continue
+			}
+			cancelledAt := now
+			old.CancelledAt = &cancelledAt
+			old.Sequence++
+		} else if now.Sub(*old.CancelledAt) > calendarCancelledTTL {
+			continue
+		}
+		result = append(result, old)
+	}
+	return result
+}
+
+// visitCalendarEvent describes a visit for the TKSK's calendar. The household is placed
+// at the visit's own geotag, else where an earlier visit found it, else by its region.
+func visitCalendarEvent(visit domain.Visit, household domain.VisitHousehold, duration time.Duration) ical.Event {
+	lat, lng := visit.GeotagLat, visit.GeotagLng
+	if lat == nil || lng == nil {
+		lat, lng = household.Lat, household.Lng
+	}
+	name := household.ApplicantName
+	if name == "" {
+		name = visit.ApplicationID
+	}
+	location := regionLabel(household.Region)
+	lines := []string{
+		"Aplikasi: " + visit.ApplicationID,
+		"Penerima: " + name,
+		"Status: " + visit.Status,
+		"Wilayah: " + location,
+	}
+	if lat != nil && lng != nil {
+		coords := strconv.FormatFloat(*lat, 'f', 6, 64) + "," + strconv.FormatFloat(*lng, 'f', 6, 64)
+		lines = append(lines, "Lokasi rumah tangga: "+coords, "https://maps.google.com/?q="+coords)
+		location = coords + " (" + location + ")"
+	}
+	lines = append(lines, "Kunjungan: "+visit.ID)
+	return ical.Event{
+		UID:         "visit-" + visit.ID + "@" + calendarUIDDomain,
+		Start:       visit.ScheduledAt,
+		End:         visit.ScheduledAt.Add(duration),
+		Summary:     "Kunjungan TKSK: " + name,
+		Description: strings.Join(lines, "\n"),
+		Location:    location,
+		Lat:         lat,
+		Lng:         lng,
+		Status:      ical.StatusConfirmed,
+		Categories:  []string{"KUNJUNGAN"},
+	}
+}
+
+func distributionCalendarEvent(dist domain.Distribution) ical.Event {
+	lines := []string{
+		"Distribusi: " + dist.ID,
+		"Kanal: " + dist.Channel,
+		"Status: " + dist.Status,
+		fmt.Sprintf("Penerima: %d", len(dist.Beneficiaries)),
+	}
+	if len(dist.BatchCodes) > 0 {
+		lines = append(lines, "Batch: "+strings.Join(dist.BatchCodes, ", "))
+	}
+	if dist.Notes != nil && strings.TrimSpace(*dist.Notes) != "" {
+		lines = append(lines, strings.TrimSpace(*dist.Notes))
+	}
+	status := ical.StatusConfirmed
+	switch strings.ToUpper(dist.Status) {
+	case "CANCELLED", "CANCELED":
+		status = ical.StatusCancelled
+	}
+	return ical.Event{
+		UID:         "distribution-" + dist.ID + "@" + calendarUIDDomain,
+		Start:       dist.ScheduledAt,
+		End:         dist.ScheduledAt.Add(distributionDuration),
+		Summary:     "Distribusi: " + dist.Name,
+		Description: strings.Join(lines, "\n"),
+		Location:    dist.Location,
+		Status:      status,
+		Categories:  []string{"DISTRIBUSI"},
+	}
+}
+
+// calendarFingerprint digests everything an event publishes besides its sequence.
+func calendarFingerprint(event ical.Event) string {
+	h := sha256.New()
+	fields := []string{
+		event.Start.UTC().Format(time.RFC3339), event.End.UTC().Format(time.RFC3339),
+		event.Summary, event.Description, event.Location, event.Status, strings.Join(event.Categories, ","),
+	}
+	if event.Lat != nil && event.Lng != nil {
+		fields = append(fields, strconv.FormatFloat(*event.Lat, 'f', 6, 64), strconv.FormatFloat(*event.Lng, 'f', 6, 64))
+	}
+	for _, field := range fields {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
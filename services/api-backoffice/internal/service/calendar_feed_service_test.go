@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+func TestReconcileCalendarFeed(t *testing.T) {
+	now := time.Date(2025, time.November, 3, 8, 0, 0, 0, time.UTC)
+	windowStart := now.Add(-calendarFeedLookback)
+	at := func(d time.Duration) time.Time { return now.Add(d) }
+	cancelledAt := func(ago time.Duration) *time.Time { t := now.Add(-ago); return &t }
+	event := func(uid, fingerprint string, seq int, start time.Time, cancelled *time.Time) domain.CalendarFeedEvent {
+		return domain.CalendarFeedEvent{UID: uid, Fingerprint: fingerprint, Sequence: seq, StartsAt: start, CancelledAt: cancelled}
+	}
+
+	tests := []struct {
+		name     string
+		previous []domain.CalendarFeedEvent
+		current  []domain.CalendarFeedEvent
+		want     []domain.CalendarFeedEvent
+	}{
+		{
+			name:    "new event starts at sequence 0",
+			current: []domain.CalendarFeedEvent{event("a", "f1", 0, at(time.Hour), nil)},
+			want:    []domain.CalendarFeedEvent{event("a", "f1", 0, at(time.Hour), nil)},
+		},
+		{
+			name:     "unchanged event keeps its sequence",
+			previous: []domain.CalendarFeedEvent{event("a", "f1", 2, at(time.Hour), nil)},
+			current:  []domain.CalendarFeedEvent{event("a", "f1", 0, at(time.Hour), nil)},
+			want:     []domain.CalendarFeedEvent{event("a", "f1", 2, at(time.Hour), nil)},
+		},
+		{
+			name:     "changed event gets the next sequence",
+			previous: []domain.CalendarFeedEvent{event("a", "f1", 2, at(time.Hour), nil)},
+			current:  []domain.CalendarFeedEvent{event("a", "f2", 0, at(2*time.Hour), nil)},
+			want:     []domain.CalendarFeedEvent{event("a", "f2", 3, at(2*time.Hour), nil)},
+		},
+		{
+			name:     "dropped event is cancelled",
+			previous: []domain.CalendarFeedEvent{event("a", "f1", 1, at(time.Hour), nil)},
+			want:     []domain.CalendarFeedEvent{event("a", "f1", 2, at(time.Hour), cancelledAt(0))},
+		},
+		{
+			name:     "cancelled event keeps its sequence",
+			previous: []domain.CalendarFeedEvent{event("a", "f1", 2, at(time.Hour), cancelledAt(24*time.Hour))},
+			want:     []domain.CalendarFeedEvent{event("a", "f1", 2, at(time.Hour), cancelledAt(24*time.Hour))},
+		},
+		{
+			name:     "cancelled event is forgotten after the TTL",
+			previous: []domain.CalendarFeedEvent{event("a", "f1", 2, at(time.Hour), cancelledAt(calendarCancelledTTL+time.Minute))},
+		},
+		{
+			name:     "cancelled event that comes back gets the next sequence",
+			previous: []domain.CalendarFeedEvent{event("a", "f1", 2, at(time.Hour), cancelledAt(time.Hour))},
+			current:  []domain.CalendarFeedEvent{event("a", "f1", 0, at(time.Hour), nil)},
+			want:     []domain.CalendarFeedEvent{event("a", "f1", 3, at(time.Hour), nil)},
+		},
+		{
+			name:     "event that fell out of the window is not cancelled",
+			previous: []domain.CalendarFeedEvent{event("a", "f1", 0, windowStart.Add(-time.Minute), nil)},
+		},
+		{
+			name:    "duplicate UIDs are published once",
+			current: []domain.CalendarFeedEvent{event("a", "f1", 0, at(time.Hour), nil), event("a", "f2", 0, at(time.Hour), nil)},
+			want:    []domain.CalendarFeedEvent{event("a", "f1", 0, at(time.Hour), nil)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reconcileCalendarFeed(tt.previous, tt.current, windowStart, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.UID != w.UID || g.Fingerprint != w.Fingerprint || g.Sequence != w.Sequence || !g.StartsAt.Equal(w.StartsAt) ||
+					(g.CancelledAt == nil) != (w.CancelledAt == nil) || (g.CancelledAt != nil && !g.CancelledAt.Equal(*w.CancelledAt)) {
+					t.Errorf("event %d = %+v, want %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
+
+// calendarFeedRepo serves one TKSK's visits and keeps what the feed published.
+type calendarFeedRepo struct {
+	domain.CalendarFeedRepository
+	visits    []domain.Visit
+	published []domain.CalendarFeedEvent
+}
+
+func (r *calendarFeedRepo) GetConfig(context.Context) (*domain.SystemConfig, error) {
+	return nil, domain.ErrNotFound
+}
+
+func (r *calendarFeedRepo) FindCalendarFeedOwner(context.Context, string) (*domain.CalendarFeedOwner, error) {
+	return &domain.CalendarFeedOwner{UserID: "tksk-1", Name: "Budi"}, nil
+}
+
+// ListVisits pages like the repository: latest first, up to the limit.
+func (r *calendarFeedRepo) ListVisits(_ context.Context, params domain.ListVisitsParams) ([]domain.Visit, error) {
+	var visits []domain.Visit
+	for i := len(r.visits) - 1; i >= 0 && len(visits) < params.Limit; i-- {
+		if !r.visits[i].ScheduledAt.Before(*params.From) {
+			visits = append(visits, r.visits[i])
+		}
+	}
+	return visits, nil
+}
+
+func (r *calendarFeedRepo) ListVisitHouseholds(context.Context, []string) (map[string]domain.VisitHousehold, error) {
+	return map[string]domain.VisitHousehold{}, nil
+}
+
+func (r *calendarFeedRepo) ListRegionDistributions(context.Context, []string, time.Time) ([]domain.Distribution, error) {
+	return nil, nil
+}
+
+func (r *calendarFeedRepo) PublishCalendarFeed(_ context.Context, _ string, reconcile domain.CalendarFeedReconcile) ([]domain.CalendarFeedEvent, error) {
+	r.published = reconcile(r.published)
+	return r.published, nil
+}
+
+// scheduledVisits returns n visits an hour apart from start, in schedule order.
+func scheduledVisits(start time.Time, n int) []domain.Visit {
+	visits := make([]domain.Visit, n)
+	for i := range visits {
+		visits[i] = domain.Visit{ID: fmt.Sprintf("v%d", i), ApplicationID: fmt.Sprintf("app-%d", i), ScheduledAt: start.Add(time.Duration(i) * time.Hour), Status: "SCHEDULED"}
+	}
+	return visits
+}
+
+// feedEvents splits a rendered feed into its VEVENTs by UID.
+func feedEvents(t *testing.T, feed []byte) map[string]string {
+	t.Helper()
+	events := map[string]string{}
+	for _, block := range strings.Split(string(feed), "BEGIN:VEVENT\r\n")[1:] {
+		uid, _, _ := strings.Cut(strings.TrimPrefix(block, "UID:"), "\r\n")
+		events[strings.TrimSuffix(uid, "@"+calendarUIDDomain)] = block
+	}
+	return events
+}
+
+func TestCalendarFeedPublishesChangesAndCancellations(t *testing.T) {
+	start := time.Now().UTC().Truncate(time.Hour).Add(24 * time.Hour)
+	repo := &calendarFeedRepo{visits: scheduledVisits(start, 3)}
+	svc := NewCalendarFeedService(repo)
+	ctx := context.Background()
+
+	if _, err := svc.Render(ctx, "token"); err != nil {
+		t.Fatal(err)
+	}
+	repo.visits[1].ScheduledAt = repo.visits[1].ScheduledAt.Add(30 * time.Minute)
+	repo.visits = repo.visits[:2]
+	feed, err := svc.Render(ctx, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := feedEvents(t, feed)
+	for uid, want := range map[string][]string{
+		"visit-v0": {"SEQUENCE:0\r\n", "STATUS:CONFIRMED\r\n"},
+		"visit-v1": {"SEQUENCE:1\r\n", "STATUS:CONFIRMED\r\n"},
+		"visit-v2": {"SEQUENCE:1\r\n", "STATUS:CANCELLED\r\n", "SUMMARY:Dibatalkan: Kunjungan v2\r\n"},
+	} {
+		for _, line := range want {
+			if !strings.Contains(events[uid], line) {
+				t.Errorf("%s is missing %q:\n%s", uid, line, events[uid])
+			}
+		}
+	}
+}
+
+func TestCalendarFeedCapDoesNotCancelEarlierVisits(t *testing.T) {
+	start := time.Now().UTC().Truncate(time.Hour).Add(24 * time.Hour)
+	repo := &calendarFeedRepo{visits: scheduledVisits(start, maxCalendarFeedVisits)}
+	svc := NewCalendarFeedService(repo)
+	ctx := context.Background()
+
+	if _, err := svc.Render(ctx, "token"); err != nil {
+		t.Fatal(err)
+	}
+	// Visits scheduled after the last one push the earliest past the cap.
+	repo.visits = scheduledVisits(start, maxCalendarFeedVisits+3)
+	feed, err := svc.Render(ctx, "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := strings.Count(string(feed), "STATUS:CANCELLED"); n != 0 {
+		t.Errorf("%d visits cut by the cap published as cancelled", n)
+	}
+	events := feedEvents(t, feed)
+	for _, uid := range []string{"visit-v0", "visit-v3"} {
+		if _, ok := events[uid]; ok {
+			t.Errorf("%s published although the cap cut it", uid)
+		}
+	}
+	if _, ok := events[fmt.Sprintf("visit-v%d", maxCalendarFeedVisits+2)]; !ok {
+		t.Error("latest visit missing from the feed")
+	}
+	for _, event := range repo.published {
+		if event.CancelledAt != nil {
+			t.Errorf("%s stored as cancelled", event.UID)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// Codes of a ChecklistFieldError.
+const (
+	checklistRequired     = "REQUIRED"
+	checklistWrongType    = "TYPE"
+	checklistNotAllowed   = "ENUM"
+	checklistUnknownField = "UNKNOWN_FIELD"
+	checklistTooSmall     = "MINIMUM"
+	checklistTooLarge     = "MAXIMUM"
+	checklistTooShort     = "MIN_LENGTH"
+	checklistTooLong      = "MAX_LENGTH"
+	checklistNoMatch      = "PATTERN"
+	checklistTooFewItems  = "MIN_ITEMS"
+	checklistTooManyItems = "MAX_ITEMS"
+)
+
+// validateVisitChecklist is the domain.ChecklistCheck used for visit writes.
+func validateVisitChecklist(template *domain.ChecklistTemplate, checklist map[string]any, submitting bool) []domain.ChecklistFieldError {
+	var fields []domain.ChecklistFieldError
+	validateChecklistValue(&template.Schema, "", checklist, submitting, &fields)
+	return fields
+}
+
+func validateChecklistValue(schema *domain.ChecklistSchema, path string, value any, submitting bool, fields *[]domain.ChecklistFieldError) {
+	fail := func(code, format string, args ...any) {
+		*fields = append(*fields, domain.ChecklistFieldError{Field: path, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch schema.Type {
+	case domain.ChecklistTypeObject:
+		object, ok := value.(map[string]any)
+		if !ok {
+			fail(checklistWrongType, "harus berupa objek")
+			return
+		}
+		if submitting {
+			for _, name := range schema.Required {
+				if v, ok := object[name]; !ok || v == nil {
+					*fields = append(*fields, domain.ChecklistFieldError{Field: joinChecklistPath(path, name), Code: checklistRequired, Message: "wajib diisi"})
+				}
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			child, known := schema.Properties[name]
+			if !known {
+				if !schema.AdditionalProperties {
+					*fields = append(*fields, domain.ChecklistFieldError{Field: joinChecklistPath(path, name), Code: checklistUnknownField, Message: "tidak ada di template"})
+				}
+				continue
+			}
+			// A null field counts as left blank.
+			if object[name] != nil {
+				validateChecklistValue(child, joinChecklistPath(path, name), object[name], submitting, fields)
+			}
+		}
+		return
+
+	case domain.ChecklistTypeArray:
+		items, ok := value.([]any)
+		if !ok {
+			fail(checklistWrongType, "harus berupa daftar")
+			return
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems && submitting {
+			fail(checklistTooFewItems, "minimal %d isian", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			fail(checklistTooManyItems, "maksimal %d isian", *schema.MaxItems)
+		}
+		if schema.Items != nil {
+			for i, item := range items {
+				validateChecklistValue(schema.Items, fmt.Sprintf("%s[%d]", path, i), item, submitting, fields)
+			}
+		}
+		return
+
+	case domain.ChecklistTypeString:
+		s, ok := value.(string)
+		if !ok {
+			fail(checklistWrongType, "harus berupa teks")
+			return
+		}
+		length := utf8.RuneCountInString(s)
+		if schema.MinLength != nil && length < *schema.MinLength {
+			fail(checklistTooShort, "minimal %d karakter", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			fail(checklistTooLong, "maksimal %d karakter", *schema.MaxLength)
+		}
+		if schema.Pattern != "" {
+			if re, err := regexp.Compile(schema.Pattern); err == nil && !re.MatchString(s) {
+				fail(checklistNoMatch, "tidak sesuai format %s", schema.Pattern)
+			}
+		}
+
+	case domain.ChecklistTypeNumber, domain.ChecklistTypeInteger:
+		n, ok := value.(float64)
+		if !ok || (schema.Type == domain.ChecklistTypeInteger && n != math.Trunc(n)) {
+			if schema.Type == domain.ChecklistTypeInteger {
+				fail(checklistWrongType, "harus berupa bilangan bulat")
+			} else {
+				fail(checklistWrongType, "harus berupa angka")
+			}
+			return
+		}
+		if schema.Minimum != nil && n < *schema.Minimum {
+			fail(checklistTooSmall, "minimal %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && n > *schema.Maximum {
+			fail(checklistTooLarge, "maksimal %v", *schema.Maximum)
+		}
+
+	case domain.ChecklistTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			fail(checklistWrongType, "harus berupa ya/tidak")
+			return
+		}
+	}
+
+	if len(schema.Enum) > 0 && !slices.Contains(schema.Enum, value) {
+		fail(checklistNotAllowed, "harus salah satu dari %s", enumLabel(schema.Enum))
+	}
+}
+
+func joinChecklistPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func enumLabel(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// validateChecklistSchema checks a template before it is published. The root must be an
+// object, and every keyword must fit the type it is used on.
+func validateChecklistSchema(schema *domain.ChecklistSchema) error {
+	if schema.Type != domain.ChecklistTypeObject {
+		return errors.New("schema root harus bertipe object")
+	}
+	if len(schema.Properties) == 0 {
+		return errors.New("schema harus memiliki properties")
+	}
+	return checkChecklistSchemaNode(schema, "")
+}
+
+func checkChecklistSchemaNode(schema *domain.ChecklistSchema, path string) error {
+	where := path
+	if where == "" {
+		where = "(root)"
+	}
+	if schema == nil {
+		return fmt.Errorf("%s: definisi kosong", where)
+	}
+	isNumber := schema.Type == domain.ChecklistTypeNumber || schema.Type == domain.ChecklistTypeInteger
+
+	switch schema.Type {
+	case domain.ChecklistTypeObject:
+		for _, name := range schema.Required {
+			if _, ok := schema.Properties[name]; !ok {
+				return fmt.Errorf("%s: required %q tidak ada di properties", where, name)
+			}
+		}
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if strings.TrimSpace(name) == "" || strings.ContainsAny(name, ".[]") {
+				return fmt.Errorf("%s: nama field %q tidak valid", where, name)
+			}
+			if err := checkChecklistSchemaNode(schema.Properties[name], joinChecklistPath(path, name)); err != nil {
+				return err
+			}
+		}
+	case domain.ChecklistTypeArray:
+		if schema.Items == nil {
+			return fmt.Errorf("%s: array harus memiliki items", where)
+		}
+		if err := checkChecklistSchemaNode(schema.Items, path+"[]"); err != nil {
+			return err
+		}
+	case domain.ChecklistTypeString, domain.ChecklistTypeNumber, domain.ChecklistTypeInteger, domain.ChecklistTypeBoolean:
+	default:
+		return fmt.Errorf("%s: tipe %q tidak didukung", where, schema.Type)
+	}
+
+	if schema.Type != domain.ChecklistTypeObject && (len(schema.Properties) > 0 || len(schema.Required) > 0) {
+		return fmt.Errorf("%s: properties/required hanya untuk object", where)
+	}
+	if schema.Type != domain.ChecklistTypeArray && (schema.Items != nil || schema.MinItems != nil || schema.MaxItems != nil) {
+		return fmt.Errorf("%s: items/minItems/maxItems hanya untuk array", where)
+	}
+	if schema.Type != domain.ChecklistTypeString && (schema.MinLength != nil || schema.MaxLength != nil || schema.Pattern != "") {
+		return fmt.Errorf("%s: minLength/maxLength/pattern hanya untuk string", where)
+	}
+	if !isNumber && (schema.Minimum != nil || schema.Maximum != nil) {
+		return fmt.Errorf("%s: minimum/maximum hanya untuk number/integer", where)
+	}
+	if schema.Minimum != nil && schema.Maximum != nil && *schema.Minimum > *schema.Maximum {
+		return fmt.Errorf("%s: minimum lebih besar dari maximum", where)
+	}
+	if schema.MinLength != nil && schema.MaxLength != nil && *schema.MinLength > *schema.MaxLength {
+		return fmt.Errorf("%s: minLength lebih besar dari maxLength", where)
+	}
+	if schema.MinItems != nil && schema.MaxItems != nil && *schema.MinItems > *schema.MaxItems {
+		return fmt.Errorf("%s: minItems lebih besar dari maxItems", where)
+	}
+	if schema.Pattern != "" {
+		if _, err := regexp.Compile(schema.Pattern); err != nil {
+			return fmt.Errorf("%s: pattern tidak valid: %v", where, err)
+		}
+	}
+	if len(schema.Enum) > 0 {
+		if schema.Type == domain.ChecklistTypeObject || schema.Type == domain.ChecklistTypeArray {
+			return fmt.Errorf("%s: enum hanya untuk nilai tunggal", where)
+		}
+		for _, v := range schema.Enum {
+			var fields []domain.ChecklistFieldError
+			plain := *schema
+			plain.Enum = nil
+			validateChecklistValue(&plain, path, v, true, &fields)
+			if len(fields) > 0 {
+				return fmt.Errorf("%s: nilai enum %v tidak sesuai tipe %s", where, v, schema.Type)
+			}
+		}
+	}
+	return nil
+}
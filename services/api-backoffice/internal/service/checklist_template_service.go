@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// ChecklistTemplateService publishes visit checklist templates. Published versions are
+// never edited: a change is a new version, bound to visits scheduled from then on.
+type ChecklistTemplateService struct {
+	repo domain.ChecklistTemplateRepository
+}
+
+var _ domain.ChecklistTemplateService = (*ChecklistTemplateService)(nil)
+
+func NewChecklistTemplateService(repo domain.ChecklistTemplateRepository) *ChecklistTemplateService {
+	return &ChecklistTemplateService{repo: repo}
+}
+
+func (s *ChecklistTemplateService) List(ctx context.Context, params domain.ListChecklistTemplatesParams) ([]domain.ChecklistTemplate, error) {
+	params.Program = strings.TrimSpace(params.Program)
+	params.Period = strings.TrimSpace(params.Period)
+	return s.repo.ListChecklistTemplates(ctx, params)
+}
+
+func (s *ChecklistTemplateService) Get(ctx context.Context, id int64) (*domain.ChecklistTemplate, error) {
+	return s.repo.GetChecklistTemplate(ctx, id)
+}
+
+func (s *ChecklistTemplateService) Publish(ctx context.Context, params domain.PublishChecklistTemplateParams) (*domain.ChecklistTemplate, error) {
+	params.Program = strings.TrimSpace(params.Program)
+	params.Period = strings.TrimSpace(params.Period)
+	params.Notes = strings.TrimSpace(params.Notes)
+	if strings.TrimSpace(params.Actor) == "" {
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	}
+	if params.Program == "" || params.Period == "" {
+		return nil, fmt.Errorf("%w: program dan period wajib diisi", domain.ErrInvalidState)
+	}
+	if err := validateChecklistSchema(&params.Schema); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidState, err)
+	}
+	return s.repo.PublishChecklistTemplate(ctx, params)
+}
+
+func (s *ChecklistTemplateService) Retire(ctx context.Context, id int64, actor string) (*domain.ChecklistTemplate, error) {
+	if strings.TrimSpace(actor) == "" {
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	}
+	return s.repo.RetireChecklistTemplate(ctx, id, actor)
+}
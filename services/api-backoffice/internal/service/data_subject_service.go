@@ -0,0 +1,204 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type DataSubjectService struct {
+	repo  domain.DataSubjectRepository
+	media domain.MediaClient
+}
+
+var _ domain.DataSubjectService = (*DataSubjectService)(nil)
+
+func NewDataSubjectService(repo domain.DataSubjectRepository, media domain.MediaClient) *DataSubjectService {
+	return &DataSubjectService{repo: repo, media: media}
+}
+
+// Export bundles the subject's records as export.json and their media under media/. A
+// file that cannot be downloaded, or whose URL is outside media storage and so is never
+// fetched, is reported in the manifest instead of failing the export.
+func (s *DataSubjectService) Export(ctx context.Context, userID, actor string) (*domain.DataSubjectBundle, error) {
+	userID, err := normalizeSubjectID(userID)
+	if err != nil {
+		return nil, err
+	}
+	actor = strings.TrimSpace(actor)
+	if actor == "" {
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	}
+	export, err := s.repo.ExportDataSubject(ctx, userID, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for i := range export.Media {
+		item := &export.Media[i]
+		image, err := s.media.Download(ctx, item.URL)
+		if err != nil {
+			item.Error = err.Error()
+			continue
+		}
+		sum := sha256.Sum256(image.Content)
+		item.SHA256 = hex.EncodeToString(sum[:])
+		item.Path = fmt.Sprintf("media/%03d-%s-%s%s", i+1, item.Table, mediaFieldName(item.Field), mediaExtension(image.MimeType))
+		file, err := archive.Create(item.Path)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := file.Write(image.Content); err != nil {
+			return nil, err
+		}
+	}
+	manifest, err := archive.Create("export.json")
+	if err != nil {
+		return nil, err
+	}
+	encoder := json.NewEncoder(manifest)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(export); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return &domain.DataSubjectBundle{
+		FileName: fmt.Sprintf("data-subject-%s-%s.zip", userID, export.GeneratedAt.Format("20060102")),
+		Content:  buf.Bytes(),
+	}, nil
+}
+
+func mediaFieldName(field string) string {
+	return strings.NewReplacer("[", "-", "]", "", "_url", "").Replace(field)
+}
+
+func mediaExtension(mimeType string) string {
+	if mimeType == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return ""
+	}
+	exts, _ := mime.ExtensionsByType(mediaType)
+	if len(exts) == 0 {
+		return ""
+	}
+	return exts[0]
+}
+
+// normalizeSubjectID reports ErrNotFound for ids that cannot belong to any user.
+func normalizeSubjectID(userID string) (string, error) {
+	parsed, err := uuid.Parse(strings.TrimSpace(userID))
+	if err != nil {
+		return "", fmt.Errorf("%w: pengguna %q", domain.ErrNotFound, userID)
+	}
+	return parsed.String(), nil
+}
+
+func (s *DataSubjectService) RequestErasure(ctx context.Context, params domain.CreateErasureRequestParams) (*domain.ErasureRequest, error) {
+	userID, err := normalizeSubjectID(params.UserID)
+	if err != nil {
+		return nil, err
+	}
+	params.UserID = userID
+	params.Reason = strings.TrimSpace(params.Reason)
+	params.Actor = strings.TrimSpace(params.Actor)
+	switch {
+	case params.Reason == "":
+		return nil, fmt.Errorf("%w: alasan wajib diisi", domain.ErrInvalidState)
+	case params.Actor == "":
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	}
+	return s.repo.CreateErasureRequest(ctx, params)
+}
+
+func (s *DataSubjectService) ListErasureRequests(ctx context.Context, params domain.ListErasureRequestsParams) ([]domain.ErasureRequest, error) {
+	params.Status = strings.ToUpper(strings.TrimSpace(params.Status))
+	switch params.Status {
+	case "", domain.ErasureStatusPending, domain.ErasureStatusCompleted, domain.ErasureStatusRejected:
+	default:
+		return nil, fmt.Errorf("%w: status %q tidak dikenal", domain.ErrInvalidState, params.Status)
+	}
+	return s.repo.ListErasureRequests(ctx, params)
+}
+
+func (s *DataSubjectService) GetErasureRequest(ctx context.Context, id int64) (*domain.ErasureRequest, error) {
+	return s.repo.GetErasureRequest(ctx, id)
+}
+
+// ApproveErasure runs the erasure, then deletes the subject's media once it has
+// committed. A file storage fails to delete stays queued on the completed request,
+// counted in MediaPending, for RetryErasureMedia.
+func (s *DataSubjectService) ApproveErasure(ctx context.Context, params domain.ReviewErasureRequestParams) (*domain.ErasureRequest, error) {
+	params.Actor = strings.TrimSpace(params.Actor)
+	params.Note = strings.TrimSpace(params.Note)
+	if params.Actor == "" {
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	}
+	request, err := s.repo.ApproveErasureRequest(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return s.deleteErasureMedia(ctx, request.ID)
+}
+
+func (s *DataSubjectService) RetryErasureMedia(ctx context.Context, id int64) (*domain.ErasureRequest, error) {
+	request, err := s.repo.GetErasureRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != domain.ErasureStatusCompleted {
+		return nil, fmt.Errorf("%w: permintaan penghapusan belum disetujui", domain.ErrInvalidState)
+	}
+	return s.deleteErasureMedia(ctx, id)
+}
+
+// deleteErasureMedia deletes the queued media of a request one file at a time, recording
+// each outcome, and returns the request with what is still pending.
+func (s *DataSubjectService) deleteErasureMedia(ctx context.Context, requestID int64) (*domain.ErasureRequest, error) {
+	media, err := s.repo.ListPendingErasureMedia(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range media {
+		if err := s.media.Delete(ctx, item.URL); err != nil {
+			log.Printf("api-backoffice: erasure %d: delete media %d: %v", requestID, item.ID, err)
+			if err := s.repo.MarkErasureMediaFailed(ctx, item.ID, err.Error()); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := s.repo.MarkErasureMediaDeleted(ctx, item.ID); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.GetErasureRequest(ctx, requestID)
+}
+
+func (s *DataSubjectService) RejectErasure(ctx context.Context, params domain.ReviewErasureRequestParams) (*domain.ErasureRequest, error) {
+	params.Actor = strings.TrimSpace(params.Actor)
+	params.Note = strings.TrimSpace(params.Note)
+	switch {
+	case params.Actor == "":
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	case params.Note == "":
+		return nil, fmt.Errorf("%w: catatan penolakan wajib diisi", domain.ErrInvalidState)
+	}
+	return s.repo.RejectErasureRequest(ctx, params)
+}
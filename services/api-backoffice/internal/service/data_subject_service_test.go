@@ -0,0 +1,179 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/services/api-backoffice/internal/infrastructure/media"
+)
+
+// dataSubjectRepo exports one user with the given media and keeps the media queue of
+// one approved erasure request.
+type dataSubjectRepo struct {
+	domain.DataSubjectRepository
+	media    []domain.DataSubjectMedia
+	approved bool
+	queue    []domain.ErasureMedia
+	deleted  map[int64]bool
+}
+
+func (r *dataSubjectRepo) ApproveErasureRequest(_ context.Context, params domain.ReviewErasureRequestParams) (*domain.ErasureRequest, error) {
+	r.approved = true
+	return r.GetErasureRequest(context.Background(), params.ID)
+}
+
+func (r *dataSubjectRepo) GetErasureRequest(_ context.Context, id int64) (*domain.ErasureRequest, error) {
+	request := &domain.ErasureRequest{ID: id, Status: domain.ErasureStatusPending}
+	if r.approved {
+		request.Status = domain.ErasureStatusCompleted
+	}
+	for _, item := range r.queue {
+		if !r.deleted[item.ID] {
+			request.MediaPending++
+		}
+	}
+	return request, nil
+}
+
+func (r *dataSubjectRepo) ListPendingErasureMedia(context.Context, int64) ([]domain.ErasureMedia, error) {
+	var pending []domain.ErasureMedia
+	for _, item := range r.queue {
+		if !r.deleted[item.ID] {
+			pending = append(pending, item)
+		}
+	}
+	return pending, nil
+}
+
+func (r *dataSubjectRepo) MarkErasureMediaDeleted(_ context.Context, id int64) error {
+	r.deleted[id] = true
+	return nil
+}
+
+func (r *dataSubjectRepo) MarkErasureMediaFailed(_ context.Context, id int64, reason string) error {
+	for i := range r.queue {
+		if r.queue[i].ID == id {
+			r.queue[i].Attempts++
+			r.queue[i].LastError = &reason
+		}
+	}
+	return nil
+}
+
+// flakyMedia fails to delete the URLs in down.
+type flakyMedia struct {
+	domain.MediaClient
+	down    map[string]bool
+	deleted []string
+}
+
+func (m *flakyMedia) Delete(_ context.Context, url string) error {
+	if m.down[url] {
+		return errors.New("delete media: status 503")
+	}
+	m.deleted = append(m.deleted, url)
+	return nil
+}
+
+func TestApproveErasureKeepsFailedMediaQueued(t *testing.T) {
+	repo := &dataSubjectRepo{deleted: map[int64]bool{}, queue: []domain.ErasureMedia{
+		{ID: 1, RequestID: 9, URL: "http://media/media/ktp"},
+		{ID: 2, RequestID: 9, URL: "http://media/media/selfie"},
+		{ID: 3, RequestID: 9, URL: "http://media/media/video"},
+	}}
+	media := &flakyMedia{down: map[string]bool{"http://media/media/selfie": true}}
+	svc := NewDataSubjectService(repo, media)
+	ctx := context.Background()
+
+	if _, err := svc.RetryErasureMedia(ctx, 9); !errors.Is(err, domain.ErrInvalidState) {
+		t.Errorf("retry before approval = %v, want ErrInvalidState", err)
+	}
+	request, err := svc.ApproveErasure(ctx, domain.ReviewErasureRequestParams{ID: 9, Actor: "dpo"})
+	if err != nil {
+		t.Fatalf("a failed media delete failed the committed erasure: %v", err)
+	}
+	if request.Status != domain.ErasureStatusCompleted || request.MediaPending != 1 {
+		t.Errorf("request %+v, want completed with 1 media pending", request)
+	}
+	if len(media.deleted) != 2 || repo.queue[1].LastError == nil || repo.queue[1].Attempts != 1 {
+		t.Errorf("deleted %v, queue %+v", media.deleted, repo.queue)
+	}
+
+	media.down = nil
+	request, err = svc.RetryErasureMedia(ctx, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.MediaPending != 0 || len(media.deleted) != 3 || media.deleted[2] != "http://media/media/selfie" {
+		t.Errorf("after retry: request %+v, deleted %v", request, media.deleted)
+	}
+}
+
+func (r *dataSubjectRepo) ExportDataSubject(_ context.Context, userID, actor string) (*domain.DataSubjectExport, error) {
+	return &domain.DataSubjectExport{UserID: userID, GeneratedBy: actor, Media: append([]domain.DataSubjectMedia(nil), r.media...)}, nil
+}
+
+func TestExportFetchesOnlyMediaStorage(t *testing.T) {
+	var internalHits atomic.Int32
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internalHits.Add(1)
+		w.Write([]byte("secret"))
+	}))
+	defer internal.Close()
+	store := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	}))
+	defer store.Close()
+
+	repo := &dataSubjectRepo{media: []domain.DataSubjectMedia{
+		{Table: "ekyc_sessions", Field: "selfie_with_id_url", URL: store.URL + "/media/selfie"},
+		{Table: "ekyc_sessions", Field: "id_card_url", URL: internal.URL + "/media/ktp"},
+	}}
+	svc := NewDataSubjectService(repo, media.NewClient(store.Client(), store.URL))
+	bundle, err := svc.Export(context.Background(), "0b6a4d4e-5f07-4e8c-9f53-3d1d2b9d6f11", "dpo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if internalHits.Load() != 0 {
+		t.Errorf("export fetched a URL outside media storage %d times", internalHits.Load())
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(bundle.Content), int64(len(bundle.Content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest domain.DataSubjectExport
+	files := 0
+	for _, file := range archive.File {
+		if file.Name != "export.json" {
+			files++
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+			t.Fatal(err)
+		}
+		r.Close()
+	}
+	if files != 1 {
+		t.Errorf("archive holds %d media files, want 1", files)
+	}
+	if len(manifest.Media) != 2 || manifest.Media[0].Path == "" || manifest.Media[0].Error != "" {
+		t.Fatalf("manifest media %+v", manifest.Media)
+	}
+	if outside := manifest.Media[1]; outside.Path != "" || outside.Error != domain.ErrMediaOutsideStorage.Error() {
+		t.Errorf("outside URL recorded as %+v, want error %q", outside, domain.ErrMediaOutsideStorage)
+	}
+}
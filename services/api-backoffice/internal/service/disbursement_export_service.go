@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// DisbursementExportService turns locked batches into bank files so finance no longer
+// types beneficiaries into the bank's portal. The file is rendered by the exporter
+// registered for the requested format from the batch's verified manifest and the
+// beneficiaries' payout destinations, stored in media storage with its checksum, and the
+// batch moves to SENT.
+type DisbursementExportService struct {
+	repo      domain.DisbursementExportRepository
+	media     domain.MediaClient
+	exporters map[string]domain.DisbursementExporter
+}
+
+var _ domain.DisbursementExportService = (*DisbursementExportService)(nil)
+
+func NewDisbursementExportService(repo domain.DisbursementExportRepository, media domain.MediaClient, exporters ...domain.DisbursementExporter) *DisbursementExportService {
+	registry := make(map[string]domain.DisbursementExporter, len(exporters))
+	for _, exporter := range exporters {
+		registry[exporter.Format()] = exporter
+	}
+	return &DisbursementExportService{repo: repo, media: media, exporters: registry}
+}
+
+func (s *DisbursementExportService) Export(ctx context.Context, batchID, format, actor string) (*domain.BatchExport, error) {
+	actor = strings.TrimSpace(actor)
+	if actor == "" {
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	}
+	exporter, ok := s.exporters[strings.ToLower(strings.TrimSpace(format))]
+	if !ok {
+		return nil, fmt.Errorf("%w: format %q tidak dikenal, pilih %s", domain.ErrInvalidState, format, strings.Join(s.formats(), ", "))
+	}
+	manifest, err := s.repo.GetBatchManifest(ctx, strings.TrimSpace(batchID))
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(domain.BatchExportStatuses, manifest.Status) {
+		return nil, fmt.Errorf("%w: batch %s berstatus %s, ekspor hanya dari %s", domain.ErrInvalidState,
+			manifest.Code, manifest.Status, strings.Join(domain.BatchExportStatuses, " atau "))
+	}
+	if manifest.LockedAt == nil || manifest.Checksum == nil {
+		return nil, fmt.Errorf("%w: batch %s dikunci tanpa manifes", domain.ErrInvalidState, manifest.Code)
+	}
+	// The bank file must pay exactly what was signed off.
+	if batchManifestChecksum(manifest.Code, manifest.Items) != *manifest.Checksum {
+		return nil, fmt.Errorf("%w: manifes batch %s tidak cocok dengan checksum-nya", domain.ErrInvalidState, manifest.Code)
+	}
+	cfg, err := loadDisbursementConfig(ctx, s.repo)
+	if err != nil {
+		return nil, err
+	}
+	payees, err := s.repo.ListDisbursementPayees(ctx, manifest.BatchID)
+	if err != nil {
+		return nil, err
+	}
+	file, err := disbursementFile(manifest, payees, cfg, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	content, err := exporter.Render(file)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(content)
+	export := &domain.BatchExport{
+		BatchID:          manifest.BatchID,
+		Format:           exporter.Format(),
+		Filename:         exportFilename(manifest.Code, exporter),
+		Checksum:         hex.EncodeToString(sum[:]),
+		ManifestChecksum: *manifest.Checksum,
+		Items:            len(file.Lines),
+		TotalAmount:      file.Total,
+		Currency:         file.Currency,
+		CreatedBy:        actor,
+	}
+	export.URL, err = s.media.Upload(ctx, export.Filename, exporter.ContentType(), content)
+	if err != nil {
+		return nil, err
+	}
+	audit := auditEntry(actor, manifest.BatchID, fmt.Sprintf("BATCH:%s", domain.BatchStatusSent), "", map[string]any{
+		"from":             manifest.Status,
+		"format":           export.Format,
+		"filename":         export.Filename,
+		"checksum":         export.Checksum,
+		"manifestChecksum": export.ManifestChecksum,
+		"items":            export.Items,
+		"totalAmount":      export.TotalAmount,
+		"currency":         export.Currency,
+	})
+	err = s.repo.RecordBatchExport(ctx, domain.RecordBatchExportParams{Export: export, From: manifest.Status, Audit: audit})
+	if err != nil {
+		if delErr := s.media.Delete(ctx, export.URL); delErr != nil {
+			log.Printf("api-backoffice: remove unrecorded batch export %s: %v", export.URL, delErr)
+		}
+		return nil, err
+	}
+	return export, nil
+}
+
+func (s *DisbursementExportService) List(ctx context.Context, batchID string) ([]domain.BatchExport, error) {
+	return s.repo.ListBatchExports(ctx, strings.TrimSpace(batchID))
+}
+
+// SetPayout records where a beneficiary is paid. Bank transfers need a bank (BIC or
+// local clearing code) and an account; other channels may carry a reference instead.
+func (s *DisbursementExportService) SetPayout(ctx context.Context, payout domain.BeneficiaryPayout, actor string) error {
+	id, err := uuid.Parse(strings.TrimSpace(payout.UserID))
+	if err != nil {
+		return fmt.Errorf("%w: penerima %q", domain.ErrNotFound, payout.UserID)
+	}
+	payout.UserID = id.String()
+	payout.Channel = strings.ToUpper(strings.TrimSpace(payout.Channel))
+	payout.Bank = strings.ToUpper(strings.TrimSpace(payout.Bank))
+	payout.Account = strings.Join(strings.Fields(payout.Account), "")
+	actor = strings.TrimSpace(actor)
+	switch {
+	case actor == "":
+		return fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	case payout.Channel == "" || strings.Trim(payout.Channel, "ABCDEFGHIJKLMNOPQRSTUVWXYZ_") != "":
+		return fmt.Errorf("%w: channel wajib diisi, mis. %s", domain.ErrInvalidState, domain.PayoutChannelBankTransfer)
+	case payout.Bank != "" && !domain.IsBIC(payout.Bank) && !isDigits(payout.Bank, 3, 11):
+		return fmt.Errorf("%w: bank harus BIC atau kode bank", domain.ErrInvalidState)
+	case payout.Account != "" && !isAlphanumeric(payout.Account, 4, 34):
+		return fmt.Errorf("%w: nomor rekening harus 4-34 huruf atau angka", domain.ErrInvalidState)
+	case payout.Channel == domain.PayoutChannelBankTransfer && (payout.Bank == "" || payout.Account == ""):
+		return fmt.Errorf("%w: transfer bank butuh bank dan nomor rekening", domain.ErrInvalidState)
+	}
+	audit := auditEntry(actor, payout.UserID, "BENEFICIARY:PAYOUT_UPDATED", "", map[string]any{
+		"channel": payout.Channel,
+		"bank":    payout.Bank,
+		"account": maskAccount(payout.Account),
+	})
+	return s.repo.SetBeneficiaryPayout(ctx, domain.SetBeneficiaryPayoutParams{Payout: payout, Audit: audit})
+}
+
+func (s *DisbursementExportService) formats() []string {
+	formats := make([]string, 0, len(s.exporters))
+	for format := range s.exporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// disbursementFile pairs each manifest item with its payee. Items that cannot be paid
+// are reported together.
+func disbursementFile(manifest *domain.BatchManifest, payees map[string]domain.DisbursementPayee, cfg domain.DisbursementConfig, now time.Time) (domain.DisbursementFile, error) {
+	file := domain.DisbursementFile{
+		BatchID:          manifest.BatchID,
+		BatchCode:        manifest.Code,
+		ManifestChecksum: *manifest.Checksum,
+		CreatedAt:        now,
+		Config:           cfg,
+	}
+	var problems []string
+	for _, item := range manifest.Items {
+		payee := payees[item.ApplicationID]
+		switch {
+		case payee.Channel == "":
+			problems = append(problems, item.ApplicationID+" (tujuan pembayaran belum diatur)")
+			continue
+		case payee.Channel == domain.PayoutChannelBankTransfer && (payee.Bank == "" || payee.Account == ""):
+			problems = append(problems, item.ApplicationID+" (rekening belum diatur)")
+			continue
+		case file.Currency != "" && item.Currency != file.Currency:
+			problems = append(problems, fmt.Sprintf("%s (mata uang %s, batch %s)", item.ApplicationID, item.Currency, file.Currency))
+			continue
+		}
+		reference := payee.Account
+		if reference == "" {
+			reference = item.ApplicationID
+		}
+		file.Currency = item.Currency
+		file.Total += item.Amount
+		file.Lines = append(file.Lines, domain.DisbursementLine{
+			ApplicationID: item.ApplicationID,
+			Name:          payee.Name,
+			NikMask:       item.NikMask,
+			Program:       item.Program,
+			Amount:        item.Amount,
+			Currency:      item.Currency,
+			Channel:       payee.Channel,
+			Bank:          payee.Bank,
+			Account:       payee.Account,
+			Reference:     reference,
+			Remittance:    fmt.Sprintf("BANSOS %s %s", item.Program, manifest.Code),
+		})
+	}
+	if len(problems) > 0 {
+		return domain.DisbursementFile{}, fmt.Errorf("%w: batch %s belum bisa dibayar: %s", domain.ErrInvalidState,
+			manifest.Code, strings.Join(problems, ", "))
+	}
+	if len(file.Lines) == 0 {
+		return domain.DisbursementFile{}, fmt.Errorf("%w: batch %s tidak punya item", domain.ErrInvalidState, manifest.Code)
+	}
+	return file, nil
+}
+
+// exportFilename names the file after the batch code, kept to characters that are safe
+// in a file name.
+func exportFilename(code string, exporter domain.DisbursementExporter) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, code)
+	format := strings.ReplaceAll(exporter.Format(), ".", "")
+	return fmt.Sprintf("%s-%s.%s", safe, format, exporter.Extension())
+}
+
+// maskAccount keeps the last four characters, enough to recognise an account in the audit log.
+func maskAccount(account string) string {
+	if len(account) <= 4 {
+		return strings.Repeat("*", len(account))
+	}
+	return strings.Repeat("*", len(account)-4) + account[len(account)-4:]
+}
+
+func isDigits(s string, minLen, maxLen int) bool {
+	if len(s) < minLen || len(s) > maxLen {
+		return false
+	}
+	return strings.Trim(s, "0123456789") == ""
+}
+
+func isAlphanumeric(s string, minLen, maxLen int) bool {
+	if len(s) < minLen || len(s) > maxLen {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
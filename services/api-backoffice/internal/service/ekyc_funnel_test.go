@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// funnelRepo returns a fixed report and keeps the params it was asked for.
+type funnelRepo struct {
+	domain.BackofficeRepository
+	params domain.EkycFunnelParams
+	report domain.EkycFunnelReport
+}
+
+func (r *funnelRepo) EkycFunnel(_ context.Context, params domain.EkycFunnelParams) (*domain.EkycFunnelReport, error) {
+	r.params = params
+	report := r.report
+	report.Buckets = append([]domain.EkycFunnelBucket(nil), r.report.Buckets...)
+	report.Gestures = append([]domain.GesturePassRate(nil), r.report.Gestures...)
+	return &report, nil
+}
+
+func TestEkycFunnelDefaults(t *testing.T) {
+	repo := &funnelRepo{}
+	svc := NewBackofficeService(repo, nil)
+	before := time.Now()
+	if _, err := svc.EkycFunnel(context.Background(), domain.EkycFunnelParams{RegionLevel: " KAB ", Region: " 3171 "}); err != nil {
+		t.Fatal(err)
+	}
+	got := repo.params
+	if got.To.Before(before) || got.To.Sub(got.From) != domain.DefaultFunnelWindow {
+		t.Errorf("window %s to %s, want the last %s", got.From, got.To, domain.DefaultFunnelWindow)
+	}
+	if got.Granularity != "day" || got.RegionLevel != "kab" || got.Region != "3171" {
+		t.Errorf("params %+v", got)
+	}
+}
+
+func TestEkycFunnelRejectsInvalidParams(t *testing.T) {
+	now := time.Now()
+	tests := map[string]domain.EkycFunnelParams{
+		"from after to":         {From: now, To: now.Add(-time.Hour)},
+		"empty window":          {From: now, To: now},
+		"unknown granularity":   {Granularity: "month"},
+		"unknown region level":  {RegionLevel: "desa"},
+		"region without level":  {Region: "31"},
+		"sql in region level":   {RegionLevel: "prov; DROP TABLE users"},
+		"granularity uppercase": {Granularity: "HOUR"},
+	}
+	for name, params := range tests {
+		t.Run(name, func(t *testing.T) {
+			repo := &funnelRepo{}
+			_, err := NewBackofficeService(repo, nil).EkycFunnel(context.Background(), params)
+			if !errors.Is(err, domain.ErrInvalidState) {
+				t.Fatalf("err = %v, want ErrInvalidState", err)
+			}
+			if !repo.params.To.IsZero() {
+				t.Error("invalid params reached the repository")
+			}
+		})
+	}
+}
+
+func TestEkycFunnelRates(t *testing.T) {
+	repo := &funnelRepo{report: domain.EkycFunnelReport{
+		Totals: domain.EkycFunnelBucket{FaceChecked: 4, FacePassed: 3, LivenessChecked: 4, LivenessPassed: 2, Decided: 4, Approved: 1},
+		Buckets: []domain.EkycFunnelBucket{
+			{FaceChecked: 2, FacePassed: 2, Decided: 0},
+			{},
+		},
+		Gestures: []domain.GesturePassRate{{Gesture: "BLINK", Attempts: 4, Passed: 3}, {Gesture: "NOD", Attempts: 0}},
+	}}
+	report, err := NewBackofficeService(repo, nil).EkycFunnel(context.Background(), domain.EkycFunnelParams{Granularity: "week"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRate(t, "totals face", report.Totals.FacePassRate, 0.75)
+	checkRate(t, "totals liveness", report.Totals.LivenessPassRate, 0.5)
+	checkRate(t, "totals approval", report.Totals.ApprovalRate, 0.25)
+	checkRate(t, "bucket face", report.Buckets[0].FacePassRate, 1)
+	if report.Buckets[0].ApprovalRate != nil || report.Buckets[1].FacePassRate != nil || report.Buckets[1].LivenessPassRate != nil {
+		t.Errorf("rates without a denominator must be nil: %+v", report.Buckets)
+	}
+	if report.Gestures[0].PassRate != 0.75 || report.Gestures[1].PassRate != 0 {
+		t.Errorf("gesture rates %+v", report.Gestures)
+	}
+}
+
+func checkRate(t *testing.T, name string, got *float64, want float64) {
+	t.Helper()
+	if got == nil || *got != want {
+		t.Errorf("%s rate = %v, want %v", name, got, want)
+	}
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// ListReviewQueue returns a page of undecided sessions ranked by their review signals.
+// The repository ranks and pages the queue, each item is explained here with the same
+// weights. Sessions without any signal are left to FinalizeSession.
+func (s *EkycService) ListReviewQueue(ctx context.Context, params domain.ListReviewQueueParams) ([]domain.ReviewQueueItem, error) {
+	limit := params.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	faceThreshold := s.faceThreshold(ctx)
+	candidates, err := s.repo.ListReviewCandidates(ctx, domain.ListReviewCandidatesParams{
+		FaceThreshold:  faceThreshold,
+		Reviewer:       params.Reviewer,
+		IncludeClaimed: params.IncludeClaimed,
+		Limit:          limit,
+		Offset:         max(params.Offset, 0),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]domain.ReviewQueueItem, 0, len(candidates))
+	for i := range candidates {
+		items = append(items, rankReviewCandidate(&candidates[i], faceThreshold))
+	}
+	return items, nil
+}
+
+func (s *EkycService) GetReviewCase(ctx context.Context, sessionID string) (*domain.ReviewCase, error) {
+	candidate, err := s.repo.GetReviewCandidate(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	verdicts, err := s.repo.ListReviewVerdicts(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	session := &candidate.Session
+	out := &domain.ReviewCase{
+		ReviewQueueItem: rankReviewCandidate(candidate, s.faceThreshold(ctx)),
+		Artifacts: domain.ReviewArtifacts{
+			IDCardURL:        session.IDCardURL,
+			SelfieWithIDURL:  session.SelfieWithIDURL,
+			RecordedVideoURL: session.RecordedVideoURL,
+		},
+		Flags:    candidate.Flags,
+		Verdicts: verdicts,
+	}
+	if ocr, err := s.repo.GetOcrResult(ctx, sessionID); err == nil {
+		out.Ocr = ocr
+	}
+	return out, nil
+}
+
+// ClaimReview reserves a session for the reviewer for DefaultReviewClaimTTL. Claiming
+// again renews the claim.
+func (s *EkycService) ClaimReview(ctx context.Context, params domain.ReviewClaimParams) (*domain.ReviewClaim, error) {
+	if !roleAllowed(params.ReviewerRole, domain.EkycReviewRoles) {
+		return nil, fmt.Errorf("%w: peran %s tidak boleh meninjau eKYC", domain.ErrForbidden, params.ReviewerRole)
+	}
+	session, err := s.repo.GetEkycSession(ctx, params.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(session.FinalDecision, "PENDING") {
+		return nil, fmt.Errorf("%w: sesi sudah diputuskan %s", domain.ErrInvalidState, session.FinalDecision)
+	}
+	return s.repo.ClaimReviewSession(ctx, params.SessionID, params.Reviewer, domain.DefaultReviewClaimTTL)
+}
+
+func (s *EkycService) ReleaseReview(ctx context.Context, params domain.ReviewClaimParams) error {
+	if !roleAllowed(params.ReviewerRole, domain.EkycReviewRoles) {
+		return fmt.Errorf("%w: peran %s tidak boleh meninjau eKYC", domain.ErrForbidden, params.ReviewerRole)
+	}
+	return s.repo.ReleaseReviewClaim(ctx, params.SessionID, params.Reviewer)
+}
+
+// SubmitReviewVerdict records the reviewer's approve/reject decision on a claimed session.
+// The repository decides the session, closes the claim and stores the verdict with its
+// signals, timeline and audit entries in one transaction, so a session decided in the
+// meantime or a lapsed claim leaves nothing behind.
+func (s *EkycService) SubmitReviewVerdict(ctx context.Context, params domain.ReviewVerdictParams) (*domain.EkycSession, error) {
+	if !roleAllowed(params.ReviewerRole, domain.EkycReviewRoles) {
+		return nil, fmt.Errorf("%w: peran %s tidak boleh meninjau eKYC", domain.ErrForbidden, params.ReviewerRole)
+	}
+	params.Verdict = strings.ToUpper(strings.TrimSpace(params.Verdict))
+	if params.Verdict != "APPROVED" && params.Verdict != "REJECTED" {
+		return nil, fmt.Errorf("%w: keputusan harus APPROVED atau REJECTED", domain.ErrInvalidState)
+	}
+	params.Reason = strings.TrimSpace(params.Reason)
+	if params.Reason == "" {
+		return nil, fmt.Errorf("%w: alasan keputusan wajib diisi", domain.ErrInvalidState)
+	}
+
+	candidate, err := s.repo.GetReviewCandidate(ctx, params.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(candidate.Session.FinalDecision, "PENDING") {
+		return nil, fmt.Errorf("%w: sesi sudah diputuskan %s", domain.ErrInvalidState, candidate.Session.FinalDecision)
+	}
+	if candidate.Claim == nil {
+		return nil, fmt.Errorf("%w: klaim sesi terlebih dahulu", domain.ErrInvalidState)
+	}
+	if candidate.Claim.Reviewer != params.Reviewer {
+		return nil, domain.ErrReviewClaimed
+	}
+	params.Signals = rankReviewCandidate(candidate, s.faceThreshold(ctx)).Signals
+
+	codes := make([]string, 0, len(params.Signals))
+	for _, signal := range params.Signals {
+		codes = append(codes, signal.Code)
+	}
+	meta := map[string]any{
+		"verdict":      params.Verdict,
+		"reviewerRole": params.ReviewerRole,
+		"signals":      codes,
+	}
+	params.Timeline = timelineEntry(params.SessionID, params.Reviewer, "EKYC:REVIEW_VERDICT", params.Reason, meta)
+	params.Audit = auditEntry(params.Reviewer, params.SessionID, "EKYC:REVIEW_VERDICT", params.Reason, map[string]any{
+		"verdict":      params.Verdict,
+		"reviewerRole": params.ReviewerRole,
+		"signals":      codes,
+	})
+
+	return s.repo.RecordReviewVerdict(ctx, params)
+}
+
+// rankReviewCandidate collects the review signals of a session and sums their weights.
+func rankReviewCandidate(candidate *domain.ReviewCandidate, faceThreshold float64) domain.ReviewQueueItem {
+	session := &candidate.Session
+	var signals []domain.ReviewSignal
+	add := func(code string, weight int, detail string) {
+		signals = append(signals, domain.ReviewSignal{Code: code, Weight: weight, Detail: detail})
+	}
+
+	if strings.EqualFold(session.Status, "MANUAL_REVIEW") {
+		add(domain.ReviewSignalLocked, domain.ReviewWeightLocked, "percobaan verifikasi habis")
+	}
+	if session.FaceMatchOverall != nil && session.LivenessOverall != nil {
+		face := strings.ToUpper(*session.FaceMatchOverall)
+		live := strings.ToUpper(*session.LivenessOverall)
+		if face != live {
+			add(domain.ReviewSignalResultConflict, domain.ReviewWeightResultConflict,
+				fmt.Sprintf("face match %s, liveness %s", face, live))
+		}
+	}
+	for _, check := range session.FaceChecks {
+		if check.Similarity == nil {
+			continue
+		}
+		threshold := faceThreshold
+		if check.Threshold != nil && *check.Threshold > 0 {
+			threshold = *check.Threshold
+		}
+		if *check.Similarity < threshold && *check.Similarity >= threshold-domain.DefaultReviewFaceMargin {
+			add(domain.ReviewSignalFaceBorderline, domain.ReviewWeightFaceBorderline,
+				fmt.Sprintf("%s similarity %.3f, ambang %.2f", check.Step, *check.Similarity, threshold))
+			break
+		}
+	}
+	if n := flagCount(candidate.Flags, "nikMismatches"); n > 0 {
+		add(domain.ReviewSignalNikMismatch, domain.ReviewWeightNikMismatch, fmt.Sprintf("%d data tidak cocok dengan NIK", n))
+	}
+	if n := flagCount(candidate.Flags, "ocrDiscrepancies"); n > 0 {
+		add(domain.ReviewSignalOcrDiscrepancy, domain.ReviewWeightOcrDiscrepancy, fmt.Sprintf("%d field OCR berbeda", n))
+	}
+	if attempts := max(session.FaceAttempts, session.LivenessAttempts); attempts > 1 {
+		add(domain.ReviewSignalRepeatedAttempts, domain.ReviewWeightRepeatedAttempts*(attempts-1),
+			fmt.Sprintf("%d percobaan", attempts))
+	}
+
+	item := domain.ReviewQueueItem{Session: session, Signals: signals, Claim: candidate.Claim}
+	if item.Signals == nil {
+		item.Signals = []domain.ReviewSignal{}
+	}
+	for _, signal := range signals {
+		item.Score += signal.Weight
+	}
+	return item
+}
+
+func flagCount(flags map[string]any, key string) int {
+	if values, ok := flags[key].([]any); ok {
+		return len(values)
+	}
+	return 0
+}
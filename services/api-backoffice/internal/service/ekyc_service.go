@@ -2,20 +2,27 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
 	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/nik"
 )
 
 type EkycService struct {
-	repo domain.EkycRepository
+	repo  domain.EkycRepository
+	ai    domain.AiSupportClient
+	media domain.MediaClient
 }
 
 var _ domain.EkycService = (*EkycService)(nil)
 
-func NewEkycService(repo domain.EkycRepository) *EkycService {
-	return &EkycService{repo: repo}
+// NewEkycService builds the eKYC use cases. ai and media may be nil, in which case
+// re-running verification from the backoffice is unavailable.
+func NewEkycService(repo domain.EkycRepository, ai domain.AiSupportClient, media domain.MediaClient) *EkycService {
+	return &EkycService{repo: repo, ai: ai, media: media}
 }
 
 func (s *EkycService) CreateSession(ctx context.Context, params domain.CreateEkycSessionParams) (*domain.EkycSession, error) {
@@ -27,10 +34,23 @@ func (s *EkycService) UpdateArtifacts(ctx context.Context, params domain.UpdateE
 }
 
 func (s *EkycService) RecordFaceChecks(ctx context.Context, params domain.SaveFaceChecksParams) (*domain.EkycSession, error) {
+	params.MaxAttempts = s.maxAttempts(ctx)
+	if params.JobID == "" {
+		for _, check := range params.Checks {
+			if id := jobIDFromMetadata(check.Metadata); id != "" {
+				params.JobID = id
+				break
+			}
+		}
+	}
 	return s.repo.SaveFaceChecks(ctx, params)
 }
 
 func (s *EkycService) RecordLiveness(ctx context.Context, params domain.SaveLivenessResultParams) (*domain.EkycSession, error) {
+	params.MaxAttempts = s.maxAttempts(ctx)
+	if params.JobID == "" {
+		params.JobID = jobIDFromMetadata(params.Metadata)
+	}
 	return s.repo.SaveLivenessResult(ctx, params)
 }
 
@@ -44,7 +64,56 @@ func (s *EkycService) AssignApplicant(ctx context.Context, params domain.Applica
 	if params.Phone == "" && params.Nik == "" {
 		return nil, fmt.Errorf("minimal salah satu dari nomor HP atau NIK wajib diisi")
 	}
-	return s.repo.AssignUserToSession(ctx, params)
+	if params.Nik != "" {
+		parsed, err := nik.Parse(params.Nik)
+		if err != nil {
+			return nil, fmt.Errorf("%w: NIK tidak valid (%v)", domain.ErrInvalidState, err)
+		}
+		params.Nik = parsed.Raw
+		params.Flags = nikFlags(parsed, params)
+	}
+	session, err := s.repo.AssignUserToSession(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	// OCR may have arrived before the applicant filled in the form; compare it now.
+	if existing, err := s.repo.GetOcrResult(ctx, params.SessionID); err == nil {
+		if _, err := s.RecordOcrResult(ctx, *existing); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	return session, nil
+}
+
+// RecordOcrResult stores the KTP extraction for a session and scores it against the
+// applicant's declared data using thresholds.ocr_min as the per-field match bar.
+func (s *EkycService) RecordOcrResult(ctx context.Context, result domain.OcrResult) (*domain.OcrResult, error) {
+	session, err := s.repo.GetEkycSession(ctx, result.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	minSimilarity := DefaultOcrMinSimilarity
+	if cfg, err := s.repo.GetConfig(ctx); err == nil && cfg != nil {
+		if v, ok := cfg.Thresholds["ocr_min"].(float64); ok && v > 0 {
+			minSimilarity = v
+		}
+	}
+	result.Fields, result.Score = compareOcr(result, declaredFromSession(session), minSimilarity)
+	return s.repo.SaveOcrResult(ctx, result)
+}
+
+func (s *EkycService) GetOcrResult(ctx context.Context, sessionID string) (*domain.OcrResult, error) {
+	return s.repo.GetOcrResult(ctx, sessionID)
+}
+
+// sessionEventPageSize bounds one read of the session event log; streams page through
+// longer backlogs.
+const sessionEventPageSize = 100
+
+func (s *EkycService) ListSessionEvents(ctx context.Context, sessionID string, afterID int64) ([]domain.EkycSessionEvent, error) {
+	return s.repo.ListEkycSessionEvents(ctx, sessionID, afterID, sessionEventPageSize)
 }
 
 func (s *EkycService) ListSessions(ctx context.Context, params domain.ListEkycSessionsParams) ([]domain.EkycSession, error) {
@@ -61,6 +130,9 @@ func (s *EkycService) FinalizeSession(ctx context.Context, id string) (*domain.E
 		return nil, err
 	}
 	_ = s.repo.EnsureApplicationFromSession(ctx, id)
+	if strings.EqualFold(session.Status, "MANUAL_REVIEW") {
+		return nil, domain.ErrSessionLocked
+	}
 	if !strings.EqualFold(session.FaceMatchingStatus, "DONE") || session.FaceMatchOverall == nil {
 		return nil, fmt.Errorf("face matching belum selesai")
 	}
@@ -92,6 +164,154 @@ func (s *EkycService) FinalizeSession(ctx context.Context, id string) (*domain.E
 	})
 }
 
-func (s *EkycService) OverrideDecision(ctx context.Context, params domain.UpdateEkycDecisionParams) (*domain.EkycSession, error) {
-	return s.repo.UpdateEkycDecision(ctx, params)
+// OverrideDecision lets an authorised reviewer replace the final decision. A reason code
+// from the managed list and a free-text explanation are both mandatory.
+func (s *EkycService) OverrideDecision(ctx context.Context, params domain.OverrideEkycDecisionParams) (*domain.EkycSession, error) {
+	if !roleAllowed(params.ActorRole, domain.DecisionOverrideRoles) {
+		return nil, fmt.Errorf("%w: peran %s tidak boleh mengubah keputusan eKYC", domain.ErrForbidden, params.ActorRole)
+	}
+	params.FinalDecision = strings.ToUpper(strings.TrimSpace(params.FinalDecision))
+	if params.FinalDecision != "APPROVED" && params.FinalDecision != "REJECTED" {
+		return nil, fmt.Errorf("%w: keputusan harus APPROVED atau REJECTED", domain.ErrInvalidState)
+	}
+	params.ReasonCode = strings.ToUpper(strings.TrimSpace(params.ReasonCode))
+	params.ReasonText = strings.TrimSpace(params.ReasonText)
+	if params.ReasonCode == "" || params.ReasonText == "" {
+		return nil, fmt.Errorf("%w: kode alasan dan keterangan wajib diisi", domain.ErrInvalidState)
+	}
+
+	meta := map[string]any{
+		"reasonCode":    params.ReasonCode,
+		"finalDecision": params.FinalDecision,
+		"actorRole":     params.ActorRole,
+	}
+	reason := fmt.Sprintf("%s: %s", params.ReasonCode, params.ReasonText)
+	params.Timeline = timelineEntry(params.SessionID, params.Actor, "EKYC:DECISION_OVERRIDDEN", reason, meta)
+	params.Audit = auditEntry(params.Actor, params.SessionID, "EKYC:DECISION_OVERRIDDEN", reason, map[string]any{
+		"reasonCode":    params.ReasonCode,
+		"finalDecision": params.FinalDecision,
+		"actorRole":     params.ActorRole,
+	})
+	return s.repo.OverrideEkycDecision(ctx, params)
+}
+
+func (s *EkycService) ListOverrideReasons(ctx context.Context) ([]domain.DecisionOverrideReason, error) {
+	return s.repo.ListDecisionOverrideReasons(ctx)
+}
+
+// RerunFaceMatch queues a fresh face-match job from the stored KTP and selfie. The
+// result comes back through RecordFaceChecks like any other attempt.
+func (s *EkycService) RerunFaceMatch(ctx context.Context, id string) (*domain.EkycRerunResult, error) {
+	if s.ai == nil || s.media == nil {
+		return nil, domain.ErrAiSupportUnavailable
+	}
+	session, err := s.repo.GetEkycSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(session.Status, "MANUAL_REVIEW") {
+		return nil, domain.ErrSessionLocked
+	}
+	if session.IDCardURL == nil || *session.IDCardURL == "" {
+		return nil, fmt.Errorf("%w: foto KTP belum diunggah", domain.ErrInvalidState)
+	}
+	if session.SelfieWithIDURL == nil || *session.SelfieWithIDURL == "" {
+		return nil, fmt.Errorf("%w: foto selfie belum diunggah", domain.ErrInvalidState)
+	}
+
+	ktp, err := s.media.Download(ctx, *session.IDCardURL)
+	if err != nil {
+		return nil, fmt.Errorf("unduh foto KTP: %w", err)
+	}
+	selfie, err := s.media.Download(ctx, *session.SelfieWithIDURL)
+	if err != nil {
+		return nil, fmt.Errorf("unduh foto selfie: %w", err)
+	}
+
+	job, err := s.ai.StartFaceMatchJob(ctx, domain.StartFaceMatchJobPayload{
+		SessionID:          id,
+		KtpImage:           *ktp,
+		SelfieImage:        *selfie,
+		FaceMatchThreshold: s.faceThreshold(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	queued := "QUEUED"
+	updated, err := s.repo.UpdateEkycSession(ctx, domain.UpdateEkycArtifactsParams{
+		SessionID:          id,
+		FaceMatchingStatus: &queued,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.EkycRerunResult{Session: updated, Job: job}, nil
+}
+
+// maxAttempts reads thresholds.ekyc_max_attempts, falling back to the default when unset.
+func (s *EkycService) maxAttempts(ctx context.Context) int {
+	cfg, err := s.repo.GetConfig(ctx)
+	if err != nil || cfg == nil {
+		return domain.DefaultEkycMaxAttempts
+	}
+	return intFromMap(cfg.Thresholds, "ekyc_max_attempts", domain.DefaultEkycMaxAttempts)
+}
+
+// faceThreshold reads thresholds.face_min, falling back to the default when unset.
+func (s *EkycService) faceThreshold(ctx context.Context) float64 {
+	if cfg, err := s.repo.GetConfig(ctx); err == nil && cfg != nil {
+		if v, ok := cfg.Thresholds["face_min"].(float64); ok && v > 0 {
+			return v
+		}
+	}
+	return domain.DefaultFaceMatchThreshold
+}
+
+// nikFlags cross-checks the submission against what the NIK encodes. Mismatches do not
+// block the applicant; they are stored on the application for the reviewer.
+func nikFlags(parsed nik.NIK, params domain.ApplicantSubmission) map[string]any {
+	mismatches := parsed.CrossCheck(nik.Claims{
+		BirthDate: params.BirthDate,
+		Province:  params.Region.Prov,
+		Regency:   params.Region.Kab,
+		District:  params.Region.Kec,
+	})
+	if mismatches == nil {
+		mismatches = []nik.Mismatch{}
+	}
+	return map[string]any{"nikMismatches": mismatches}
+}
+
+func roleAllowed(role string, allowed []string) bool {
+	for _, candidate := range allowed {
+		if strings.EqualFold(strings.TrimSpace(role), candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// jobIDFromMetadata returns the AI job id the workers put in rawMetadata.jobId.
+func jobIDFromMetadata(meta map[string]any) string {
+	if id, ok := meta["jobId"].(string); ok {
+		return strings.TrimSpace(id)
+	}
+	return ""
+}
+
+func intFromMap(values map[string]any, key string, fallback int) int {
+	switch v := values[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return int(n)
+		}
+	}
+	return fallback
 }
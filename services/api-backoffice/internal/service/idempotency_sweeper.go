@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// IdempotencySweeper periodically drops idempotency keys whose TTL has passed.
+type IdempotencySweeper struct {
+	repo     domain.IdempotencyRepository
+	interval time.Duration
+}
+
+func NewIdempotencySweeper(repo domain.IdempotencyRepository, interval time.Duration) *IdempotencySweeper {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &IdempotencySweeper{repo: repo, interval: interval}
+}
+
+// Run blocks until ctx is cancelled.
+func (s *IdempotencySweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.Sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *IdempotencySweeper) Sweep(ctx context.Context) {
+	removed, err := s.repo.DeleteExpired(ctx, time.Now().UTC())
+	if err != nil {
+		log.Printf("api-backoffice: sweep idempotency keys: %v", err)
+		return
+	}
+	if removed > 0 {
+		log.Printf("api-backoffice: swept %d expired idempotency keys", removed)
+	}
+}
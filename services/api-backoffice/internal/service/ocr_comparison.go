@@ -0,0 +1,195 @@
+package service
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/nik"
+)
+
+// DefaultOcrMinSimilarity applies when thresholds.ocr_min is not configured.
+const DefaultOcrMinSimilarity = 0.8
+
+// ocrFieldWeights decide how much each field contributes to ScoreOCR. Fields missing on
+// either side are left out and the remaining weights are renormalised.
+var ocrFieldWeights = map[string]float64{
+	"nik":       0.35,
+	"name":      0.30,
+	"birthDate": 0.20,
+	"address":   0.15,
+}
+
+// declaredApplicant is what the applicant typed in, read from the session metadata.
+type declaredApplicant struct {
+	Nik       string
+	Name      string
+	BirthDate *time.Time
+	Address   string
+}
+
+// declaredFromSession reads metadata.applicant written by AssignUserToSession.
+func declaredFromSession(session *domain.EkycSession) declaredApplicant {
+	var out declaredApplicant
+	applicant, ok := session.Metadata["applicant"].(map[string]any)
+	if !ok {
+		return out
+	}
+	out.Nik, _ = applicant["nik"].(string)
+	out.Name, _ = applicant["name"].(string)
+	out.Address, _ = applicant["address"].(string)
+	if raw, ok := applicant["birthDate"].(string); ok && raw != "" {
+		if parsed, err := time.Parse(time.RFC3339, raw); err == nil {
+			out.BirthDate = &parsed
+		}
+	}
+	return out
+}
+
+// compareOcr fuzzy-matches every extracted field against the declared value. It returns
+// the per-field comparison and the weighted score, or nil when nothing was comparable.
+func compareOcr(result domain.OcrResult, declared declaredApplicant, minSimilarity float64) ([]domain.OcrFieldComparison, *float64) {
+	var (
+		fields      []domain.OcrFieldComparison
+		weighted    float64
+		totalWeight float64
+	)
+	add := func(field, extracted, declaredValue string, similarity float64) {
+		comparison := domain.OcrFieldComparison{
+			Field:      field,
+			Extracted:  extracted,
+			Declared:   declaredValue,
+			Similarity: similarity,
+			Match:      similarity >= minSimilarity,
+		}
+		if c, ok := result.Confidence[field]; ok {
+			conf := c
+			comparison.Confidence = &conf
+		}
+		fields = append(fields, comparison)
+		weighted += ocrFieldWeights[field] * similarity
+		totalWeight += ocrFieldWeights[field]
+	}
+
+	if result.Nik != nil && *result.Nik != "" && declared.Nik != "" {
+		extracted := nik.Normalize(*result.Nik)
+		add("nik", extracted, declared.Nik, stringSimilarity(extracted, nik.Normalize(declared.Nik)))
+	}
+	if result.Name != nil && *result.Name != "" && declared.Name != "" {
+		add("name", *result.Name, declared.Name, nameSimilarity(*result.Name, declared.Name))
+	}
+	if result.BirthDate != nil && declared.BirthDate != nil {
+		extracted := result.BirthDate.Format("2006-01-02")
+		declaredValue := declared.BirthDate.UTC().Format("2006-01-02")
+		// Dates either agree or not; a one-digit edit is a different day.
+		similarity := 0.0
+		if extracted == declaredValue {
+			similarity = 1
+		}
+		add("birthDate", extracted, declaredValue, similarity)
+	}
+	if result.Address != nil && *result.Address != "" && declared.Address != "" {
+		add("address", *result.Address, declared.Address, addressSimilarity(*result.Address, declared.Address))
+	}
+
+	if totalWeight == 0 {
+		return fields, nil
+	}
+	score := weighted / totalWeight
+	return fields, &score
+}
+
+// nameSimilarity ignores case, punctuation, honorifics and word order.
+func nameSimilarity(a, b string) float64 {
+	ta, tb := nameTokens(a), nameTokens(b)
+	direct := stringSimilarity(strings.Join(ta, " "), strings.Join(tb, " "))
+	slices.Sort(ta)
+	slices.Sort(tb)
+	sorted := stringSimilarity(strings.Join(ta, " "), strings.Join(tb, " "))
+	return max(direct, sorted)
+}
+
+var nameHonorifics = map[string]struct{}{
+	"BPK": {}, "BAPAK": {}, "IBU": {}, "SDR": {}, "SDRI": {}, "NY": {}, "TN": {},
+	"H": {}, "HJ": {}, "DR": {}, "IR": {}, "ST": {}, "SE": {}, "SH": {}, "SPD": {}, "SKOM": {},
+}
+
+func nameTokens(value string) []string {
+	var tokens []string
+	for _, token := range strings.Fields(normalizeText(value)) {
+		if _, skip := nameHonorifics[token]; skip {
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
+
+// addressSimilarity takes the better of an edit-distance ratio and token overlap, since
+// OCR often drops or reorders RT/RW and kelurahan parts.
+func addressSimilarity(a, b string) float64 {
+	na, nb := normalizeText(a), normalizeText(b)
+	edit := stringSimilarity(na, nb)
+	setA := map[string]struct{}{}
+	for _, token := range strings.Fields(na) {
+		setA[token] = struct{}{}
+	}
+	setB := map[string]struct{}{}
+	for _, token := range strings.Fields(nb) {
+		setB[token] = struct{}{}
+	}
+	if len(setA) == 0 || len(setB) == 0 {
+		return edit
+	}
+	shared := 0
+	for token := range setA {
+		if _, ok := setB[token]; ok {
+			shared++
+		}
+	}
+	overlap := float64(shared) / float64(min(len(setA), len(setB)))
+	return max(edit, overlap)
+}
+
+// normalizeText upper-cases and replaces punctuation with spaces.
+func normalizeText(value string) string {
+	mapped := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return ' '
+	}, value)
+	return strings.Join(strings.Fields(mapped), " ")
+}
+
+// stringSimilarity is 1 - levenshtein/maxLen.
+func stringSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	distance := levenshtein(ra, rb)
+	return 1 - float64(distance)/float64(max(len(ra), len(rb)))
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
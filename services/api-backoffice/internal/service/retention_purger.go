@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// retentionPurgeBatch bounds one purge pass; a backlog drains over consecutive runs.
+const retentionPurgeBatch = 500
+
+// RetentionPurger periodically purges artifacts past their retention period.
+type RetentionPurger struct {
+	svc      domain.RetentionService
+	interval time.Duration
+}
+
+func NewRetentionPurger(svc domain.RetentionService, interval time.Duration) *RetentionPurger {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	return &RetentionPurger{svc: svc, interval: interval}
+}
+
+// Run blocks until ctx is cancelled.
+func (p *RetentionPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.Purge(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *RetentionPurger) Purge(ctx context.Context) {
+	report, err := p.svc.Purge(ctx, domain.RetentionPurgeActor, retentionPurgeBatch)
+	if err != nil {
+		log.Printf("api-backoffice: retention purge: %v", err)
+		return
+	}
+	for _, failure := range report.Failures {
+		log.Printf("api-backoffice: retention purge %s %s: %s", failure.SessionID, failure.Artifact, failure.Error)
+	}
+	if report.Purged > 0 || report.Held > 0 {
+		log.Printf("api-backoffice: retention purged %d artifacts, %d held", report.Purged, report.Held)
+	}
+}
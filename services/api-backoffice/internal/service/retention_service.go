@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+type RetentionService struct {
+	repo  domain.RetentionRepository
+	media domain.MediaClient
+}
+
+var _ domain.RetentionService = (*RetentionService)(nil)
+
+func NewRetentionService(repo domain.RetentionRepository, media domain.MediaClient) *RetentionService {
+	return &RetentionService{repo: repo, media: media}
+}
+
+// Report is the dry run of Purge: it lists what would be purged now without deleting.
+func (s *RetentionService) Report(ctx context.Context, limit int) (*domain.RetentionReport, error) {
+	return s.collect(ctx, limit, true)
+}
+
+// Purge deletes the media of every due artifact that is not under legal hold and
+// tombstones it. A failed artifact is reported and left for the next run.
+func (s *RetentionService) Purge(ctx context.Context, actor string, limit int) (*domain.RetentionReport, error) {
+	actor = strings.TrimSpace(actor)
+	if actor == "" {
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	}
+	report, err := s.collect(ctx, limit, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range report.Candidates {
+		if candidate.Held {
+			continue
+		}
+		url := candidate.URL
+		_, err := s.repo.TombstoneArtifact(ctx, candidate, actor, func(ctx context.Context) error {
+			return s.media.Delete(ctx, url)
+		})
+		if err != nil {
+			report.Failures = append(report.Failures, domain.RetentionFailure{
+				SessionID: candidate.SessionID,
+				Artifact:  candidate.Artifact,
+				Error:     err.Error(),
+			})
+			continue
+		}
+		report.Purged++
+	}
+	return report, nil
+}
+
+func (s *RetentionService) collect(ctx context.Context, limit int, dryRun bool) (*domain.RetentionReport, error) {
+	cfg, err := s.repo.GetConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	periods, err := retentionPeriods(cfg.Retention)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	// A purge only lists what it may delete, so held artifacts cannot use up its limit.
+	candidates, err := s.repo.ListRetentionCandidates(ctx, periods, now, limit, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	held, err := s.repo.CountHeldRetentionCandidates(ctx, periods, now)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RetentionReport{
+		GeneratedAt: now,
+		DryRun:      dryRun,
+		Periods:     periods,
+		Candidates:  candidates,
+		Held:        held,
+		Failures:    []domain.RetentionFailure{},
+	}, nil
+}
+
+// retentionPeriods reads the per-artifact periods in days out of SystemConfig.Retention.
+// Unknown keys and negative or fractional values are rejected so a typo in the config
+// cannot silently disable, or shorten, a period.
+func retentionPeriods(raw map[string]any) (map[string]int, error) {
+	known := make(map[string]string, len(domain.RetentionArtifacts))
+	for _, artifact := range domain.RetentionArtifacts {
+		known[domain.RetentionPeriodKey(artifact)] = artifact
+	}
+	periods := make(map[string]int, len(domain.RetentionArtifacts))
+	for key, value := range raw {
+		artifact, ok := known[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: retensi %q tidak dikenal", domain.ErrInvalidState, key)
+		}
+		days, ok := value.(float64)
+		if !ok || days < 0 || days != math.Trunc(days) {
+			return nil, fmt.Errorf("%w: retensi %q harus bilangan bulat hari >= 0", domain.ErrInvalidState, key)
+		}
+		periods[artifact] = int(days)
+	}
+	return periods, nil
+}
+
+func (s *RetentionService) ListTombstones(ctx context.Context, sessionID string, limit int) ([]domain.ArtifactTombstone, error) {
+	return s.repo.ListArtifactTombstones(ctx, strings.TrimSpace(sessionID), limit)
+}
+
+func (s *RetentionService) ListLegalHolds(ctx context.Context, activeOnly bool) ([]domain.LegalHold, error) {
+	return s.repo.ListLegalHolds(ctx, activeOnly)
+}
+
+func (s *RetentionService) PlaceLegalHold(ctx context.Context, params domain.PlaceLegalHoldParams) (*domain.LegalHold, error) {
+	params.Scope = strings.ToUpper(strings.TrimSpace(params.Scope))
+	if params.Scope == "" {
+		params.Scope = domain.LegalHoldScopeSession
+	}
+	if params.Scope != domain.LegalHoldScopeSession && params.Scope != domain.LegalHoldScopeApplication {
+		return nil, fmt.Errorf("%w: scope harus %s atau %s", domain.ErrInvalidState,
+			domain.LegalHoldScopeSession, domain.LegalHoldScopeApplication)
+	}
+	params.TargetID = strings.TrimSpace(params.TargetID)
+	params.Reason = strings.TrimSpace(params.Reason)
+	params.Actor = strings.TrimSpace(params.Actor)
+	switch {
+	case params.TargetID == "":
+		return nil, fmt.Errorf("%w: targetId wajib diisi", domain.ErrInvalidState)
+	case params.Reason == "":
+		return nil, fmt.Errorf("%w: alasan wajib diisi", domain.ErrInvalidState)
+	case params.Actor == "":
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	}
+	return s.repo.PlaceLegalHold(ctx, params)
+}
+
+func (s *RetentionService) ReleaseLegalHold(ctx context.Context, params domain.ReleaseLegalHoldParams) (*domain.LegalHold, error) {
+	params.Actor = strings.TrimSpace(params.Actor)
+	if params.Actor == "" {
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	}
+	return s.repo.ReleaseLegalHold(ctx, params)
+}
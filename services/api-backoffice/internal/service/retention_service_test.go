@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// retentionRepo serves due artifacts the way the repository pages them: purgeable ones
+// first, held ones only when asked for.
+type retentionRepo struct {
+	domain.RetentionRepository
+	due        []domain.RetentionCandidate
+	tombstoned []string
+}
+
+func (r *retentionRepo) GetConfig(context.Context) (*domain.SystemConfig, error) {
+	return &domain.SystemConfig{Retention: map[string]any{domain.RetentionPeriodKey(domain.ArtifactIDCard): float64(30)}}, nil
+}
+
+func (r *retentionRepo) ListRetentionCandidates(_ context.Context, _ map[string]int, _ time.Time, limit int, includeHeld bool) ([]domain.RetentionCandidate, error) {
+	var listed []domain.RetentionCandidate
+	for _, held := range []bool{false, true} {
+		for _, candidate := range r.due {
+			if candidate.Held == held && (includeHeld || !held) && len(listed) < limit {
+				listed = append(listed, candidate)
+			}
+		}
+	}
+	return listed, nil
+}
+
+func (r *retentionRepo) CountHeldRetentionCandidates(context.Context, map[string]int, time.Time) (int, error) {
+	held := 0
+	for _, candidate := range r.due {
+		if candidate.Held {
+			held++
+		}
+	}
+	return held, nil
+}
+
+func (r *retentionRepo) TombstoneArtifact(ctx context.Context, candidate domain.RetentionCandidate, _ string, deleteMedia func(context.Context) error) (*domain.ArtifactTombstone, error) {
+	if err := deleteMedia(ctx); err != nil {
+		return nil, err
+	}
+	r.tombstoned = append(r.tombstoned, candidate.SessionID)
+	return &domain.ArtifactTombstone{SessionID: candidate.SessionID}, nil
+}
+
+type nopMedia struct{ domain.MediaClient }
+
+func (nopMedia) Delete(context.Context, string) error { return nil }
+
+func TestRetentionPurgeLimitSkipsHeldArtifacts(t *testing.T) {
+	repo := &retentionRepo{due: []domain.RetentionCandidate{
+		{SessionID: "held-1", Artifact: domain.ArtifactIDCard, Held: true},
+		{SessionID: "held-2", Artifact: domain.ArtifactIDCard, Held: true},
+		{SessionID: "free-1", Artifact: domain.ArtifactIDCard},
+		{SessionID: "free-2", Artifact: domain.ArtifactIDCard},
+	}}
+	svc := NewRetentionService(repo, nopMedia{})
+
+	report, err := svc.Purge(context.Background(), "ops", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Purged != 2 || report.Held != 2 || len(repo.tombstoned) != 2 {
+		t.Errorf("purged %d (%v), held %d, want both free artifacts purged and 2 held", report.Purged, repo.tombstoned, report.Held)
+	}
+
+	dry, err := svc.Report(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dry.Candidates) != 4 || !dry.Candidates[3].Held || dry.Held != 2 {
+		t.Errorf("dry run listed %+v, held %d, want held artifacts listed last", dry.Candidates, dry.Held)
+	}
+}
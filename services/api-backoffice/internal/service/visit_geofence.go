@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/geo"
+)
+
+const defaultGeofenceMaxDistance = 2000.0 // metres
+
+type configGetter interface {
+	GetConfig(ctx context.Context) (*domain.SystemConfig, error)
+}
+
+// VisitGeofence checks submitted visit geotags against the kelurahan boundary dataset.
+// A geotag is flagged when it lies outside the beneficiary's kelurahan or farther than
+// the configured distance from its centroid. Registered addresses are only known down to
+// the kelurahan, so the centroid stands in for the household.
+type VisitGeofence struct {
+	boundaries *geo.Boundaries
+	repo       configGetter
+}
+
+func NewVisitGeofence(boundaries *geo.Boundaries, repo configGetter) *VisitGeofence {
+	return &VisitGeofence{boundaries: boundaries, repo: repo}
+}
+
+// Check returns the geofence check with the configured maximum distance. A nil
+// VisitGeofence checks nothing.
+func (g *VisitGeofence) Check(ctx context.Context) (domain.GeofenceCheck, error) {
+	if g == nil {
+		return nil, nil
+	}
+	maxDistance := defaultGeofenceMaxDistance
+	cfg, err := g.repo.GetConfig(ctx)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return nil, err
+	}
+	if cfg != nil {
+		if v, ok := cfg.Thresholds[domain.ThresholdGeofenceMaxDistance].(float64); ok && v > 0 {
+			maxDistance = v
+		}
+	}
+	return func(lat, lng *float64, region domain.Region) domain.VisitGeofence {
+		return evaluateGeofence(g.boundaries, maxDistance, lat, lng, region)
+	}, nil
+}
+
+func evaluateGeofence(boundaries *geo.Boundaries, maxDistance float64, lat, lng *float64, region domain.Region) domain.VisitGeofence {
+	result := domain.VisitGeofence{Flags: []string{}, CheckedAt: time.Now().UTC()}
+	if lat == nil || lng == nil {
+		result.Flags = append(result.Flags, domain.GeofenceNoGeotag)
+		return result
+	}
+	pt := geo.Point{Lng: *lng, Lat: *lat}
+	if found, ok := boundaries.Locate(pt); ok {
+		result.Kelurahan = found.Kel
+	}
+	home, ok := boundaries.Lookup(region.Kab, region.Kec, region.Kel)
+	if !ok {
+		result.Flags = append(result.Flags, domain.GeofenceUnknownKelurahan)
+		return result
+	}
+	// Kelurahan known only by centroid are checked on distance alone.
+	if len(home.Area) > 0 && !home.Area.Contains(pt) {
+		result.Flags = append(result.Flags, domain.GeofenceOutsideKelurahan)
+	}
+	distance := math.Round(geo.DistanceMeters(home.Centroid, pt))
+	result.DistanceMeters = &distance
+	if distance > maxDistance {
+		result.Flags = append(result.Flags, domain.GeofenceTooFar)
+	}
+	return result
+}
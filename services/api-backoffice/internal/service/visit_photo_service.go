@@ -0,0 +1,214 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/jpeg"
+	"log"
+	"math"
+	"path"
+	"strings"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/exif"
+	"e-kyc/shared/geo"
+	"e-kyc/shared/imagehash"
+)
+
+const (
+	// maxVisitPhotoPixels keeps decoding for the perceptual hash within memory.
+	maxVisitPhotoPixels         = 50_000_000
+	defaultPhotoTimeTolerance   = 240 * time.Minute
+	defaultPhotoMaxDistance     = 300.0 // metres
+	defaultPhotoReuseDistance   = 6     // bits out of 64
+	maxPhotoReuseDistanceConfig = 16
+)
+
+// VisitPhotoService uploads visit photos to media storage and verifies them. The EXIF
+// capture time is compared with the visit's scheduled start and the EXIF GPS position
+// with the visit geotag, and mismatches, like stripped EXIF, are flagged on the photo.
+// A photo whose perceptual hash matches one already uploaded for any visit is rejected.
+// Blank, dark or otherwise flat photos hash alike, so they are flagged instead of matched.
+type VisitPhotoService struct {
+	repo  domain.VisitPhotoRepository
+	media domain.MediaClient
+}
+
+var _ domain.VisitPhotoService = (*VisitPhotoService)(nil)
+
+func NewVisitPhotoService(repo domain.VisitPhotoRepository, media domain.MediaClient) *VisitPhotoService {
+	return &VisitPhotoService{repo: repo, media: media}
+}
+
+type photoThresholds struct {
+	timeTolerance time.Duration
+	maxDistance   float64
+	reuseDistance int
+}
+
+func (s *VisitPhotoService) Upload(ctx context.Context, params domain.UploadVisitPhotoParams) (*domain.VisitPhoto, error) {
+	if strings.TrimSpace(params.Actor) == "" {
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	}
+	if len(params.Content) == 0 {
+		return nil, fmt.Errorf("%w: file foto kosong", domain.ErrInvalidState)
+	}
+	if len(params.Content) > domain.MaxVisitPhotoBytes {
+		return nil, fmt.Errorf("%w: foto maksimal %d MB", domain.ErrInvalidState, domain.MaxVisitPhotoBytes>>20)
+	}
+	schedule, err := loadVisitSchedule(ctx, s.repo)
+	if err != nil {
+		return nil, err
+	}
+	thresholds, err := s.thresholds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	photo, hash, data, err := inspectVisitPhoto(params.Content, schedule.Location)
+	if err != nil {
+		return nil, err
+	}
+	// Checked again when the photo is stored, this spares the upload of a re-used photo.
+	if hash != nil {
+		match, err := s.repo.FindSimilarVisitPhoto(ctx, *hash, thresholds.reuseDistance)
+		switch {
+		case err == nil:
+			return nil, &domain.PhotoReuseError{Match: *match}
+		case !errors.Is(err, domain.ErrNotFound):
+			return nil, err
+		}
+	}
+
+	filename := path.Base(strings.TrimSpace(params.Filename))
+	if filename == "." || filename == "/" || filename == "" {
+		filename = "visit-photo.jpg"
+	}
+	url, err := s.media.Upload(ctx, filename, "image/jpeg", params.Content)
+	if err != nil {
+		return nil, err
+	}
+	photo.VisitID = params.VisitID
+	photo.URL = url
+	stored, err := s.repo.AddVisitPhoto(ctx, domain.AddVisitPhotoParams{
+		ApplicationID: params.ApplicationID,
+		Actor:         params.Actor,
+		Photo:         photo,
+		Hash:          hash,
+		ReuseDistance: thresholds.reuseDistance,
+		Check: func(photo *domain.VisitPhoto, scheduledAt time.Time, lat, lng *float64) domain.VisitPhotoVerification {
+			result := verifyVisitPhoto(photo, data != nil, scheduledAt, lat, lng, thresholds, time.Now())
+			if hash == nil {
+				result.Flags = append(result.Flags, domain.PhotoLowDetail)
+			}
+			return result
+		},
+	})
+	if err != nil {
+		if delErr := s.media.Delete(ctx, url); delErr != nil {
+			log.Printf("api-backoffice: remove unstored visit photo %s: %v", url, delErr)
+		}
+		return nil, err
+	}
+	return stored, nil
+}
+
+func (s *VisitPhotoService) List(ctx context.Context, appID, visitID string) ([]domain.VisitPhoto, error) {
+	return s.repo.ListVisitPhotos(ctx, appID, visitID)
+}
+
+func (s *VisitPhotoService) thresholds(ctx context.Context) (photoThresholds, error) {
+	thresholds := photoThresholds{
+		timeTolerance: defaultPhotoTimeTolerance,
+		maxDistance:   defaultPhotoMaxDistance,
+		reuseDistance: defaultPhotoReuseDistance,
+	}
+	cfg, err := s.repo.GetConfig(ctx)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return photoThresholds{}, err
+	}
+	if cfg == nil {
+		return thresholds, nil
+	}
+	if v, ok := cfg.Thresholds[domain.ThresholdPhotoTimeTolerance].(float64); ok && v > 0 {
+		thresholds.timeTolerance = time.Duration(v * float64(time.Minute))
+	}
+	if v, ok := cfg.Thresholds[domain.ThresholdPhotoMaxDistance].(float64); ok && v > 0 {
+		thresholds.maxDistance = v
+	}
+	// Past a quarter of the bits different pictures start to match.
+	if v, ok := cfg.Thresholds[domain.ThresholdPhotoReuseDistance].(float64); ok && v >= 0 && v <= maxPhotoReuseDistanceConfig {
+		thresholds.reuseDistance = int(v)
+	}
+	return thresholds, nil
+}
+
+// inspectVisitPhoto hashes a JPEG and reads its EXIF. hash is nil when the picture is too
+// flat for its hash to tell it apart, and data is nil when the EXIF block is missing or
+// unreadable. EXIF times without an offset are read in loc.
+func inspectVisitPhoto(content []byte, loc *time.Location) (photo domain.VisitPhoto, hash *uint64, data *exif.Data, err error) {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return photo, nil, nil, fmt.Errorf("%w: foto harus berformat JPEG", domain.ErrInvalidState)
+	}
+	if cfg.Width*cfg.Height > maxVisitPhotoPixels {
+		return photo, nil, nil, fmt.Errorf("%w: resolusi foto terlalu besar (%dx%d)", domain.ErrInvalidState, cfg.Width, cfg.Height)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(content))
+	if err != nil {
+		return photo, nil, nil, fmt.Errorf("%w: foto JPEG rusak: %v", domain.ErrInvalidState, err)
+	}
+	if imagehash.Distinctive(img) {
+		h := imagehash.DHash(img)
+		hash = &h
+		photo.PerceptualHash = fmt.Sprintf("%016x", h)
+	}
+	sum := sha256.Sum256(content)
+	photo.SHA256 = hex.EncodeToString(sum[:])
+
+	data, err = exif.Decode(content)
+	if err != nil {
+		return photo, hash, nil, nil
+	}
+	if t, ok := data.CaptureTime(loc); ok {
+		t = t.UTC()
+		photo.CapturedAt = &t
+	}
+	photo.GpsLat, photo.GpsLng = data.Lat, data.Lng
+	photo.Camera = strings.TrimSpace(data.Make + " " + data.Model)
+	return photo, hash, data, nil
+}
+
+// verifyVisitPhoto compares the photo's EXIF with the visit. Without a visit geotag the
+// position is not compared.
+func verifyVisitPhoto(photo *domain.VisitPhoto, hasExif bool, scheduledAt time.Time, lat, lng *float64, thresholds photoThresholds, now time.Time) domain.VisitPhotoVerification {
+	result := domain.VisitPhotoVerification{Flags: []string{}}
+	if !hasExif {
+		result.Flags = append(result.Flags, domain.PhotoNoExif)
+		return result
+	}
+	if photo.CapturedAt == nil {
+		result.Flags = append(result.Flags, domain.PhotoNoCaptureTime)
+	} else {
+		delta := photo.CapturedAt.Sub(scheduledAt)
+		minutes := int(math.Round(delta.Minutes()))
+		result.TimeDeltaMinutes = &minutes
+		if delta.Abs() > thresholds.timeTolerance || photo.CapturedAt.After(now.Add(maxClientClockSkew)) {
+			result.Flags = append(result.Flags, domain.PhotoTimeMismatch)
+		}
+	}
+	if photo.GpsLat == nil || photo.GpsLng == nil {
+		result.Flags = append(result.Flags, domain.PhotoNoGPS)
+	} else if lat != nil && lng != nil {
+		distance := math.Round(geo.DistanceMeters(geo.Point{Lng: *lng, Lat: *lat}, geo.Point{Lng: *photo.GpsLng, Lat: *photo.GpsLat}))
+		result.DistanceMeters = &distance
+		if distance > thresholds.maxDistance {
+			result.Flags = append(result.Flags, domain.PhotoLocationMismatch)
+		}
+	}
+	return result
+}
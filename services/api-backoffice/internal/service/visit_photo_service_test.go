@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"slices"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/imagehash"
+)
+
+// photoRepo keeps the hashes of stored photos and matches them the way the repository
+// does, both before the upload and inside AddVisitPhoto.
+type photoRepo struct {
+	domain.VisitPhotoRepository
+	hashes []uint64
+	stored []domain.VisitPhoto
+}
+
+func (r *photoRepo) GetConfig(context.Context) (*domain.SystemConfig, error) {
+	return nil, domain.ErrNotFound
+}
+
+func (r *photoRepo) FindSimilarVisitPhoto(_ context.Context, hash uint64, maxDistance int) (*domain.VisitPhotoMatch, error) {
+	for i, stored := range r.hashes {
+		if d := imagehash.Distance(hash, stored); d <= maxDistance {
+			return &domain.VisitPhotoMatch{PhotoID: int64(i + 1), HashDistance: d}, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (r *photoRepo) AddVisitPhoto(ctx context.Context, params domain.AddVisitPhotoParams) (*domain.VisitPhoto, error) {
+	if params.Hash != nil {
+		if match, err := r.FindSimilarVisitPhoto(ctx, *params.Hash, params.ReuseDistance); err == nil {
+			return nil, &domain.PhotoReuseError{Match: *match}
+		}
+		r.hashes = append(r.hashes, *params.Hash)
+	}
+	photo := params.Photo
+	photo.Verification = params.Check(&photo, time.Now(), nil, nil)
+	r.stored = append(r.stored, photo)
+	return &photo, nil
+}
+
+type photoMedia struct{ domain.MediaClient }
+
+func (m *photoMedia) Upload(_ context.Context, filename, _ string, _ []byte) (string, error) {
+	return "https://media.test/" + filename, nil
+}
+
+func (m *photoMedia) Delete(context.Context, string) error { return nil }
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// blocks is a 640x480 picture of 40-pixel blocks in pseudo-random shades.
+func blocks(seed uint32) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 640, 480))
+	state := seed
+	for by := 0; by < 480; by += 40 {
+		for bx := 0; bx < 640; bx += 40 {
+			state = state*1664525 + 1013904223
+			for y := by; y < by+40; y++ {
+				for x := bx; x < bx+40; x++ {
+					img.SetGray(x, y, color.Gray{Y: uint8(state >> 24)})
+				}
+			}
+		}
+	}
+	return img
+}
+
+func TestVisitPhotoUploadReuse(t *testing.T) {
+	black := encodeJPEG(t, image.NewGray(image.Rect(0, 0, 640, 480)))
+	dark := image.NewGray(image.Rect(0, 0, 640, 480))
+	for i, v := range blocks(7).Pix {
+		dark.Pix[i] = 4 + v/128
+	}
+	scene := encodeJPEG(t, blocks(1))
+
+	tests := []struct {
+		name    string
+		uploads [][]byte
+		reused  bool
+	}{
+		{"black frames", [][]byte{black, black}, false},
+		{"black then dark", [][]byte{black, encodeJPEG(t, dark)}, false},
+		{"same scene", [][]byte{scene, scene}, true},
+		{"scene after a black frame", [][]byte{black, scene}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, media := &photoRepo{}, &photoMedia{}
+			svc := NewVisitPhotoService(repo, media)
+			var err error
+			for _, content := range tt.uploads {
+				_, err = svc.Upload(context.Background(), domain.UploadVisitPhotoParams{
+					ApplicationID: "APP-1",
+					VisitID:       "visit-1",
+					Actor:         "tksk@example.test",
+					Filename:      "photo.jpg",
+					Content:       content,
+				})
+				if err != nil {
+					break
+				}
+			}
+			var reuse *domain.PhotoReuseError
+			if got := errors.As(err, &reuse); got != tt.reused {
+				t.Fatalf("Upload = %v, want re-used %v", err, tt.reused)
+			}
+			if !tt.reused && err != nil {
+				t.Fatal(err)
+			}
+			for _, photo := range repo.stored {
+				flat := slices.Contains(photo.Verification.Flags, domain.PhotoLowDetail)
+				if flat != (photo.PerceptualHash == "") {
+					t.Errorf("photo hashed %q flagged %v", photo.PerceptualHash, photo.Verification.Flags)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+// VisitReviewService is the supervisor step after a TKSK submits a visit. A supervisor
+// whose region scope covers the beneficiary either verifies the visit, which locks it,
+// or returns it with a comment so the TKSK can fix and resubmit it. Supervisors cannot
+// review visits assigned to themselves.
+type VisitReviewService struct {
+	repo domain.VisitReviewRepository
+}
+
+var _ domain.VisitReviewService = (*VisitReviewService)(nil)
+
+func NewVisitReviewService(repo domain.VisitReviewRepository) *VisitReviewService {
+	return &VisitReviewService{repo: repo}
+}
+
+func (s *VisitReviewService) Queue(ctx context.Context, params domain.ListVisitReviewQueueParams) ([]domain.VisitReviewQueueItem, error) {
+	if params.Limit <= 0 || params.Limit > 200 {
+		params.Limit = 50
+	}
+	params.Region = strings.TrimSpace(params.Region)
+	return s.repo.ListVisitReviewQueue(ctx, params)
+}
+
+func (s *VisitReviewService) Review(ctx context.Context, params domain.ReviewVisitParams) (*domain.VisitReview, error) {
+	if !roleAllowed(params.ReviewerRole, domain.VisitReviewRoles) {
+		return nil, fmt.Errorf("%w: peran %s tidak boleh memverifikasi kunjungan", domain.ErrForbidden, params.ReviewerRole)
+	}
+	params.Decision = strings.ToUpper(strings.TrimSpace(params.Decision))
+	if params.Decision != domain.VisitReviewVerified && params.Decision != domain.VisitReviewReturned {
+		return nil, fmt.Errorf("%w: keputusan harus %s atau %s", domain.ErrInvalidState, domain.VisitReviewVerified, domain.VisitReviewReturned)
+	}
+	params.Comment = strings.TrimSpace(params.Comment)
+	if params.Decision == domain.VisitReviewReturned && params.Comment == "" {
+		return nil, fmt.Errorf("%w: komentar wajib diisi saat mengembalikan kunjungan", domain.ErrInvalidState)
+	}
+
+	target, err := s.repo.GetVisitReviewTarget(ctx, params.ApplicationID, params.VisitID)
+	if err != nil {
+		return nil, err
+	}
+	if !coversRegion(params.RegionScope, target.Region) {
+		return nil, fmt.Errorf("%w: wilayah %s di luar cakupan supervisor", domain.ErrForbidden, regionLabel(target.Region))
+	}
+	if target.TkskID != "" && target.TkskID == params.Reviewer {
+		return nil, fmt.Errorf("%w: kunjungan sendiri tidak boleh diverifikasi", domain.ErrForbidden)
+	}
+	if target.Status != domain.VisitStatusSubmitted {
+		return nil, fmt.Errorf("%w: kunjungan %s berstatus %s, bukan %s", domain.ErrInvalidState, params.VisitID, target.Status, domain.VisitStatusSubmitted)
+	}
+
+	action := "VISIT:VERIFIED"
+	if params.Decision == domain.VisitReviewReturned {
+		action = "VISIT:RETURNED"
+	}
+	params.Timeline = timelineEntry(params.ApplicationID, params.Reviewer, action, params.Comment, map[string]any{
+		"visitId":      params.VisitID,
+		"tkskId":       target.TkskID,
+		"reviewerRole": params.ReviewerRole,
+	})
+	params.Audit = auditEntry(params.Reviewer, params.ApplicationID, action, params.Comment, map[string]any{
+		"visitId":      params.VisitID,
+		"reviewerRole": params.ReviewerRole,
+	})
+	return s.repo.RecordVisitReview(ctx, params)
+}
+
+func (s *VisitReviewService) List(ctx context.Context, appID, visitID string) ([]domain.VisitReview, error) {
+	return s.repo.ListVisitReviews(ctx, appID, visitID)
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/geo"
+)
+
+const (
+	// routeDetourFactor turns a straight-line distance into an estimate of the distance
+	// on the road.
+	routeDetourFactor = 1.4
+	// routeTimeStep is what suggested start times are rounded up to.
+	routeTimeStep = 5 * time.Minute
+	maxRouteStops = 50
+)
+
+// VisitRoutePlanner orders a TKSK's planned visits for one day into a short route and
+// suggests start times along it: each visit starts after the previous one plus the
+// travel time between them. A household is located by the geotag of an earlier visit,
+// or else by its kelurahan centroid. Visits that cannot be located go last.
+type VisitRoutePlanner struct {
+	repo       domain.VisitRouteRepository
+	boundaries *geo.Boundaries
+}
+
+var _ domain.VisitRouteService = (*VisitRoutePlanner)(nil)
+
+// NewVisitRoutePlanner takes the kelurahan boundary dataset used for centroids. With nil
+// boundaries only households with an earlier geotag are located.
+func NewVisitRoutePlanner(repo domain.VisitRouteRepository, boundaries *geo.Boundaries) *VisitRoutePlanner {
+	return &VisitRoutePlanner{repo: repo, boundaries: boundaries}
+}
+
+func (p *VisitRoutePlanner) Plan(ctx context.Context, params domain.PlanVisitRouteParams) (*domain.VisitRoute, error) {
+	schedule, err := loadVisitSchedule(ctx, p.repo)
+	if err != nil {
+		return nil, err
+	}
+	assignee, err := tkskAssignee(ctx, p.repo, params.TkskID)
+	if err != nil {
+		return nil, err
+	}
+	day, err := routeDay(schedule, params.Date)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !day.AddDate(0, 0, 1).After(now) {
+		return nil, fmt.Errorf("%w: tanggal %s sudah lewat", domain.ErrInvalidState, day.Format(time.DateOnly))
+	}
+	var start *geo.Point
+	if params.Start != nil {
+		start = &geo.Point{Lng: params.Start.Lng, Lat: params.Start.Lat}
+		if !start.Valid() {
+			return nil, fmt.Errorf("%w: titik awal tidak valid", domain.ErrInvalidState)
+		}
+	}
+	visits, err := p.repo.ListRouteVisits(ctx, assignee.ID, day.UTC(), day.AddDate(0, 0, 1).UTC())
+	if err != nil {
+		return nil, err
+	}
+	if len(visits) > maxRouteStops {
+		return nil, fmt.Errorf("%w: maksimal %d kunjungan per rute", domain.ErrInvalidState, maxRouteStops)
+	}
+
+	// Visits come in schedule order, so points is also the route as currently scheduled.
+	stops := make([]domain.RouteStop, len(visits))
+	var (
+		located []int
+		points  []geo.Point
+	)
+	for i, visit := range visits {
+		stops[i] = domain.RouteStop{
+			VisitID:        visit.VisitID,
+			ApplicationID:  visit.ApplicationID,
+			ApplicantName:  visit.ApplicantName,
+			Kelurahan:      visit.Region.Kel,
+			LocationSource: domain.RouteLocationUnknown,
+			ScheduledAt:    visit.ScheduledAt,
+		}
+		if pt, source, ok := p.locate(visit); ok {
+			stops[i].Lat, stops[i].Lng = &pt.Lat, &pt.Lng
+			stops[i].LocationSource = source
+			located = append(located, i)
+			points = append(points, pt)
+		}
+	}
+	order := make([]int, 0, len(stops))
+	for _, k := range geo.OrderRoute(start, points) {
+		order = append(order, located[k])
+	}
+	for i := range stops {
+		if stops[i].LocationSource == domain.RouteLocationUnknown {
+			order = append(order, i)
+		}
+	}
+
+	scheduled := points
+	if start != nil {
+		scheduled = append([]geo.Point{*start}, points...)
+	}
+	route := &domain.VisitRoute{
+		TkskID:                  assignee.ID,
+		TkskName:                assignee.Name,
+		Date:                    day.Format(time.DateOnly),
+		Timezone:                schedule.Location.String(),
+		Start:                   params.Start,
+		VisitDurationMinutes:    int(schedule.VisitDuration.Minutes()),
+		TravelSpeedKmh:          schedule.TravelSpeedKmh,
+		Stops:                   make([]domain.RouteStop, 0, len(order)),
+		ScheduledDistanceMeters: math.Round(geo.PathLength(scheduled)),
+		FitsWorkingHours:        true,
+	}
+	// Today's route starts now at the earliest.
+	cursor := day.Add(schedule.WorkStart)
+	if cursor.Before(now) {
+		cursor = now.In(schedule.Location)
+	}
+	var total float64
+	previous := start
+	for seq, i := range order {
+		stop := stops[i]
+		if stop.Lat != nil {
+			here := geo.Point{Lng: *stop.Lng, Lat: *stop.Lat}
+			if previous != nil {
+				distance := geo.DistanceMeters(*previous, here)
+				total += distance
+				stop.DistanceMeters = math.Round(distance)
+				stop.TravelMinutes = travelMinutes(distance, schedule.TravelSpeedKmh)
+			}
+			previous = &here
+		}
+		cursor = ceilTime(cursor.Add(time.Duration(stop.TravelMinutes)*time.Minute), routeTimeStep)
+		stop.Sequence = seq + 1
+		stop.SuggestedAt = cursor.UTC()
+		stop.SuggestedEnd = cursor.Add(schedule.VisitDuration).UTC()
+		stop.WithinWorkingHours = fitsWorkingHours(schedule, cursor)
+		route.FitsWorkingHours = route.FitsWorkingHours && stop.WithinWorkingHours
+		route.TotalTravelMinutes += stop.TravelMinutes
+		route.Stops = append(route.Stops, stop)
+		cursor = cursor.Add(schedule.VisitDuration)
+	}
+	route.TotalDistanceMeters = math.Round(total)
+	return route, nil
+}
+
+// Apply moves the visits to the given start times, typically the SuggestedAt of a
+// planned route. Every time must be in working hours and the visits may not overlap each
+// other or the TKSK's other visits.
+func (p *VisitRoutePlanner) Apply(ctx context.Context, params domain.ApplyVisitRouteParams) ([]domain.CalendarVisit, error) {
+	if strings.TrimSpace(params.Actor) == "" {
+		return nil, fmt.Errorf("%w: actor wajib diisi", domain.ErrInvalidState)
+	}
+	if len(params.Stops) == 0 {
+		return nil, fmt.Errorf("%w: stops wajib diisi", domain.ErrInvalidState)
+	}
+	if len(params.Stops) > maxRouteStops {
+		return nil, fmt.Errorf("%w: maksimal %d kunjungan per rute", domain.ErrInvalidState, maxRouteStops)
+	}
+	schedule, err := loadVisitSchedule(ctx, p.repo)
+	if err != nil {
+		return nil, err
+	}
+	assignee, err := tkskAssignee(ctx, p.repo, params.TkskID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	stops := make([]domain.VisitReschedule, len(params.Stops))
+	seen := make(map[string]bool, len(params.Stops))
+	for i, stop := range params.Stops {
+		stop.VisitID = strings.TrimSpace(stop.VisitID)
+		stop.ScheduledAt = stop.ScheduledAt.UTC()
+		switch {
+		case stop.VisitID == "":
+			return nil, fmt.Errorf("%w: stops[%d]: visitId wajib diisi", domain.ErrInvalidState, i)
+		case seen[stop.VisitID]:
+			return nil, fmt.Errorf("%w: kunjungan %s muncul lebih dari sekali", domain.ErrInvalidState, stop.VisitID)
+		case stop.ScheduledAt.Before(now):
+			return nil, fmt.Errorf("%w: jadwal kunjungan %s sudah lewat", domain.ErrInvalidState, stop.VisitID)
+		case !fitsWorkingHours(schedule, stop.ScheduledAt):
+			return nil, fmt.Errorf("%w: jadwal kunjungan %s di luar jam kerja (%s)", domain.ErrInvalidState, stop.VisitID, workingHoursLabel(schedule))
+		}
+		seen[stop.VisitID] = true
+		stops[i] = stop
+	}
+	byTime := append([]domain.VisitReschedule(nil), stops...)
+	sort.Slice(byTime, func(i, j int) bool { return byTime[i].ScheduledAt.Before(byTime[j].ScheduledAt) })
+	for i := 1; i < len(byTime); i++ {
+		if byTime[i].ScheduledAt.Sub(byTime[i-1].ScheduledAt) < schedule.VisitDuration {
+			return nil, fmt.Errorf("%w: kunjungan %s dan %s bertabrakan", domain.ErrInvalidState, byTime[i-1].VisitID, byTime[i].VisitID)
+		}
+	}
+	return p.repo.RescheduleVisits(ctx, domain.RescheduleVisitsParams{
+		TkskID:   assignee.ID,
+		Actor:    params.Actor,
+		Stops:    stops,
+		Duration: schedule.VisitDuration,
+	})
+}
+
+func (p *VisitRoutePlanner) locate(visit domain.RouteVisit) (geo.Point, string, bool) {
+	if visit.HouseholdLat != nil && visit.HouseholdLng != nil {
+		pt := geo.Point{Lng: *visit.HouseholdLng, Lat: *visit.HouseholdLat}
+		if pt.Valid() {
+			return pt, domain.RouteLocationHousehold, true
+		}
+	}
+	if p.boundaries != nil {
+		if boundary, ok := p.boundaries.Lookup(visit.Region.Kab, visit.Region.Kec, visit.Region.Kel); ok {
+			return boundary.Centroid, domain.RouteLocationKelurahan, true
+		}
+	}
+	return geo.Point{}, "", false
+}
+
+// routeDay parses date as a local day, today when empty, and returns its midnight.
+func routeDay(schedule domain.VisitSchedule, date string) (time.Time, error) {
+	date = strings.TrimSpace(date)
+	if date == "" {
+		now := time.Now().In(schedule.Location)
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, schedule.Location), nil
+	}
+	day, err := time.ParseInLocation(time.DateOnly, date, schedule.Location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: tanggal harus YYYY-MM-DD", domain.ErrInvalidState)
+	}
+	return day, nil
+}
+
+// travelMinutes estimates the time to cover a straight-line distance on the road.
+func travelMinutes(meters, speedKmh float64) int {
+	return int(math.Ceil(meters * routeDetourFactor / 1000 / speedKmh * 60))
+}
+
+func ceilTime(t time.Time, step time.Duration) time.Time {
+	if floor := t.Truncate(step); floor.Before(t) {
+		return floor.Add(step)
+	}
+	return t
+}
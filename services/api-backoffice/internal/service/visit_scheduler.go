@@ -0,0 +1,360 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// visitSlotStep is the grid free slots are suggested on.
+	visitSlotStep = 30 * time.Minute
+	// visitSuggestionHorizon bounds how far ahead free slots are searched for.
+	visitSuggestionHorizon = 14 * 24 * time.Hour
+	maxCalendarRange       = 62 * 24 * time.Hour
+	defaultSlotSuggestions = 3
+	maxSlotSuggestions     = 20
+)
+
+var calendarVisitStatuses = []string{domain.VisitStatusPlanned, domain.VisitStatusInProgress, domain.VisitStatusSubmitted}
+
+// VisitScheduler assigns TKSK visits: the assignee must be a TKSK covering the
+// beneficiary's region, and the slot must fall in working hours without overlapping the
+// TKSK's other planned or in-progress visits.
+type VisitScheduler struct {
+	repo domain.VisitScheduleRepository
+}
+
+var _ domain.VisitScheduleService = (*VisitScheduler)(nil)
+
+func NewVisitScheduler(repo domain.VisitScheduleRepository) *VisitScheduler {
+	return &VisitScheduler{repo: repo}
+}
+
+func (s *VisitScheduler) ScheduleVisit(ctx context.Context, appID, actor string, scheduledAt time.Time, tkskID string) (*domain.Visit, error) {
+	appID = strings.TrimSpace(appID)
+	if appID == "" {
+		return nil, fmt.Errorf("%w: application id required", domain.ErrInvalidState)
+	}
+	schedule, err := s.schedule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	assignee, err := tkskAssignee(ctx, s.repo, tkskID)
+	if err != nil {
+		return nil, err
+	}
+	region, err := s.repo.GetApplicationRegion(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if !coversRegion(assignee.RegionScope, *region) {
+		return nil, fmt.Errorf("%w: wilayah %s di luar cakupan TKSK %s", domain.ErrInvalidState, regionLabel(*region), assignee.Name)
+	}
+	scheduledAt = scheduledAt.UTC()
+	if scheduledAt.Before(time.Now()) {
+		return nil, fmt.Errorf("%w: jadwal kunjungan sudah lewat", domain.ErrInvalidState)
+	}
+	if !fitsWorkingHours(schedule, scheduledAt) {
+		return nil, fmt.Errorf("%w: jadwal di luar jam kerja (%s)", domain.ErrInvalidState, workingHoursLabel(schedule))
+	}
+
+	visit := domain.Visit{
+		ID:            fmt.Sprintf("VST-%d", time.Now().UnixNano()),
+		ApplicationID: appID,
+		ScheduledAt:   scheduledAt,
+		Status:        domain.VisitStatusPlanned,
+		TkskID:        assignee.ID,
+		CreatedAt:     time.Now().UTC(),
+	}
+	timelineMeta := map[string]any{"visitId": visit.ID, "tkskId": assignee.ID}
+	// The visit keeps this template version even when a newer one is published later.
+	template, err := s.repo.CurrentChecklistTemplate(ctx, appID)
+	switch {
+	case err == nil:
+		visit.ChecklistTemplateID = &template.ID
+		timelineMeta["checklistTemplateId"] = template.ID
+		timelineMeta["checklistTemplateVersion"] = template.Version
+	case !errors.Is(err, domain.ErrNotFound):
+		return nil, err
+	}
+	timeline := timelineEntry(appID, actor, "VISIT:CREATED", "", timelineMeta)
+	if err := s.repo.CreateScheduledVisit(ctx, &visit, schedule.VisitDuration, timeline); err != nil {
+		var conflict *domain.ScheduleConflictError
+		if errors.As(err, &conflict) {
+			conflict.Suggestions, err = s.freeSlots(ctx, schedule, assignee.ID, scheduledAt, defaultSlotSuggestions)
+			if err != nil {
+				return nil, err
+			}
+			return nil, conflict
+		}
+		return nil, err
+	}
+	return &visit, nil
+}
+
+// SuggestSlots returns the TKSK's next free slots from params.After, or from now.
+func (s *VisitScheduler) SuggestSlots(ctx context.Context, params domain.SuggestVisitSlotsParams) ([]domain.VisitSlot, error) {
+	schedule, err := s.schedule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	assignee, err := tkskAssignee(ctx, s.repo, params.TkskID)
+	if err != nil {
+		return nil, err
+	}
+	count := params.Count
+	if count <= 0 {
+		count = defaultSlotSuggestions
+	}
+	count = min(count, maxSlotSuggestions)
+	after := time.Now().UTC()
+	if params.After.After(after) {
+		after = params.After.UTC()
+	}
+	return s.freeSlots(ctx, schedule, assignee.ID, after, count)
+}
+
+// Calendar lists the TKSK's planned, in-progress and submitted visits in [From, To). The
+// range defaults to the coming week from today's local midnight.
+func (s *VisitScheduler) Calendar(ctx context.Context, params domain.TkskCalendarParams) (*domain.TkskCalendar, error) {
+	schedule, err := s.schedule(ctx)
+	if err != nil {
+		return nil, err
+	}
+	assignee, err := tkskAssignee(ctx, s.repo, params.TkskID)
+	if err != nil {
+		return nil, err
+	}
+	from, to := params.From, params.To
+	if from.IsZero() {
+		now := time.Now().In(schedule.Location)
+		from = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, schedule.Location)
+	}
+	if to.IsZero() {
+		to = from.Add(7 * 24 * time.Hour)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("%w: rentang tanggal tidak valid", domain.ErrInvalidState)
+	}
+	if to.Sub(from) > maxCalendarRange {
+		return nil, fmt.Errorf("%w: rentang kalender maksimal %d hari", domain.ErrInvalidState, int(maxCalendarRange.Hours()/24))
+	}
+	visits, err := s.repo.ListTkskVisits(ctx, assignee.ID, from.UTC(), to.UTC(), calendarVisitStatuses)
+	if err != nil {
+		return nil, err
+	}
+	for i := range visits {
+		visits[i].End = visits[i].Start.Add(schedule.VisitDuration)
+	}
+	return &domain.TkskCalendar{
+		TkskID:               assignee.ID,
+		TkskName:             assignee.Name,
+		Timezone:             schedule.Location.String(),
+		VisitDurationMinutes: int(schedule.VisitDuration.Minutes()),
+		From:                 from.UTC(),
+		To:                   to.UTC(),
+		Visits:               visits,
+	}, nil
+}
+
+func (s *VisitScheduler) schedule(ctx context.Context) (domain.VisitSchedule, error) {
+	return loadVisitSchedule(ctx, s.repo)
+}
+
+// loadVisitSchedule reads the scheduling config, or the default when none is stored.
+func loadVisitSchedule(ctx context.Context, repo configGetter) (domain.VisitSchedule, error) {
+	cfg, err := repo.GetConfig(ctx)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.DefaultVisitSchedule(), nil
+		}
+		return domain.VisitSchedule{}, err
+	}
+	return visitSchedule(cfg.Scheduling)
+}
+
+type visitAssigneeGetter interface {
+	GetVisitAssignee(ctx context.Context, userID string) (*domain.VisitAssignee, error)
+}
+
+// tkskAssignee resolves tkskID to a user with the TKSK role.
+func tkskAssignee(ctx context.Context, repo visitAssigneeGetter, tkskID string) (*domain.VisitAssignee, error) {
+	id, err := uuid.Parse(strings.TrimSpace(tkskID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: TKSK %q", domain.ErrNotFound, tkskID)
+	}
+	assignee, err := repo.GetVisitAssignee(ctx, id.String())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("%w: TKSK %q", domain.ErrNotFound, tkskID)
+		}
+		return nil, err
+	}
+	if assignee.Role != "TKSK" {
+		return nil, fmt.Errorf("%w: pengguna %s bukan TKSK", domain.ErrInvalidState, assignee.Name)
+	}
+	return assignee, nil
+}
+
+func (s *VisitScheduler) freeSlots(ctx context.Context, schedule domain.VisitSchedule, tkskID string, after time.Time, count int) ([]domain.VisitSlot, error) {
+	busy, err := s.repo.ListTkskVisits(ctx, tkskID,
+		after.Add(-schedule.VisitDuration), after.Add(visitSuggestionHorizon+schedule.VisitDuration),
+		[]string{domain.VisitStatusPlanned, domain.VisitStatusInProgress})
+	if err != nil {
+		return nil, err
+	}
+	return nextFreeSlots(schedule, busy, after, count), nil
+}
+
+// nextFreeSlots walks the working hours from after on a visitSlotStep grid and returns the
+// first count slots that overlap none of busy.
+func nextFreeSlots(schedule domain.VisitSchedule, busy []domain.CalendarVisit, after time.Time, count int) []domain.VisitSlot {
+	slots := []domain.VisitSlot{}
+	local := after.In(schedule.Location)
+	firstDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, schedule.Location)
+	for day := 0; day <= int(visitSuggestionHorizon/(24*time.Hour)) && len(slots) < count; day++ {
+		midnight := firstDay.AddDate(0, 0, day)
+		if !slices.Contains(schedule.WorkDays, midnight.Weekday()) {
+			continue
+		}
+		start := midnight.Add(schedule.WorkStart)
+		if start.Before(after) {
+			steps := math.Ceil(float64(after.Sub(midnight)) / float64(visitSlotStep))
+			start = midnight.Add(time.Duration(steps) * visitSlotStep)
+		}
+		for ; !start.Add(schedule.VisitDuration).After(midnight.Add(schedule.WorkEnd)) && len(slots) < count; start = start.Add(visitSlotStep) {
+			end := start.Add(schedule.VisitDuration)
+			free := true
+			for _, visit := range busy {
+				if visit.Start.Before(end) && start.Before(visit.Start.Add(schedule.VisitDuration)) {
+					free = false
+					break
+				}
+			}
+			if free {
+				slots = append(slots, domain.VisitSlot{Start: start.UTC(), End: end.UTC()})
+			}
+		}
+	}
+	return slots
+}
+
+func fitsWorkingHours(schedule domain.VisitSchedule, start time.Time) bool {
+	local := start.In(schedule.Location)
+	if !slices.Contains(schedule.WorkDays, local.Weekday()) {
+		return false
+	}
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, schedule.Location)
+	offset := local.Sub(midnight)
+	return offset >= schedule.WorkStart && offset+schedule.VisitDuration <= schedule.WorkEnd
+}
+
+// coversRegion reports whether a region scope entry names the province, regency, district
+// or village of region. An empty scope covers every region.
+func coversRegion(scope []string, region domain.Region) bool {
+	if len(scope) == 0 {
+		return true
+	}
+	for _, entry := range scope {
+		for _, name := range []string{region.Prov, region.Kab, region.Kec, region.Kel} {
+			if name != "" && strings.EqualFold(strings.TrimSpace(entry), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func regionLabel(region domain.Region) string {
+	var parts []string
+	for _, name := range []string{region.Kel, region.Kec, region.Kab, region.Prov} {
+		if name != "" {
+			parts = append(parts, name)
+		}
+	}
+	if len(parts) == 0 {
+		return "tanpa wilayah"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func workingHoursLabel(schedule domain.VisitSchedule) string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%s-%s %s, kunjungan %d menit", clock(schedule.WorkStart), clock(schedule.WorkEnd),
+		schedule.Location, int(schedule.VisitDuration.Minutes()))
+}
+
+// visitSchedule parses SystemConfig.Scheduling over DefaultVisitSchedule. Unknown keys and
+// malformed values are rejected rather than ignored.
+func visitSchedule(raw map[string]any) (domain.VisitSchedule, error) {
+	schedule := domain.DefaultVisitSchedule()
+	invalid := func(key, want string) (domain.VisitSchedule, error) {
+		return domain.VisitSchedule{}, fmt.Errorf("%w: scheduling %q harus %s", domain.ErrInvalidState, key, want)
+	}
+	for key, value := range raw {
+		switch key {
+		case domain.ScheduleKeyVisitDuration:
+			minutes, ok := value.(float64)
+			if !ok || minutes <= 0 || minutes > 12*60 || minutes != math.Trunc(minutes) {
+				return invalid(key, "bilangan bulat menit antara 1 dan 720")
+			}
+			schedule.VisitDuration = time.Duration(minutes) * time.Minute
+		case domain.ScheduleKeyWorkStart, domain.ScheduleKeyWorkEnd:
+			text, _ := value.(string)
+			clock, err := time.Parse("15:04", text)
+			if err != nil {
+				return invalid(key, "jam HH:MM")
+			}
+			offset := time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute
+			if key == domain.ScheduleKeyWorkStart {
+				schedule.WorkStart = offset
+			} else {
+				schedule.WorkEnd = offset
+			}
+		case domain.ScheduleKeyWorkDays:
+			list, ok := value.([]any)
+			if !ok || len(list) == 0 {
+				return invalid(key, "daftar hari ISO 1-7")
+			}
+			days := make([]time.Weekday, 0, len(list))
+			for _, item := range list {
+				day, ok := item.(float64)
+				if !ok || day < 1 || day > 7 || day != math.Trunc(day) {
+					return invalid(key, "daftar hari ISO 1-7")
+				}
+				days = append(days, time.Weekday(int(day)%7))
+			}
+			schedule.WorkDays = days
+		case domain.ScheduleKeyTravelSpeed:
+			kmh, ok := value.(float64)
+			if !ok || kmh <= 0 || kmh > 120 {
+				return invalid(key, "kecepatan km/jam antara 0 dan 120")
+			}
+			schedule.TravelSpeedKmh = kmh
+		case domain.ScheduleKeyTimezone:
+			name, _ := value.(string)
+			loc, err := time.LoadLocation(name)
+			if name == "" || err != nil {
+				return invalid(key, "zona waktu IANA")
+			}
+			schedule.Location = loc
+		default:
+			return domain.VisitSchedule{}, fmt.Errorf("%w: scheduling %q tidak dikenal", domain.ErrInvalidState, key)
+		}
+	}
+	if schedule.WorkEnd-schedule.WorkStart < schedule.VisitDuration {
+		return domain.VisitSchedule{}, fmt.Errorf("%w: jam kerja lebih pendek dari durasi kunjungan", domain.ErrInvalidState)
+	}
+	return schedule, nil
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+const (
+	maxVisitSyncMutations = 200
+	maxDeviceIDLength     = 128
+	// maxClientClockSkew tolerates device clocks running ahead of the server.
+	maxClientClockSkew = 10 * time.Minute
+)
+
+// offlineVisitStatuses are the statuses a TKSK may set from the field. VERIFIED is left
+// to the back office.
+var offlineVisitStatuses = []string{domain.VisitStatusPlanned, domain.VisitStatusInProgress, domain.VisitStatusSubmitted}
+
+// VisitSyncService takes the visit changes a TKSK recorded offline. Mutations are applied
+// in the order the device sent them. One whose base version is behind the server comes
+// back as a conflict with the server's copy, for the device to resolve and resend.
+type VisitSyncService struct {
+	repo     domain.VisitSyncRepository
+	geofence *VisitGeofence
+}
+
+var _ domain.VisitSyncService = (*VisitSyncService)(nil)
+
+func NewVisitSyncService(repo domain.VisitSyncRepository, geofence *VisitGeofence) *VisitSyncService {
+	return &VisitSyncService{repo: repo, geofence: geofence}
+}
+
+func (s *VisitSyncService) Sync(ctx context.Context, params domain.VisitSyncParams) (*domain.VisitSyncResult, error) {
+	if err := s.ensureEnabled(ctx); err != nil {
+		return nil, err
+	}
+	assignee, err := tkskAssignee(ctx, s.repo, params.TkskID)
+	if err != nil {
+		return nil, err
+	}
+	deviceID := strings.TrimSpace(params.DeviceID)
+	if deviceID == "" || len(deviceID) > maxDeviceIDLength {
+		return nil, fmt.Errorf("%w: deviceId wajib diisi (maks %d karakter)", domain.ErrInvalidState, maxDeviceIDLength)
+	}
+	var since int64
+	if token := strings.TrimSpace(params.SyncToken); token != "" {
+		since, err = strconv.ParseInt(token, 10, 64)
+		if err != nil || since < 0 {
+			return nil, fmt.Errorf("%w: syncToken tidak valid", domain.ErrInvalidState)
+		}
+	}
+	if len(params.Mutations) > maxVisitSyncMutations {
+		return nil, fmt.Errorf("%w: maksimal %d mutasi per sinkronisasi", domain.ErrInvalidState, maxVisitSyncMutations)
+	}
+
+	// Invalid mutations are rejected here and the rest go to the repository, which
+	// answers them in order. The results are merged back into request order.
+	results := make([]domain.VisitMutationResult, len(params.Mutations))
+	var (
+		valid   []domain.VisitMutation
+		pending []int
+	)
+	seen := map[string]bool{}
+	now := time.Now()
+	for i, mutation := range params.Mutations {
+		mutation.MutationID = strings.TrimSpace(mutation.MutationID)
+		mutation.VisitID = strings.TrimSpace(mutation.VisitID)
+		results[i] = domain.VisitMutationResult{MutationID: mutation.MutationID, VisitID: mutation.VisitID}
+		problem := validateVisitMutation(mutation, now)
+		if problem == "" && seen[mutation.MutationID] {
+			problem = "mutationId ganda dalam satu sinkronisasi"
+		}
+		if problem != "" {
+			results[i].Result = domain.VisitSyncRejected
+			results[i].Error = problem
+			continue
+		}
+		seen[mutation.MutationID] = true
+		mutation.ClientRecordedAt = mutation.ClientRecordedAt.UTC()
+		valid = append(valid, mutation)
+		pending = append(pending, i)
+	}
+
+	geofence, err := s.geofence.Check(ctx)
+	if err != nil {
+		return nil, err
+	}
+	applied, changes, token, err := s.repo.ApplyVisitSync(ctx, domain.ApplyVisitSyncParams{
+		TkskID:         assignee.ID,
+		DeviceID:       deviceID,
+		Actor:          params.Actor,
+		Since:          since,
+		Mutations:      valid,
+		Geofence:       geofence,
+		ChecklistCheck: validateVisitChecklist,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range pending {
+		results[i] = applied[j]
+	}
+	return &domain.VisitSyncResult{
+		DeviceID:  deviceID,
+		SyncToken: strconv.FormatInt(token, 10),
+		Results:   results,
+		Changes:   changes,
+	}, nil
+}
+
+func (s *VisitSyncService) ensureEnabled(ctx context.Context) error {
+	cfg, err := s.repo.GetConfig(ctx)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if enabled, ok := cfg.Features[domain.FeatureOfflineTKSK].(bool); ok && !enabled {
+		return fmt.Errorf("%w: sinkronisasi offline TKSK dinonaktifkan", domain.ErrForbidden)
+	}
+	return nil
+}
+
+// validateVisitMutation returns why the mutation cannot be applied, or "" if it can.
+func validateVisitMutation(mutation domain.VisitMutation, now time.Time) string {
+	switch {
+	case mutation.MutationID == "":
+		return "mutationId wajib diisi"
+	case mutation.VisitID == "":
+		return "visitId wajib diisi"
+	case mutation.BaseVersion < 1:
+		return "baseVersion wajib diisi"
+	case mutation.ClientRecordedAt.IsZero():
+		return "clientRecordedAt wajib diisi"
+	case mutation.ClientRecordedAt.After(now.Add(maxClientClockSkew)):
+		return "clientRecordedAt berada di masa depan"
+	}
+	if mutation.Status == nil && mutation.Geotag == nil && mutation.Checklist == nil && mutation.Photos == nil {
+		return "mutasi tidak berisi perubahan"
+	}
+	if mutation.Status != nil && !slices.Contains(offlineVisitStatuses, *mutation.Status) {
+		return fmt.Sprintf("status %q tidak dapat diubah dari lapangan", *mutation.Status)
+	}
+	if geotag := mutation.Geotag; geotag != nil && (geotag.Lat < -90 || geotag.Lat > 90 || geotag.Lng < -180 || geotag.Lng > 180) {
+		return "geotag di luar rentang koordinat"
+	}
+	return ""
+}
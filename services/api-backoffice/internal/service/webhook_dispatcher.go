@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/shared/retry"
+)
+
+const (
+	webhookBatchSize = 20
+	// webhookLease hides a claimed delivery from other replicas while it is being sent.
+	// It must outlast the sender's HTTP timeout.
+	webhookLease = 2 * time.Minute
+)
+
+// DefaultWebhookRetryConfig spreads nine attempts over roughly eight hours.
+func DefaultWebhookRetryConfig() retry.Config {
+	return retry.Config{MaxRetries: 8, InitialWait: 30 * time.Second, MaxWait: 2 * time.Hour}
+}
+
+// WebhookDispatcher drains the webhook_deliveries queue. Failed attempts are retried
+// with exponential backoff; after MaxRetries retries the delivery is dead-lettered.
+type WebhookDispatcher struct {
+	repo     domain.WebhookRepository
+	sender   domain.WebhookSender
+	retry    retry.Config
+	interval time.Duration
+}
+
+func NewWebhookDispatcher(repo domain.WebhookRepository, sender domain.WebhookSender, retryCfg retry.Config, interval time.Duration) *WebhookDispatcher {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &WebhookDispatcher{repo: repo, sender: sender, retry: retryCfg, interval: interval}
+}
+
+// Run blocks until ctx is cancelled.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep draining while full batches come back.
+		for d.DispatchDue(ctx) == webhookBatchSize {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue sends one batch of due deliveries and returns how many it claimed.
+func (d *WebhookDispatcher) DispatchDue(ctx context.Context) int {
+	deliveries, err := d.repo.ClaimDueWebhookDeliveries(ctx, webhookBatchSize, webhookLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("api-backoffice: claim webhook deliveries: %v", err)
+		}
+		return 0
+	}
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery domain.WebhookDelivery) {
+			defer wg.Done()
+			d.dispatch(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+	return len(deliveries)
+}
+
+func (d *WebhookDispatcher) dispatch(ctx context.Context, delivery domain.WebhookDelivery) {
+	status, err := d.sender.Send(ctx, delivery)
+	if err == nil {
+		if err := d.repo.MarkWebhookDelivered(ctx, delivery.ID, status); err != nil {
+			log.Printf("api-backoffice: mark webhook delivery %d delivered: %v", delivery.ID, err)
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the lease expires and another run picks it up.
+		return
+	}
+
+	failure := domain.WebhookFailure{DeliveryID: delivery.ID, Error: err.Error()}
+	if status > 0 {
+		failure.StatusCode = &status
+	}
+	// Attempts already counts this one; retry n follows attempt n.
+	if delivery.Attempts <= d.retry.MaxRetries {
+		next := time.Now().Add(d.retry.Delay(delivery.Attempts))
+		failure.NextAttemptAt = &next
+	} else {
+		log.Printf("api-backoffice: webhook delivery %d dead after %d attempts: %v", delivery.ID, delivery.Attempts, err)
+	}
+	if err := d.repo.MarkWebhookFailed(ctx, failure); err != nil {
+		log.Printf("api-backoffice: mark webhook delivery %d failed: %v", delivery.ID, err)
+	}
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+	"e-kyc/services/api-backoffice/internal/infrastructure/webhook"
+	"e-kyc/shared/retry"
+)
+
+// memoryWebhookQueue follows webhookRepository's delivery lifecycle: a claim counts an
+// attempt and leases the delivery, a failure without a next attempt dead-letters it and
+// a redelivery resets the attempt budget.
+type memoryWebhookQueue struct {
+	domain.WebhookRepository
+	mu         sync.Mutex
+	deliveries map[int64]*domain.WebhookDelivery
+}
+
+func newMemoryWebhookQueue(deliveries ...domain.WebhookDelivery) *memoryWebhookQueue {
+	q := &memoryWebhookQueue{deliveries: map[int64]*domain.WebhookDelivery{}}
+	for _, delivery := range deliveries {
+		delivery.Status = domain.WebhookDeliveryPending
+		q.deliveries[delivery.ID] = &delivery
+	}
+	return q
+}
+
+func (q *memoryWebhookQueue) ClaimDueWebhookDeliveries(_ context.Context, limit int, lease time.Duration) ([]domain.WebhookDelivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	var claimed []domain.WebhookDelivery
+	for _, delivery := range q.deliveries {
+		if len(claimed) == limit || delivery.Status != domain.WebhookDeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		delivery.Attempts++
+		delivery.NextAttemptAt = now.Add(lease)
+		claimed = append(claimed, *delivery)
+	}
+	return claimed, nil
+}
+
+func (q *memoryWebhookQueue) MarkWebhookDelivered(_ context.Context, id int64, statusCode int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delivery := q.deliveries[id]
+	now := time.Now()
+	delivery.Status = domain.WebhookDeliveryDelivered
+	delivery.LastStatusCode = &statusCode
+	delivery.LastError = nil
+	delivery.DeliveredAt = &now
+	return nil
+}
+
+func (q *memoryWebhookQueue) MarkWebhookFailed(_ context.Context, failure domain.WebhookFailure) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delivery := q.deliveries[failure.DeliveryID]
+	delivery.Status = domain.WebhookDeliveryDead
+	if failure.NextAttemptAt != nil {
+		delivery.Status = domain.WebhookDeliveryPending
+		delivery.NextAttemptAt = *failure.NextAttemptAt
+	}
+	delivery.LastStatusCode = failure.StatusCode
+	delivery.LastError = &failure.Error
+	return nil
+}
+
+func (q *memoryWebhookQueue) RedeliverWebhook(_ context.Context, id int64) (*domain.WebhookDelivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delivery, ok := q.deliveries[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	delivery.Status = domain.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.DeliveredAt = nil
+	queued := *delivery
+	return &queued, nil
+}
+
+func (q *memoryWebhookQueue) get(id int64) domain.WebhookDelivery {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return *q.deliveries[id]
+}
+
+// makeDue skips the backoff wait of a pending delivery.
+func (q *memoryWebhookQueue) makeDue(id int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.deliveries[id].NextAttemptAt = time.Now().Add(-time.Second)
+}
+
+// partnerEndpoint answers with status and rejects deliveries whose signature does not verify.
+type partnerEndpoint struct {
+	*httptest.Server
+	status   atomic.Int32
+	received atomic.Int32
+}
+
+func newPartnerEndpoint(t *testing.T, secret string, status int) *partnerEndpoint {
+	t.Helper()
+	p := &partnerEndpoint{}
+	p.status.Store(int32(status))
+	p.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !webhook.Verify(secret, r.Header.Get(webhook.HeaderSignature), body, time.Now(), time.Minute) {
+			t.Errorf("delivery %s failed signature verification", r.Header.Get(webhook.HeaderDelivery))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		p.received.Add(1)
+		w.WriteHeader(int(p.status.Load()))
+	}))
+	t.Cleanup(p.Close)
+	return p
+}
+
+func testDelivery(url string) domain.WebhookDelivery {
+	return domain.WebhookDelivery{
+		ID:        7,
+		EventID:   "evt-7",
+		EventType: domain.WebhookEventApplicationStatusChanged,
+		Payload:   json.RawMessage(`{"id":"evt-7","type":"application.status_changed"}`),
+		URL:       url,
+		Secret:    "whsec_partner",
+	}
+}
+
+func TestWebhookDispatcherDelivers(t *testing.T) {
+	partner := newPartnerEndpoint(t, "whsec_partner", http.StatusNoContent)
+	queue := newMemoryWebhookQueue(testDelivery(partner.URL))
+	dispatcher := NewWebhookDispatcher(queue, webhook.NewSender(partner.Client()), DefaultWebhookRetryConfig(), time.Second)
+
+	if n := dispatcher.DispatchDue(context.Background()); n != 1 {
+		t.Fatalf("claimed %d deliveries, want 1", n)
+	}
+	got := queue.get(7)
+	if got.Status != domain.WebhookDeliveryDelivered || got.Attempts != 1 || *got.LastStatusCode != http.StatusNoContent || got.DeliveredAt == nil {
+		t.Errorf("delivery %+v", got)
+	}
+	if n := dispatcher.DispatchDue(context.Background()); n != 0 || partner.received.Load() != 1 {
+		t.Errorf("delivered delivery sent again: claimed %d, partner received %d", n, partner.received.Load())
+	}
+}
+
+func TestWebhookDispatcherBacksOffThenDeadLetters(t *testing.T) {
+	partner := newPartnerEndpoint(t, "whsec_partner", http.StatusServiceUnavailable)
+	queue := newMemoryWebhookQueue(testDelivery(partner.URL))
+	cfg := retry.Config{MaxRetries: 3, InitialWait: time.Minute, MaxWait: 3 * time.Minute}
+	dispatcher := NewWebhookDispatcher(queue, webhook.NewSender(partner.Client()), cfg, time.Second)
+	ctx := context.Background()
+
+	// Retry n waits InitialWait doubled n-1 times, capped at MaxWait.
+	for attempt, wait := range []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute} {
+		before := time.Now()
+		dispatcher.DispatchDue(ctx)
+		got := queue.get(7)
+		if got.Status != domain.WebhookDeliveryPending || got.Attempts != attempt+1 || *got.LastStatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("after attempt %d: %+v", attempt+1, got)
+		}
+		if delay := got.NextAttemptAt.Sub(before); delay < wait || delay > wait+time.Second {
+			t.Errorf("retry %d scheduled in %s, want %s", attempt+1, delay, wait)
+		}
+		if n := dispatcher.DispatchDue(ctx); n != 0 {
+			t.Fatalf("delivery claimed again before its backoff elapsed")
+		}
+		queue.makeDue(7)
+	}
+
+	dispatcher.DispatchDue(ctx)
+	dead := queue.get(7)
+	if dead.Status != domain.WebhookDeliveryDead || dead.Attempts != cfg.MaxRetries+1 || dead.LastError == nil {
+		t.Fatalf("after MaxRetries: %+v", dead)
+	}
+	if n := dispatcher.DispatchDue(ctx); n != 0 || partner.received.Load() != int32(cfg.MaxRetries+1) {
+		t.Errorf("dead delivery sent again: claimed %d, partner received %d", n, partner.received.Load())
+	}
+}
+
+func TestWebhookDispatcherRedeliversDeadDelivery(t *testing.T) {
+	partner := newPartnerEndpoint(t, "whsec_partner", http.StatusBadGateway)
+	queue := newMemoryWebhookQueue(testDelivery(partner.URL))
+	cfg := retry.Config{MaxRetries: 0, InitialWait: time.Minute, MaxWait: time.Minute}
+	dispatcher := NewWebhookDispatcher(queue, webhook.NewSender(partner.Client()), cfg, time.Second)
+	ctx := context.Background()
+
+	dispatcher.DispatchDue(ctx)
+	if got := queue.get(7); got.Status != domain.WebhookDeliveryDead {
+		t.Fatalf("delivery %+v, want dead after one attempt", got)
+	}
+
+	// The partner fixes their endpoint and an operator redelivers.
+	partner.status.Store(http.StatusOK)
+	redelivered, err := NewWebhookService(queue).Redeliver(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redelivered.Status != domain.WebhookDeliveryPending || redelivered.Attempts != 0 {
+		t.Fatalf("redelivered %+v", redelivered)
+	}
+	if n := dispatcher.DispatchDue(ctx); n != 1 {
+		t.Fatalf("claimed %d deliveries after redelivery, want 1", n)
+	}
+	if got := queue.get(7); got.Status != domain.WebhookDeliveryDelivered || got.Attempts != 1 || got.LastError != nil {
+		t.Errorf("delivery %+v", got)
+	}
+	if partner.received.Load() != 2 {
+		t.Errorf("partner received %d deliveries, want 2", partner.received.Load())
+	}
+}
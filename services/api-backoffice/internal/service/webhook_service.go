@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	domain "e-kyc/services/api-backoffice/internal/domain"
+)
+
+type WebhookService struct {
+	repo domain.WebhookRepository
+}
+
+var _ domain.WebhookService = (*WebhookService)(nil)
+
+func NewWebhookService(repo domain.WebhookRepository) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return s.repo.ListWebhookSubscriptions(ctx)
+}
+
+// CreateSubscription registers a partner endpoint. A secret is generated when none is
+// given; it is returned only in this response.
+func (s *WebhookService) CreateSubscription(ctx context.Context, params domain.CreateWebhookSubscriptionParams) (*domain.WebhookSubscription, error) {
+	name := strings.TrimSpace(params.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: nama wajib diisi", domain.ErrInvalidState)
+	}
+	endpoint, err := validateWebhookURL(params.URL)
+	if err != nil {
+		return nil, err
+	}
+	eventTypes, err := normalizeWebhookEventTypes(params.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	secret := strings.TrimSpace(params.Secret)
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(buf)
+	} else if len(secret) < 16 {
+		return nil, fmt.Errorf("%w: secret minimal 16 karakter", domain.ErrInvalidState)
+	}
+	return s.repo.CreateWebhookSubscription(ctx, domain.WebhookSubscription{
+		Name:       name,
+		URL:        endpoint,
+		Secret:     secret,
+		EventTypes: eventTypes,
+	})
+}
+
+func (s *WebhookService) UpdateSubscription(ctx context.Context, params domain.UpdateWebhookSubscriptionParams) (*domain.WebhookSubscription, error) {
+	if params.Name != nil {
+		name := strings.TrimSpace(*params.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: nama wajib diisi", domain.ErrInvalidState)
+		}
+		params.Name = &name
+	}
+	if params.URL != nil {
+		endpoint, err := validateWebhookURL(*params.URL)
+		if err != nil {
+			return nil, err
+		}
+		params.URL = &endpoint
+	}
+	if params.EventTypes != nil {
+		eventTypes, err := normalizeWebhookEventTypes(*params.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		params.EventTypes = &eventTypes
+	}
+	return s.repo.UpdateWebhookSubscription(ctx, params)
+}
+
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id string) error {
+	return s.repo.DeleteWebhookSubscription(ctx, id)
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, params domain.ListWebhookDeliveriesParams) ([]domain.WebhookDelivery, error) {
+	params.Status = strings.ToUpper(strings.TrimSpace(params.Status))
+	return s.repo.ListWebhookDeliveries(ctx, params)
+}
+
+// Redeliver queues a delivery again with a fresh attempt budget, typically a dead one
+// after the partner fixed their endpoint.
+func (s *WebhookService) Redeliver(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	return s.repo.RedeliverWebhook(ctx, id)
+}
+
+func validateWebhookURL(raw string) (string, error) {
+	endpoint := strings.TrimSpace(raw)
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		return "", fmt.Errorf("%w: url webhook harus http(s) absolut", domain.ErrInvalidState)
+	}
+	return endpoint, nil
+}
+
+func normalizeWebhookEventTypes(types []string) ([]string, error) {
+	out := []string{}
+	for _, raw := range types {
+		eventType := strings.ToLower(strings.TrimSpace(raw))
+		if eventType != domain.WebhookEventAll && !slices.Contains(domain.WebhookEventTypes, eventType) {
+			return nil, fmt.Errorf("%w: event %q tidak dikenal", domain.ErrInvalidState, raw)
+		}
+		if !slices.Contains(out, eventType) {
+			out = append(out, eventType)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("%w: pilih minimal satu event", domain.ErrInvalidState)
+	}
+	return out, nil
+}
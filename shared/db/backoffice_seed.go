@@ -7,15 +7,18 @@ import (
 	"strings"
 	"time"
 
+	"e-kyc/shared/pii"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // SeedBackoffice populates the shared Postgres with beneficiary + submission data
 // consumed by both the self-service (react-main) and backoffice portals.
-func SeedBackoffice(ctx context.Context, pool *pgxpool.Pool) error {
+// NIK, phone and email are sealed with keys.
+func SeedBackoffice(ctx context.Context, pool *pgxpool.Pool, keys *pii.Keyring) error {
 	return WithTx(ctx, pool, func(tx pgx.Tx) error {
-		if err := seedUsers(ctx, tx); err != nil {
+		if err := seedUsers(ctx, tx, keys); err != nil {
 			return err
 		}
 		if err := seedBeneficiaries(ctx, tx); err != nil {
@@ -447,15 +450,33 @@ var operatorSeeds = []operatorSeed{
 }
 
 type configSeed struct {
-	Period     string
-	Thresholds map[string]any
-	Features   map[string]any
+	Period       string
+	Thresholds   map[string]any
+	Features     map[string]any
+	Retention    map[string]any
+	Scheduling   map[string]any
+	Disbursement map[string]any
 }
 
 var cfgSeed = configSeed{
 	Period:     "2025-Q4",
-	Thresholds: map[string]any{"ocr_min": 0.8, "face_min": 0.8},
-	Features:   map[string]any{"enableAppeal": true, "enableOfflineTKSK": true},
+	Thresholds: map[string]any{"ocr_min": 0.8, "face_min": 0.8, "ekyc_max_attempts": 3, "geofence_max_distance_m": 2000, "photo_time_tolerance_minutes": 240, "photo_max_distance_m": 300, "photo_reuse_max_hash_distance": 6},
+	Features:   map[string]any{"enableAppeal": true, "enableOfflineTKSK": true, "requireVerifiedVisit": false},
+	Retention:  map[string]any{"id_card_days": 365, "selfie_with_id_days": 365, "recorded_video_days": 90},
+	Scheduling: map[string]any{
+		"visit_duration_minutes": 90,
+		"work_start":             "08:00",
+		"work_end":               "16:00",
+		"work_days":              []int{1, 2, 3, 4, 5},
+		"timezone":               "Asia/Jakarta",
+	},
+	Disbursement: map[string]any{
+		"currency":       "IDR",
+		"amounts":        map[string]any{"PBI": 400000, "BPNT": 200000},
+		"debtor_name":    "Dinas Sosial Kota Batam",
+		"debtor_account": "0011223344",
+		"debtor_bic":     "BRINIDJA",
+	},
 }
 
 type distributionSeed struct {
@@ -607,20 +628,26 @@ func seedBeneficiaries(ctx context.Context, tx pgx.Tx) error {
 	return nil
 }
 
-func seedUsers(ctx context.Context, tx pgx.Tx) error {
+func seedUsers(ctx context.Context, tx pgx.Tx, keys *pii.Keyring) error {
 	for _, u := range operatorSeeds {
+		sealed, err := keys.SealUser(u.NIK, u.Phone, u.Email)
+		if err != nil {
+			return fmt.Errorf("seal operator user %s: %w", u.ID, err)
+		}
 		if _, err := tx.Exec(ctx, `
             INSERT INTO users (
-                id, role, nik, name, phone, email, pin_hash,
+                id, role, name, pin_hash,
                 region_prov, region_kab, region_kec, region_kel,
-                region_scope, metadata)
-            VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13::jsonb)
+                region_scope, metadata,
+                nik_enc, nik_bidx, phone_enc, phone_bidx, email_enc, email_bidx)
+            VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10::jsonb,$11,$12,$13,$14,$15,$16)
             ON CONFLICT (id) DO UPDATE
             SET role = EXCLUDED.role,
-                nik = EXCLUDED.nik,
                 name = EXCLUDED.name,
-                phone = EXCLUDED.phone,
-                email = EXCLUDED.email,
+                nik = NULL, phone = NULL, email = NULL,
+                nik_enc = EXCLUDED.nik_enc, nik_bidx = EXCLUDED.nik_bidx,
+                phone_enc = EXCLUDED.phone_enc, phone_bidx = EXCLUDED.phone_bidx,
+                email_enc = EXCLUDED.email_enc, email_bidx = EXCLUDED.email_bidx,
                 pin_hash = EXCLUDED.pin_hash,
                 region_prov = EXCLUDED.region_prov,
                 region_kab = EXCLUDED.region_kab,
@@ -629,9 +656,11 @@ func seedUsers(ctx context.Context, tx pgx.Tx) error {
                 region_scope = EXCLUDED.region_scope,
                 metadata = EXCLUDED.metadata,
                 updated_at = NOW()`,
-			u.ID, u.Role, u.NIK, u.Name, u.Phone, u.Email, hashPIN(u.PIN),
+			u.ID, u.Role, u.Name, hashPIN(u.PIN),
 			u.Region.Prov, u.Region.Kab, u.Region.Kec, u.Region.Kel,
 			u.RegionScope, mustJSON(map[string]any{"type": "operator"}),
+			sealed.NIK.Sealed, sealed.NIK.Index, sealed.Phone.Sealed, sealed.Phone.Index,
+			sealed.Email.Sealed, sealed.Email.Index,
 		); err != nil {
 			return fmt.Errorf("seed operator user %s: %w", u.ID, err)
 		}
@@ -642,18 +671,24 @@ func seedUsers(ctx context.Context, tx pgx.Tx) error {
 		if err != nil {
 			return err
 		}
+		sealed, err := keys.SealUser(b.Nik, b.Phone, b.Email)
+		if err != nil {
+			return fmt.Errorf("seal beneficiary user %s: %w", b.ID, err)
+		}
 		if _, err := tx.Exec(ctx, `
             INSERT INTO users (
-                id, role, nik, name, dob, phone, email, pin_hash,
+                id, role, name, dob, pin_hash,
                 region_prov, region_kab, region_kec, region_kel,
-                region_scope, metadata)
-            VALUES ($1,'BENEFICIARY',$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13::jsonb)
+                region_scope, metadata,
+                nik_enc, nik_bidx, phone_enc, phone_bidx, email_enc, email_bidx)
+            VALUES ($1,'BENEFICIARY',$2,$3,$4,$5,$6,$7,$8,$9,$10::jsonb,$11,$12,$13,$14,$15,$16)
             ON CONFLICT (id) DO UPDATE
-            SET nik = EXCLUDED.nik,
-                name = EXCLUDED.name,
+            SET name = EXCLUDED.name,
                 dob = EXCLUDED.dob,
-                phone = EXCLUDED.phone,
-                email = EXCLUDED.email,
+                nik = NULL, phone = NULL, email = NULL,
+                nik_enc = EXCLUDED.nik_enc, nik_bidx = EXCLUDED.nik_bidx,
+                phone_enc = EXCLUDED.phone_enc, phone_bidx = EXCLUDED.phone_bidx,
+                email_enc = EXCLUDED.email_enc, email_bidx = EXCLUDED.email_bidx,
                 pin_hash = EXCLUDED.pin_hash,
                 region_prov = EXCLUDED.region_prov,
                 region_kab = EXCLUDED.region_kab,
@@ -662,10 +697,12 @@ func seedUsers(ctx context.Context, tx pgx.Tx) error {
                 region_scope = EXCLUDED.region_scope,
                 metadata = EXCLUDED.metadata,
                 updated_at = NOW()`,
-			b.ID, b.Nik, b.Name, dob, b.Phone, b.Email, hashPIN(b.Portal.PIN),
+			b.ID, b.Name, dob, hashPIN(b.Portal.PIN),
 			b.Region.Prov, b.Region.Kab, b.Region.Kec, b.Region.Kel,
 			[]string{b.Region.Kab},
 			mustJSON(map[string]any{"verificationStatus": b.Portal.VerificationStatus}),
+			sealed.NIK.Sealed, sealed.NIK.Index, sealed.Phone.Sealed, sealed.Phone.Index,
+			sealed.Email.Sealed, sealed.Email.Index,
 		); err != nil {
 			return fmt.Errorf("seed beneficiary user %s: %w", b.ID, err)
 		}
@@ -675,14 +712,18 @@ func seedUsers(ctx context.Context, tx pgx.Tx) error {
 
 func seedConfig(ctx context.Context, tx pgx.Tx) error {
 	if _, err := tx.Exec(ctx, `
-        INSERT INTO system_config (id, period, thresholds, features)
-        VALUES (1, $1, $2::jsonb, $3::jsonb)
+        INSERT INTO system_config (id, period, thresholds, features, retention, scheduling, disbursement)
+        VALUES (1, $1, $2::jsonb, $3::jsonb, $4::jsonb, $5::jsonb, $6::jsonb)
         ON CONFLICT (id) DO UPDATE
         SET period = EXCLUDED.period,
             thresholds = EXCLUDED.thresholds,
             features = EXCLUDED.features,
+            retention = EXCLUDED.retention,
+            scheduling = EXCLUDED.scheduling,
+            disbursement = EXCLUDED.disbursement,
             updated_at = NOW()`,
-		cfgSeed.Period, mustJSON(cfgSeed.Thresholds), mustJSON(cfgSeed.Features),
+		cfgSeed.Period, mustJSON(cfgSeed.Thresholds), mustJSON(cfgSeed.Features), mustJSON(cfgSeed.Retention),
+		mustJSON(cfgSeed.Scheduling), mustJSON(cfgSeed.Disbursement),
 	); err != nil {
 		return fmt.Errorf("seed config: %w", err)
 	}
@@ -829,12 +870,13 @@ func seedApplications(ctx context.Context, tx pgx.Tx) error {
 func seedBatches(ctx context.Context, tx pgx.Tx) error {
 	if _, err := tx.Exec(ctx, `
         INSERT INTO batches (id, code, status, checksum)
-        VALUES ($1,$2,$3,$4)
+        VALUES ($1,$2,$3,NULL)
         ON CONFLICT (id) DO UPDATE SET
             code=EXCLUDED.code,
             status=EXCLUDED.status,
-            checksum=EXCLUDED.checksum`,
-		"BATCH-001", "BAT-2025Q4-001", "DRAFT", "mock-checksum",
+            checksum=NULL,
+            locked_at=NULL`,
+		"BATCH-001", "BAT-2025Q4-001", "DRAFT",
 	); err != nil {
 		return err
 	}
@@ -2,27 +2,58 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
+	"e-kyc/shared/nik"
+	"e-kyc/shared/pii"
+
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// SyncBeneficiaries inserts/updates users and beneficiary rows from dataset records in a single transaction.
+// SyncStats summarises a SyncBeneficiaries run.
 type SyncStats struct {
 	UsersInserted       int
 	UsersUpdated        int
 	BeneficiariesLinked int
+	RowsFlagged         int
+	Rejected            []RowRejection
+}
+
+// RowRejection is a dataset row skipped because its NIK is malformed. Nik only keeps
+// the last 4 digits so rejections can be logged.
+type RowRejection struct {
+	RowNo  int
+	Nik    string
+	Reason string
 }
 
-func SyncBeneficiaries(ctx context.Context, pool *pgxpool.Pool, records []DatasetRecord) (SyncStats, error) {
+// SyncBeneficiaries inserts/updates users and beneficiary rows from dataset records in a single transaction.
+// Rows with a malformed NIK are skipped and reported; rows whose NIK disagrees with the dataset birth
+// year are imported with the mismatch recorded in users.metadata.nikMismatches. NIKs are stored sealed
+// with keys and matched through their blind index.
+func SyncBeneficiaries(ctx context.Context, pool *pgxpool.Pool, keys *pii.Keyring, records []DatasetRecord) (SyncStats, error) {
 	var stats SyncStats
 	err := WithTx(ctx, pool, func(tx pgx.Tx) error {
 		for _, rec := range records {
-			userID, created, err := ensureUser(ctx, tx, rec)
+			parsed, err := nik.Parse(rec.Nik)
+			if err != nil {
+				stats.Rejected = append(stats.Rejected, RowRejection{RowNo: rec.RowNo, Nik: maskNik(rec.Nik), Reason: err.Error()})
+				continue
+			}
+			rec.Nik = parsed.Raw
+			birthYear, _ := strconv.Atoi(rec.Fields["birthYear"])
+			mismatches := parsed.CrossCheck(nik.Claims{BirthYear: birthYear})
+			if len(mismatches) > 0 {
+				stats.RowsFlagged++
+			}
+
+			userID, created, err := ensureUser(ctx, tx, keys, rec, mismatches)
 			if err != nil {
-				return fmt.Errorf("row %d (%s): ensure user: %w", rec.RowNo, rec.Nik, err)
+				return fmt.Errorf("row %d (%s): ensure user: %w", rec.RowNo, maskNik(rec.Nik), err)
 			}
 			if created {
 				stats.UsersInserted++
@@ -31,7 +62,7 @@ func SyncBeneficiaries(ctx context.Context, pool *pgxpool.Pool, records []Datase
 			}
 			inserted, err := ensureBeneficiary(ctx, tx, userID, rec)
 			if err != nil {
-				return fmt.Errorf("row %d (%s): ensure beneficiary: %w", rec.RowNo, rec.Nik, err)
+				return fmt.Errorf("row %d (%s): ensure beneficiary: %w", rec.RowNo, maskNik(rec.Nik), err)
 			}
 			if inserted {
 				stats.BeneficiariesLinked++
@@ -42,50 +73,63 @@ func SyncBeneficiaries(ctx context.Context, pool *pgxpool.Pool, records []Datase
 	return stats, err
 }
 
-func ensureUser(ctx context.Context, tx pgx.Tx, rec DatasetRecord) (string, bool, error) {
+func ensureUser(ctx context.Context, tx pgx.Tx, keys *pii.Keyring, rec DatasetRecord, mismatches []nik.Mismatch) (string, bool, error) {
+	if mismatches == nil {
+		mismatches = []nik.Mismatch{}
+	}
+	mismatchJSON, _ := json.Marshal(mismatches)
 	var id string
-	err := tx.QueryRow(ctx, `SELECT id FROM users WHERE nik = $1`, rec.Nik).Scan(&id)
+	err := tx.QueryRow(ctx, `SELECT id FROM users WHERE nik_bidx = ANY($1)`, keys.BlindIndexes(pii.FieldNIK, rec.Nik)).Scan(&id)
 	switch err {
 	case nil:
 		if _, err := tx.Exec(ctx, `
 			UPDATE users
 			   SET name = $1,
-			       metadata = metadata || jsonb_build_object('datasetRow', $2::int),
+			       metadata = metadata || jsonb_build_object('datasetRow', $2::int, 'nikMismatches', $4::jsonb),
 			       updated_at = NOW()
 			 WHERE id = $3`,
 			rec.Name,
 			rec.RowNo,
 			id,
+			mismatchJSON,
 		); err != nil {
-			return "", false, fmt.Errorf("update user %s: %w", rec.Nik, err)
+			return "", false, fmt.Errorf("update user: %w", err)
 		}
 		return id, false, nil
 	default:
 		if err != pgx.ErrNoRows {
-			return "", false, fmt.Errorf("lookup user %s: %w", rec.Nik, err)
+			return "", false, fmt.Errorf("lookup user: %w", err)
 		}
 	}
 
+	sealed, err := keys.SealColumn(pii.FieldNIK, rec.Nik)
+	if err != nil {
+		return "", false, fmt.Errorf("seal nik: %w", err)
+	}
 	var newID string
 	err = tx.QueryRow(ctx, `
-		INSERT INTO users (role, nik, name, metadata)
+		INSERT INTO users (role, nik_enc, nik_bidx, name, metadata)
 		VALUES (
 			'BENEFICIARY',
 			$1,
 			$2,
+			$3,
 			jsonb_build_object(
-				'datasetRow', $3::int,
-				'datasetSeededAt', $4::timestamptz
+				'datasetRow', $4::int,
+				'datasetSeededAt', $5::timestamptz,
+				'nikMismatches', $6::jsonb
 			)
 		)
 		RETURNING id`,
-		rec.Nik,
+		sealed.Sealed,
+		sealed.Index,
 		rec.Name,
 		rec.RowNo,
 		time.Now().UTC(),
+		mismatchJSON,
 	).Scan(&newID)
 	if err != nil {
-		return "", false, fmt.Errorf("insert user %s: %w", rec.Nik, err)
+		return "", false, fmt.Errorf("insert user: %w", err)
 	}
 	return newID, true, nil
 }
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	shareddb "e-kyc/shared/db"
+	"e-kyc/shared/pii"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -30,9 +31,26 @@ func main() {
 		skipSeedFlag    = flag.Bool("skip-seed", false, "Skip running base seed data")
 		skipDatasetFlag = flag.Bool("skip-dataset", false, "Skip syncing beneficiary dataset")
 		cleanFlag       = flag.Bool("clean", false, "Drop and recreate schema before running migrations")
+		skipEncryptFlag = flag.Bool("skip-encrypt", false, "Skip sealing plaintext PII left in users and ekyc_sessions")
+		rotateKeyFlag   = flag.Bool("rotate-pii-key", false, "Re-wrap sealed PII under the active master key and recompute blind indexes")
+		genKeyFlag      = flag.String("gen-pii-key", "", "Print a new master key entry with the given id and exit")
 	)
 	flag.Parse()
 
+	if *genKeyFlag != "" {
+		entry, err := pii.GenerateKey(*genKeyFlag)
+		if err != nil {
+			log.Fatalf("generate pii key: %v", err)
+		}
+		fmt.Println(entry)
+		return
+	}
+
+	keys, err := pii.LoadKeyring()
+	if err != nil {
+		log.Fatalf("load pii keyring: %v", err)
+	}
+
 	dsn := firstNonEmpty(*dsnFlag, os.Getenv("BACKOFFICE_DB_DSN"), defaultDSN)
 	workbook := firstNonEmpty(*workbookFlag, os.Getenv("BENEFICIARY_DATASET_PATH"), defaultWorkbook)
 
@@ -57,17 +75,34 @@ func main() {
 	}
 
 	if !*skipSeedFlag {
-		if err := shareddb.SeedBackoffice(ctx, pool); err != nil {
+		if err := shareddb.SeedBackoffice(ctx, pool, keys); err != nil {
 			log.Fatalf("seed backoffice: %v", err)
 		}
 	}
 
 	if !*skipDatasetFlag {
-		if err := syncDataset(ctx, pool, workbook); err != nil {
+		if err := syncDataset(ctx, pool, keys, workbook); err != nil {
 			log.Fatalf("sync dataset: %v", err)
 		}
 	}
 
+	if !*skipEncryptFlag {
+		stats, err := shareddb.EncryptPII(ctx, pool, keys)
+		if err != nil {
+			log.Fatalf("encrypt pii: %v", err)
+		}
+		log.Printf("pii: sealed %d users, %d ekyc session applicants, %d ocr results", stats.Users, stats.Sessions, stats.OcrResults)
+	}
+
+	if *rotateKeyFlag {
+		stats, err := shareddb.RotatePIIKey(ctx, pool, keys)
+		if err != nil {
+			log.Fatalf("rotate pii key: %v", err)
+		}
+		log.Printf("pii: re-wrapped %d users, %d ekyc session applicants, %d ocr results, %d payout accounts under key %s",
+			stats.Users, stats.Sessions, stats.OcrResults, stats.Beneficiaries, keys.ActiveKeyID())
+	}
+
 	log.Println("clutch tasks completed successfully")
 }
 
@@ -103,7 +138,7 @@ func applyMigrations(ctx context.Context, pool *pgxpool.Pool) error {
 	return nil
 }
 
-func syncDataset(ctx context.Context, pool *pgxpool.Pool, workbook string) error {
+func syncDataset(ctx context.Context, pool *pgxpool.Pool, keys *pii.Keyring, workbook string) error {
 	start := time.Now()
 	records, err := shareddb.LoadBeneficiaryDataset(workbook)
 	if err != nil {
@@ -114,11 +149,15 @@ func syncDataset(ctx context.Context, pool *pgxpool.Pool, workbook string) error
 		return nil
 	}
 	log.Printf("syncing %d beneficiaries from %s", len(records), workbook)
-	stats, err := shareddb.SyncBeneficiaries(ctx, pool, records)
+	stats, err := shareddb.SyncBeneficiaries(ctx, pool, keys, records)
 	if err != nil {
 		return err
 	}
 	log.Printf("users: %d inserted, %d reconciled · beneficiaries linked: %d", stats.UsersInserted, stats.UsersUpdated, stats.BeneficiariesLinked)
+	log.Printf("nik: %d rows flagged, %d rows rejected", stats.RowsFlagged, len(stats.Rejected))
+	for _, rej := range stats.Rejected {
+		log.Printf("  row %d (%s): %s", rej.RowNo, rej.Nik, rej.Reason)
+	}
 	log.Printf("dataset sync completed in %s", time.Since(start).Round(time.Millisecond))
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	shareddb "e-kyc/shared/db"
+	"e-kyc/shared/pii"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -39,6 +40,11 @@ func main() {
 		return
 	}
 
+	keys, err := pii.LoadKeyring()
+	if err != nil {
+		log.Fatalf("load pii keyring: %v", err)
+	}
+
 	pool, err := pgxpool.New(ctx, dsn)
 	if err != nil {
 		log.Fatalf("connect postgres: %v", err)
@@ -47,11 +53,15 @@ func main() {
 
 	start := time.Now()
 	log.Printf("Seeding %d beneficiaries from %s", len(records), workbook)
-	stats, err := shareddb.SyncBeneficiaries(ctx, pool, records)
+	stats, err := shareddb.SyncBeneficiaries(ctx, pool, keys, records)
 	if err != nil {
 		log.Fatalf("seed beneficiaries: %v", err)
 	}
 	log.Printf("users: %d inserted, %d reconciled · beneficiaries linked: %d", stats.UsersInserted, stats.UsersUpdated, stats.BeneficiariesLinked)
+	log.Printf("nik: %d rows flagged, %d rows rejected", stats.RowsFlagged, len(stats.Rejected))
+	for _, rej := range stats.Rejected {
+		log.Printf("  row %d (%s): %s", rej.RowNo, rej.Nik, rej.Reason)
+	}
 	log.Printf("Dataset sync completed in %s", time.Since(start).Round(time.Millisecond))
 }
 
@@ -0,0 +1,8 @@
+-- Keep every face-match and liveness attempt instead of overwriting earlier results.
+ALTER TABLE face_checks ADD COLUMN IF NOT EXISTS attempt_no INT NOT NULL DEFAULT 1;
+ALTER TABLE face_checks ADD COLUMN IF NOT EXISTS is_current BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE liveness_checks ADD COLUMN IF NOT EXISTS attempt_no INT NOT NULL DEFAULT 1;
+ALTER TABLE liveness_checks ADD COLUMN IF NOT EXISTS is_current BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE INDEX IF NOT EXISTS idx_face_checks_session_attempt ON face_checks(ekyc_session_id, attempt_no);
+CREATE INDEX IF NOT EXISTS idx_liveness_checks_session_attempt ON liveness_checks(ekyc_session_id, attempt_no);
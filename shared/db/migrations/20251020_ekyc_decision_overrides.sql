@@ -0,0 +1,31 @@
+-- Managed reason codes and history for manual eKYC decision overrides.
+CREATE TABLE IF NOT EXISTS decision_override_reasons (
+    code TEXT PRIMARY KEY,
+    label TEXT NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO decision_override_reasons (code, label) VALUES
+    ('DOCUMENT_VERIFIED_MANUALLY', 'Dokumen diverifikasi manual oleh petugas'),
+    ('FACE_MATCH_FALSE_NEGATIVE', 'Hasil face match keliru (foto sah)'),
+    ('LIVENESS_FALSE_NEGATIVE', 'Hasil liveness keliru (pemohon hadir)'),
+    ('FIELD_VISIT_CONFIRMED', 'Dikonfirmasi melalui kunjungan lapangan'),
+    ('FRAUD_SUSPECTED', 'Indikasi pemalsuan identitas'),
+    ('DUPLICATE_APPLICANT', 'Pemohon terdaftar ganda'),
+    ('OTHER', 'Lainnya')
+ON CONFLICT (code) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS ekyc_decision_overrides (
+    id BIGSERIAL PRIMARY KEY,
+    ekyc_session_id UUID NOT NULL REFERENCES ekyc_sessions(id) ON DELETE CASCADE,
+    previous_decision TEXT NOT NULL,
+    new_decision TEXT NOT NULL,
+    reason_code TEXT NOT NULL REFERENCES decision_override_reasons(code),
+    reason_text TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    actor_role TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ekyc_decision_overrides_session ON ekyc_decision_overrides(ekyc_session_id, created_at);
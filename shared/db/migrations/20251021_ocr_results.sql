@@ -0,0 +1,14 @@
+-- Latest KTP OCR extraction per eKYC session and its comparison with the declared data.
+CREATE TABLE IF NOT EXISTS ocr_results (
+    ekyc_session_id UUID PRIMARY KEY REFERENCES ekyc_sessions(id) ON DELETE CASCADE,
+    nik TEXT,
+    name TEXT,
+    birth_date DATE,
+    address TEXT,
+    confidence JSONB NOT NULL DEFAULT '{}'::jsonb,
+    raw_metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    comparison JSONB NOT NULL DEFAULT '[]'::jsonb,
+    score DOUBLE PRECISION,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,11 @@
+-- Ordered log of eKYC session state changes, streamed to clients over SSE.
+-- Rows are announced with NOTIFY ekyc_session_events so every backoffice replica sees them.
+CREATE TABLE IF NOT EXISTS ekyc_session_events (
+    id BIGSERIAL PRIMARY KEY,
+    ekyc_session_id UUID NOT NULL REFERENCES ekyc_sessions(id) ON DELETE CASCADE,
+    event_type TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ekyc_session_events_session ON ekyc_session_events(ekyc_session_id, id);
@@ -0,0 +1,21 @@
+-- Manual review queue: one active claim per session and the verdicts reviewers record.
+CREATE TABLE IF NOT EXISTS ekyc_review_claims (
+    ekyc_session_id UUID PRIMARY KEY REFERENCES ekyc_sessions(id) ON DELETE CASCADE,
+    reviewer TEXT NOT NULL,
+    claimed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS ekyc_review_verdicts (
+    id BIGSERIAL PRIMARY KEY,
+    ekyc_session_id UUID NOT NULL REFERENCES ekyc_sessions(id) ON DELETE CASCADE,
+    verdict TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    reviewer TEXT NOT NULL,
+    reviewer_role TEXT NOT NULL,
+    signals JSONB NOT NULL DEFAULT '[]'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ekyc_review_verdicts_session ON ekyc_review_verdicts(ekyc_session_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ekyc_sessions_review ON ekyc_sessions(updated_at) WHERE final_decision = 'PENDING';
@@ -0,0 +1,6 @@
+-- Supports the eKYC funnel report, which scans sessions by creation time and looks up
+-- when each decision was first made.
+CREATE INDEX IF NOT EXISTS idx_ekyc_sessions_created_at ON ekyc_sessions(created_at);
+CREATE INDEX IF NOT EXISTS idx_ekyc_session_events_decision
+    ON ekyc_session_events(ekyc_session_id, created_at)
+    WHERE event_type = 'decision.made';
@@ -0,0 +1,15 @@
+-- Field-level encryption for users PII. *_enc holds the envelope-sealed value and *_bidx
+-- its blind index (keyed HMAC of the normalized value), which replaces the plaintext
+-- columns for lookups and uniqueness. `clutch` seals existing rows and clears the
+-- plaintext columns. Run it before deploying a backoffice build that reads these columns.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS nik_enc TEXT,
+    ADD COLUMN IF NOT EXISTS nik_bidx TEXT,
+    ADD COLUMN IF NOT EXISTS phone_enc TEXT,
+    ADD COLUMN IF NOT EXISTS phone_bidx TEXT,
+    ADD COLUMN IF NOT EXISTS email_enc TEXT,
+    ADD COLUMN IF NOT EXISTS email_bidx TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_nik_bidx_idx ON users (nik_bidx) WHERE nik_bidx IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_bidx_idx ON users (phone_bidx) WHERE phone_bidx IS NOT NULL;
+CREATE INDEX IF NOT EXISTS users_email_bidx_idx ON users (email_bidx) WHERE email_bidx IS NOT NULL;
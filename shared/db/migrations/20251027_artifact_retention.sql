@@ -0,0 +1,35 @@
+-- Retention of biometric artifacts. Periods live in system_config.retention as
+-- {"id_card_days": n, "selfie_with_id_days": n, "recorded_video_days": n}. The purge job
+-- deletes due media, nulls the session URL and leaves a tombstone behind.
+ALTER TABLE system_config ADD COLUMN IF NOT EXISTS retention JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE TABLE IF NOT EXISTS ekyc_artifact_tombstones (
+    id BIGSERIAL PRIMARY KEY,
+    ekyc_session_id UUID NOT NULL REFERENCES ekyc_sessions(id) ON DELETE CASCADE,
+    artifact TEXT NOT NULL,
+    media_url TEXT NOT NULL,
+    retention_days INTEGER NOT NULL,
+    purged_by TEXT NOT NULL,
+    purged_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ekyc_artifact_tombstones_session
+    ON ekyc_artifact_tombstones (ekyc_session_id, purged_at DESC);
+
+-- An active hold (released_at IS NULL) exempts its session or application from purging.
+-- Applications share the id of the session they were built from.
+CREATE TABLE IF NOT EXISTS legal_holds (
+    id BIGSERIAL PRIMARY KEY,
+    scope TEXT NOT NULL CHECK (scope IN ('SESSION', 'APPLICATION')),
+    target_id TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    placed_by TEXT NOT NULL,
+    placed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    released_by TEXT,
+    released_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS legal_holds_active_target_idx
+    ON legal_holds (scope, target_id) WHERE released_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_legal_holds_active_target
+    ON legal_holds (target_id) WHERE released_at IS NULL;
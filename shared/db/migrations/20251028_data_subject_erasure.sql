@@ -0,0 +1,20 @@
+-- Erasure requests of a data subject (beneficiary). A request is approved by a second
+-- operator. Approval pseudonymizes the subject's rows in place so aggregates and
+-- audit_logs stay intact, and records per-table counts in summary.
+CREATE TABLE IF NOT EXISTS erasure_requests (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'COMPLETED', 'REJECTED')),
+    requested_by TEXT NOT NULL,
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_by TEXT,
+    reviewed_at TIMESTAMPTZ,
+    review_note TEXT,
+    summary JSONB NOT NULL DEFAULT '{}'::jsonb
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS erasure_requests_pending_user_idx
+    ON erasure_requests (user_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_erasure_requests_status
+    ON erasure_requests (status, requested_at DESC);
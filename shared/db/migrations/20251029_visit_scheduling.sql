@@ -0,0 +1,7 @@
+-- TKSK visit scheduling. system_config.scheduling holds the visit duration and working
+-- hours ({"visit_duration_minutes", "work_start", "work_end", "work_days", "timezone"}),
+-- missing keys fall back to the defaults in api-backoffice.
+ALTER TABLE system_config ADD COLUMN IF NOT EXISTS scheduling JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_application_visits_tksk_scheduled
+    ON application_visits (tksk_id, scheduled_at);
@@ -0,0 +1,32 @@
+-- Offline TKSK sync. Every write to a visit bumps version (checked against the base
+-- version of an offline mutation) and takes a fresh change_seq. A device's sync token is
+-- the highest change_seq of its TKSK's visits at the time of its last sync.
+CREATE SEQUENCE IF NOT EXISTS application_visits_change_seq;
+
+ALTER TABLE application_visits
+    ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ADD COLUMN IF NOT EXISTS change_seq BIGINT NOT NULL DEFAULT nextval('application_visits_change_seq');
+
+CREATE INDEX IF NOT EXISTS idx_application_visits_tksk_change
+    ON application_visits (tksk_id, change_seq);
+
+CREATE TABLE IF NOT EXISTS tksk_devices (
+    device_id TEXT PRIMARY KEY,
+    tksk_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_sync_token BIGINT NOT NULL DEFAULT 0,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Applied mutations, so a batch resent after a lost response is not applied twice.
+CREATE TABLE IF NOT EXISTS visit_sync_mutations (
+    device_id TEXT NOT NULL REFERENCES tksk_devices(device_id) ON DELETE CASCADE,
+    mutation_id TEXT NOT NULL,
+    visit_id TEXT NOT NULL REFERENCES application_visits(id) ON DELETE CASCADE,
+    base_version INT NOT NULL,
+    version INT NOT NULL,
+    client_recorded_at TIMESTAMPTZ NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (device_id, mutation_id)
+);
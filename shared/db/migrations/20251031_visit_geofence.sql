@@ -0,0 +1,9 @@
+-- Geofence result of a submitted visit. geofence_flags lists what failed (empty when the
+-- geotag is inside the beneficiary's kelurahan and close enough), geofence_kelurahan is
+-- the kelurahan the geotag fell in and geofence_distance_m its distance to the
+-- registered kelurahan's centroid.
+ALTER TABLE application_visits
+    ADD COLUMN IF NOT EXISTS geofence_flags TEXT[] NOT NULL DEFAULT ARRAY[]::text[],
+    ADD COLUMN IF NOT EXISTS geofence_kelurahan TEXT,
+    ADD COLUMN IF NOT EXISTS geofence_distance_m DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS geofence_checked_at TIMESTAMPTZ;
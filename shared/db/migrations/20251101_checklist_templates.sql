@@ -0,0 +1,21 @@
+-- Visit checklist templates. Versions of a (program, period) are immutable once
+-- published. A visit is bound to the newest published version when it is scheduled and
+-- keeps validating against it, so publishing or retiring a version never affects visits
+-- in flight. program is the beneficiary's bansos_utama, or '*' for any program.
+CREATE TABLE IF NOT EXISTS checklist_templates (
+    id BIGSERIAL PRIMARY KEY,
+    program TEXT NOT NULL,
+    period TEXT NOT NULL,
+    version INT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PUBLISHED' CHECK (status IN ('PUBLISHED', 'RETIRED')),
+    schema JSONB NOT NULL,
+    notes TEXT,
+    published_by TEXT NOT NULL,
+    published_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    retired_by TEXT,
+    retired_at TIMESTAMPTZ,
+    UNIQUE (program, period, version)
+);
+
+ALTER TABLE application_visits
+    ADD COLUMN IF NOT EXISTS checklist_template_id BIGINT REFERENCES checklist_templates(id);
@@ -0,0 +1,22 @@
+-- Visit photos uploaded through the backoffice, with what their EXIF block says and how
+-- it compares with the visit. The URL is also appended to application_visits.photos.
+-- phash is the 64-bit difference hash of the image, used to find photos re-used across
+-- visits by Hamming distance.
+CREATE TABLE IF NOT EXISTS visit_photos (
+    id BIGSERIAL PRIMARY KEY,
+    visit_id TEXT NOT NULL REFERENCES application_visits(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    sha256 TEXT NOT NULL,
+    phash BIGINT,
+    captured_at TIMESTAMPTZ,
+    gps_lat DOUBLE PRECISION,
+    gps_lng DOUBLE PRECISION,
+    camera TEXT,
+    flags TEXT[] NOT NULL DEFAULT '{}',
+    time_delta_minutes INT,
+    distance_m DOUBLE PRECISION,
+    uploaded_by TEXT NOT NULL,
+    uploaded_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_visit_photos_visit ON visit_photos (visit_id, uploaded_at);
@@ -0,0 +1,17 @@
+-- Supervisor reviews of submitted visits. A visit is either verified or returned to its
+-- TKSK, who reopens and resubmits it. visit_version is the version the supervisor
+-- reviewed, before the review itself bumped it.
+CREATE TABLE IF NOT EXISTS visit_reviews (
+    id BIGSERIAL PRIMARY KEY,
+    visit_id TEXT NOT NULL REFERENCES application_visits(id) ON DELETE CASCADE,
+    decision TEXT NOT NULL CHECK (decision IN ('VERIFIED', 'RETURNED')),
+    comment TEXT NOT NULL DEFAULT '',
+    reviewer TEXT NOT NULL,
+    reviewer_role TEXT NOT NULL,
+    visit_version INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_visit_reviews_visit ON visit_reviews (visit_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_application_visits_submitted
+    ON application_visits (updated_at) WHERE status = 'SUBMITTED';
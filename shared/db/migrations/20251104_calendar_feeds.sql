@@ -0,0 +1,25 @@
+-- iCalendar feeds of TKSK visits and distributions. A user has at most one feed token,
+-- kept as its SHA-256 hex digest, and issuing a new one replaces it.
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_fetched_at TIMESTAMPTZ
+);
+
+-- Events as last published in a user's feed. fingerprint is a digest of what the event
+-- said, and sequence grows each time it changes so calendar apps replace their copy.
+-- Events that drop out of the feed are published as cancelled (cancelled_at) for a while
+-- before being forgotten. label is a PII-free title for the cancelled entry.
+CREATE TABLE IF NOT EXISTS calendar_feed_events (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    uid TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    sequence INT NOT NULL DEFAULT 0,
+    label TEXT NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    cancelled_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, uid)
+);
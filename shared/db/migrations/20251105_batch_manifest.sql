@@ -0,0 +1,13 @@
+-- Disbursement amounts per program live in system_config.disbursement as
+-- {"currency": "IDR", "amounts": {"<bansos_utama>": n}}, n in whole currency units.
+ALTER TABLE system_config ADD COLUMN IF NOT EXISTS disbursement JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+-- A batch is locked when it leaves DRAFT. Each item then keeps the program, masked NIK
+-- and amount it was locked with, and batches.checksum holds the SHA-256 of that manifest.
+ALTER TABLE batches ADD COLUMN IF NOT EXISTS locked_at TIMESTAMPTZ;
+
+ALTER TABLE batch_items
+    ADD COLUMN IF NOT EXISTS program TEXT,
+    ADD COLUMN IF NOT EXISTS nik_mask TEXT,
+    ADD COLUMN IF NOT EXISTS amount BIGINT,
+    ADD COLUMN IF NOT EXISTS currency TEXT;
@@ -0,0 +1,26 @@
+-- Where a beneficiary is paid. payout_channel is BANK_TRANSFER or a non-bank channel such
+-- as POS, payout_bank the BIC or local bank code and payout_account_enc the account or
+-- card number, sealed like users PII.
+ALTER TABLE beneficiaries
+    ADD COLUMN IF NOT EXISTS payout_channel TEXT,
+    ADD COLUMN IF NOT EXISTS payout_bank TEXT,
+    ADD COLUMN IF NOT EXISTS payout_account_enc TEXT;
+
+-- Bank files generated for a batch. checksum is the SHA-256 of the file as stored in
+-- media storage and manifest_checksum the batch checksum it was built from.
+CREATE TABLE IF NOT EXISTS batch_exports (
+    id BIGSERIAL PRIMARY KEY,
+    batch_id TEXT NOT NULL REFERENCES batches(id) ON DELETE CASCADE,
+    format TEXT NOT NULL,
+    filename TEXT NOT NULL,
+    media_url TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    manifest_checksum TEXT NOT NULL,
+    items INT NOT NULL,
+    total_amount BIGINT NOT NULL,
+    currency TEXT NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_batch_exports_batch ON batch_exports (batch_id, created_at);
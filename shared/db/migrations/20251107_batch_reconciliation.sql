@@ -0,0 +1,23 @@
+-- Outcome of each transfer as reported by the bank. result is DISBURSED or
+-- DISBURSEMENT_FAILED, result_reason the bank's reason for a failure.
+ALTER TABLE batch_items
+    ADD COLUMN IF NOT EXISTS result TEXT,
+    ADD COLUMN IF NOT EXISTS result_reason TEXT,
+    ADD COLUMN IF NOT EXISTS bank_reference TEXT,
+    ADD COLUMN IF NOT EXISTS reconciled_at TIMESTAMPTZ;
+
+-- Bank result files imported for a batch. checksum is the SHA-256 of the file as stored
+-- in media storage and report what the import matched, applied and left out.
+CREATE TABLE IF NOT EXISTS batch_reconciliations (
+    id BIGSERIAL PRIMARY KEY,
+    batch_id TEXT NOT NULL REFERENCES batches(id) ON DELETE CASCADE,
+    format TEXT NOT NULL,
+    filename TEXT NOT NULL,
+    media_url TEXT NOT NULL,
+    checksum TEXT NOT NULL,
+    report JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_batch_reconciliations_batch ON batch_reconciliations (batch_id, created_at);
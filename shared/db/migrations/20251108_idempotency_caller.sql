@@ -0,0 +1,9 @@
+-- Idempotency keys are scoped to the caller (the bearer token subject, '' when
+-- unauthenticated). reservation is the token of the request holding an unfinished key.
+ALTER TABLE idempotency_keys
+    ADD COLUMN IF NOT EXISTS caller TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS reservation TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (idempotency_key, method, path, caller);
@@ -0,0 +1,8 @@
+-- Field-level encryption for the KTP fields OCR extracts. *_enc holds the envelope-sealed
+-- value, birth_date_enc seals the date as YYYY-MM-DD. `clutch` seals existing rows and
+-- clears the plaintext columns, reads fall back to them until it has.
+ALTER TABLE ocr_results
+    ADD COLUMN IF NOT EXISTS nik_enc TEXT,
+    ADD COLUMN IF NOT EXISTS name_enc TEXT,
+    ADD COLUMN IF NOT EXISTS birth_date_enc TEXT,
+    ADD COLUMN IF NOT EXISTS address_enc TEXT;
@@ -0,0 +1,15 @@
+-- Media files an approved erasure has to delete. They are queued in the approval
+-- transaction and deleted after it commits, deleted_at marking each file done, so a
+-- storage failure leaves the rest queued for a retry instead of undoing the erasure.
+CREATE TABLE IF NOT EXISTS erasure_media (
+    id BIGSERIAL PRIMARY KEY,
+    erasure_request_id BIGINT NOT NULL REFERENCES erasure_requests(id) ON DELETE CASCADE,
+    media_url TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    deleted_at TIMESTAMPTZ,
+    UNIQUE (erasure_request_id, media_url)
+);
+
+CREATE INDEX IF NOT EXISTS idx_erasure_media_pending
+    ON erasure_media (erasure_request_id) WHERE deleted_at IS NULL;
@@ -0,0 +1,513 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"e-kyc/shared/pii"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const piiBatchSize = 500
+
+// PIIStats summarises an EncryptPII or RotatePIIKey run.
+type PIIStats struct {
+	Users      int
+	Sessions   int
+	OcrResults int
+	// Beneficiaries counts payout accounts. They are sealed when written, so only
+	// RotatePIIKey touches them.
+	Beneficiaries int
+}
+
+// EncryptPII seals plaintext users.nik/phone/email, ekyc_sessions.metadata.applicant and
+// the ocr_results KTP fields in place, clearing the plaintext as it goes. It works in batches, each in its own
+// transaction, and is safe to rerun: only rows still holding plaintext are touched.
+func EncryptPII(ctx context.Context, pool *pgxpool.Pool, keys *pii.Keyring) (PIIStats, error) {
+	var stats PIIStats
+	for {
+		n, err := encryptUserBatch(ctx, pool, keys)
+		if err != nil {
+			return stats, err
+		}
+		stats.Users += n
+		if n < piiBatchSize {
+			break
+		}
+	}
+	for {
+		n, err := sealApplicantBatch(ctx, pool, keys)
+		if err != nil {
+			return stats, err
+		}
+		stats.Sessions += n
+		if n < piiBatchSize {
+			break
+		}
+	}
+	for {
+		n, err := sealOcrBatch(ctx, pool, keys)
+		if err != nil {
+			return stats, err
+		}
+		stats.OcrResults += n
+		if n < piiBatchSize {
+			break
+		}
+	}
+	return stats, nil
+}
+
+func encryptUserBatch(ctx context.Context, pool *pgxpool.Pool, keys *pii.Keyring) (int, error) {
+	count := 0
+	err := WithTx(ctx, pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+            SELECT id, COALESCE(nik, ''), COALESCE(phone, ''), COALESCE(email, '')
+            FROM users
+            WHERE nik IS NOT NULL OR phone IS NOT NULL OR email IS NOT NULL
+            ORDER BY id
+            LIMIT $1
+            FOR UPDATE`, piiBatchSize)
+		if err != nil {
+			return err
+		}
+		type plainUser struct{ id, nik, phone, email string }
+		var batch []plainUser
+		for rows.Next() {
+			var u plainUser
+			if err := rows.Scan(&u.id, &u.nik, &u.phone, &u.email); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, u)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, u := range batch {
+			sealed, err := keys.SealUser(u.nik, u.phone, u.email)
+			if err != nil {
+				return fmt.Errorf("seal user %s: %w", u.id, err)
+			}
+			// Plaintext wins over an existing sealed value: it is the newer write.
+			if _, err := tx.Exec(ctx, `
+                UPDATE users
+                SET nik = NULL, phone = NULL, email = NULL,
+                    nik_enc = COALESCE($2, nik_enc), nik_bidx = COALESCE($3, nik_bidx),
+                    phone_enc = COALESCE($4, phone_enc), phone_bidx = COALESCE($5, phone_bidx),
+                    email_enc = COALESCE($6, email_enc), email_bidx = COALESCE($7, email_bidx)
+                WHERE id = $1`,
+				u.id, sealed.NIK.Sealed, sealed.NIK.Index, sealed.Phone.Sealed, sealed.Phone.Index,
+				sealed.Email.Sealed, sealed.Email.Index,
+			); err != nil {
+				return fmt.Errorf("encrypt user %s: %w", u.id, err)
+			}
+		}
+		count = len(batch)
+		return nil
+	})
+	return count, err
+}
+
+func sealApplicantBatch(ctx context.Context, pool *pgxpool.Pool, keys *pii.Keyring) (int, error) {
+	count := 0
+	err := WithTx(ctx, pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+            SELECT id, metadata->'applicant'
+            FROM ekyc_sessions
+            WHERE jsonb_typeof(metadata->'applicant') = 'object'
+            ORDER BY id
+            LIMIT $1
+            FOR UPDATE`, piiBatchSize)
+		if err != nil {
+			return err
+		}
+		type plainApplicant struct {
+			id        string
+			applicant []byte
+		}
+		var batch []plainApplicant
+		for rows.Next() {
+			var a plainApplicant
+			if err := rows.Scan(&a.id, &a.applicant); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, a)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, a := range batch {
+			sealed, err := keys.Seal(pii.FieldApplicant, string(a.applicant))
+			if err != nil {
+				return fmt.Errorf("seal applicant %s: %w", a.id, err)
+			}
+			if err := setSealedApplicant(ctx, tx, a.id, sealed); err != nil {
+				return err
+			}
+		}
+		count = len(batch)
+		return nil
+	})
+	return count, err
+}
+
+// ocrFields are the pii fields of the sealed ocr_results columns, in column order.
+var ocrFields = [4]string{pii.FieldOcrNIK, pii.FieldOcrName, pii.FieldOcrBirthDate, pii.FieldOcrAddress}
+
+func sealOcrBatch(ctx context.Context, pool *pgxpool.Pool, keys *pii.Keyring) (int, error) {
+	count := 0
+	err := WithTx(ctx, pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+            SELECT ekyc_session_id, nik, name, to_char(birth_date, 'YYYY-MM-DD'), address
+            FROM ocr_results
+            WHERE nik IS NOT NULL OR name IS NOT NULL OR birth_date IS NOT NULL OR address IS NOT NULL
+            ORDER BY ekyc_session_id
+            LIMIT $1
+            FOR UPDATE`, piiBatchSize)
+		if err != nil {
+			return err
+		}
+		type plainOcr struct {
+			id     string
+			values [4]*string
+		}
+		var batch []plainOcr
+		for rows.Next() {
+			var o plainOcr
+			if err := rows.Scan(&o.id, &o.values[0], &o.values[1], &o.values[2], &o.values[3]); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, o)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, o := range batch {
+			var sealed [4]*string
+			for i, value := range o.values {
+				if value == nil {
+					continue
+				}
+				v, err := keys.Seal(ocrFields[i], *value)
+				if err != nil {
+					return fmt.Errorf("seal ocr %s: %w", o.id, err)
+				}
+				sealed[i] = &v
+			}
+			// Plaintext wins over an existing sealed value: it is the newer write.
+			if _, err := tx.Exec(ctx, `
+                UPDATE ocr_results
+                SET nik = NULL, name = NULL, birth_date = NULL, address = NULL,
+                    nik_enc = COALESCE($2, nik_enc), name_enc = COALESCE($3, name_enc),
+                    birth_date_enc = COALESCE($4, birth_date_enc), address_enc = COALESCE($5, address_enc)
+                WHERE ekyc_session_id = $1`,
+				o.id, sealed[0], sealed[1], sealed[2], sealed[3],
+			); err != nil {
+				return fmt.Errorf("encrypt ocr %s: %w", o.id, err)
+			}
+		}
+		count = len(batch)
+		return nil
+	})
+	return count, err
+}
+
+func setSealedApplicant(ctx context.Context, tx pgx.Tx, sessionID, sealed string) error {
+	value, _ := json.Marshal(sealed)
+	if _, err := tx.Exec(ctx, `
+        UPDATE ekyc_sessions
+        SET metadata = jsonb_set(metadata, '{applicant}', $2::jsonb)
+        WHERE id = $1`, sessionID, value); err != nil {
+		return fmt.Errorf("update applicant %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// RotatePIIKey re-wraps every data key not yet under the active master key and recomputes
+// the blind indexes with it. The retired keys must still be in the keyring. Ciphertexts
+// are untouched, so a rotation only costs one small write per row. Once it reports zero
+// rows the retired keys can be dropped from the keyring.
+func RotatePIIKey(ctx context.Context, pool *pgxpool.Pool, keys *pii.Keyring) (PIIStats, error) {
+	var stats PIIStats
+	for {
+		n, err := rotateUserBatch(ctx, pool, keys)
+		if err != nil {
+			return stats, err
+		}
+		stats.Users += n
+		if n < piiBatchSize {
+			break
+		}
+	}
+	for {
+		n, err := rotateApplicantBatch(ctx, pool, keys)
+		if err != nil {
+			return stats, err
+		}
+		stats.Sessions += n
+		if n < piiBatchSize {
+			break
+		}
+	}
+	for {
+		n, err := rotateOcrBatch(ctx, pool, keys)
+		if err != nil {
+			return stats, err
+		}
+		stats.OcrResults += n
+		if n < piiBatchSize {
+			break
+		}
+	}
+	for {
+		n, err := rotatePayoutBatch(ctx, pool, keys)
+		if err != nil {
+			return stats, err
+		}
+		stats.Beneficiaries += n
+		if n < piiBatchSize {
+			break
+		}
+	}
+	return stats, nil
+}
+
+func rotateUserBatch(ctx context.Context, pool *pgxpool.Pool, keys *pii.Keyring) (int, error) {
+	count := 0
+	err := WithTx(ctx, pool, func(tx pgx.Tx) error {
+		// split_part(.., ':', 3) is the key id of "pii:v1:<id>:..".
+		rows, err := tx.Query(ctx, `
+            SELECT id, COALESCE(nik_enc, ''), COALESCE(phone_enc, ''), COALESCE(email_enc, '')
+            FROM users
+            WHERE split_part(nik_enc, ':', 3) <> $1
+               OR split_part(phone_enc, ':', 3) <> $1
+               OR split_part(email_enc, ':', 3) <> $1
+            ORDER BY id
+            LIMIT $2
+            FOR UPDATE`, keys.ActiveKeyID(), piiBatchSize)
+		if err != nil {
+			return err
+		}
+		type sealedRow struct{ id, nik, phone, email string }
+		var batch []sealedRow
+		for rows.Next() {
+			var r sealedRow
+			if err := rows.Scan(&r.id, &r.nik, &r.phone, &r.email); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, r)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, r := range batch {
+			nik, err := rotateColumn(keys, pii.FieldNIK, r.nik)
+			if err != nil {
+				return fmt.Errorf("rotate user %s nik: %w", r.id, err)
+			}
+			phone, err := rotateColumn(keys, pii.FieldPhone, r.phone)
+			if err != nil {
+				return fmt.Errorf("rotate user %s phone: %w", r.id, err)
+			}
+			email, err := rotateColumn(keys, pii.FieldEmail, r.email)
+			if err != nil {
+				return fmt.Errorf("rotate user %s email: %w", r.id, err)
+			}
+			if _, err := tx.Exec(ctx, `
+                UPDATE users
+                SET nik_enc = $2, nik_bidx = $3,
+                    phone_enc = $4, phone_bidx = $5,
+                    email_enc = $6, email_bidx = $7
+                WHERE id = $1`,
+				r.id, nik.Sealed, nik.Index, phone.Sealed, phone.Index, email.Sealed, email.Index,
+			); err != nil {
+				return fmt.Errorf("rotate user %s: %w", r.id, err)
+			}
+		}
+		count = len(batch)
+		return nil
+	})
+	return count, err
+}
+
+// rotateColumn re-wraps a sealed column and recomputes its blind index under the active key.
+func rotateColumn(keys *pii.Keyring, field, sealed string) (pii.Column, error) {
+	if sealed == "" {
+		return pii.Column{}, nil
+	}
+	plain, err := keys.Open(field, sealed)
+	if err != nil {
+		return pii.Column{}, err
+	}
+	rewrapped, _, err := keys.Rewrap(sealed)
+	if err != nil {
+		return pii.Column{}, err
+	}
+	index := keys.BlindIndex(field, plain)
+	return pii.Column{Sealed: &rewrapped, Index: &index}, nil
+}
+
+func rotateApplicantBatch(ctx context.Context, pool *pgxpool.Pool, keys *pii.Keyring) (int, error) {
+	count := 0
+	err := WithTx(ctx, pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+            SELECT id, metadata->>'applicant'
+            FROM ekyc_sessions
+            WHERE jsonb_typeof(metadata->'applicant') = 'string'
+              AND split_part(metadata->>'applicant', ':', 3) <> $1
+            ORDER BY id
+            LIMIT $2
+            FOR UPDATE`, keys.ActiveKeyID(), piiBatchSize)
+		if err != nil {
+			return err
+		}
+		type sealedApplicant struct{ id, sealed string }
+		var batch []sealedApplicant
+		for rows.Next() {
+			var a sealedApplicant
+			if err := rows.Scan(&a.id, &a.sealed); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, a)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, a := range batch {
+			rewrapped, _, err := keys.Rewrap(a.sealed)
+			if err != nil {
+				return fmt.Errorf("rotate applicant %s: %w", a.id, err)
+			}
+			if err := setSealedApplicant(ctx, tx, a.id, rewrapped); err != nil {
+				return err
+			}
+		}
+		count = len(batch)
+		return nil
+	})
+	return count, err
+}
+
+func rotateOcrBatch(ctx context.Context, pool *pgxpool.Pool, keys *pii.Keyring) (int, error) {
+	count := 0
+	err := WithTx(ctx, pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+            SELECT ekyc_session_id, nik_enc, name_enc, birth_date_enc, address_enc
+            FROM ocr_results
+            WHERE split_part(nik_enc, ':', 3) <> $1
+               OR split_part(name_enc, ':', 3) <> $1
+               OR split_part(birth_date_enc, ':', 3) <> $1
+               OR split_part(address_enc, ':', 3) <> $1
+            ORDER BY ekyc_session_id
+            LIMIT $2
+            FOR UPDATE`, keys.ActiveKeyID(), piiBatchSize)
+		if err != nil {
+			return err
+		}
+		type sealedOcr struct {
+			id     string
+			values [4]*string
+		}
+		var batch []sealedOcr
+		for rows.Next() {
+			var o sealedOcr
+			if err := rows.Scan(&o.id, &o.values[0], &o.values[1], &o.values[2], &o.values[3]); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, o)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, o := range batch {
+			for i, value := range o.values {
+				if value == nil {
+					continue
+				}
+				rewrapped, _, err := keys.Rewrap(*value)
+				if err != nil {
+					return fmt.Errorf("rotate ocr %s: %w", o.id, err)
+				}
+				o.values[i] = &rewrapped
+			}
+			if _, err := tx.Exec(ctx, `
+                UPDATE ocr_results
+                SET nik_enc = $2, name_enc = $3, birth_date_enc = $4, address_enc = $5
+                WHERE ekyc_session_id = $1`,
+				o.id, o.values[0], o.values[1], o.values[2], o.values[3],
+			); err != nil {
+				return fmt.Errorf("rotate ocr %s: %w", o.id, err)
+			}
+		}
+		count = len(batch)
+		return nil
+	})
+	return count, err
+}
+
+func rotatePayoutBatch(ctx context.Context, pool *pgxpool.Pool, keys *pii.Keyring) (int, error) {
+	count := 0
+	err := WithTx(ctx, pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, `
+            SELECT user_id, payout_account_enc
+            FROM beneficiaries
+            WHERE split_part(payout_account_enc, ':', 3) <> $1
+            ORDER BY user_id
+            LIMIT $2
+            FOR UPDATE`, keys.ActiveKeyID(), piiBatchSize)
+		if err != nil {
+			return err
+		}
+		type sealedPayout struct{ userID, sealed string }
+		var batch []sealedPayout
+		for rows.Next() {
+			var p sealedPayout
+			if err := rows.Scan(&p.userID, &p.sealed); err != nil {
+				rows.Close()
+				return err
+			}
+			batch = append(batch, p)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, p := range batch {
+			rewrapped, _, err := keys.Rewrap(p.sealed)
+			if err != nil {
+				return fmt.Errorf("rotate payout account %s: %w", p.userID, err)
+			}
+			if _, err := tx.Exec(ctx, `
+                UPDATE beneficiaries
+                SET payout_account_enc = $2
+                WHERE user_id = $1`, p.userID, rewrapped); err != nil {
+				return fmt.Errorf("rotate payout account %s: %w", p.userID, err)
+			}
+		}
+		count = len(batch)
+		return nil
+	})
+	return count, err
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"e-kyc/shared/pii"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// testDSNEnv points at a disposable database. Tests wipe it before migrating it.
+const testDSNEnv = "BACKOFFICE_TEST_DB_DSN"
+
+func openTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set", testDSNEnv)
+	}
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	if err := CleanDatabase(ctx, pool); err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob("migrations/*.sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no migrations found: %v", err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		blob, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ApplySchema(ctx, pool, string(blob)); err != nil {
+			t.Fatalf("%s: %v", filepath.Base(file), err)
+		}
+	}
+	return pool
+}
+
+func TestRotatePIIKeyRewrapsPayoutAccounts(t *testing.T) {
+	pool := openTestDB(t)
+	ctx := context.Background()
+
+	oldEntry, err := pii.GenerateKey("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newEntry, err := pii.GenerateKey("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldKeys, err := pii.ParseKeyring(oldEntry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := pii.ParseKeyring(newEntry + "\n" + oldEntry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const account = "0123456789"
+	sealed, err := oldKeys.Seal(pii.FieldPayoutAccount, account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var userID string
+	if err := pool.QueryRow(ctx, `
+        INSERT INTO users (role, name) VALUES ('beneficiary', 'Siti')
+        RETURNING id`).Scan(&userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.Exec(ctx, `
+        INSERT INTO beneficiaries (user_id, payout_channel, payout_bank, payout_account_enc)
+        VALUES ($1, 'BANK_TRANSFER', 'BRINIDJA', $2)`, userID, sealed); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := RotatePIIKey(ctx, pool, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Beneficiaries != 1 {
+		t.Errorf("re-wrapped %d payout accounts, want 1", stats.Beneficiaries)
+	}
+	var stored string
+	if err := pool.QueryRow(ctx, `SELECT payout_account_enc FROM beneficiaries WHERE user_id = $1`, userID).Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if pii.KeyID(stored) != "new" {
+		t.Errorf("payout account under key %q, want new", pii.KeyID(stored))
+	}
+	if plain, err := keys.Open(pii.FieldPayoutAccount, stored); err != nil || plain != account {
+		t.Errorf("opened %q, %v", plain, err)
+	}
+
+	again, err := RotatePIIKey(ctx, pool, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != (PIIStats{}) {
+		t.Errorf("second rotation touched %+v", again)
+	}
+}
@@ -0,0 +1,254 @@
+/*
+Package exif reads the capture time, GPS position and camera of a JPEG from its EXIF
+block. Only the tags those need are decoded.
+*/
+package exif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// ErrNoExif is returned for a JPEG without an EXIF block, for instance one that was
+// stripped when the photo was edited or shared.
+var ErrNoExif = errors.New("exif: no exif data")
+
+// Data is what the EXIF block says about a photo. Missing tags are left nil or empty.
+type Data struct {
+	// DateTime is the DateTimeOriginal wall-clock time (DateTime when absent), as UTC.
+	// EXIF stores it without a zone, see CaptureTime.
+	DateTime *time.Time
+	// Offset is OffsetTimeOriginal in seconds east of UTC, when the camera wrote it.
+	Offset *int
+	Lat    *float64
+	Lng    *float64
+	Make   string
+	Model  string
+}
+
+// CaptureTime returns when the photo was taken. Without an offset tag the wall-clock
+// time is read in loc, typically the zone the photo is expected to be taken in.
+func (d *Data) CaptureTime(loc *time.Location) (time.Time, bool) {
+	if d.DateTime == nil {
+		return time.Time{}, false
+	}
+	t := *d.DateTime
+	if d.Offset != nil {
+		loc = time.FixedZone("", *d.Offset)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), true
+}
+
+// EXIF tags read by Decode.
+const (
+	tagMake             = 0x010f
+	tagModel            = 0x0110
+	tagDateTime         = 0x0132
+	tagExifIFD          = 0x8769
+	tagGPSIFD           = 0x8825
+	tagDateTimeOriginal = 0x9003
+	tagOffsetOriginal   = 0x9011
+	tagGPSLatRef        = 0x0001
+	tagGPSLat           = 0x0002
+	tagGPSLngRef        = 0x0003
+	tagGPSLng           = 0x0004
+)
+
+// Decode reads the EXIF block of a JPEG. It returns ErrNoExif when the JPEG has none and
+// an error when content is not a JPEG or the block is malformed.
+func Decode(content []byte) (*Data, error) {
+	tiff, err := findExif(content)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTIFF(tiff)
+}
+
+// findExif walks the JPEG markers up to the image data and returns the TIFF structure of
+// the first APP1 Exif segment.
+func findExif(content []byte) ([]byte, error) {
+	if len(content) < 4 || content[0] != 0xff || content[1] != 0xd8 {
+		return nil, errors.New("exif: not a JPEG")
+	}
+	for pos := 2; pos+4 <= len(content); {
+		if content[pos] != 0xff {
+			return nil, fmt.Errorf("exif: bad marker at %d", pos)
+		}
+		marker := content[pos+1]
+		switch {
+		case marker == 0xff: // fill byte
+			pos++
+			continue
+		case marker == 0xd8 || marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			pos += 2
+			continue
+		case marker == 0xda || marker == 0xd9: // start of scan, end of image
+			return nil, ErrNoExif
+		}
+		length := int(binary.BigEndian.Uint16(content[pos+2:]))
+		if length < 2 || pos+2+length > len(content) {
+			return nil, fmt.Errorf("exif: truncated segment at %d", pos)
+		}
+		segment := content[pos+4 : pos+2+length]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:], nil
+		}
+		pos += 2 + length
+	}
+	return nil, ErrNoExif
+}
+
+type reader struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+type entry struct {
+	typ   uint16
+	count uint32
+	value []byte
+}
+
+// Sizes of the TIFF field types, by type number.
+var typeSizes = map[uint16]uint32{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 7: 1, 9: 4, 10: 8}
+
+func decodeTIFF(data []byte) (*Data, error) {
+	if len(data) < 8 {
+		return nil, errors.New("exif: truncated header")
+	}
+	r := reader{data: data}
+	switch string(data[:2]) {
+	case "II":
+		r.order = binary.LittleEndian
+	case "MM":
+		r.order = binary.BigEndian
+	default:
+		return nil, errors.New("exif: bad byte order")
+	}
+	if r.order.Uint16(data[2:]) != 42 {
+		return nil, errors.New("exif: bad TIFF magic")
+	}
+	ifd0, err := r.ifd(r.order.Uint32(data[4:]))
+	if err != nil {
+		return nil, err
+	}
+
+	var d Data
+	d.Make = r.ascii(ifd0[tagMake])
+	d.Model = r.ascii(ifd0[tagModel])
+	dateTime := r.ascii(ifd0[tagDateTime])
+	if pointer, ok := r.long(ifd0[tagExifIFD]); ok {
+		exifIFD, err := r.ifd(pointer)
+		if err != nil {
+			return nil, err
+		}
+		if original := r.ascii(exifIFD[tagDateTimeOriginal]); original != "" {
+			dateTime = original
+		}
+		if offset, ok := parseOffset(r.ascii(exifIFD[tagOffsetOriginal])); ok {
+			d.Offset = &offset
+		}
+	}
+	if t, err := time.Parse("2006:01:02 15:04:05", dateTime); err == nil {
+		d.DateTime = &t
+	}
+	if pointer, ok := r.long(ifd0[tagGPSIFD]); ok {
+		gps, err := r.ifd(pointer)
+		if err != nil {
+			return nil, err
+		}
+		lat, okLat := r.degrees(gps[tagGPSLat], r.ascii(gps[tagGPSLatRef]), "S")
+		lng, okLng := r.degrees(gps[tagGPSLng], r.ascii(gps[tagGPSLngRef]), "W")
+		// Cameras without a fix write zeros.
+		if okLat && okLng && (lat != 0 || lng != 0) && math.Abs(lat) <= 90 && math.Abs(lng) <= 180 {
+			d.Lat, d.Lng = &lat, &lng
+		}
+	}
+	return &d, nil
+}
+
+// ifd reads the entries of the image file directory at offset, by tag.
+func (r reader) ifd(offset uint32) (map[uint16]entry, error) {
+	if uint64(offset)+2 > uint64(len(r.data)) {
+		return nil, errors.New("exif: IFD out of range")
+	}
+	count := int(r.order.Uint16(r.data[offset:]))
+	start := int(offset) + 2
+	if start+count*12 > len(r.data) {
+		return nil, errors.New("exif: IFD truncated")
+	}
+	entries := make(map[uint16]entry, count)
+	for i := range count {
+		raw := r.data[start+i*12 : start+i*12+12]
+		e := entry{typ: r.order.Uint16(raw[2:]), count: r.order.Uint32(raw[4:])}
+		size, known := typeSizes[e.typ]
+		if !known || e.count > uint32(len(r.data)) {
+			continue
+		}
+		total := uint64(size) * uint64(e.count)
+		if total <= 4 {
+			e.value = raw[8 : 8+total]
+		} else {
+			at := uint64(r.order.Uint32(raw[8:]))
+			if at+total > uint64(len(r.data)) {
+				continue
+			}
+			e.value = r.data[at : at+total]
+		}
+		entries[r.order.Uint16(raw)] = e
+	}
+	return entries, nil
+}
+
+func (r reader) ascii(e entry) string {
+	if e.typ != 2 {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimRight(string(e.value), "\x00"))
+}
+
+func (r reader) long(e entry) (uint32, bool) {
+	switch {
+	case e.typ == 4 && len(e.value) >= 4:
+		return r.order.Uint32(e.value), true
+	case e.typ == 3 && len(e.value) >= 2:
+		return uint32(r.order.Uint16(e.value)), true
+	}
+	return 0, false
+}
+
+// degrees reads a degrees/minutes/seconds GPS coordinate, negated when ref is negative.
+func (r reader) degrees(e entry, ref, negative string) (float64, bool) {
+	if e.typ != 5 || e.count < 3 || len(e.value) < 24 {
+		return 0, false
+	}
+	var parts [3]float64
+	for i := range parts {
+		num := r.order.Uint32(e.value[i*8:])
+		den := r.order.Uint32(e.value[i*8+4:])
+		if den == 0 {
+			return 0, false
+		}
+		parts[i] = float64(num) / float64(den)
+	}
+	value := parts[0] + parts[1]/60 + parts[2]/3600
+	if strings.EqualFold(ref, negative) {
+		value = -value
+	}
+	return value, true
+}
+
+// parseOffset reads an EXIF offset such as "+07:00".
+func parseOffset(s string) (int, bool) {
+	t, err := time.Parse("-07:00", s)
+	if err != nil {
+		return 0, false
+	}
+	_, offset := t.Zone()
+	return offset, true
+}
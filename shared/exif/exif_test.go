@@ -0,0 +1,385 @@
+package exif
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// field is one IFD entry as written by tiffWriter. Values longer than four bytes go
+// after the IFD unless offset is set, which points the entry wherever a test wants.
+type field struct {
+	tag, typ uint16
+	count    uint32
+	value    []byte
+	offset   *uint32
+}
+
+func asciiField(tag uint16, s string) field {
+	return field{tag: tag, typ: 2, count: uint32(len(s) + 1), value: append([]byte(s), 0)}
+}
+
+type byteOrder interface {
+	binary.ByteOrder
+	binary.AppendByteOrder
+}
+
+// tiffWriter builds a TIFF structure one IFD at a time. Write sub-IFDs first so their
+// offsets can go into the IFDs that point at them.
+type tiffWriter struct {
+	order byteOrder
+	data  []byte
+}
+
+func newTIFF(order byteOrder) *tiffWriter {
+	w := &tiffWriter{order: order}
+	if order == binary.LittleEndian {
+		w.data = []byte("II")
+	} else {
+		w.data = []byte("MM")
+	}
+	w.data = order.AppendUint16(w.data, 42)
+	w.data = order.AppendUint32(w.data, 0)
+	return w
+}
+
+func (w *tiffWriter) long(tag uint16, v uint32) field {
+	return field{tag: tag, typ: 4, count: 1, value: w.order.AppendUint32(nil, v)}
+}
+
+// rationals is a GPS coordinate: degrees, minutes and seconds as num/den pairs.
+func (w *tiffWriter) rationals(tag uint16, parts ...uint32) field {
+	var value []byte
+	for _, p := range parts {
+		value = w.order.AppendUint32(value, p)
+	}
+	return field{tag: tag, typ: 5, count: uint32(len(parts) / 2), value: value}
+}
+
+// ifd appends an IFD with fields and returns its offset.
+func (w *tiffWriter) ifd(fields ...field) uint32 {
+	offset := uint32(len(w.data))
+	extra := offset + 2 + uint32(len(fields))*12 + 4
+	var values []byte
+	w.data = w.order.AppendUint16(w.data, uint16(len(fields)))
+	for _, f := range fields {
+		w.data = w.order.AppendUint16(w.data, f.tag)
+		w.data = w.order.AppendUint16(w.data, f.typ)
+		w.data = w.order.AppendUint32(w.data, f.count)
+		switch {
+		case f.offset != nil:
+			w.data = w.order.AppendUint32(w.data, *f.offset)
+		case len(f.value) <= 4:
+			w.data = append(w.data, append(f.value, make([]byte, 4-len(f.value))...)...)
+		default:
+			w.data = w.order.AppendUint32(w.data, extra+uint32(len(values)))
+			values = append(values, f.value...)
+		}
+	}
+	w.data = w.order.AppendUint32(w.data, 0)
+	w.data = append(w.data, values...)
+	return offset
+}
+
+// root makes the IFD at offset IFD0 and returns the finished TIFF structure.
+func (w *tiffWriter) root(offset uint32) []byte {
+	w.order.PutUint32(w.data[4:], offset)
+	return w.data
+}
+
+// jpeg wraps a TIFF structure in an APP1 Exif segment between an APP0 and the scan.
+func jpeg(tiff []byte) []byte {
+	out := []byte{0xff, 0xd8, 0xff, 0xe0, 0x00, 0x04, 'J', 'F'}
+	out = append(out, 0xff, 0xe1)
+	out = binary.BigEndian.AppendUint16(out, uint16(2+6+len(tiff)))
+	out = append(out, "Exif\x00\x00"...)
+	out = append(out, tiff...)
+	return append(out, 0xff, 0xda, 0x00, 0x02, 0xff, 0xd9)
+}
+
+// photo is a complete EXIF block: camera, capture time with offset, and a GPS fix in
+// Jakarta (6°12'S 106°49'E).
+func photo(order byteOrder) []byte {
+	w := newTIFF(order)
+	exifIFD := w.ifd(
+		asciiField(tagDateTimeOriginal, "2025:11:03 09:15:00"),
+		asciiField(tagOffsetOriginal, "+07:00"),
+	)
+	gps := w.ifd(
+		asciiField(tagGPSLatRef, "S"),
+		w.rationals(tagGPSLat, 6, 1, 12, 1, 0, 1),
+		asciiField(tagGPSLngRef, "E"),
+		w.rationals(tagGPSLng, 106, 1, 49, 1, 0, 1),
+	)
+	ifd0 := w.ifd(
+		asciiField(tagMake, "Samsung"),
+		asciiField(tagModel, "SM-A155F"),
+		asciiField(tagDateTime, "2025:11:04 10:00:00"),
+		w.long(tagExifIFD, exifIFD),
+		w.long(tagGPSIFD, gps),
+	)
+	return jpeg(w.root(ifd0))
+}
+
+func TestDecode(t *testing.T) {
+	for name, order := range map[string]byteOrder{"little endian": binary.LittleEndian, "big endian": binary.BigEndian} {
+		t.Run(name, func(t *testing.T) {
+			d, err := Decode(photo(order))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.Make != "Samsung" || d.Model != "SM-A155F" {
+				t.Errorf("camera %q %q", d.Make, d.Model)
+			}
+			taken, ok := d.CaptureTime(time.UTC)
+			if want := time.Date(2025, time.November, 3, 2, 15, 0, 0, time.UTC); !ok || !taken.Equal(want) {
+				t.Errorf("taken %v, want %v", taken, want)
+			}
+			if d.Lat == nil || d.Lng == nil || math.Abs(*d.Lat+6.2) > 1e-9 || math.Abs(*d.Lng-(106+49.0/60)) > 1e-9 {
+				t.Errorf("position %v, %v", d.Lat, d.Lng)
+			}
+		})
+	}
+}
+
+func TestCaptureTime(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*3600)
+	wall := time.Date(2025, time.November, 3, 9, 15, 0, 0, time.UTC)
+	offset := 9 * 3600
+
+	tests := []struct {
+		name string
+		data Data
+		want time.Time
+		ok   bool
+	}{
+		{"no time", Data{}, time.Time{}, false},
+		{"read in the expected zone", Data{DateTime: &wall}, time.Date(2025, time.November, 3, 2, 15, 0, 0, time.UTC), true},
+		{"offset tag wins", Data{DateTime: &wall, Offset: &offset}, time.Date(2025, time.November, 3, 0, 15, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.data.CaptureTime(wib)
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("CaptureTime = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestDecodeJPEGStructure(t *testing.T) {
+	tiff := newTIFF(binary.LittleEndian)
+	valid := tiff.root(tiff.ifd(asciiField(tagMake, "Samsung")))
+
+	tests := []struct {
+		name    string
+		content []byte
+		noExif  bool
+	}{
+		{"empty", nil, false},
+		{"not a JPEG", []byte("\x89PNG\r\n\x1a\n"), false},
+		{"stripped", []byte{0xff, 0xd8, 0xff, 0xd9}, true},
+		{"scan before exif", append([]byte{0xff, 0xd8, 0xff, 0xda, 0x00, 0x02}, jpeg(valid)[2:]...), true},
+		{"segment longer than the file", []byte{0xff, 0xd8, 0xff, 0xe1, 0xff, 0xff, 'E', 'x'}, false},
+		{"segment length below 2", []byte{0xff, 0xd8, 0xff, 0xe1, 0x00, 0x01, 0x00, 0x00}, false},
+		{"garbage between segments", []byte{0xff, 0xd8, 0x00, 0x00, 0x00, 0x00}, false},
+		{"APP1 that is not exif", []byte{0xff, 0xd8, 0xff, 0xe1, 0x00, 0x06, 'h', 't', 't', 'p', 0xff, 0xd9}, true},
+		{"exif with a short header", jpeg([]byte("II*\x00")), false},
+		{"bad byte order", jpeg([]byte("XX*\x00\x08\x00\x00\x00")), false},
+		{"bad magic", jpeg([]byte("II\x2b\x00\x08\x00\x00\x00")), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Decode(tt.content)
+			if err == nil {
+				t.Fatalf("decoded %+v", d)
+			}
+			if errors.Is(err, ErrNoExif) != tt.noExif {
+				t.Errorf("err = %v, want no-exif %v", err, tt.noExif)
+			}
+		})
+	}
+
+	// Fill bytes and standalone markers before the segment are skipped.
+	padded := append([]byte{0xff, 0xd8, 0xff, 0xff, 0xff, 0x01}, jpeg(valid)[2:]...)
+	if d, err := Decode(padded); err != nil || d.Make != "Samsung" {
+		t.Errorf("padded JPEG decoded to %+v, %v", d, err)
+	}
+}
+
+func TestDecodeSurvivesMaliciousIFDs(t *testing.T) {
+	far := func(v uint32) *uint32 { return &v }
+
+	tests := []struct {
+		name  string
+		build func(w *tiffWriter) []byte
+		fails bool
+		check func(t *testing.T, d *Data)
+	}{
+		{
+			name:  "IFD0 past the end",
+			build: func(w *tiffWriter) []byte { return w.root(4096) },
+			fails: true,
+		},
+		{
+			name:  "IFD0 at the largest offset",
+			build: func(w *tiffWriter) []byte { return w.root(math.MaxUint32) },
+			fails: true,
+		},
+		{
+			name: "IFD0 claims more entries than fit",
+			build: func(w *tiffWriter) []byte {
+				offset := w.ifd(asciiField(tagMake, "Samsung"))
+				w.order.PutUint16(w.data[offset:], 0xffff)
+				return w.root(offset)
+			},
+			fails: true,
+		},
+		{
+			name: "exif IFD past the end",
+			build: func(w *tiffWriter) []byte {
+				return w.root(w.ifd(w.long(tagExifIFD, math.MaxUint32-1)))
+			},
+			fails: true,
+		},
+		{
+			name: "GPS IFD past the end",
+			build: func(w *tiffWriter) []byte {
+				return w.root(w.ifd(w.long(tagGPSIFD, 1<<20)))
+			},
+			fails: true,
+		},
+		{
+			name: "value past the end",
+			build: func(w *tiffWriter) []byte {
+				return w.root(w.ifd(field{tag: tagMake, typ: 2, count: 16, offset: far(1 << 20)}))
+			},
+			check: func(t *testing.T, d *Data) {
+				if d.Make != "" {
+					t.Errorf("make %q", d.Make)
+				}
+			},
+		},
+		{
+			name: "value offset wraps around",
+			build: func(w *tiffWriter) []byte {
+				return w.root(w.ifd(field{tag: tagMake, typ: 2, count: 32, offset: far(math.MaxUint32 - 8)}))
+			},
+			check: func(t *testing.T, d *Data) {
+				if d.Make != "" {
+					t.Errorf("make %q", d.Make)
+				}
+			},
+		},
+		{
+			name: "huge count",
+			build: func(w *tiffWriter) []byte {
+				return w.root(w.ifd(field{tag: tagGPSLat, typ: 5, count: math.MaxUint32, offset: far(8)}))
+			},
+		},
+		{
+			name: "unknown type",
+			build: func(w *tiffWriter) []byte {
+				return w.root(w.ifd(field{tag: tagMake, typ: 99, count: 4, value: []byte("abc\x00")}))
+			},
+			check: func(t *testing.T, d *Data) {
+				if d.Make != "" {
+					t.Errorf("make %q", d.Make)
+				}
+			},
+		},
+		{
+			name: "wrong type for a pointer",
+			build: func(w *tiffWriter) []byte {
+				return w.root(w.ifd(asciiField(tagExifIFD, "8")))
+			},
+		},
+		{
+			name: "exif IFD pointing back at IFD0",
+			build: func(w *tiffWriter) []byte {
+				return w.root(w.ifd(w.long(tagExifIFD, 8), asciiField(tagDateTime, "2025:11:03 09:15:00")))
+			},
+			check: func(t *testing.T, d *Data) {
+				if d.DateTime == nil {
+					t.Error("lost the date")
+				}
+			},
+		},
+		{
+			name: "GPS with a zero denominator",
+			build: func(w *tiffWriter) []byte {
+				gps := w.ifd(w.rationals(tagGPSLat, 6, 0, 12, 1, 0, 1), w.rationals(tagGPSLng, 106, 1, 49, 1, 0, 1))
+				return w.root(w.ifd(w.long(tagGPSIFD, gps)))
+			},
+			check: func(t *testing.T, d *Data) {
+				if d.Lat != nil || d.Lng != nil {
+					t.Errorf("position %v, %v", *d.Lat, *d.Lng)
+				}
+			},
+		},
+		{
+			name: "GPS without a fix",
+			build: func(w *tiffWriter) []byte {
+				gps := w.ifd(w.rationals(tagGPSLat, 0, 1, 0, 1, 0, 1), w.rationals(tagGPSLng, 0, 1, 0, 1, 0, 1))
+				return w.root(w.ifd(w.long(tagGPSIFD, gps)))
+			},
+			check: func(t *testing.T, d *Data) {
+				if d.Lat != nil || d.Lng != nil {
+					t.Errorf("position %v, %v", *d.Lat, *d.Lng)
+				}
+			},
+		},
+		{
+			name: "GPS out of range",
+			build: func(w *tiffWriter) []byte {
+				gps := w.ifd(w.rationals(tagGPSLat, 95, 1, 0, 1, 0, 1), w.rationals(tagGPSLng, 106, 1, 0, 1, 0, 1))
+				return w.root(w.ifd(w.long(tagGPSIFD, gps)))
+			},
+			check: func(t *testing.T, d *Data) {
+				if d.Lat != nil || d.Lng != nil {
+					t.Errorf("position %v, %v", *d.Lat, *d.Lng)
+				}
+			},
+		},
+		{
+			name: "unparseable date",
+			build: func(w *tiffWriter) []byte {
+				return w.root(w.ifd(asciiField(tagDateTime, "0000:00:00 00:00:00")))
+			},
+			check: func(t *testing.T, d *Data) {
+				if d.DateTime != nil {
+					t.Errorf("date %v", d.DateTime)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := Decode(jpeg(tt.build(newTIFF(binary.LittleEndian))))
+			if tt.fails {
+				if err == nil {
+					t.Errorf("decoded %+v", d)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.check != nil {
+				tt.check(t, d)
+			}
+		})
+	}
+}
+
+func TestDecodeTruncatedPhoto(t *testing.T) {
+	full := photo(binary.BigEndian)
+	for n := range len(full) {
+		// Every prefix must decode or fail cleanly, never panic.
+		if d, err := Decode(full[:n]); err == nil && d == nil {
+			t.Errorf("%d bytes: nil data without an error", n)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Boundary is the area of one kelurahan. Area is empty when the dataset only gives a
+// centroid for it.
+type Boundary struct {
+	Prov     string
+	Kab      string
+	Kec      string
+	Kel      string
+	Area     MultiPolygon
+	Centroid Point
+}
+
+// Boundaries is a kelurahan boundary dataset, looked up by kabupaten, kecamatan and
+// kelurahan name. Names are matched case-insensitively.
+type Boundaries struct {
+	list  []Boundary
+	byKey map[string]int
+}
+
+// LoadBoundaries reads a GeoJSON FeatureCollection from path. See ParseBoundaries.
+func LoadBoundaries(path string) (*Boundaries, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseBoundaries(f)
+}
+
+// ParseBoundaries reads a GeoJSON FeatureCollection with one feature per kelurahan. Each
+// feature has "kab", "kec" and "kel" (and optionally "prov") properties and a Polygon,
+// MultiPolygon or Point geometry. A polygon's centroid can be pinned with a
+// "centroid": [lng, lat] property, for instance to the kantor kelurahan.
+func ParseBoundaries(r io.Reader) (*Boundaries, error) {
+	var collection struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Properties struct {
+				Prov     string    `json:"prov"`
+				Kab      string    `json:"kab"`
+				Kec      string    `json:"kec"`
+				Kel      string    `json:"kel"`
+				Centroid []float64 `json:"centroid"`
+			} `json:"properties"`
+			Geometry struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, fmt.Errorf("decode boundaries: %w", err)
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("boundaries must be a FeatureCollection, got %q", collection.Type)
+	}
+
+	b := &Boundaries{byKey: make(map[string]int, len(collection.Features))}
+	for i, feature := range collection.Features {
+		props := feature.Properties
+		boundary := Boundary{Prov: props.Prov, Kab: props.Kab, Kec: props.Kec, Kel: props.Kel}
+		if strings.TrimSpace(boundary.Kel) == "" {
+			return nil, fmt.Errorf("feature %d: kel property required", i)
+		}
+
+		var err error
+		hasCentroid := false
+		switch feature.Geometry.Type {
+		case "Polygon":
+			var coords [][][]float64
+			if err = json.Unmarshal(feature.Geometry.Coordinates, &coords); err == nil {
+				var polygon Polygon
+				if polygon, err = toPolygon(coords); err == nil {
+					boundary.Area = MultiPolygon{polygon}
+				}
+			}
+		case "MultiPolygon":
+			var coords [][][][]float64
+			if err = json.Unmarshal(feature.Geometry.Coordinates, &coords); err == nil {
+				for _, c := range coords {
+					var polygon Polygon
+					if polygon, err = toPolygon(c); err != nil {
+						break
+					}
+					boundary.Area = append(boundary.Area, polygon)
+				}
+			}
+		case "Point":
+			var coords []float64
+			if err = json.Unmarshal(feature.Geometry.Coordinates, &coords); err == nil {
+				boundary.Centroid, err = toPoint(coords)
+				hasCentroid = true
+			}
+		default:
+			err = fmt.Errorf("unsupported geometry %q", feature.Geometry.Type)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("feature %d (%s): %w", i, boundary.Kel, err)
+		}
+
+		if props.Centroid != nil {
+			if boundary.Centroid, err = toPoint(props.Centroid); err != nil {
+				return nil, fmt.Errorf("feature %d (%s): centroid: %w", i, boundary.Kel, err)
+			}
+		} else if !hasCentroid {
+			boundary.Centroid, _ = boundary.Area.Centroid()
+		}
+
+		key := boundaryKey(boundary.Kab, boundary.Kec, boundary.Kel)
+		if _, dup := b.byKey[key]; dup {
+			return nil, fmt.Errorf("feature %d: duplicate kelurahan %s/%s/%s", i, boundary.Kab, boundary.Kec, boundary.Kel)
+		}
+		b.byKey[key] = len(b.list)
+		b.list = append(b.list, boundary)
+	}
+	return b, nil
+}
+
+func (b *Boundaries) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.list)
+}
+
+// Lookup finds the kelurahan by name.
+func (b *Boundaries) Lookup(kab, kec, kel string) (*Boundary, bool) {
+	if b == nil {
+		return nil, false
+	}
+	i, ok := b.byKey[boundaryKey(kab, kec, kel)]
+	if !ok {
+		return nil, false
+	}
+	return &b.list[i], true
+}
+
+// Locate finds the kelurahan whose area contains pt. Kelurahan known only by centroid
+// are never matched.
+func (b *Boundaries) Locate(pt Point) (*Boundary, bool) {
+	if b == nil {
+		return nil, false
+	}
+	for i := range b.list {
+		if b.list[i].Area.Contains(pt) {
+			return &b.list[i], true
+		}
+	}
+	return nil, false
+}
+
+func boundaryKey(kab, kec, kel string) string {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.Join(strings.Fields(s), " "))
+	}
+	return normalize(kab) + "|" + normalize(kec) + "|" + normalize(kel)
+}
+
+func toPolygon(coords [][][]float64) (Polygon, error) {
+	if len(coords) == 0 {
+		return nil, fmt.Errorf("polygon without rings")
+	}
+	polygon := make(Polygon, 0, len(coords))
+	for _, ringCoords := range coords {
+		if len(ringCoords) < 3 {
+			return nil, fmt.Errorf("ring needs at least 3 points, got %d", len(ringCoords))
+		}
+		ring := make(Ring, 0, len(ringCoords))
+		for _, c := range ringCoords {
+			pt, err := toPoint(c)
+			if err != nil {
+				return nil, err
+			}
+			ring = append(ring, pt)
+		}
+		polygon = append(polygon, ring)
+	}
+	return polygon, nil
+}
+
+func toPoint(c []float64) (Point, error) {
+	if len(c) < 2 {
+		return Point{}, fmt.Errorf("position needs [lng, lat], got %v", c)
+	}
+	pt := Point{Lng: c[0], Lat: c[1]}
+	if !pt.Valid() {
+		return Point{}, fmt.Errorf("position %v out of range", c)
+	}
+	return pt, nil
+}
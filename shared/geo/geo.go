@@ -0,0 +1,146 @@
+/*
+Package geo checks WGS84 points against region boundaries.
+
+Polygons are treated as planar in longitude/latitude, which is accurate enough at
+kelurahan scale. Distances use the haversine formula on a spherical Earth.
+*/
+package geo
+
+import "math"
+
+const earthRadiusMeters = 6371008.8
+
+type Point struct {
+	Lng float64
+	Lat float64
+}
+
+func (p Point) Valid() bool {
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lng >= -180 && p.Lng <= 180
+}
+
+// Ring is a closed line of points. The closing point may be repeated or left out.
+type Ring []Point
+
+// Polygon is an outer ring followed by its holes, as in GeoJSON.
+type Polygon []Ring
+
+type MultiPolygon []Polygon
+
+// Contains reports whether pt lies inside the ring or on its edge (ray casting).
+func (r Ring) Contains(pt Point) bool {
+	n := len(r)
+	if n < 3 {
+		return false
+	}
+	inside := false
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		a, b := r[i], r[j]
+		if onSegment(a, b, pt) {
+			return true
+		}
+		if (a.Lat > pt.Lat) != (b.Lat > pt.Lat) {
+			cross := a.Lng + (pt.Lat-a.Lat)*(b.Lng-a.Lng)/(b.Lat-a.Lat)
+			if pt.Lng < cross {
+				inside = !inside
+			}
+		}
+	}
+	return inside
+}
+
+// Contains reports whether pt lies inside the outer ring and not strictly inside a hole.
+// A point on a hole's edge belongs to the polygon.
+func (p Polygon) Contains(pt Point) bool {
+	if len(p) == 0 || !p[0].Contains(pt) {
+		return false
+	}
+	for _, hole := range p[1:] {
+		if hole.Contains(pt) && !onRing(hole, pt) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m MultiPolygon) Contains(pt Point) bool {
+	for _, polygon := range m {
+		if polygon.Contains(pt) {
+			return true
+		}
+	}
+	return false
+}
+
+// Centroid is the area centroid of the largest polygon's outer ring, or the vertex
+// average when the ring has no area.
+func (m MultiPolygon) Centroid() (Point, bool) {
+	var (
+		best     Point
+		bestArea = -1.0
+	)
+	for _, polygon := range m {
+		if len(polygon) == 0 || len(polygon[0]) == 0 {
+			continue
+		}
+		c, area := polygon[0].centroid()
+		if area > bestArea {
+			best, bestArea = c, area
+		}
+	}
+	return best, bestArea >= 0
+}
+
+func (r Ring) centroid() (Point, float64) {
+	var area2, cx, cy float64
+	n := len(r)
+	for i, j := 0, n-1; i < n; j, i = i, i+1 {
+		cross := r[j].Lng*r[i].Lat - r[i].Lng*r[j].Lat
+		area2 += cross
+		cx += (r[j].Lng + r[i].Lng) * cross
+		cy += (r[j].Lat + r[i].Lat) * cross
+	}
+	if area2 == 0 {
+		var sum Point
+		for _, p := range r {
+			sum.Lng += p.Lng
+			sum.Lat += p.Lat
+		}
+		return Point{Lng: sum.Lng / float64(n), Lat: sum.Lat / float64(n)}, 0
+	}
+	return Point{Lng: cx / (3 * area2), Lat: cy / (3 * area2)}, math.Abs(area2 / 2)
+}
+
+// DistanceMeters is the great-circle distance between a and b.
+func DistanceMeters(a, b Point) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(b.Lng - a.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func onRing(r Ring, pt Point) bool {
+	for i, j := 0, len(r)-1; i < len(r); j, i = i, i+1 {
+		if onSegment(r[i], r[j], pt) {
+			return true
+		}
+	}
+	return false
+}
+
+// onSegmentEpsilon absorbs float noise, about a millimetre in degrees.
+const onSegmentEpsilon = 1e-9
+
+func onSegment(a, b, pt Point) bool {
+	cross := (b.Lng-a.Lng)*(pt.Lat-a.Lat) - (b.Lat-a.Lat)*(pt.Lng-a.Lng)
+	if math.Abs(cross) > onSegmentEpsilon {
+		return false
+	}
+	return pt.Lng >= math.Min(a.Lng, b.Lng)-onSegmentEpsilon && pt.Lng <= math.Max(a.Lng, b.Lng)+onSegmentEpsilon &&
+		pt.Lat >= math.Min(a.Lat, b.Lat)-onSegmentEpsilon && pt.Lat <= math.Max(a.Lat, b.Lat)+onSegmentEpsilon
+}
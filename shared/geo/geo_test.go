@@ -0,0 +1,107 @@
+package geo
+
+import "testing"
+
+// square is the ring around (0,0)-(10,10), with a (4,4)-(6,6) hole for polygon tests.
+var (
+	square = Ring{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	hole   = Ring{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}}
+)
+
+func TestRingContains(t *testing.T) {
+	// A concave "L" makes rays cross the ring more than once.
+	ell := Ring{{0, 0}, {4, 0}, {4, 2}, {2, 2}, {2, 4}, {0, 4}, {0, 0}}
+
+	tests := []struct {
+		name string
+		ring Ring
+		pt   Point
+		want bool
+	}{
+		{"inside", square, Point{5, 5}, true},
+		{"outside", square, Point{11, 5}, false},
+		{"outside on the ray's line", square, Point{-1, 10}, false},
+		{"on edge", square, Point{10, 5}, true},
+		{"on bottom edge", square, Point{5, 0}, true},
+		{"on vertex", square, Point{10, 10}, true},
+		{"on closing vertex", square, Point{0, 0}, true},
+		{"just outside an edge", square, Point{10.000001, 5}, false},
+		{"inside concave", ell, Point{1, 3}, true},
+		{"in concave notch", ell, Point{3, 3}, false},
+		{"on reflex vertex", ell, Point{2, 2}, true},
+		{"level with reflex vertex", ell, Point{1, 2}, true},
+		{"open ring", Ring{{0, 0}, {10, 0}, {10, 10}, {0, 10}}, Point{5, 5}, true},
+		{"degenerate ring", Ring{{0, 0}, {10, 10}}, Point{5, 5}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ring.Contains(tt.pt); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.pt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	polygon := Polygon{square, hole}
+
+	tests := []struct {
+		name string
+		pt   Point
+		want bool
+	}{
+		{"inside", Point{2, 2}, true},
+		{"outside", Point{-1, 5}, false},
+		{"on outer edge", Point{0, 5}, true},
+		{"on outer vertex", Point{10, 0}, true},
+		{"in hole", Point{5, 5}, false},
+		{"on hole edge", Point{5, 4}, true},
+		{"on hole vertex", Point{6, 6}, true},
+		{"just inside hole edge", Point{5, 4.000001}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := polygon.Contains(tt.pt); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.pt, got, tt.want)
+			}
+		})
+	}
+
+	if (Polygon{}).Contains(Point{0, 0}) {
+		t.Error("empty polygon contains a point")
+	}
+}
+
+func TestMultiPolygonContains(t *testing.T) {
+	// An island sits inside the first polygon's hole, the way enclaves appear in boundary data.
+	island := Polygon{{{4.5, 4.5}, {5.5, 4.5}, {5.5, 5.5}, {4.5, 5.5}, {4.5, 4.5}}}
+	far := Polygon{{{20, 20}, {30, 20}, {30, 30}, {20, 30}, {20, 20}}}
+	multi := MultiPolygon{{square, hole}, island, far}
+
+	tests := []struct {
+		name string
+		pt   Point
+		want bool
+	}{
+		{"in first polygon", Point{2, 2}, true},
+		{"in second polygon", Point{25, 25}, true},
+		{"between polygons", Point{15, 15}, false},
+		{"on edge", Point{30, 25}, true},
+		{"on vertex", Point{20, 20}, true},
+		{"in hole", Point{4.2, 4.2}, false},
+		{"on hole edge", Point{4, 5}, true},
+		{"on island in hole", Point{5, 5}, true},
+		{"on island edge", Point{4.5, 5}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := multi.Contains(tt.pt); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.pt, got, tt.want)
+			}
+		})
+	}
+
+	if (MultiPolygon{}).Contains(Point{0, 0}) {
+		t.Error("empty multipolygon contains a point")
+	}
+}
@@ -0,0 +1,111 @@
+package geo
+
+// OrderRoute orders stops into a short open path: nearest neighbour first, then 2-opt
+// until no segment reversal shortens it. The path leaves from start when it is given.
+// Without a start it may begin at any stop, and each stop is tried as the first one.
+// The result holds indexes into stops.
+func OrderRoute(start *Point, stops []Point) []int {
+	n := len(stops)
+	if n == 0 {
+		return []int{}
+	}
+	nodes := stops
+	if start != nil {
+		nodes = append(append(make([]Point, 0, n+1), stops...), *start)
+	}
+	dist := make([][]float64, len(nodes))
+	for i := range nodes {
+		dist[i] = make([]float64, len(nodes))
+		for j := range i {
+			dist[i][j] = DistanceMeters(nodes[i], nodes[j])
+			dist[j][i] = dist[i][j]
+		}
+	}
+
+	if start != nil {
+		path := nearestNeighbour(dist, n, n)
+		twoOpt(dist, path, 1)
+		return path[1:]
+	}
+	var (
+		best       []int
+		bestLength float64
+	)
+	for first := range n {
+		path := nearestNeighbour(dist, n, first)
+		twoOpt(dist, path, 0)
+		if length := pathLength(dist, path); best == nil || length < bestLength {
+			best, bestLength = path, length
+		}
+	}
+	return best
+}
+
+// PathLength is the length in metres of the path through points in order.
+func PathLength(points []Point) float64 {
+	var total float64
+	for i := 1; i < len(points); i++ {
+		total += DistanceMeters(points[i-1], points[i])
+	}
+	return total
+}
+
+// nearestNeighbour builds a path from first through the stops 0..n-1, always moving to
+// the closest stop not yet visited. first may be the start node n.
+func nearestNeighbour(dist [][]float64, n, first int) []int {
+	visited := make([]bool, len(dist))
+	// Without a start node the path holds the n stops, otherwise the start too.
+	length := n
+	if first == n {
+		length++
+	}
+	path := make([]int, 0, length)
+	path = append(path, first)
+	visited[first] = true
+	for current := first; len(path) < length; {
+		next := -1
+		for candidate := range n {
+			if !visited[candidate] && (next < 0 || dist[current][candidate] < dist[current][next]) {
+				next = candidate
+			}
+		}
+		path = append(path, next)
+		visited[next] = true
+		current = next
+	}
+	return path
+}
+
+// twoOpt reverses path segments while that shortens the path. Nodes before from keep
+// their place. The path is open, so reversing a tail only changes the edge into it.
+func twoOpt(dist [][]float64, path []int, from int) {
+	edge := func(i, j int) float64 {
+		if i < 0 || j >= len(path) {
+			return 0
+		}
+		return dist[path[i]][path[j]]
+	}
+	for improved := true; improved; {
+		improved = false
+		for i := from; i < len(path)-1; i++ {
+			for k := i + 1; k < len(path); k++ {
+				before := edge(i-1, i) + edge(k, k+1)
+				after := edge(i-1, k) + edge(i, k+1)
+				if after < before-1e-6 {
+					for a, b := i, k; a < b; a, b = a+1, b-1 {
+						path[a], path[b] = path[b], path[a]
+					}
+					improved = true
+				}
+			}
+		}
+	}
+}
+
+func pathLength(dist [][]float64, path []int) float64 {
+	var total float64
+	for i := 1; i < len(path); i++ {
+		total += dist[path[i-1]][path[i]]
+	}
+	return total
+}
@@ -0,0 +1,218 @@
+/*
+Package ical writes iCalendar (RFC 5545) feeds of events, the format calendar apps
+subscribe to. Times are written in UTC, so no VTIMEZONE is needed.
+*/
+package ical
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Event statuses (RFC 5545 section 3.8.1.11).
+const (
+	StatusTentative = "TENTATIVE"
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+// maxLineOctets is the longest content line before it is folded, without the CRLF.
+const maxLineOctets = 75
+
+// Calendar is a VCALENDAR published for subscription (METHOD:PUBLISH).
+type Calendar struct {
+	// ProdID identifies the product that wrote the feed, e.g. "-//Example//Feed//EN".
+	ProdID string
+	// Name is shown by calendar apps as the subscription name (X-WR-CALNAME).
+	Name string
+	// RefreshInterval, when set, suggests how often apps poll the feed (RFC 7986).
+	RefreshInterval time.Duration
+	Events          []Event
+}
+
+// Event is one VEVENT. UID must stay the same for the life of the event and Sequence
+// must grow whenever its time, place or status changes, or apps keep the old copy.
+type Event struct {
+	UID          string
+	Sequence     int
+	Start        time.Time
+	End          time.Time
+	Summary      string
+	Description  string
+	Location     string
+	Lat, Lng     *float64
+	Status       string
+	Categories   []string
+	URL          string
+	LastModified time.Time
+}
+
+// Marshal returns the calendar as iCalendar text. stamp is written as every event's
+// DTSTAMP, the time the feed was generated.
+func (c *Calendar) Marshal(stamp time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.Encode(&buf, stamp); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Encode writes the calendar as iCalendar text to w. See Marshal.
+func (c *Calendar) Encode(w io.Writer, stamp time.Time) error {
+	if strings.TrimSpace(c.ProdID) == "" {
+		return errors.New("ical: PRODID required")
+	}
+	var e encoder
+	e.line("BEGIN", "VCALENDAR")
+	e.line("VERSION", "2.0")
+	e.line("PRODID", escapeText(c.ProdID))
+	e.line("CALSCALE", "GREGORIAN")
+	e.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		e.line("X-WR-CALNAME", escapeText(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		e.line("REFRESH-INTERVAL;VALUE=DURATION", formatDuration(c.RefreshInterval))
+		e.line("X-PUBLISHED-TTL", formatDuration(c.RefreshInterval))
+	}
+	for i := range c.Events {
+		if err := e.event(&c.Events[i], stamp); err != nil {
+			return err
+		}
+	}
+	e.line("END", "VCALENDAR")
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
+
+type encoder struct {
+	buf bytes.Buffer
+}
+
+func (e *encoder) event(ev *Event, stamp time.Time) error {
+	if strings.TrimSpace(ev.UID) == "" {
+		return errors.New("ical: event UID required")
+	}
+	if ev.Start.IsZero() {
+		return fmt.Errorf("ical: event %s has no start", ev.UID)
+	}
+	if !ev.End.IsZero() && ev.End.Before(ev.Start) {
+		return fmt.Errorf("ical: event %s ends before it starts", ev.UID)
+	}
+	if ev.Sequence < 0 {
+		return fmt.Errorf("ical: event %s has a negative sequence", ev.UID)
+	}
+	switch ev.Status {
+	case "", StatusTentative, StatusConfirmed, StatusCancelled:
+	default:
+		return fmt.Errorf("ical: event %s has unknown status %q", ev.UID, ev.Status)
+	}
+
+	e.line("BEGIN", "VEVENT")
+	e.line("UID", escapeText(ev.UID))
+	e.line("DTSTAMP", formatTime(stamp))
+	e.line("DTSTART", formatTime(ev.Start))
+	if !ev.End.IsZero() {
+		e.line("DTEND", formatTime(ev.End))
+	}
+	e.line("SEQUENCE", strconv.Itoa(ev.Sequence))
+	if ev.Status != "" {
+		e.line("STATUS", ev.Status)
+	}
+	if ev.Summary != "" {
+		e.line("SUMMARY", escapeText(ev.Summary))
+	}
+	if ev.Description != "" {
+		e.line("DESCRIPTION", escapeText(ev.Description))
+	}
+	if ev.Location != "" {
+		e.line("LOCATION", escapeText(ev.Location))
+	}
+	if ev.Lat != nil && ev.Lng != nil {
+		e.line("GEO", strconv.FormatFloat(*ev.Lat, 'f', 6, 64)+";"+strconv.FormatFloat(*ev.Lng, 'f', 6, 64))
+	}
+	if len(ev.Categories) > 0 {
+		categories := make([]string, len(ev.Categories))
+		for i, category := range ev.Categories {
+			categories[i] = escapeText(category)
+		}
+		e.line("CATEGORIES", strings.Join(categories, ","))
+	}
+	if ev.URL != "" {
+		e.line("URL;VALUE=URI", ev.URL)
+	}
+	if !ev.LastModified.IsZero() {
+		e.line("LAST-MODIFIED", formatTime(ev.LastModified))
+	}
+	e.line("END", "VEVENT")
+	return nil
+}
+
+// line writes one content line, folded so no line is longer than 75 octets. Folds never
+// split a UTF-8 sequence, and each continuation line starts with a space.
+func (e *encoder) line(name, value string) {
+	content := name + ":" + value
+	limit := maxLineOctets
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		e.buf.WriteString(content[:cut])
+		e.buf.WriteString("\r\n ")
+		content = content[cut:]
+		limit = maxLineOctets - 1
+	}
+	e.buf.WriteString(content)
+	e.buf.WriteString("\r\n")
+}
+
+// escapeText escapes a TEXT value (section 3.3.11). Line breaks become \n and other
+// control characters, which TEXT may not contain, are dropped.
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == ';':
+			b.WriteString(`\;`)
+		case r == ',':
+			b.WriteString(`\,`)
+		case r == '\n' || r == '\r':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+		case r == utf8.RuneError:
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// formatDuration writes d as a dur-time, in whole hours when it divides evenly and in
+// minutes otherwise. Seconds are dropped.
+func formatDuration(d time.Duration) string {
+	minutes := int64(d / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("PT%dH", minutes/60)
+	}
+	return fmt.Sprintf("PT%dM", minutes)
+}
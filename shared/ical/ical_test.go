@@ -0,0 +1,157 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+var stamp = time.Date(2025, time.November, 3, 8, 30, 0, 0, time.UTC)
+
+func TestEncodeUsesCRLF(t *testing.T) {
+	cal := Calendar{ProdID: "-//e-kyc//test//ID", Events: []Event{{UID: "a@test", Start: stamp}}}
+	out, err := cal.Marshal(stamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(out)
+	if !strings.HasSuffix(text, "END:VCALENDAR\r\n") {
+		t.Errorf("feed does not end with a CRLF-terminated END:VCALENDAR: %q", text[max(0, len(text)-20):])
+	}
+	if bare := strings.Count(text, "\n") - strings.Count(text, "\r\n"); bare != 0 {
+		t.Errorf("%d lines end in a bare LF", bare)
+	}
+	if strings.Count(strings.ReplaceAll(text, "\r\n", ""), "\r") != 0 {
+		t.Error("bare CR in output")
+	}
+}
+
+func TestLineFolding(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"short", "Kunjungan TKSK"},
+		{"exactly 75 octets", strings.Repeat("a", maxLineOctets-len("SUMMARY:"))},
+		{"76 octets", strings.Repeat("a", maxLineOctets-len("SUMMARY:")+1)},
+		{"several folds", strings.Repeat("abcdefghij", 30)},
+		// Two-octet runes shifted by one so a rune straddles every fold.
+		{"two-octet runes", "x" + strings.Repeat("é", 120)},
+		{"three-octet runes", strings.Repeat("日本", 60)},
+		{"four-octet runes", "ab" + strings.Repeat("😀", 50)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e encoder
+			e.line("SUMMARY", tt.value)
+			out := e.buf.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("line not CRLF-terminated: %q", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			for i, line := range lines {
+				if len(line) > maxLineOctets {
+					t.Errorf("line %d is %d octets: %q", i, len(line), line)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 sequence: %q", i, line)
+				}
+				if i > 0 && !strings.HasPrefix(line, " ") {
+					t.Errorf("continuation line %d does not start with a space: %q", i, line)
+				}
+			}
+			if unfolded := strings.ReplaceAll(strings.TrimSuffix(out, "\r\n"), "\r\n ", ""); unfolded != "SUMMARY:"+tt.value {
+				t.Errorf("unfolds to %q", unfolded)
+			}
+			if want := len("SUMMARY:"+tt.value) > maxLineOctets; (len(lines) > 1) != want {
+				t.Errorf("folded into %d lines", len(lines))
+			}
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`plain text`, `plain text`},
+		{`C:\data`, `C:\\data`},
+		{`a;b`, `a\;b`},
+		{`Jl. Merdeka, No. 1`, `Jl. Merdeka\, No. 1`},
+		{"line one\nline two", `line one\nline two`},
+		{"windows\r\nbreak", `windows\nbreak`},
+		{"old mac\rbreak", `old mac\nbreak`},
+		{`\;,`, `\\\;\,`},
+		{"tab\tkept", "tab\tkept"},
+		{"bell\x07 and del\x7f dropped", "bell and del dropped"},
+		{"Ibu Siti – Kelurahan Cempaka", "Ibu Siti – Kelurahan Cempaka"},
+	}
+	for _, tt := range tests {
+		if got := escapeText(tt.in); got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeEvent(t *testing.T) {
+	lat, lng := -6.2, 106.816666
+	cal := Calendar{
+		ProdID:          "-//e-kyc//test//ID",
+		Name:            "Jadwal Budi, TKSK",
+		RefreshInterval: time.Hour,
+		Events: []Event{{
+			UID:         "visit-1@test",
+			Sequence:    3,
+			Start:       time.Date(2025, time.November, 4, 9, 0, 0, 0, time.FixedZone("WIB", 7*3600)),
+			End:         time.Date(2025, time.November, 4, 10, 0, 0, 0, time.FixedZone("WIB", 7*3600)),
+			Summary:     "Kunjungan; rumah",
+			Description: "Aplikasi: A-1\nPenerima: Budi",
+			Lat:         &lat,
+			Lng:         &lng,
+			Status:      StatusCancelled,
+			Categories:  []string{"KUNJUNGAN", "A,B"},
+		}},
+	}
+	out, err := cal.Marshal(stamp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"X-WR-CALNAME:Jadwal Budi\\, TKSK\r\n",
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H\r\n",
+		"DTSTAMP:20251103T083000Z\r\n",
+		"DTSTART:20251104T020000Z\r\n",
+		"DTEND:20251104T030000Z\r\n",
+		"SEQUENCE:3\r\n",
+		"STATUS:CANCELLED\r\n",
+		"SUMMARY:Kunjungan\\; rumah\r\n",
+		"DESCRIPTION:Aplikasi: A-1\\nPenerima: Budi\r\n",
+		"GEO:-6.200000;106.816666\r\n",
+		"CATEGORIES:KUNJUNGAN,A\\,B\r\n",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("feed is missing %q", want)
+		}
+	}
+}
+
+func TestEncodeRejectsInvalidEvents(t *testing.T) {
+	tests := map[string]Event{
+		"no UID":          {Start: stamp},
+		"no start":        {UID: "a@test"},
+		"ends before":     {UID: "a@test", Start: stamp, End: stamp.Add(-time.Minute)},
+		"negative seq":    {UID: "a@test", Start: stamp, Sequence: -1},
+		"unknown status":  {UID: "a@test", Start: stamp, Status: "POSTPONED"},
+		"lowercase state": {UID: "a@test", Start: stamp, Status: "cancelled"},
+	}
+	for name, event := range tests {
+		cal := Calendar{ProdID: "-//e-kyc//test//ID", Events: []Event{event}}
+		if _, err := cal.Marshal(stamp); err == nil {
+			t.Errorf("%s: encoded without error", name)
+		}
+	}
+	if _, err := (&Calendar{}).Marshal(stamp); err == nil {
+		t.Error("calendar without PRODID encoded without error")
+	}
+}
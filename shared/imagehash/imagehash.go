@@ -0,0 +1,119 @@
+/*
+Package imagehash computes perceptual hashes, which stay close when an image is
+re-encoded, resized or lightly edited, so re-used photos can be found by Hamming distance.
+*/
+package imagehash
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/bits"
+)
+
+const (
+	// samplesPerCell bounds the pixels averaged per hash cell, keeping large photos cheap.
+	samplesPerCell = 16
+	// minCellContrast is the mean luma difference, out of 255, between neighbouring cells
+	// below which the bits only record noise.
+	minCellContrast = 2.0
+	// minSetBits is how many bits of a hash, and of its complement, must be set for it
+	// to say more than "brighter to one side".
+	minSetBits = 8
+)
+
+const gridWidth, gridHeight = 9, 8
+
+type grid [gridHeight][gridWidth]float64
+
+// DHash is the 64-bit difference hash of img: the image is reduced to 9x8 grey cells and
+// each bit says whether a cell is brighter than its right neighbour.
+func DHash(img image.Image) uint64 {
+	return lumaGrid(img).hash()
+}
+
+// Distinctive reports whether img's hash tells it apart from other pictures. A blank,
+// black or washed-out frame hashes to noise or to zero, and a plain gradient to nearly
+// all zeros or ones, so such hashes match unrelated photos.
+func Distinctive(img image.Image) bool {
+	g := lumaGrid(img)
+	if ones := bits.OnesCount64(g.hash()); ones < minSetBits || ones > 64-minSetBits {
+		return false
+	}
+	return g.contrast() >= minCellContrast
+}
+
+// Distance is the number of bits two hashes differ in, 0 for the same image.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func lumaGrid(img image.Image) *grid {
+	bounds := img.Bounds()
+	var g grid
+	for y := range gridHeight {
+		for x := range gridWidth {
+			g[y][x] = cellLuma(img, bounds, x, y, gridWidth, gridHeight)
+		}
+	}
+	return &g
+}
+
+func (g *grid) hash() uint64 {
+	var hash uint64
+	for y := range gridHeight {
+		for x := range gridWidth - 1 {
+			hash <<= 1
+			if g[y][x] > g[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// contrast is the mean luma difference between horizontal neighbours, the pairs the
+// hash bits compare.
+func (g *grid) contrast() float64 {
+	var sum float64
+	for y := range gridHeight {
+		for x := range gridWidth - 1 {
+			sum += math.Abs(g[y][x] - g[y][x+1])
+		}
+	}
+	return sum / ((gridWidth - 1) * gridHeight)
+}
+
+// cellLuma averages the luma of a grid of sample points in cell (cx, cy).
+func cellLuma(img image.Image, bounds image.Rectangle, cx, cy, width, height int) float64 {
+	x0 := bounds.Min.X + cx*bounds.Dx()/width
+	x1 := bounds.Min.X + (cx+1)*bounds.Dx()/width
+	y0 := bounds.Min.Y + cy*bounds.Dy()/height
+	y1 := bounds.Min.Y + (cy+1)*bounds.Dy()/height
+	if x1 <= x0 {
+		x1 = x0 + 1
+	}
+	if y1 <= y0 {
+		y1 = y0 + 1
+	}
+	stepX := max(1, (x1-x0)/samplesPerCell)
+	stepY := max(1, (y1-y0)/samplesPerCell)
+	var sum, n float64
+	for y := y0; y < y1 && y < bounds.Max.Y; y += stepY {
+		for x := x0; x < x1 && x < bounds.Max.X; x += stepX {
+			sum += luma(img, x, y)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / n
+}
+
+func luma(img image.Image, x, y int) float64 {
+	if ycc, ok := img.(*image.YCbCr); ok {
+		return float64(ycc.Y[ycc.YOffset(x, y)])
+	}
+	return float64(color.GrayModel.Convert(img.At(x, y)).(color.Gray).Y)
+}
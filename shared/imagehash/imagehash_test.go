@@ -0,0 +1,195 @@
+package imagehash
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"testing"
+)
+
+// scene is a 640x480 grey image of 40-pixel blocks in pseudo-random shades, enough
+// structure for neighbouring cells to differ. Each seed gives a different scene.
+func scene(seed uint32) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, 640, 480))
+	state := seed*2654435761 + 1
+	for by := 0; by < 480; by += 40 {
+		for bx := 0; bx < 640; bx += 40 {
+			state = state*1664525 + 1013904223
+			shade := color.Gray{Y: uint8(state >> 24)}
+			draw.Draw(img, image.Rect(bx, by, bx+40, by+40), image.NewUniform(shade), image.Point{}, draw.Src)
+		}
+	}
+	return img
+}
+
+// resize scales img to w x h by nearest neighbour.
+func resize(img image.Image, w, h int) *image.Gray {
+	b := img.Bounds()
+	out := image.NewGray(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			out.Set(x, y, img.At(b.Min.X+x*b.Dx()/w, b.Min.Y+y*b.Dy()/h))
+		}
+	}
+	return out
+}
+
+func reencode(t *testing.T, img image.Image, quality int) image.Image {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := jpeg.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func brighten(img *image.Gray, delta int) *image.Gray {
+	out := image.NewGray(img.Bounds())
+	for i, v := range img.Pix {
+		out.Pix[i] = uint8(min(255, int(v)+delta))
+	}
+	return out
+}
+
+func TestDHashSimilarImages(t *testing.T) {
+	original := scene(1)
+	offset := image.NewGray(image.Rect(100, 50, 740, 530))
+	draw.Draw(offset, offset.Bounds(), original, image.Point{}, draw.Src)
+
+	tests := []struct {
+		name    string
+		img     image.Image
+		maxDist int
+	}{
+		{"same image", original, 0},
+		{"image not at the origin", offset, 0},
+		{"re-encoded as JPEG", reencode(t, original, 75), 2},
+		{"heavily compressed", reencode(t, original, 20), 4},
+		{"halved", resize(original, 320, 240), 2},
+		{"thumbnail", resize(original, 96, 72), 4},
+		{"brighter", brighten(original, 10), 2},
+	}
+	want := DHash(original)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := Distance(want, DHash(tt.img)); d > tt.maxDist {
+				t.Errorf("distance %d, want at most %d", d, tt.maxDist)
+			}
+		})
+	}
+}
+
+func TestDHashDifferentImages(t *testing.T) {
+	for seed := uint32(2); seed <= 6; seed++ {
+		if d := Distance(DHash(scene(1)), DHash(scene(seed))); d < 10 {
+			t.Errorf("scene %d is %d bits from scene 1", seed, d)
+		}
+	}
+}
+
+func TestDHashGrid(t *testing.T) {
+	// Left to right darker: every cell is brighter than its right neighbour.
+	falling := image.NewGray(image.Rect(0, 0, 90, 80))
+	rising := image.NewGray(image.Rect(0, 0, 90, 80))
+	for y := range 80 {
+		for x := range 90 {
+			falling.SetGray(x, y, color.Gray{Y: uint8(255 - x*2)})
+			rising.SetGray(x, y, color.Gray{Y: uint8(x * 2)})
+		}
+	}
+
+	tests := []struct {
+		name string
+		img  image.Image
+		want uint64
+	}{
+		{"falling", falling, math.MaxUint64},
+		{"rising", rising, 0},
+		{"uniform", image.NewUniform(color.Gray{Y: 128}), 0},
+		{"one pixel", image.NewGray(image.Rect(0, 0, 1, 1)), 0},
+		{"empty", image.NewGray(image.Rectangle{}), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DHash(tt.img); got != tt.want {
+				t.Errorf("DHash = %016x, want %016x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDistinctive(t *testing.T) {
+	// A dim scene keeps its structure at under a hundredth of the contrast.
+	dim := image.NewGray(image.Rect(0, 0, 640, 480))
+	for i, v := range scene(1).Pix {
+		dim.Pix[i] = 10 + v/128
+	}
+	rising := image.NewGray(image.Rect(0, 0, 640, 480))
+	for y := range 480 {
+		for x := range 640 {
+			rising.SetGray(x, y, color.Gray{Y: uint8(x * 255 / 640)})
+		}
+	}
+
+	tests := []struct {
+		name string
+		img  image.Image
+		want bool
+	}{
+		{"scene", scene(1), true},
+		{"re-encoded scene", reencode(t, scene(2), 75), true},
+		{"dim scene", dim, false},
+		{"black frame", reencode(t, image.NewGray(image.Rect(0, 0, 640, 480)), 75), false},
+		{"overexposed", brighten(scene(3), 255), false},
+		{"uniform", image.NewUniform(color.Gray{Y: 128}), false},
+		{"gradient", rising, false},
+		{"empty", image.NewGray(image.Rectangle{}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Distinctive(tt.img); got != tt.want {
+				t.Errorf("Distinctive = %v, want %v (hash %016x)", got, tt.want, DHash(tt.img))
+			}
+		})
+	}
+}
+
+func TestDHashReadsYCbCrLuma(t *testing.T) {
+	grey := scene(3)
+	ycc := image.NewYCbCr(grey.Bounds(), image.YCbCrSubsampleRatio420)
+	copy(ycc.Y, grey.Pix)
+	for i := range ycc.Cb {
+		ycc.Cb[i], ycc.Cr[i] = 128, 128
+	}
+	if a, b := DHash(grey), DHash(ycc); a != b {
+		t.Errorf("YCbCr hash %016x, grey hash %016x", b, a)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b uint64
+		want int
+	}{
+		{0, 0, 0},
+		{0, 1, 1},
+		{0xff, 0x0f, 4},
+		{0, math.MaxUint64, 64},
+		{0xaaaaaaaaaaaaaaaa, 0x5555555555555555, 64},
+	}
+	for _, tt := range tests {
+		if got := Distance(tt.a, tt.b); got != tt.want {
+			t.Errorf("Distance(%x, %x) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if Distance(tt.b, tt.a) != Distance(tt.a, tt.b) {
+			t.Errorf("Distance(%x, %x) is not symmetric", tt.a, tt.b)
+		}
+	}
+}
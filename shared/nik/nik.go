@@ -0,0 +1,205 @@
+/*
+Package nik parses and validates the 16-digit Indonesian population number (NIK).
+
+Layout: PPKKCC DDMMYY SSSS
+  - PP     province code (Kemendagri)
+  - KK     regency/city code within the province
+  - CC     district (kecamatan) code within the regency
+  - DDMMYY birth date; women have 40 added to the day
+  - SSSS   registration serial, never 0000
+*/
+package nik
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrLength    = errors.New("nik must be 16 digits")
+	ErrNonDigit  = errors.New("nik must only contain digits")
+	ErrProvince  = errors.New("nik has an unknown province code")
+	ErrRegion    = errors.New("nik has an empty regency or district code")
+	ErrBirthDate = errors.New("nik encodes an invalid birth date")
+	ErrSerial    = errors.New("nik serial must not be 0000")
+)
+
+type Sex string
+
+const (
+	Male   Sex = "M"
+	Female Sex = "F"
+)
+
+// NIK is a parsed population number.
+type NIK struct {
+	Raw          string    `json:"nik"`
+	ProvinceCode string    `json:"provinceCode"`
+	RegencyCode  string    `json:"regencyCode"`
+	DistrictCode string    `json:"districtCode"`
+	BirthDate    time.Time `json:"birthDate"`
+	Sex          Sex       `json:"sex"`
+	Serial       string    `json:"serial"`
+}
+
+// Parse validates raw and decodes its fields. Spaces, dots and dashes are ignored so
+// formatted input such as "3204.1201.0190.0001" is accepted.
+func Parse(raw string) (NIK, error) {
+	return ParseAt(raw, time.Now())
+}
+
+// ParseAt is Parse with an explicit reference time, used to resolve the two-digit year:
+// years after the reference year are taken to be in the previous century.
+func ParseAt(raw string, now time.Time) (NIK, error) {
+	digits := Normalize(raw)
+	if len(digits) != 16 {
+		return NIK{}, ErrLength
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return NIK{}, ErrNonDigit
+		}
+	}
+
+	province := digits[0:2]
+	if _, ok := provinces[province]; !ok {
+		return NIK{}, ErrProvince
+	}
+	if digits[2:4] == "00" || digits[4:6] == "00" {
+		return NIK{}, ErrRegion
+	}
+
+	day, _ := strconv.Atoi(digits[6:8])
+	month, _ := strconv.Atoi(digits[8:10])
+	year, _ := strconv.Atoi(digits[10:12])
+	sex := Male
+	if day > 40 {
+		sex = Female
+		day -= 40
+	}
+	century := (now.Year() / 100) * 100
+	if year > now.Year()%100 {
+		century -= 100
+	}
+	year += century
+	birth := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if day < 1 || month < 1 || month > 12 || birth.Day() != day || birth.Month() != time.Month(month) {
+		return NIK{}, ErrBirthDate
+	}
+
+	serial := digits[12:16]
+	if serial == "0000" {
+		return NIK{}, ErrSerial
+	}
+
+	return NIK{
+		Raw:          digits,
+		ProvinceCode: province,
+		RegencyCode:  digits[0:4],
+		DistrictCode: digits[0:6],
+		BirthDate:    birth,
+		Sex:          sex,
+		Serial:       serial,
+	}, nil
+}
+
+// Normalize strips the separators people commonly type inside a NIK.
+func Normalize(raw string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '.', '-', '\t':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(raw))
+}
+
+// ProvinceName returns the province the NIK was issued in.
+func (n NIK) ProvinceName() string {
+	return provinces[n.ProvinceCode]
+}
+
+// Claims are the details an applicant submitted next to the NIK. Empty fields are not
+// checked.
+type Claims struct {
+	BirthDate *time.Time
+	BirthYear int
+	Sex       Sex
+	// Province may be a code ("21") or a name ("Kepulauan Riau", "Kepri").
+	Province string
+	// Regency and District are only compared when given as Kemendagri codes,
+	// e.g. "21.71" and "21.71.01".
+	Regency  string
+	District string
+}
+
+// Mismatch describes one submitted detail that contradicts the NIK.
+type Mismatch struct {
+	Field     string `json:"field"`
+	FromNIK   string `json:"fromNik"`
+	Submitted string `json:"submitted"`
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s: nik=%s submitted=%s", m.Field, m.FromNIK, m.Submitted)
+}
+
+// CrossCheck compares the NIK against submitted claims and lists every disagreement.
+func (n NIK) CrossCheck(c Claims) []Mismatch {
+	var out []Mismatch
+	if c.BirthDate != nil {
+		b := c.BirthDate.UTC()
+		if b.Year() != n.BirthDate.Year() || b.Month() != n.BirthDate.Month() || b.Day() != n.BirthDate.Day() {
+			out = append(out, Mismatch{"birthDate", n.BirthDate.Format("2006-01-02"), b.Format("2006-01-02")})
+		}
+	} else if c.BirthYear > 0 && c.BirthYear != n.BirthDate.Year() {
+		out = append(out, Mismatch{"birthYear", strconv.Itoa(n.BirthDate.Year()), strconv.Itoa(c.BirthYear)})
+	}
+	if c.Sex != "" && c.Sex != n.Sex {
+		out = append(out, Mismatch{"sex", string(n.Sex), string(c.Sex)})
+	}
+	if p := strings.TrimSpace(c.Province); p != "" {
+		if code, ok := ProvinceCode(p); ok && code != n.ProvinceCode {
+			out = append(out, Mismatch{"province", n.ProvinceCode + " " + n.ProvinceName(), p})
+		}
+	}
+	if code, ok := numericCode(c.Regency, 4); ok && code != n.RegencyCode {
+		out = append(out, Mismatch{"regency", n.RegencyCode, c.Regency})
+	}
+	if code, ok := numericCode(c.District, 6); ok && code != n.DistrictCode {
+		out = append(out, Mismatch{"district", n.DistrictCode, c.District})
+	}
+	return out
+}
+
+// ProvinceCode resolves a province code or name (including common abbreviations).
+func ProvinceCode(value string) (string, bool) {
+	if code, ok := numericCode(value, 2); ok {
+		_, known := provinces[code]
+		return code, known
+	}
+	key := strings.ToLower(strings.Join(strings.Fields(value), " "))
+	key = strings.TrimPrefix(key, "provinsi ")
+	key = strings.TrimPrefix(key, "prov. ")
+	key = strings.TrimPrefix(key, "prov ")
+	code, ok := provinceNames[key]
+	return code, ok
+}
+
+// numericCode accepts "2171", "21.71" or "21 71" and returns the digits when they have
+// the expected length.
+func numericCode(value string, length int) (string, bool) {
+	digits := Normalize(value)
+	if len(digits) != length {
+		return "", false
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return digits, true
+}
@@ -0,0 +1,159 @@
+package nik
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// now is the reference time two-digit years are resolved against.
+var now = time.Date(2025, time.November, 3, 8, 0, 0, 0, time.UTC)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseAt(t *testing.T) {
+	tests := []struct {
+		name  string
+		raw   string
+		birth time.Time
+		sex   Sex
+	}{
+		{"male", "3171012501900001", date(1990, time.January, 25), Male},
+		{"female adds 40 to the day", "3171016501900001", date(1990, time.January, 25), Female},
+		{"formatted", "3171.0125.0190.0001", date(1990, time.January, 25), Male},
+		{"spaces and dashes", " 3171 01-2501 90-0001 ", date(1990, time.January, 25), Male},
+		{"this year", "3171010111250001", date(2025, time.November, 1), Male},
+		{"next year is last century", "3171010101260001", date(1926, time.January, 1), Male},
+		{"leap day 2000", "3171012902000001", date(2000, time.February, 29), Male},
+		{"leap day 1996, female", "3171016902960001", date(1996, time.February, 29), Female},
+		{"female on the 31st", "3171017112990001", date(1999, time.December, 31), Female},
+		{"2022 Papua province", "9701010101900001", date(1990, time.January, 1), Male},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := ParseAt(tt.raw, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !n.BirthDate.Equal(tt.birth) || n.Sex != tt.sex {
+				t.Errorf("born %s %s, want %s %s", n.BirthDate.Format(time.DateOnly), n.Sex, tt.birth.Format(time.DateOnly), tt.sex)
+			}
+			if len(n.Raw) != 16 || n.RegencyCode != n.Raw[:4] || n.DistrictCode != n.Raw[:6] || n.Serial != n.Raw[12:] {
+				t.Errorf("decoded %+v", n)
+			}
+		})
+	}
+}
+
+func TestParseAtRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want error
+	}{
+		{"short", "317101250190001", ErrLength},
+		{"long", "31710125019000011", ErrLength},
+		{"empty", "", ErrLength},
+		{"letter", "31710125019000A1", ErrNonDigit},
+		{"unknown province", "9901012501900001", ErrProvince},
+		{"retired province code", "2001012501900001", ErrProvince},
+		{"province 00", "0001012501900001", ErrProvince},
+		{"regency 00", "3100012501900001", ErrRegion},
+		{"district 00", "3171002501900001", ErrRegion},
+		{"day 00", "3171010001900001", ErrBirthDate},
+		{"day 32", "3171013201900001", ErrBirthDate},
+		{"female day 40", "3171014001900001", ErrBirthDate},
+		{"female day 72", "3171017201900001", ErrBirthDate},
+		{"month 00", "3171012500900001", ErrBirthDate},
+		{"month 13", "3171012513900001", ErrBirthDate},
+		{"30 February", "3171013002900001", ErrBirthDate},
+		{"29 February 2001", "3171012902010001", ErrBirthDate},
+		{"31 April", "3171013104900001", ErrBirthDate},
+		{"serial 0000", "3171012501900000", ErrSerial},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseAt(tt.raw, now); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvinceCode(t *testing.T) {
+	tests := []struct {
+		value string
+		code  string
+		ok    bool
+	}{
+		{"31", "31", true},
+		{"3 1", "31", true},
+		{"99", "99", false},
+		{"Kepulauan Riau", "21", true},
+		{"  kepulauan   riau ", "21", true},
+		{"Provinsi Jawa Barat", "32", true},
+		{"Prov. Jawa Timur", "35", true},
+		{"prov DKI Jakarta", "31", true},
+		{"Kepri", "21", true},
+		{"DIY", "34", true},
+		{"Papua Barat Daya", "92", true},
+		{"Papua Pegunungan", "97", true},
+		{"Atlantis", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			code, ok := ProvinceCode(tt.value)
+			if code != tt.code || ok != tt.ok {
+				t.Errorf("ProvinceCode(%q) = %q, %v, want %q, %v", tt.value, code, ok, tt.code, tt.ok)
+			}
+		})
+	}
+}
+
+func TestCrossCheck(t *testing.T) {
+	n, err := ParseAt("3171016501900001", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	birth := date(1990, time.January, 25)
+	otherDay := date(1990, time.January, 26)
+	// Midday in WIB is still the same day in UTC.
+	wibMidday := time.Date(1990, time.January, 25, 12, 0, 0, 0, time.FixedZone("WIB", 7*3600))
+
+	tests := []struct {
+		name   string
+		claims Claims
+		fields []string
+	}{
+		{"nothing claimed", Claims{}, nil},
+		{"all agree", Claims{BirthDate: &birth, Sex: Female, Province: "DKI Jakarta", Regency: "31.71", District: "31.71.01"}, nil},
+		{"birth date in another zone", Claims{BirthDate: &wibMidday}, nil},
+		{"birth date", Claims{BirthDate: &otherDay}, []string{"birthDate"}},
+		{"birth year", Claims{BirthYear: 1991}, []string{"birthYear"}},
+		{"birth date wins over year", Claims{BirthDate: &birth, BirthYear: 1991}, nil},
+		{"sex", Claims{Sex: Male}, []string{"sex"}},
+		{"province by name", Claims{Province: "Jabar"}, []string{"province"}},
+		{"province by code", Claims{Province: "32"}, []string{"province"}},
+		{"unknown province is not compared", Claims{Province: "Atlantis"}, nil},
+		{"regency", Claims{Regency: "3172"}, []string{"regency"}},
+		{"regency name is not compared", Claims{Regency: "Jakarta Pusat"}, nil},
+		{"district", Claims{District: "31.71.02"}, []string{"district"}},
+		{"several", Claims{BirthYear: 1989, Sex: Male, Province: "Banten"}, []string{"birthYear", "sex", "province"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := n.CrossCheck(tt.claims)
+			if len(got) != len(tt.fields) {
+				t.Fatalf("mismatches %v, want %v", got, tt.fields)
+			}
+			for i, m := range got {
+				if m.Field != tt.fields[i] {
+					t.Errorf("mismatch %d is %s, want %s", i, m, tt.fields[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package nik
+
+import "strings"
+
+// provinces maps Kemendagri province codes to their names, including the 2022 Papua
+// provinces.
+var provinces = map[string]string{
+	"11": "Aceh",
+	"12": "Sumatera Utara",
+	"13": "Sumatera Barat",
+	"14": "Riau",
+	"15": "Jambi",
+	"16": "Sumatera Selatan",
+	"17": "Bengkulu",
+	"18": "Lampung",
+	"19": "Kepulauan Bangka Belitung",
+	"21": "Kepulauan Riau",
+	"31": "DKI Jakarta",
+	"32": "Jawa Barat",
+	"33": "Jawa Tengah",
+	"34": "DI Yogyakarta",
+	"35": "Jawa Timur",
+	"36": "Banten",
+	"51": "Bali",
+	"52": "Nusa Tenggara Barat",
+	"53": "Nusa Tenggara Timur",
+	"61": "Kalimantan Barat",
+	"62": "Kalimantan Tengah",
+	"63": "Kalimantan Selatan",
+	"64": "Kalimantan Timur",
+	"65": "Kalimantan Utara",
+	"71": "Sulawesi Utara",
+	"72": "Sulawesi Tengah",
+	"73": "Sulawesi Selatan",
+	"74": "Sulawesi Tenggara",
+	"75": "Gorontalo",
+	"76": "Sulawesi Barat",
+	"81": "Maluku",
+	"82": "Maluku Utara",
+	"91": "Papua Barat",
+	"92": "Papua Barat Daya",
+	"94": "Papua",
+	"95": "Papua Selatan",
+	"96": "Papua Tengah",
+	"97": "Papua Pegunungan",
+}
+
+// provinceNames indexes lower-cased names and the abbreviations used in our data.
+var provinceNames = func() map[string]string {
+	names := map[string]string{
+		"nad":         "11",
+		"sumut":       "12",
+		"sumbar":      "13",
+		"babel":       "19",
+		"kepri":       "21",
+		"jakarta":     "31",
+		"dki":         "31",
+		"jabar":       "32",
+		"jateng":      "33",
+		"diy":         "34",
+		"yogyakarta":  "34",
+		"jatim":       "35",
+		"ntb":         "52",
+		"ntt":         "53",
+		"kalbar":      "61",
+		"kalteng":     "62",
+		"kalsel":      "63",
+		"kaltim":      "64",
+		"kaltara":     "65",
+		"sulut":       "71",
+		"sulteng":     "72",
+		"sulsel":      "73",
+		"sultra":      "74",
+		"sulbar":      "76",
+		"malut":       "82",
+		"sumsel":      "16",
+		"papua barat": "91",
+	}
+	for code, name := range provinces {
+		names[strings.ToLower(name)] = code
+	}
+	return names
+}()
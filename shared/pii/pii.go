@@ -0,0 +1,388 @@
+/*
+Package pii seals personal data at rest with envelope encryption.
+
+Every value gets its own random AES-256 data key. The value is sealed with AES-GCM under
+that data key, and the data key is wrapped with AES-GCM under a master key. A sealed
+value is a self-describing string:
+
+	pii:v1:<master key id>:<wrapped data key>:<ciphertext>
+
+so rotating the master key only re-wraps the data key; the ciphertext never changes.
+
+Equality lookups go through blind indexes: a keyed HMAC of the normalized value, which is
+deterministic and can back a unique index without revealing the value.
+*/
+package pii
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Fields name what a value is. The field is bound into the ciphertext and the blind index,
+// so a sealed NIK cannot be passed off as a phone number and equal values in different
+// fields index differently.
+const (
+	FieldNIK       = "users.nik"
+	FieldPhone     = "users.phone"
+	FieldEmail     = "users.email"
+	FieldApplicant = "ekyc_sessions.applicant"
+	// FieldPayoutAccount is sealed without a blind index, nothing looks it up.
+	FieldPayoutAccount = "beneficiaries.payout_account"
+	// The KTP fields read by OCR are sealed without blind indexes too.
+	FieldOcrNIK       = "ocr_results.nik"
+	FieldOcrName      = "ocr_results.name"
+	FieldOcrBirthDate = "ocr_results.birth_date"
+	FieldOcrAddress   = "ocr_results.address"
+)
+
+// Environment variables read by LoadKeyring. PII_MASTER_KEY takes precedence.
+const (
+	EnvMasterKey     = "PII_MASTER_KEY"
+	EnvMasterKeyFile = "PII_MASTER_KEY_FILE"
+)
+
+const (
+	sealedPrefix = "pii:v1:"
+	keySize      = 32
+)
+
+var (
+	ErrNoMasterKey = errors.New("pii: no master key configured, set " + EnvMasterKey + " or " + EnvMasterKeyFile)
+	ErrUnknownKey  = errors.New("pii: sealed with an unknown master key")
+	ErrMalformed   = errors.New("pii: malformed sealed value")
+)
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+type masterKey struct {
+	id    string
+	wrap  cipher.AEAD
+	index []byte
+}
+
+// Keyring holds the master keys. The first one is active: it wraps new data keys and
+// computes the blind indexes that get stored. The others are retired keys kept only to
+// open values sealed before a rotation.
+type Keyring struct {
+	keys []*masterKey
+	byID map[string]*masterKey
+}
+
+// LoadKeyring reads the keyring from PII_MASTER_KEY or, failing that, the file named by
+// PII_MASTER_KEY_FILE. See ParseKeyring for the format.
+func LoadKeyring() (*Keyring, error) {
+	if spec := strings.TrimSpace(os.Getenv(EnvMasterKey)); spec != "" {
+		return ParseKeyring(spec)
+	}
+	path := strings.TrimSpace(os.Getenv(EnvMasterKeyFile))
+	if path == "" {
+		return nil, ErrNoMasterKey
+	}
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("pii: read key file: %w", err)
+	}
+	return ParseKeyring(string(blob))
+}
+
+// ParseKeyring parses "<id>:<base64 32-byte key>" entries separated by commas or newlines,
+// active key first. Blank lines and lines starting with # are ignored, so a key file can
+// list the new key above the retired ones during a rotation.
+func ParseKeyring(spec string) (*Keyring, error) {
+	ring := &Keyring{byID: map[string]*masterKey{}}
+	scanner := bufio.NewScanner(strings.NewReader(strings.ReplaceAll(spec, ",", "\n")))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(line, ":")
+		if !ok || !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("pii: key entry must be <id>:<base64 key>")
+		}
+		if _, dup := ring.byID[id]; dup {
+			return nil, fmt.Errorf("pii: duplicate key id %q", id)
+		}
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(raw) != keySize {
+			return nil, fmt.Errorf("pii: key %q must be %d bytes of base64", id, keySize)
+		}
+		key, err := newMasterKey(id, raw)
+		if err != nil {
+			return nil, err
+		}
+		ring.keys = append(ring.keys, key)
+		ring.byID[id] = key
+	}
+	if len(ring.keys) == 0 {
+		return nil, ErrNoMasterKey
+	}
+	return ring, nil
+}
+
+// GenerateKey returns a fresh key entry for a keyring, e.g. "k2:<base64>".
+func GenerateKey(id string) (string, error) {
+	if !keyIDPattern.MatchString(id) {
+		return "", fmt.Errorf("pii: invalid key id %q", id)
+	}
+	raw := make([]byte, keySize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return id + ":" + base64.StdEncoding.EncodeToString(raw), nil
+}
+
+func newMasterKey(id string, raw []byte) (*masterKey, error) {
+	// Separate subkeys for wrapping and indexing, so neither use weakens the other.
+	wrap, err := newGCM(derive(raw, "key-wrap"))
+	if err != nil {
+		return nil, err
+	}
+	return &masterKey{id: id, wrap: wrap, index: derive(raw, "blind-index")}, nil
+}
+
+func derive(master []byte, label string) []byte {
+	mac := hmac.New(sha256.New, master)
+	mac.Write([]byte("e-kyc/pii/" + label))
+	return mac.Sum(nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ActiveKeyID is the id of the key new values are sealed with.
+func (k *Keyring) ActiveKeyID() string {
+	return k.keys[0].id
+}
+
+// Seal encrypts value for field under a new data key wrapped by the active master key.
+func (k *Keyring) Seal(field, value string) (string, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	active := k.keys[0]
+	wrapped, err := seal(active.wrap, dataKey, []byte(active.id))
+	if err != nil {
+		return "", err
+	}
+	body, err := seal(aead, []byte(value), []byte(field))
+	if err != nil {
+		return "", err
+	}
+	return sealedPrefix + active.id + ":" + encode(wrapped) + ":" + encode(body), nil
+}
+
+// Open decrypts a value produced by Seal. Values that are not sealed are returned as is,
+// so rows written before the encryption migration still read correctly.
+func (k *Keyring) Open(field, value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	_, dataKey, body, err := k.unwrap(value)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plain, err := open(aead, body, []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("pii: open %s: %w", field, err)
+	}
+	return string(plain), nil
+}
+
+// Rewrap re-wraps the data key of a sealed value under the active master key. It reports
+// false when the value already uses the active key.
+func (k *Keyring) Rewrap(value string) (string, bool, error) {
+	key, dataKey, body, err := k.unwrap(value)
+	if err != nil {
+		return "", false, err
+	}
+	active := k.keys[0]
+	if key == active {
+		return value, false, nil
+	}
+	wrapped, err := seal(active.wrap, dataKey, []byte(active.id))
+	if err != nil {
+		return "", false, err
+	}
+	return sealedPrefix + active.id + ":" + encode(wrapped) + ":" + encode(body), true, nil
+}
+
+func (k *Keyring) unwrap(value string) (*masterKey, []byte, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(value, sealedPrefix), ":")
+	if !IsSealed(value) || len(parts) != 3 {
+		return nil, nil, nil, ErrMalformed
+	}
+	key, ok := k.byID[parts[0]]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("%w %q", ErrUnknownKey, parts[0])
+	}
+	wrapped, err := decode(parts[1])
+	if err != nil {
+		return nil, nil, nil, ErrMalformed
+	}
+	body, err := decode(parts[2])
+	if err != nil {
+		return nil, nil, nil, ErrMalformed
+	}
+	dataKey, err := open(key.wrap, wrapped, []byte(key.id))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("pii: unwrap data key: %w", err)
+	}
+	return key, dataKey, body, nil
+}
+
+// Column is what gets stored for a nullable PII column: the sealed value and its blind
+// index, both nil when the value is empty.
+type Column struct {
+	Sealed *string
+	Index  *string
+}
+
+// SealColumn seals value and computes its blind index.
+func (k *Keyring) SealColumn(field, value string) (Column, error) {
+	index := k.BlindIndex(field, value)
+	if index == "" {
+		return Column{}, nil
+	}
+	sealed, err := k.Seal(field, strings.TrimSpace(value))
+	if err != nil {
+		return Column{}, err
+	}
+	return Column{Sealed: &sealed, Index: &index}, nil
+}
+
+// UserColumns is the stored form of users.nik, users.phone and users.email.
+type UserColumns struct {
+	NIK   Column
+	Phone Column
+	Email Column
+}
+
+// SealUser seals the three users PII columns at once.
+func (k *Keyring) SealUser(nik, phone, email string) (UserColumns, error) {
+	var (
+		out UserColumns
+		err error
+	)
+	if out.NIK, err = k.SealColumn(FieldNIK, nik); err != nil {
+		return out, err
+	}
+	if out.Phone, err = k.SealColumn(FieldPhone, phone); err != nil {
+		return out, err
+	}
+	if out.Email, err = k.SealColumn(FieldEmail, email); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// IsSealed reports whether value looks like the output of Seal.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// KeyID returns the master key id a sealed value is wrapped with.
+func KeyID(value string) string {
+	if !IsSealed(value) {
+		return ""
+	}
+	id, _, _ := strings.Cut(strings.TrimPrefix(value, sealedPrefix), ":")
+	return id
+}
+
+// BlindIndex is the stored lookup token for value under the active key. Empty values have
+// no index so they never collide in a unique constraint.
+func (k *Keyring) BlindIndex(field, value string) string {
+	return blindIndex(k.keys[0], field, value)
+}
+
+// BlindIndexes returns the token under every key, active first. Lookups match any of them
+// so rows not yet rotated are still found.
+func (k *Keyring) BlindIndexes(field, value string) []string {
+	if Normalize(field, value) == "" {
+		return []string{}
+	}
+	out := make([]string, 0, len(k.keys))
+	for _, key := range k.keys {
+		out = append(out, blindIndex(key, field, value))
+	}
+	return out
+}
+
+func blindIndex(key *masterKey, field, value string) string {
+	normalized := Normalize(field, value)
+	if normalized == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, key.index)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+var nonDigits = regexp.MustCompile(`\D+`)
+
+// Normalize is the canonical form a blind index is computed over: digits only for phone
+// numbers, lower case for email, trimmed otherwise.
+func Normalize(field, value string) string {
+	value = strings.TrimSpace(value)
+	switch field {
+	case FieldPhone:
+		return nonDigits.ReplaceAllString(value, "")
+	case FieldEmail:
+		return strings.ToLower(value)
+	default:
+		return value
+	}
+}
+
+func seal(aead cipher.AEAD, plain, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, aad), nil
+}
+
+func open(aead cipher.AEAD, sealed, aad []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	nonce, body := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, body, aad)
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,251 @@
+package pii
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testKeyrings returns a keyring with only the old key and one rotated to a new key with
+// the old one retired.
+func testKeyrings(t *testing.T) (old, rotated *Keyring) {
+	t.Helper()
+	oldEntry, err := GenerateKey("old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newEntry, err := GenerateKey("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old, err = ParseKeyring(oldEntry); err != nil {
+		t.Fatal(err)
+	}
+	if rotated, err = ParseKeyring("# rotation\n" + newEntry + "\n\n" + oldEntry); err != nil {
+		t.Fatal(err)
+	}
+	return old, rotated
+}
+
+func TestSealOpenRoundTrip(t *testing.T) {
+	keys, _ := testKeyrings(t)
+	tests := []struct {
+		name, field, value string
+	}{
+		{"nik", FieldNIK, "3171012501900001"},
+		{"phone", FieldPhone, "+62 812-3456-7890"},
+		{"email", FieldEmail, "Siti@Example.ID"},
+		{"empty", FieldOcrName, ""},
+		{"unicode", FieldOcrAddress, "Jl. Merdeka No. 1, RT 01/RW 02 – Cempaka Putih"},
+		{"colons", FieldApplicant, `{"name":"pii:v1:x:y:z"}`},
+		{"long", FieldOcrAddress, strings.Repeat("alamat ", 500)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := keys.Seal(tt.field, tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IsSealed(sealed) || KeyID(sealed) != "old" {
+				t.Fatalf("sealed as %q", sealed)
+			}
+			if tt.value != "" && strings.Contains(sealed, tt.value) {
+				t.Error("sealed value contains the plaintext")
+			}
+			if again, _ := keys.Seal(tt.field, tt.value); again == sealed {
+				t.Error("sealing twice gave the same ciphertext")
+			}
+			plain, err := keys.Open(tt.field, sealed)
+			if err != nil || plain != tt.value {
+				t.Errorf("opened %q, %v", plain, err)
+			}
+			if _, err := keys.Open(FieldPayoutAccount, sealed); err == nil {
+				t.Error("opened under another field")
+			}
+		})
+	}
+}
+
+func TestOpenRejectsDamagedValues(t *testing.T) {
+	keys, _ := testKeyrings(t)
+	other, _ := testKeyrings(t)
+	sealed, err := keys.Seal(FieldNIK, "3171012501900001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(sealed, ":")
+	flip := func(s string) string {
+		b := []byte(s)
+		b[len(b)/2] ^= 'A' ^ 'B'
+		return string(b)
+	}
+
+	tests := []struct {
+		name  string
+		keys  *Keyring
+		value string
+		want  error
+	}{
+		{"missing part", keys, strings.Join(parts[:4], ":"), ErrMalformed},
+		{"extra part", keys, sealed + ":x", ErrMalformed},
+		{"bad base64", keys, strings.Join([]string{parts[0], parts[1], parts[2], "!!", parts[4]}, ":"), ErrMalformed},
+		{"short ciphertext", keys, strings.Join([]string{parts[0], parts[1], parts[2], parts[3], "AAAA"}, ":"), ErrMalformed},
+		{"same id, other key", other, sealed, nil},
+		{"renamed key", keys, strings.Join([]string{parts[0], parts[1], "new", parts[3], parts[4]}, ":"), ErrUnknownKey},
+		{"tampered data key", keys, strings.Join([]string{parts[0], parts[1], parts[2], flip(parts[3]), parts[4]}, ":"), nil},
+		{"tampered ciphertext", keys, strings.Join([]string{parts[0], parts[1], parts[2], parts[3], flip(parts[4])}, ":"), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plain, err := tt.keys.Open(FieldNIK, tt.value)
+			if err == nil {
+				t.Fatalf("opened to %q", plain)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if plain, err := keys.Open(FieldNIK, "3171012501900001"); err != nil || plain != "3171012501900001" {
+		t.Errorf("plaintext row opened to %q, %v", plain, err)
+	}
+}
+
+func TestRewrap(t *testing.T) {
+	old, rotated := testKeyrings(t)
+	tests := []struct {
+		field, value string
+	}{
+		{FieldNIK, "3171012501900001"},
+		{FieldPayoutAccount, "0123456789"},
+		{FieldOcrBirthDate, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			sealed, err := old.Seal(tt.field, tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plain, err := rotated.Open(tt.field, sealed); err != nil || plain != tt.value {
+				t.Fatalf("rotated keyring opened the old value to %q, %v", plain, err)
+			}
+
+			rewrapped, changed, err := rotated.Rewrap(sealed)
+			if err != nil || !changed {
+				t.Fatalf("rewrap changed=%v, %v", changed, err)
+			}
+			if KeyID(rewrapped) != "new" {
+				t.Errorf("rewrapped under %q, want new", KeyID(rewrapped))
+			}
+			// Only the data key is re-wrapped, the ciphertext stays as it was.
+			if oldBody, newBody := sealed[strings.LastIndex(sealed, ":"):], rewrapped[strings.LastIndex(rewrapped, ":"):]; oldBody != newBody {
+				t.Error("rewrap changed the ciphertext")
+			}
+			if plain, err := rotated.Open(tt.field, rewrapped); err != nil || plain != tt.value {
+				t.Errorf("opened the rewrapped value to %q, %v", plain, err)
+			}
+			if _, err := old.Open(tt.field, rewrapped); !errors.Is(err, ErrUnknownKey) {
+				t.Errorf("retired-only keyring opened the rewrapped value: %v", err)
+			}
+
+			again, changed, err := rotated.Rewrap(rewrapped)
+			if err != nil || changed || again != rewrapped {
+				t.Errorf("second rewrap changed=%v, %v", changed, err)
+			}
+		})
+	}
+
+	if _, _, err := rotated.Rewrap("3171012501900001"); !errors.Is(err, ErrMalformed) {
+		t.Errorf("rewrapped a plaintext value: %v", err)
+	}
+}
+
+func TestBlindIndexAcrossRotation(t *testing.T) {
+	old, rotated := testKeyrings(t)
+	tests := []struct {
+		name         string
+		field        string
+		value, alike string
+	}{
+		{"nik", FieldNIK, "3171012501900001", " 3171012501900001 "},
+		{"phone", FieldPhone, "+62 812-3456-7890", "6281234567890"},
+		{"email", FieldEmail, "Siti@Example.ID", "siti@example.id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := old.BlindIndex(tt.field, tt.value)
+			if stored == "" || stored != old.BlindIndex(tt.field, tt.value) {
+				t.Fatalf("index %q is not stable", stored)
+			}
+			if old.BlindIndex(tt.field, tt.alike) != stored {
+				t.Errorf("%q and %q index differently", tt.value, tt.alike)
+			}
+			if old.BlindIndex(FieldApplicant, tt.value) == stored {
+				t.Error("same value indexes alike in another field")
+			}
+
+			active := rotated.BlindIndex(tt.field, tt.value)
+			if active == stored {
+				t.Error("new key computes the old index")
+			}
+			lookups := rotated.BlindIndexes(tt.field, tt.alike)
+			if !slices.Equal(lookups, []string{active, stored}) {
+				t.Errorf("lookups %v, want the new then the old index", lookups)
+			}
+			if got := old.BlindIndexes(tt.field, tt.value); !slices.Equal(got, []string{stored}) {
+				t.Errorf("single-key lookups %v", got)
+			}
+		})
+	}
+
+	if index := rotated.BlindIndex(FieldPhone, " - "); index != "" {
+		t.Errorf("empty phone indexed as %q", index)
+	}
+	if lookups := rotated.BlindIndexes(FieldEmail, "  "); len(lookups) != 0 {
+		t.Errorf("empty email looked up as %v", lookups)
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	entry, err := GenerateKey("k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, encoded, _ := strings.Cut(entry, ":")
+
+	tests := []struct {
+		name, spec string
+		ok         bool
+		active     string
+	}{
+		{"one key", entry, true, "k1"},
+		{"comma separated", "k2:" + encoded + "," + entry, true, "k2"},
+		{"comments and blanks", "# keys\n\n" + entry + "\n", true, "k1"},
+		{"empty", "", false, ""},
+		{"only comments", "# none", false, ""},
+		{"no id", encoded, false, ""},
+		{"bad id", "k 1:" + encoded, false, ""},
+		{"duplicate id", entry + "\n" + entry, false, ""},
+		{"short key", "k1:c2hvcnQ=", false, ""},
+		{"not base64", "k1:%%%", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseKeyring(tt.spec)
+			if !tt.ok {
+				if err == nil {
+					t.Error("parsed without error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if keys.ActiveKeyID() != tt.active {
+				t.Errorf("active key %q, want %q", keys.ActiveKeyID(), tt.active)
+			}
+		})
+	}
+}
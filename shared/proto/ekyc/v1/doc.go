@@ -0,0 +1,7 @@
+/*
+Package ekycv1 holds the Go stubs generated from ekyc.proto, the contract between the
+Rust gateway, the backoffice and the Python AI support service.
+*/
+package ekycv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative ekyc/v1/ekyc.proto
@@ -0,0 +1,490 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: ekyc/v1/ekyc.proto
+
+package ekycv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ImagePayload struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       []byte                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	MimeType      string                 `protobuf:"bytes,2,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImagePayload) Reset() {
+	*x = ImagePayload{}
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImagePayload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImagePayload) ProtoMessage() {}
+
+func (x *ImagePayload) ProtoReflect() protoreflect.Message {
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImagePayload.ProtoReflect.Descriptor instead.
+func (*ImagePayload) Descriptor() ([]byte, []int) {
+	return file_ekyc_v1_ekyc_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ImagePayload) GetContent() []byte {
+	if x != nil {
+		return x.Content
+	}
+	return nil
+}
+
+func (x *ImagePayload) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+type FaceMatchJobHandle struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Queue         string                 `protobuf:"bytes,2,opt,name=queue,proto3" json:"queue,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FaceMatchJobHandle) Reset() {
+	*x = FaceMatchJobHandle{}
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FaceMatchJobHandle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FaceMatchJobHandle) ProtoMessage() {}
+
+func (x *FaceMatchJobHandle) ProtoReflect() protoreflect.Message {
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FaceMatchJobHandle.ProtoReflect.Descriptor instead.
+func (*FaceMatchJobHandle) Descriptor() ([]byte, []int) {
+	return file_ekyc_v1_ekyc_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FaceMatchJobHandle) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *FaceMatchJobHandle) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+type LivenessJobHandle struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Queue         string                 `protobuf:"bytes,2,opt,name=queue,proto3" json:"queue,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LivenessJobHandle) Reset() {
+	*x = LivenessJobHandle{}
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LivenessJobHandle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LivenessJobHandle) ProtoMessage() {}
+
+func (x *LivenessJobHandle) ProtoReflect() protoreflect.Message {
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LivenessJobHandle.ProtoReflect.Descriptor instead.
+func (*LivenessJobHandle) Descriptor() ([]byte, []int) {
+	return file_ekyc_v1_ekyc_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LivenessJobHandle) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *LivenessJobHandle) GetQueue() string {
+	if x != nil {
+		return x.Queue
+	}
+	return ""
+}
+
+type StartFaceMatchRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	SessionId          string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	KtpImage           *ImagePayload          `protobuf:"bytes,2,opt,name=ktp_image,json=ktpImage,proto3" json:"ktp_image,omitempty"`
+	SelfieImage        *ImagePayload          `protobuf:"bytes,3,opt,name=selfie_image,json=selfieImage,proto3" json:"selfie_image,omitempty"`
+	FaceMatchThreshold float64                `protobuf:"fixed64,4,opt,name=face_match_threshold,json=faceMatchThreshold,proto3" json:"face_match_threshold,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *StartFaceMatchRequest) Reset() {
+	*x = StartFaceMatchRequest{}
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartFaceMatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartFaceMatchRequest) ProtoMessage() {}
+
+func (x *StartFaceMatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartFaceMatchRequest.ProtoReflect.Descriptor instead.
+func (*StartFaceMatchRequest) Descriptor() ([]byte, []int) {
+	return file_ekyc_v1_ekyc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StartFaceMatchRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *StartFaceMatchRequest) GetKtpImage() *ImagePayload {
+	if x != nil {
+		return x.KtpImage
+	}
+	return nil
+}
+
+func (x *StartFaceMatchRequest) GetSelfieImage() *ImagePayload {
+	if x != nil {
+		return x.SelfieImage
+	}
+	return nil
+}
+
+func (x *StartFaceMatchRequest) GetFaceMatchThreshold() float64 {
+	if x != nil {
+		return x.FaceMatchThreshold
+	}
+	return 0
+}
+
+type StartFaceMatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *FaceMatchJobHandle    `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartFaceMatchResponse) Reset() {
+	*x = StartFaceMatchResponse{}
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartFaceMatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartFaceMatchResponse) ProtoMessage() {}
+
+func (x *StartFaceMatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartFaceMatchResponse.ProtoReflect.Descriptor instead.
+func (*StartFaceMatchResponse) Descriptor() ([]byte, []int) {
+	return file_ekyc_v1_ekyc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StartFaceMatchResponse) GetJob() *FaceMatchJobHandle {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type StartLivenessRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	SessionId      string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	LivenessFrames []*ImagePayload        `protobuf:"bytes,2,rep,name=liveness_frames,json=livenessFrames,proto3" json:"liveness_frames,omitempty"`
+	Gestures       []string               `protobuf:"bytes,3,rep,name=gestures,proto3" json:"gestures,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StartLivenessRequest) Reset() {
+	*x = StartLivenessRequest{}
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartLivenessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartLivenessRequest) ProtoMessage() {}
+
+func (x *StartLivenessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartLivenessRequest.ProtoReflect.Descriptor instead.
+func (*StartLivenessRequest) Descriptor() ([]byte, []int) {
+	return file_ekyc_v1_ekyc_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StartLivenessRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *StartLivenessRequest) GetLivenessFrames() []*ImagePayload {
+	if x != nil {
+		return x.LivenessFrames
+	}
+	return nil
+}
+
+func (x *StartLivenessRequest) GetGestures() []string {
+	if x != nil {
+		return x.Gestures
+	}
+	return nil
+}
+
+type StartLivenessResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Job           *LivenessJobHandle     `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartLivenessResponse) Reset() {
+	*x = StartLivenessResponse{}
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartLivenessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartLivenessResponse) ProtoMessage() {}
+
+func (x *StartLivenessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ekyc_v1_ekyc_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartLivenessResponse.ProtoReflect.Descriptor instead.
+func (*StartLivenessResponse) Descriptor() ([]byte, []int) {
+	return file_ekyc_v1_ekyc_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StartLivenessResponse) GetJob() *LivenessJobHandle {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+var File_ekyc_v1_ekyc_proto protoreflect.FileDescriptor
+
+const file_ekyc_v1_ekyc_proto_rawDesc = "" +
+	"\n" +
+	"\x12ekyc/v1/ekyc.proto\x12\aekyc.v1\"E\n" +
+	"\fImagePayload\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\fR\acontent\x12\x1b\n" +
+	"\tmime_type\x18\x02 \x01(\tR\bmimeType\"A\n" +
+	"\x12FaceMatchJobHandle\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x14\n" +
+	"\x05queue\x18\x02 \x01(\tR\x05queue\"@\n" +
+	"\x11LivenessJobHandle\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x14\n" +
+	"\x05queue\x18\x02 \x01(\tR\x05queue\"\xd6\x01\n" +
+	"\x15StartFaceMatchRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x122\n" +
+	"\tktp_image\x18\x02 \x01(\v2\x15.ekyc.v1.ImagePayloadR\bktpImage\x128\n" +
+	"\fselfie_image\x18\x03 \x01(\v2\x15.ekyc.v1.ImagePayloadR\vselfieImage\x120\n" +
+	"\x14face_match_threshold\x18\x04 \x01(\x01R\x12faceMatchThreshold\"G\n" +
+	"\x16StartFaceMatchResponse\x12-\n" +
+	"\x03job\x18\x01 \x01(\v2\x1b.ekyc.v1.FaceMatchJobHandleR\x03job\"\x91\x01\n" +
+	"\x14StartLivenessRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12>\n" +
+	"\x0fliveness_frames\x18\x02 \x03(\v2\x15.ekyc.v1.ImagePayloadR\x0elivenessFrames\x12\x1a\n" +
+	"\bgestures\x18\x03 \x03(\tR\bgestures\"E\n" +
+	"\x15StartLivenessResponse\x12,\n" +
+	"\x03job\x18\x01 \x01(\v2\x1a.ekyc.v1.LivenessJobHandleR\x03job2\xbd\x01\n" +
+	"\x12EkycSupportService\x12T\n" +
+	"\x11StartFaceMatchJob\x12\x1e.ekyc.v1.StartFaceMatchRequest\x1a\x1f.ekyc.v1.StartFaceMatchResponse\x12Q\n" +
+	"\x10StartLivenessJob\x12\x1d.ekyc.v1.StartLivenessRequest\x1a\x1e.ekyc.v1.StartLivenessResponseB#Z!e-kyc/shared/proto/ekyc/v1;ekycv1b\x06proto3"
+
+var (
+	file_ekyc_v1_ekyc_proto_rawDescOnce sync.Once
+	file_ekyc_v1_ekyc_proto_rawDescData []byte
+)
+
+func file_ekyc_v1_ekyc_proto_rawDescGZIP() []byte {
+	file_ekyc_v1_ekyc_proto_rawDescOnce.Do(func() {
+		file_ekyc_v1_ekyc_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ekyc_v1_ekyc_proto_rawDesc), len(file_ekyc_v1_ekyc_proto_rawDesc)))
+	})
+	return file_ekyc_v1_ekyc_proto_rawDescData
+}
+
+var file_ekyc_v1_ekyc_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_ekyc_v1_ekyc_proto_goTypes = []any{
+	(*ImagePayload)(nil),           // 0: ekyc.v1.ImagePayload
+	(*FaceMatchJobHandle)(nil),     // 1: ekyc.v1.FaceMatchJobHandle
+	(*LivenessJobHandle)(nil),      // 2: ekyc.v1.LivenessJobHandle
+	(*StartFaceMatchRequest)(nil),  // 3: ekyc.v1.StartFaceMatchRequest
+	(*StartFaceMatchResponse)(nil), // 4: ekyc.v1.StartFaceMatchResponse
+	(*StartLivenessRequest)(nil),   // 5: ekyc.v1.StartLivenessRequest
+	(*StartLivenessResponse)(nil),  // 6: ekyc.v1.StartLivenessResponse
+}
+var file_ekyc_v1_ekyc_proto_depIdxs = []int32{
+	0, // 0: ekyc.v1.StartFaceMatchRequest.ktp_image:type_name -> ekyc.v1.ImagePayload
+	0, // 1: ekyc.v1.StartFaceMatchRequest.selfie_image:type_name -> ekyc.v1.ImagePayload
+	1, // 2: ekyc.v1.StartFaceMatchResponse.job:type_name -> ekyc.v1.FaceMatchJobHandle
+	0, // 3: ekyc.v1.StartLivenessRequest.liveness_frames:type_name -> ekyc.v1.ImagePayload
+	2, // 4: ekyc.v1.StartLivenessResponse.job:type_name -> ekyc.v1.LivenessJobHandle
+	3, // 5: ekyc.v1.EkycSupportService.StartFaceMatchJob:input_type -> ekyc.v1.StartFaceMatchRequest
+	5, // 6: ekyc.v1.EkycSupportService.StartLivenessJob:input_type -> ekyc.v1.StartLivenessRequest
+	4, // 7: ekyc.v1.EkycSupportService.StartFaceMatchJob:output_type -> ekyc.v1.StartFaceMatchResponse
+	6, // 8: ekyc.v1.EkycSupportService.StartLivenessJob:output_type -> ekyc.v1.StartLivenessResponse
+	7, // [7:9] is the sub-list for method output_type
+	5, // [5:7] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_ekyc_v1_ekyc_proto_init() }
+func file_ekyc_v1_ekyc_proto_init() {
+	if File_ekyc_v1_ekyc_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ekyc_v1_ekyc_proto_rawDesc), len(file_ekyc_v1_ekyc_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ekyc_v1_ekyc_proto_goTypes,
+		DependencyIndexes: file_ekyc_v1_ekyc_proto_depIdxs,
+		MessageInfos:      file_ekyc_v1_ekyc_proto_msgTypes,
+	}.Build()
+	File_ekyc_v1_ekyc_proto = out.File
+	file_ekyc_v1_ekyc_proto_goTypes = nil
+	file_ekyc_v1_ekyc_proto_depIdxs = nil
+}
@@ -0,0 +1,159 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: ekyc/v1/ekyc.proto
+
+package ekycv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	EkycSupportService_StartFaceMatchJob_FullMethodName = "/ekyc.v1.EkycSupportService/StartFaceMatchJob"
+	EkycSupportService_StartLivenessJob_FullMethodName  = "/ekyc.v1.EkycSupportService/StartLivenessJob"
+)
+
+// EkycSupportServiceClient is the client API for EkycSupportService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EkycSupportServiceClient interface {
+	StartFaceMatchJob(ctx context.Context, in *StartFaceMatchRequest, opts ...grpc.CallOption) (*StartFaceMatchResponse, error)
+	StartLivenessJob(ctx context.Context, in *StartLivenessRequest, opts ...grpc.CallOption) (*StartLivenessResponse, error)
+}
+
+type ekycSupportServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEkycSupportServiceClient(cc grpc.ClientConnInterface) EkycSupportServiceClient {
+	return &ekycSupportServiceClient{cc}
+}
+
+func (c *ekycSupportServiceClient) StartFaceMatchJob(ctx context.Context, in *StartFaceMatchRequest, opts ...grpc.CallOption) (*StartFaceMatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartFaceMatchResponse)
+	err := c.cc.Invoke(ctx, EkycSupportService_StartFaceMatchJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ekycSupportServiceClient) StartLivenessJob(ctx context.Context, in *StartLivenessRequest, opts ...grpc.CallOption) (*StartLivenessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StartLivenessResponse)
+	err := c.cc.Invoke(ctx, EkycSupportService_StartLivenessJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EkycSupportServiceServer is the server API for EkycSupportService service.
+// All implementations must embed UnimplementedEkycSupportServiceServer
+// for forward compatibility.
+type EkycSupportServiceServer interface {
+	StartFaceMatchJob(context.Context, *StartFaceMatchRequest) (*StartFaceMatchResponse, error)
+	StartLivenessJob(context.Context, *StartLivenessRequest) (*StartLivenessResponse, error)
+	mustEmbedUnimplementedEkycSupportServiceServer()
+}
+
+// UnimplementedEkycSupportServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedEkycSupportServiceServer struct{}
+
+func (UnimplementedEkycSupportServiceServer) StartFaceMatchJob(context.Context, *StartFaceMatchRequest) (*StartFaceMatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartFaceMatchJob not implemented")
+}
+func (UnimplementedEkycSupportServiceServer) StartLivenessJob(context.Context, *StartLivenessRequest) (*StartLivenessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartLivenessJob not implemented")
+}
+func (UnimplementedEkycSupportServiceServer) mustEmbedUnimplementedEkycSupportServiceServer() {}
+func (UnimplementedEkycSupportServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeEkycSupportServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EkycSupportServiceServer will
+// result in compilation errors.
+type UnsafeEkycSupportServiceServer interface {
+	mustEmbedUnimplementedEkycSupportServiceServer()
+}
+
+func RegisterEkycSupportServiceServer(s grpc.ServiceRegistrar, srv EkycSupportServiceServer) {
+	// If the following call pancis, it indicates UnimplementedEkycSupportServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&EkycSupportService_ServiceDesc, srv)
+}
+
+func _EkycSupportService_StartFaceMatchJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartFaceMatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EkycSupportServiceServer).StartFaceMatchJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EkycSupportService_StartFaceMatchJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EkycSupportServiceServer).StartFaceMatchJob(ctx, req.(*StartFaceMatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EkycSupportService_StartLivenessJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartLivenessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EkycSupportServiceServer).StartLivenessJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EkycSupportService_StartLivenessJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EkycSupportServiceServer).StartLivenessJob(ctx, req.(*StartLivenessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// EkycSupportService_ServiceDesc is the grpc.ServiceDesc for EkycSupportService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var EkycSupportService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ekyc.v1.EkycSupportService",
+	HandlerType: (*EkycSupportServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartFaceMatchJob",
+			Handler:    _EkycSupportService_StartFaceMatchJob_Handler,
+		},
+		{
+			MethodName: "StartLivenessJob",
+			Handler:    _EkycSupportService_StartLivenessJob_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ekyc/v1/ekyc.proto",
+}
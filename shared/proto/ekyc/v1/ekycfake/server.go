@@ -0,0 +1,189 @@
+/*
+Package ekycfake provides a scriptable in-process EkycSupportService so callers of the
+AI support contract can be exercised without the Python service or a GPU.
+*/
+package ekycfake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	ekycv1 "e-kyc/shared/proto/ekyc/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	FaceMatchQueue = "ai.face_match.jobs"
+	LivenessQueue  = "ai.liveness.jobs"
+	bufferSize     = 1 << 20
+)
+
+type FaceMatchFunc func(ctx context.Context, req *ekycv1.StartFaceMatchRequest) (*ekycv1.StartFaceMatchResponse, error)
+type LivenessFunc func(ctx context.Context, req *ekycv1.StartLivenessRequest) (*ekycv1.StartLivenessResponse, error)
+
+// Server records every request it receives and answers with the scripted handlers.
+// Without a script it accepts the job and returns a sequential job id.
+type Server struct {
+	ekycv1.UnimplementedEkycSupportServiceServer
+
+	mu                sync.Mutex
+	faceMatch         FaceMatchFunc
+	liveness          LivenessFunc
+	faceMatchRequests []*ekycv1.StartFaceMatchRequest
+	livenessRequests  []*ekycv1.StartLivenessRequest
+	seq               int
+
+	grpcServer *grpc.Server
+	listener   *bufconn.Listener
+}
+
+func NewServer() *Server {
+	return &Server{}
+}
+
+// OnFaceMatch replaces the StartFaceMatchJob behaviour. Pass nil to restore the default.
+func (s *Server) OnFaceMatch(fn FaceMatchFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faceMatch = fn
+}
+
+// OnLiveness replaces the StartLivenessJob behaviour. Pass nil to restore the default.
+func (s *Server) OnLiveness(fn LivenessFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.liveness = fn
+}
+
+// FailWith makes both RPCs return err until the handlers are replaced again.
+func (s *Server) FailWith(err error) {
+	s.OnFaceMatch(func(context.Context, *ekycv1.StartFaceMatchRequest) (*ekycv1.StartFaceMatchResponse, error) {
+		return nil, err
+	})
+	s.OnLiveness(func(context.Context, *ekycv1.StartLivenessRequest) (*ekycv1.StartLivenessResponse, error) {
+		return nil, err
+	})
+}
+
+func (s *Server) FaceMatchRequests() []*ekycv1.StartFaceMatchRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*ekycv1.StartFaceMatchRequest(nil), s.faceMatchRequests...)
+}
+
+func (s *Server) LivenessRequests() []*ekycv1.StartLivenessRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*ekycv1.StartLivenessRequest(nil), s.livenessRequests...)
+}
+
+// Reset drops recorded requests and scripted handlers.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faceMatch = nil
+	s.liveness = nil
+	s.faceMatchRequests = nil
+	s.livenessRequests = nil
+	s.seq = 0
+}
+
+func (s *Server) StartFaceMatchJob(ctx context.Context, req *ekycv1.StartFaceMatchRequest) (*ekycv1.StartFaceMatchResponse, error) {
+	s.mu.Lock()
+	s.faceMatchRequests = append(s.faceMatchRequests, req)
+	fn := s.faceMatch
+	s.seq++
+	jobID := fmt.Sprintf("fake-face-match-%d", s.seq)
+	s.mu.Unlock()
+
+	if fn != nil {
+		return fn(ctx, req)
+	}
+	return &ekycv1.StartFaceMatchResponse{
+		Job: &ekycv1.FaceMatchJobHandle{JobId: jobID, Queue: FaceMatchQueue},
+	}, nil
+}
+
+func (s *Server) StartLivenessJob(ctx context.Context, req *ekycv1.StartLivenessRequest) (*ekycv1.StartLivenessResponse, error) {
+	s.mu.Lock()
+	s.livenessRequests = append(s.livenessRequests, req)
+	fn := s.liveness
+	s.seq++
+	jobID := fmt.Sprintf("fake-liveness-%d", s.seq)
+	s.mu.Unlock()
+
+	if fn != nil {
+		return fn(ctx, req)
+	}
+	return &ekycv1.StartLivenessResponse{
+		Job: &ekycv1.LivenessJobHandle{JobId: jobID, Queue: LivenessQueue},
+	}, nil
+}
+
+// Start serves the fake over an in-memory listener; use Dial to connect to it.
+func (s *Server) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.grpcServer != nil {
+		return
+	}
+	s.listener = bufconn.Listen(bufferSize)
+	s.grpcServer = grpc.NewServer()
+	ekycv1.RegisterEkycSupportServiceServer(s.grpcServer, s)
+	go func(srv *grpc.Server, lis net.Listener) {
+		_ = srv.Serve(lis)
+	}(s.grpcServer, s.listener)
+}
+
+// Dial returns a client connection to the in-memory listener started by Start.
+func (s *Server) Dial(ctx context.Context) (*grpc.ClientConn, error) {
+	s.mu.Lock()
+	lis := s.listener
+	s.mu.Unlock()
+	if lis == nil {
+		return nil, errors.New("ekycfake: server not started")
+	}
+	return grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+}
+
+// ListenAndServe serves the fake on a real TCP address, for running it next to other
+// services during local development. It blocks until Stop is called.
+func (s *Server) ListenAndServe(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if s.grpcServer != nil {
+		s.mu.Unlock()
+		lis.Close()
+		return errors.New("ekycfake: server already running")
+	}
+	s.grpcServer = grpc.NewServer()
+	ekycv1.RegisterEkycSupportServiceServer(s.grpcServer, s)
+	srv := s.grpcServer
+	s.mu.Unlock()
+	return srv.Serve(lis)
+}
+
+func (s *Server) Stop() {
+	s.mu.Lock()
+	srv := s.grpcServer
+	s.grpcServer = nil
+	s.listener = nil
+	s.mu.Unlock()
+	if srv != nil {
+		srv.Stop()
+	}
+}
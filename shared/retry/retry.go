@@ -25,13 +25,26 @@ func DefaultConfig() Config {
 	}
 }
 
+// Delay returns how long to wait before retry number attempt (1-based): InitialWait,
+// doubled per attempt and capped at MaxWait.
+func (cfg Config) Delay(attempt int) time.Duration {
+	wait := cfg.InitialWait
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if wait >= cfg.MaxWait {
+			return cfg.MaxWait
+		}
+	}
+	return wait
+}
+
 // WithBackoff executes the given operation with exponential backoff retry logic
 func WithBackoff(ctx context.Context, cfg Config, operation func() error) error {
 	var err error
-	wait := cfg.InitialWait
 
 	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
 		if attempt > 0 {
+			wait := cfg.Delay(attempt)
 			log.Printf("Retry attempt %d/%d after %v", attempt, cfg.MaxRetries, wait)
 
 			select {
@@ -39,12 +52,6 @@ func WithBackoff(ctx context.Context, cfg Config, operation func() error) error
 				return ctx.Err()
 			case <-time.After(wait):
 			}
-
-			// Exponential backoff with max wait cap
-			wait *= 2
-			if wait > cfg.MaxWait {
-				wait = cfg.MaxWait
-			}
 		}
 
 		if err = operation(); err == nil {